@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	controllerNamespace string
+	controllerSelector  string
+	controllerLease     string
+	controllerPort      int
+)
+
+// controllerGet fetches a path from the metrics server of the running svclink controller, proxied through the
+// local API server so that no port-forward is required. The pods holding the leader election Lease are queried,
+// one per shard, and every running pod when no pod holds it. The response of each queried pod is returned.
+func controllerGet(ctx context.Context, path string) ([][]byte, error) {
+	restConfig, err := buildRestConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build REST config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	pods, err := clientset.CoreV1().Pods(controllerNamespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list svclink pods: %w", err)
+	}
+	var leases []coordinationv1.Lease
+	leaseList, err := clientset.CoordinationV1().Leases(controllerNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to list leases in namespace %s, querying every svclink pod: %v\n",
+			controllerNamespace, err)
+	} else {
+		leases = leaseList.Items
+	}
+
+	targets := controllerTargets(pods.Items, leases, controllerLease)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no running svclink pod matches %q in namespace %s", controllerSelector, controllerNamespace)
+	}
+	port := strconv.Itoa(controllerPort)
+	responses := make([][]byte, 0, len(targets))
+	var errs []error
+	for _, pod := range targets {
+		data, err := clientset.CoreV1().Pods(pod.Namespace).ProxyGet("http", pod.Name, port, path, nil).DoRaw(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to query svclink pod %s: %w", pod.Name, err))
+			continue
+		}
+		responses = append(responses, data)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return responses, nil
+}
+
+// controllerTargets returns the running pods holding the leader election Lease, or the Lease of a shard, and
+// every running pod when none of them holds one, e.g. when leader election is disabled
+func controllerTargets(pods []corev1.Pod, leases []coordinationv1.Lease, lease string) []corev1.Pod {
+	holders := sets.New[string]()
+	for _, l := range leases {
+		if !isControllerLease(l.Name, lease) || l.Spec.HolderIdentity == nil {
+			continue
+		}
+		// The holder is identified by the hostname of the leader, its pod name, followed by a UID
+		if holder, _, _ := strings.Cut(*l.Spec.HolderIdentity, "_"); holder != "" {
+			holders.Insert(holder)
+		}
+	}
+
+	var running, leaders []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		running = append(running, pod)
+		if holders.Has(pod.Name) {
+			leaders = append(leaders, pod)
+		}
+	}
+	if len(leaders) > 0 {
+		return leaders
+	}
+	return running
+}
+
+// isControllerLease reports whether a Lease is the leader election Lease of the controller, or the Lease of one
+// of its shards named after the shard index
+func isControllerLease(name, lease string) bool {
+	if name == lease {
+		return true
+	}
+	shard, ok := strings.CutPrefix(name, lease+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(shard)
+	return err == nil
+}
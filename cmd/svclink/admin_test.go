@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+func TestControllerTargets(t *testing.T) {
+	pod := func(name, ip string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: name}, Status: corev1.PodStatus{PodIP: ip}}
+	}
+	lease := func(name, holder string) coordinationv1.Lease {
+		return coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: coordinationv1.LeaseSpec{HolderIdentity: ptr.To(holder)}}
+	}
+	pods := []corev1.Pod{pod("svclink-0", "10.0.0.1"), pod("svclink-1", "10.0.0.2"), pod("svclink-2", ""), pod("svclink-3", "10.0.0.4")}
+
+	tests := []struct {
+		name   string
+		leases []coordinationv1.Lease
+		want   []string
+	}{
+		{name: "leader", leases: []coordinationv1.Lease{lease("svclink-leader", "svclink-1_3f2a")}, want: []string{"svclink-1"}},
+		{
+			name:   "leader of each shard",
+			leases: []coordinationv1.Lease{lease("svclink-leader-0", "svclink-0_9c1d"), lease("svclink-leader-1", "svclink-3_77be")},
+			want:   []string{"svclink-0", "svclink-3"},
+		},
+		{
+			name:   "other leases",
+			leases: []coordinationv1.Lease{lease("svclink-leader-east", "svclink-1_3f2a"), lease("other", "svclink-0_9c1d")},
+			want:   []string{"svclink-0", "svclink-1", "svclink-3"},
+		},
+		{name: "released lease", leases: []coordinationv1.Lease{lease("svclink-leader", "")}, want: []string{"svclink-0", "svclink-1", "svclink-3"}},
+		{name: "holder not running", leases: []coordinationv1.Lease{lease("svclink-leader", "svclink-2_5e0c")}, want: []string{"svclink-0", "svclink-1", "svclink-3"}},
+		{name: "leader election disabled", want: []string{"svclink-0", "svclink-1", "svclink-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, pod := range controllerTargets(pods, tt.leases, "svclink-leader") {
+				got = append(got, pod.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("controllerTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeReports(t *testing.T) {
+	encode := func(v any) []byte {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	filtered, err := mergeFilteredReports([][]byte{
+		encode(apisdiscoverer.FilteredReport{Clusters: map[string]*apisdiscoverer.FilteredCluster{"east": {}}}),
+		encode(apisdiscoverer.FilteredReport{Clusters: map[string]*apisdiscoverer.FilteredCluster{"west": {}}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered.Clusters) != 2 || filtered.Clusters["east"] == nil || filtered.Clusters["west"] == nil {
+		t.Errorf("expected the clusters of both shards, got %v", filtered.Clusters)
+	}
+
+	orphans, err := mergeOrphanReports([][]byte{
+		encode(apisdiscoverer.OrphanReport{Services: []apisdiscoverer.OrphanedService{{Namespace: "payments", Name: "ledger"}}}),
+		encode(apisdiscoverer.OrphanReport{Services: []apisdiscoverer.OrphanedService{
+			{Namespace: "orders", Name: "api"},
+			{Namespace: "payments", Name: "ledger"},
+		}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, orphan := range orphans.Services {
+		got = append(got, orphan.Namespace+"/"+orphan.Name)
+	}
+	if want := []string{"orders/api", "payments/ledger"}; !slices.Equal(got, want) {
+		t.Errorf("merged orphans = %v, want %v", got, want)
+	}
+
+	if _, err := mergeOrphanReports([][]byte{[]byte("not json")}); err == nil {
+		t.Error("expected an error for a malformed report")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+var (
+	chaosNamespace string
+	chaosDuration  time.Duration
+)
+
+// newChaosCommand creates the "chaos" command group for rehearsing cross-cluster failures
+func newChaosCommand() *cobra.Command {
+	chaosCmd := &cobra.Command{
+		Use:   "chaos",
+		Short: "Inject faults to rehearse cross-cluster failover",
+	}
+	chaosCmd.PersistentFlags().StringVarP(&chaosNamespace, "namespace", "n", "", "Namespace of the ClusterLink, required when several namespaces have a ClusterLink of the same name")
+
+	disconnectCmd := &cobra.Command{
+		Use:   "disconnect <cluster>",
+		Short: "Make the controller treat a cluster as unreachable",
+		Long: `Make the controller treat a linked cluster as unreachable for a while, without touching the network,
+to rehearse how services fail over to the other clusters. The cluster reconnects by itself after --for,
+or earlier with "svclink chaos reconnect".`,
+		Example: "  svclink chaos disconnect prod-us --for 5m",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runChaosDisconnect,
+	}
+	disconnectCmd.Flags().DurationVar(&chaosDuration, "for", 5*time.Minute, "How long the cluster is treated as unreachable")
+
+	chaosCmd.AddCommand(disconnectCmd, &cobra.Command{
+		Use:   "reconnect <cluster>",
+		Short: "End the simulated disconnect of a cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runChaosReconnect,
+	})
+	return chaosCmd
+}
+
+func runChaosDisconnect(cmd *cobra.Command, args []string) error {
+	if chaosDuration <= 0 {
+		return fmt.Errorf("--for must be positive")
+	}
+	until := time.Now().Add(chaosDuration)
+	if err := patchClusterLink(cmd, chaosNamespace, args[0], func(clusterLink *svclinkv1alpha1.ClusterLink) {
+		config.SimulateDisconnect(clusterLink, until)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Cluster %s is treated as unreachable until %s\n", args[0], until.Format(time.RFC3339))
+	return nil
+}
+
+func runChaosReconnect(cmd *cobra.Command, args []string) error {
+	if err := patchClusterLink(cmd, chaosNamespace, args[0], func(clusterLink *svclinkv1alpha1.ClusterLink) {
+		config.EndSimulatedDisconnect(clusterLink)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Cluster %s reconnects on the next sync\n", args[0])
+	return nil
+}
+
+// patchClusterLink applies mutate to the ClusterLink of the cluster with a merge patch. An empty namespace looks
+// the ClusterLink up in all namespaces.
+func patchClusterLink(cmd *cobra.Command, namespace, cluster string, mutate func(*svclinkv1alpha1.ClusterLink)) error {
+	kubeClient, err := newCLIClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+
+	var cks svclinkv1alpha1.ClusterLinkList
+	if err := kubeClient.List(ctx, &cks, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list ClusterLinks: %w", err)
+	}
+	var clusterLink *svclinkv1alpha1.ClusterLink
+	for i := range cks.Items {
+		if cks.Items[i].Name != cluster {
+			continue
+		}
+		if clusterLink != nil {
+			return fmt.Errorf("several namespaces have a ClusterLink %s, select one with --namespace", cluster)
+		}
+		clusterLink = &cks.Items[i]
+	}
+	if clusterLink == nil {
+		return fmt.Errorf("ClusterLink %s not found", cluster)
+	}
+
+	original := clusterLink.DeepCopy()
+	mutate(clusterLink)
+	if err := kubeClient.Patch(ctx, clusterLink, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("failed to patch ClusterLink %s/%s: %w", clusterLink.Namespace, clusterLink.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// newClustersCommand creates the "clusters" command group for inspecting linked clusters
+func newClustersCommand() *cobra.Command {
+	clustersCmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "Inspect clusters linked through ClusterLinks",
+	}
+
+	clustersCmd.AddCommand(&cobra.Command{
+		Use:   "capabilities",
+		Short: "Show the version and API support of each linked cluster",
+		RunE:  runClustersCapabilities,
+	})
+
+	return clustersCmd
+}
+
+func runClustersCapabilities(cmd *cobra.Command, args []string) error {
+	kubeClient, err := newCLIClient()
+	if err != nil {
+		return err
+	}
+	links := clusterlink.NewLinks(kubeClient, &config.Config{ExecPluginDir: execPluginDir, ExecPlugins: execPlugins})
+
+	var cks svclinkv1alpha1.ClusterLinkList
+	if err := kubeClient.List(cmd.Context(), &cks); err != nil {
+		return fmt.Errorf("failed to list ClusterLinks: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tVERSION\tENDPOINTSLICE/V1\tSELFSUBJECTACCESSREVIEW\tERROR")
+	for i := range cks.Items {
+		clusterLink := &cks.Items[i]
+		capabilities, err := links.ClusterCapabilities(cmd.Context(), clusterLink)
+		if err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t%v\n", clusterLink.Name, err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\t\n", clusterLink.Name, capabilities.Version,
+			capabilities.EndpointSliceV1, capabilities.SelfSubjectAccessReview)
+	}
+	return w.Flush()
+}
+
+// newCLIClient creates a controller-runtime client for the local cluster used by CLI commands
+func newCLIClient() (client.Client, error) {
+	restConfig, err := buildRestConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build REST config: %w", err)
+	}
+
+	runtimeScheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(runtimeScheme); err != nil {
+		return nil, fmt.Errorf("failed to add core scheme: %w", err)
+	}
+	if err := svclinkv1alpha1.AddToScheme(runtimeScheme); err != nil {
+		return nil, fmt.Errorf("failed to add svclink scheme: %w", err)
+	}
+
+	return client.New(restConfig, client.Options{Scheme: runtimeScheme})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/discoverer"
+)
+
+var filteredCluster string
+
+// newFilteredCommand creates the "filtered" command listing services suppressed by filters
+func newFilteredCommand() *cobra.Command {
+	filteredCmd := &cobra.Command{
+		Use:   "filtered",
+		Short: "Show remote services and namespaces that are not synced because of a filter",
+		RunE:  runFiltered,
+	}
+	filteredCmd.Flags().StringVar(&filteredCluster, "cluster", "", "Only show the given cluster")
+	return filteredCmd
+}
+
+func runFiltered(cmd *cobra.Command, args []string) error {
+	responses, err := controllerGet(cmd.Context(), discoverer.FilteredPath)
+	if err != nil {
+		return err
+	}
+	report, err := mergeFilteredReports(responses)
+	if err != nil {
+		return err
+	}
+
+	clusters := make([]string, 0, len(report.Clusters))
+	for cluster := range report.Clusters {
+		if filteredCluster == "" || cluster == filteredCluster {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tREASON\tNAMESPACES\tSERVICES")
+	for _, cluster := range clusters {
+		record := report.Clusters[cluster]
+		reasons := make(map[apisdiscoverer.FilterReason]struct{})
+		for reason := range record.Namespaces {
+			reasons[reason] = struct{}{}
+		}
+		for reason := range record.Services {
+			reasons[reason] = struct{}{}
+		}
+		for _, reason := range sortedReasons(reasons) {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", cluster, reason, record.Namespaces[reason], record.Services[reason])
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tNAMESPACE\tSERVICE\tREASON")
+	for _, cluster := range clusters {
+		for _, entry := range report.Clusters[cluster].Sample {
+			name := entry.Name
+			if name == "" {
+				name = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cluster, entry.Namespace, name, entry.Reason)
+		}
+	}
+	return w.Flush()
+}
+
+// mergeFilteredReports merges the filtered reports of the queried controller pods. Shards report disjoint
+// clusters, a cluster reported by several pods is taken from the first one.
+func mergeFilteredReports(responses [][]byte) (*apisdiscoverer.FilteredReport, error) {
+	merged := &apisdiscoverer.FilteredReport{Clusters: make(map[string]*apisdiscoverer.FilteredCluster)}
+	for _, data := range responses {
+		var report apisdiscoverer.FilteredReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse filtered report: %w", err)
+		}
+		for cluster, record := range report.Clusters {
+			if _, ok := merged.Clusters[cluster]; !ok {
+				merged.Clusters[cluster] = record
+			}
+		}
+	}
+	return merged, nil
+}
+
+func sortedReasons(reasons map[apisdiscoverer.FilterReason]struct{}) []apisdiscoverer.FilterReason {
+	sorted := make([]apisdiscoverer.FilterReason, 0, len(reasons))
+	for reason := range reasons {
+		sorted = append(sorted, reason)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
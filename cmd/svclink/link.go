@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+var (
+	linkNamespace       string
+	linkSanitized       bool
+	linkCredentialsDir  string
+	linkFile            string
+	linkSecretNamespace string
+	linkOverwrite       bool
+	linkDryRun          bool
+)
+
+// newLinkCommand creates the "link" command group for moving ClusterLinks between hub clusters
+func newLinkCommand() *cobra.Command {
+	linkCmd := &cobra.Command{
+		Use:   "link",
+		Short: "Export and import ClusterLinks, e.g. to migrate to a new hub cluster",
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print ClusterLink manifests",
+		Long: `Print ClusterLink manifests of the local cluster.
+
+With --sanitized, the manifests carry no credentials: embedded kubeconfigs are replaced by a
+kubeconfigSecretRef to a Secret named <clusterlink>-kubeconfig. With --credentials-dir, the
+credentials of every ClusterLink are written to <dir>/<secret>/<key>, ready for "svclink link import".`,
+		RunE: runLinkExport,
+	}
+	exportCmd.Flags().StringVarP(&linkNamespace, "namespace", "n", "", "Only export ClusterLinks of this namespace")
+	exportCmd.Flags().BoolVar(&linkSanitized, "sanitized", false, "Externalize embedded kubeconfigs to Secret references")
+	exportCmd.Flags().StringVar(&linkCredentialsDir, "credentials-dir", "", "Directory the credentials are written to")
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Create ClusterLinks from manifests",
+		Long: `Create the ClusterLinks of manifests printed by "svclink link export".
+
+Secret references are re-linked against the credentials store of the local cluster: --secret-namespace
+moves every referenced Secret to a namespace, and --credentials-dir creates the referenced Secrets from
+<dir>/<secret>/<key> files. Referenced Secrets that do not exist are reported.`,
+		RunE: runLinkImport,
+	}
+	importCmd.Flags().StringVarP(&linkFile, "filename", "f", "-", "File with the ClusterLink manifests, - for stdin")
+	importCmd.Flags().StringVar(&linkSecretNamespace, "secret-namespace", "", "Namespace of the referenced Secrets in the local cluster")
+	importCmd.Flags().StringVar(&linkCredentialsDir, "credentials-dir", "", "Directory the referenced Secrets are created from")
+	importCmd.Flags().BoolVar(&linkOverwrite, "overwrite", false, "Replace the spec of ClusterLinks that already exist")
+	importCmd.Flags().BoolVar(&linkDryRun, "dry-run", false, "Only print what would be done")
+
+	linkCmd.AddCommand(exportCmd, importCmd)
+	return linkCmd
+}
+
+func runLinkExport(cmd *cobra.Command, args []string) error {
+	kubeClient, err := newCLIClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+
+	var cks svclinkv1alpha1.ClusterLinkList
+	if err := kubeClient.List(ctx, &cks, client.InNamespace(linkNamespace)); err != nil {
+		return fmt.Errorf("failed to list ClusterLinks: %w", err)
+	}
+
+	for i := range cks.Items {
+		clusterLink := exportedClusterLink(&cks.Items[i])
+
+		if linkCredentialsDir != "" {
+			if err := exportCredentials(ctx, kubeClient, &cks.Items[i], linkCredentialsDir); err != nil {
+				return fmt.Errorf("failed to export credentials of ClusterLink %s: %w", clusterLink.Name, err)
+			}
+		}
+		if linkSanitized {
+			sanitizeClusterLink(clusterLink)
+		}
+
+		data, err := yaml.Marshal(clusterLink)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "---\n%s", data)
+	}
+	return nil
+}
+
+// exportedClusterLink strips the status and server-populated metadata of a ClusterLink
+func exportedClusterLink(in *svclinkv1alpha1.ClusterLink) *svclinkv1alpha1.ClusterLink {
+	return &svclinkv1alpha1.ClusterLink{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: svclinkv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ClusterLink",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        in.Name,
+			Namespace:   in.Namespace,
+			Labels:      in.Labels,
+			Annotations: in.Annotations,
+		},
+		Spec: *in.Spec.DeepCopy(),
+	}
+}
+
+// sanitizeClusterLink replaces the embedded kubeconfig of a ClusterLink by a reference to a Secret
+func sanitizeClusterLink(clusterLink *svclinkv1alpha1.ClusterLink) {
+	if clusterLink.Spec.Kubeconfig == "" {
+		return
+	}
+	clusterLink.Spec.Kubeconfig = ""
+	clusterLink.Spec.KubeconfigSecretRef = &svclinkv1alpha1.SecretKeyReference{
+		Name: clusterLink.Name + "-kubeconfig",
+		Key:  clusterlink.KubeconfigSecretKey,
+	}
+}
+
+// exportCredentials writes the kubeconfig or token of a ClusterLink to <dir>/<secret>/<key>, using the
+// Secret the sanitized ClusterLink references
+func exportCredentials(ctx context.Context, kubeClient client.Client, clusterLink *svclinkv1alpha1.ClusterLink, dir string) error {
+	spec := clusterLink.Spec
+
+	var (
+		ref        *svclinkv1alpha1.SecretKeyReference
+		defaultKey string
+		value      []byte
+	)
+	switch {
+	case spec.Kubeconfig != "":
+		data, err := base64.StdEncoding.DecodeString(spec.Kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to decode kubeconfig: %w", err)
+		}
+		sanitized := exportedClusterLink(clusterLink)
+		sanitizeClusterLink(sanitized)
+		ref, defaultKey, value = sanitized.Spec.KubeconfigSecretRef, clusterlink.KubeconfigSecretKey, data
+	case spec.KubeconfigSecretRef != nil:
+		ref, defaultKey = spec.KubeconfigSecretRef, clusterlink.KubeconfigSecretKey
+	case spec.ServiceAccountTokenSecretRef != nil:
+		ref, defaultKey = spec.ServiceAccountTokenSecretRef, corev1.ServiceAccountTokenKey
+	default:
+		return nil
+	}
+
+	namespace, key := clusterlink.SecretKeyRef(clusterLink, ref, defaultKey)
+	if value == nil {
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			return fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+		}
+		value = secret.Data[key]
+	}
+
+	path := filepath.Join(dir, ref.Name, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, value, 0o600)
+}
+
+func runLinkImport(cmd *cobra.Command, args []string) error {
+	var reader io.Reader = os.Stdin
+	if linkFile != "-" {
+		file, err := os.Open(linkFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	clusterLinks, err := readClusterLinks(reader)
+	if err != nil {
+		return err
+	}
+
+	kubeClient, err := newCLIClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+
+	var errs []error
+	for _, clusterLink := range clusterLinks {
+		if err := importClusterLink(ctx, kubeClient, clusterLink); err != nil {
+			errs = append(errs, fmt.Errorf("ClusterLink %s/%s: %w", clusterLink.Namespace, clusterLink.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readClusterLinks decodes the ClusterLinks of a multi-document YAML stream
+func readClusterLinks(reader io.Reader) ([]*svclinkv1alpha1.ClusterLink, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bufio.NewReader(reader), 4096)
+
+	var clusterLinks []*svclinkv1alpha1.ClusterLink
+	for {
+		clusterLink := &svclinkv1alpha1.ClusterLink{}
+		if err := decoder.Decode(clusterLink); err != nil {
+			if errors.Is(err, io.EOF) {
+				return clusterLinks, nil
+			}
+			return nil, fmt.Errorf("failed to decode ClusterLink manifests: %w", err)
+		}
+		if clusterLink.Kind != "ClusterLink" {
+			continue
+		}
+		clusterLinks = append(clusterLinks, clusterLink)
+	}
+}
+
+// importClusterLink re-links the Secret references of a ClusterLink against the local credentials store and creates it
+func importClusterLink(ctx context.Context, kubeClient client.Client, clusterLink *svclinkv1alpha1.ClusterLink) error {
+	refs := map[*svclinkv1alpha1.SecretKeyReference]string{}
+	if ref := clusterLink.Spec.KubeconfigSecretRef; ref != nil {
+		refs[ref] = clusterlink.KubeconfigSecretKey
+	}
+	if ref := clusterLink.Spec.ServiceAccountTokenSecretRef; ref != nil {
+		refs[ref] = corev1.ServiceAccountTokenKey
+	}
+
+	for ref, defaultKey := range refs {
+		if linkSecretNamespace != "" {
+			ref.Namespace = linkSecretNamespace
+		}
+		if err := ensureSecret(ctx, kubeClient, clusterLink, ref, defaultKey); err != nil {
+			return err
+		}
+	}
+
+	if linkDryRun {
+		fmt.Printf("clusterlink %s/%s would be imported\n", clusterLink.Namespace, clusterLink.Name)
+		return nil
+	}
+
+	err := kubeClient.Create(ctx, clusterLink)
+	if err == nil {
+		fmt.Printf("clusterlink %s/%s imported\n", clusterLink.Namespace, clusterLink.Name)
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if !linkOverwrite {
+		fmt.Printf("clusterlink %s/%s already exists, skipped\n", clusterLink.Namespace, clusterLink.Name)
+		return nil
+	}
+
+	existing := &svclinkv1alpha1.ClusterLink{}
+	if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(clusterLink), existing); err != nil {
+		return err
+	}
+	existing.Labels = clusterLink.Labels
+	existing.Annotations = clusterLink.Annotations
+	existing.Spec = clusterLink.Spec
+	if err := kubeClient.Update(ctx, existing); err != nil {
+		return err
+	}
+	fmt.Printf("clusterlink %s/%s updated\n", clusterLink.Namespace, clusterLink.Name)
+	return nil
+}
+
+// ensureSecret creates or updates a referenced Secret from --credentials-dir, or checks that it exists
+func ensureSecret(ctx context.Context, kubeClient client.Client, clusterLink *svclinkv1alpha1.ClusterLink, ref *svclinkv1alpha1.SecretKeyReference, defaultKey string) error {
+	namespace, key := clusterlink.SecretKeyRef(clusterLink, ref, defaultKey)
+
+	secret := &corev1.Secret{}
+	err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	var value []byte
+	if linkCredentialsDir != "" {
+		value, err = os.ReadFile(filepath.Join(linkCredentialsDir, ref.Name, key))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if value == nil {
+		if !exists {
+			fmt.Fprintf(os.Stderr, "warning: secret %s/%s referenced by clusterlink %s/%s does not exist\n",
+				namespace, ref.Name, clusterLink.Namespace, clusterLink.Name)
+		}
+		return nil
+	}
+
+	if linkDryRun {
+		fmt.Printf("secret %s/%s would be written\n", namespace, ref.Name)
+		return nil
+	}
+
+	if !exists {
+		secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: ref.Name}}
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte, 1)
+	}
+	secret.Data[key] = value
+
+	if exists {
+		err = kubeClient.Update(ctx, secret)
+	} else {
+		err = kubeClient.Create(ctx, secret)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	fmt.Printf("secret %s/%s written\n", namespace, ref.Name)
+	return nil
+}
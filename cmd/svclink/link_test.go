@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// linkClient finds no objects and records the objects created
+type linkClient struct {
+	client.Client
+	created []client.Object
+}
+
+func (c *linkClient) Get(_ context.Context, key client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+	return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+}
+
+func (c *linkClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.created = append(c.created, obj)
+	return nil
+}
+
+func TestLinkExportImport(t *testing.T) {
+	t.Cleanup(func() {
+		linkCredentialsDir, linkSecretNamespace = "", ""
+	})
+	dir := t.TempDir()
+	clusterLink := &svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east", UID: "uid-east", ResourceVersion: "42"},
+		Spec: svclinkv1alpha1.ClusterLinkSpec{
+			Enabled:    true,
+			Kubeconfig: base64.StdEncoding.EncodeToString([]byte("east-kubeconfig")),
+		},
+		Status: svclinkv1alpha1.ClusterLinkStatus{Connected: true},
+	}
+
+	// Export from the old hub
+	if err := exportCredentials(context.Background(), &linkClient{}, clusterLink, dir); err != nil {
+		t.Fatalf("exportCredentials() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "east-kubeconfig", "kubeconfig")); err != nil || string(data) != "east-kubeconfig" {
+		t.Fatalf("expected the kubeconfig to be exported, got %q (err %v)", data, err)
+	}
+	exported := exportedClusterLink(clusterLink)
+	sanitizeClusterLink(exported)
+	if exported.Spec.Kubeconfig != "" || exported.Spec.KubeconfigSecretRef == nil || exported.Spec.KubeconfigSecretRef.Name != "east-kubeconfig" {
+		t.Errorf("expected the kubeconfig to be replaced by a Secret reference, got %+v", exported.Spec)
+	}
+	if exported.UID != "" || exported.ResourceVersion != "" || exported.Status.Connected {
+		t.Errorf("expected server-populated fields to be dropped, got %+v", exported)
+	}
+
+	manifest, err := yaml.Marshal(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := bytes.NewBufferString("---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: unrelated\n---\n")
+	stream.Write(manifest)
+
+	// Import into the new hub
+	clusterLinks, err := readClusterLinks(stream)
+	if err != nil || len(clusterLinks) != 1 {
+		t.Fatalf("readClusterLinks() = %v (err %v), want the ClusterLink only", clusterLinks, err)
+	}
+	linkCredentialsDir, linkSecretNamespace = dir, "svclink"
+	fake := &linkClient{}
+	if err := importClusterLink(context.Background(), fake, clusterLinks[0]); err != nil {
+		t.Fatalf("importClusterLink() error = %v", err)
+	}
+
+	if len(fake.created) != 2 {
+		t.Fatalf("expected a Secret and a ClusterLink to be created, got %d objects", len(fake.created))
+	}
+	secret, ok := fake.created[0].(*corev1.Secret)
+	if !ok || secret.Namespace != "svclink" || secret.Name != "east-kubeconfig" || string(secret.Data["kubeconfig"]) != "east-kubeconfig" {
+		t.Errorf("unexpected Secret %+v", fake.created[0])
+	}
+	imported, ok := fake.created[1].(*svclinkv1alpha1.ClusterLink)
+	if !ok || imported.Namespace != "cloudpilot" || imported.Spec.KubeconfigSecretRef.Namespace != "svclink" {
+		t.Errorf("expected the ClusterLink to reference the Secret in svclink, got %+v", fake.created[1])
+	}
+}
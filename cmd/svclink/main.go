@@ -6,11 +6,17 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/client-go/pkg/version"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -18,15 +24,80 @@ import (
 	api "k8s.io/kubernetes/pkg/apis/core"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
 	"github.com/cloudpilot-ai/svclink/pkg/config"
 	"github.com/cloudpilot-ai/svclink/pkg/controller"
 )
 
 var (
+	clusterName                string
 	syncInterval               time.Duration
+	syncJitter                 float64
+	syncSplay                  time.Duration
+	syncWorkers                int
+	discoveryWorkers           int
+	serviceFailureBudget       int
+	serviceFailureRetry        time.Duration
+	serviceRetryBaseDelay      time.Duration
+	serviceRetryMaxDelay       time.Duration
+	verificationInterval       time.Duration
+	namespaceEndpointQuota     int
+	maxEndpointsPerSlice       int
+	mirrorEndpoints            bool
+	sliceWriteQPS              float64
+	sliceWriteBurst            int
+	maxClustersPerService      int
+	localClusterPriority       int32
+	onboardingBatchSize        int
 	kubeconfig                 string
 	includedNamespaces         []string
+	syncServiceTypes           []string
 	syncServicesToLocalCluster bool
+	stripSelectors             bool
+	serviceNameTemplate        string
+	capabilityCacheTTL         time.Duration
+	execPluginDir              string
+	execPlugins                []string
+	allowCrossNamespaceSecrets bool
+	prometheusMetadata         bool
+	prometheusAnnotations      []string
+	prometheusPorts            []string
+	debugConfigMap             string
+	preflight                  string
+	publishNotReadyAddresses   string
+	portMergePolicy            string
+	disabledClusterSlices      string
+	staleEndpointTTL           time.Duration
+	metricsBindAddress         string
+	memoryLimit                string
+	listPageSize               int64
+	shutdownGracePeriod        time.Duration
+	leaderElection             bool
+	leaderElectionNamespace    string
+	leaderElectionID           string
+	shardCount                 int
+	shardIndex                 int
+	hotStandby                 bool
+	credentialsExpiryWindow    time.Duration
+	statusHistoryRetention     time.Duration
+	statusHistoryLimit         int
+	statusErrorLimit           int
+	orphanExpiry               time.Duration
+	syncedServiceRetention     time.Duration
+	sliceGCInterval            time.Duration
+	namespaceSummary           bool
+	statusHeartbeatInterval    time.Duration
+	circuitBreakerThreshold    int
+	circuitBreakerProbe        time.Duration
+	reachabilitySampleSize     int
+	reachabilityTimeout        time.Duration
+	watchRemoteEndpoints       bool
+	normalizeKubeconfigs       bool
+	capiDiscovery              bool
+	clusterDiscoveryProviders  []string
+	clusterDiscoveryNamespace  string
+	clusterDiscoveryInterval   time.Duration
+	fixtureDir                 string
 
 	rootCmd = &cobra.Command{
 		Use:   "svclink",
@@ -40,10 +111,87 @@ It watches for ClusterLink CRDs with embedded kubeconfigs and service changes, a
 func main() {
 	klog.InitFlags(nil)
 
+	rootCmd.Flags().StringVar(&clusterName, "cluster-name", "", "Name of this svclink deployment, matched against the cloudpilot.ai/svclink-target-clusters annotation of remote services")
 	rootCmd.Flags().DurationVar(&syncInterval, "sync-interval", config.DefaultSyncInterval, "Sync interval")
-	rootCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (for local development)")
+	rootCmd.Flags().Float64Var(&syncJitter, "sync-jitter", config.DefaultSyncJitter, "Maximum fraction of --sync-interval randomly added to each interval, so that instances started together do not sync in lockstep (0 disables)")
+	rootCmd.Flags().DurationVar(&syncSplay, "sync-splay", 0, "Window over which the remote clusters of a sync cycle are discovered, each at a stable offset derived from the hostname and the cluster, so that instances sharing remote clusters do not list them at the same time (0 disables)")
+	rootCmd.Flags().IntVar(&syncWorkers, "sync-workers", config.DefaultSyncWorkers, "Number of services synced concurrently; work is shared fairly between remote clusters")
+	rootCmd.Flags().IntVar(&discoveryWorkers, "discovery-workers", config.DefaultDiscoveryWorkers, "Number of remote clusters whose services are discovered concurrently")
+	rootCmd.Flags().IntVar(&serviceFailureBudget, "service-failure-budget", config.DefaultServiceFailureBudget, "Consecutive failed syncs after which a service is only retried every --service-failure-retry-interval (0 disables)")
+	rootCmd.Flags().DurationVar(&serviceFailureRetry, "service-failure-retry-interval", config.DefaultServiceFailureRetryInterval, "How often services that exhausted their failure budget are retried")
+	rootCmd.Flags().DurationVar(&serviceRetryBaseDelay, "service-retry-base-delay", config.DefaultServiceRetryBaseDelay, "Backoff before a service that failed to sync is retried on its own, doubled on every further failure")
+	rootCmd.Flags().DurationVar(&serviceRetryMaxDelay, "service-retry-max-delay", config.DefaultServiceRetryMaxDelay, "Maximum backoff between retries of a service that keeps failing to sync")
+	rootCmd.Flags().DurationVar(&verificationInterval, "verification-interval", config.DefaultVerificationInterval, "How often managed EndpointSlices are verified against a fresh read of the remote clusters (0 disables)")
+	rootCmd.Flags().IntVar(&namespaceEndpointQuota, "namespace-endpoint-quota", 0, "Maximum number of endpoints published into a local namespace, overridden by the cloudpilot.ai/svclink-endpoint-quota namespace annotation (0 disables)")
+	rootCmd.Flags().IntVar(&maxEndpointsPerSlice, "max-endpoints-per-slice", config.DefaultMaxEndpointsPerSlice, "Maximum number of endpoints per imported EndpointSlice; the endpoints of a cluster are split across several EndpointSlices above it (at most 1000)")
+	rootCmd.Flags().Float64Var(&sliceWriteQPS, "slice-write-qps", 0, "Maximum number of EndpointSlice writes per second sent to the local API server (0 leaves writes unlimited)")
+	rootCmd.Flags().IntVar(&sliceWriteBurst, "slice-write-burst", config.DefaultSliceWriteBurst, "Number of EndpointSlice writes sent in a burst above --slice-write-qps")
+	rootCmd.Flags().BoolVar(&mirrorEndpoints, "mirror-endpoints", false, "Mirror the imported endpoints of Services without a selector into core/v1 Endpoints objects, truncated to 1000 addresses")
+	rootCmd.Flags().IntVar(&maxClustersPerService, "max-clusters-per-service", 0, "Maximum number of clusters contributing endpoints to a service, preferring clusters with a lower spec.costWeight and probed latency (0 disables)")
+	rootCmd.Flags().Int32Var(&localClusterPriority, "local-cluster-priority", 0, "Failover priority of the local cluster's endpoints; remote clusters with a lower spec.priority are only published while the local cluster has no ready endpoints")
+	rootCmd.Flags().IntVar(&onboardingBatchSize, "onboarding-batch-size", 0, "Number of namespaces of a newly connected cluster imported per sync cycle, progress is reported in status.onboarding of the ClusterLink (0 imports every namespace at once)")
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (for local development)")
+	rootCmd.Flags().StringSliceVar(&syncServiceTypes, "sync-service-types", []string{}, "Global service type filter: if specified, only services of these types (ClusterIP, NodePort, LoadBalancer, ExternalName) are synced across all clusters")
 	rootCmd.Flags().StringSliceVar(&includedNamespaces, "included-namespaces", []string{}, "Global namespace filter: if specified, only services in these namespaces will be synced across all clusters (overrides ClusterLink-level inclusion rules)")
 	rootCmd.Flags().BoolVar(&syncServicesToLocalCluster, "sync-services-to-local-cluster", false, "Whether to sync services from remote clusters to the local cluster")
+	rootCmd.Flags().BoolVar(&stripSelectors, "strip-selectors", true, "Create the services synced to the local cluster without a selector, so that svclink is the only source of their endpoints")
+	rootCmd.Flags().StringVar(&serviceNameTemplate, "service-name-template", "", "Name template of services synced to the local cluster, e.g. '{{.Name}}-remote' or '{{.Name}}-{{.Cluster}}' (fields: Name, Namespace, Cluster)")
+	rootCmd.Flags().DurationVar(&credentialsExpiryWindow, "credentials-expiry-window", config.DefaultCredentialsExpiryWindow, "How long before client certificate expiry a ClusterLink reports the CredentialsExpiring condition")
+	rootCmd.Flags().DurationVar(&statusHistoryRetention, "status-history-retention", config.DefaultStatusHistoryRetention, "How long resolved disconnect and sync error episodes are kept in status.history of ClusterLinks (0 disables the history)")
+	rootCmd.Flags().IntVar(&statusHistoryLimit, "status-history-limit", config.DefaultStatusHistoryLimit, "Maximum number of episodes kept in status.history of a ClusterLink")
+	rootCmd.Flags().IntVar(&statusErrorLimit, "status-error-limit", config.DefaultStatusErrorLimit, "Maximum number of distinct errors kept in status.errors of a ClusterLink (0 disables the error list)")
+	rootCmd.Flags().DurationVar(&statusHeartbeatInterval, "status-heartbeat-interval", config.DefaultStatusHeartbeatInterval, "How often lastConnected and lastSyncTime are refreshed in the status of ClusterLinks when nothing else changed")
+	rootCmd.Flags().IntVar(&circuitBreakerThreshold, "circuit-breaker-threshold", config.DefaultCircuitBreakerThreshold, "Consecutive connection or discovery failures after which a ClusterLink is Degraded and only probed periodically (0 disables the circuit breaker)")
+	rootCmd.Flags().DurationVar(&circuitBreakerProbe, "circuit-breaker-probe-interval", config.DefaultCircuitBreakerProbeInterval, "How often a Degraded ClusterLink is probed until it recovers")
+	rootCmd.Flags().IntVar(&reachabilitySampleSize, "reachability-sample-size", config.DefaultReachabilitySampleSize, "Number of imported endpoints dialed per sync cycle for ClusterLinks with spec.reachabilityProbe")
+	rootCmd.Flags().DurationVar(&reachabilityTimeout, "reachability-timeout", config.DefaultReachabilityTimeout, "How long the reachability probe waits for an imported endpoint to accept a TCP connection")
+	rootCmd.Flags().BoolVar(&watchRemoteEndpoints, "watch-remote-endpoints", true, "Watch the EndpointSlices of remote clusters with informers, so that endpoint changes are synced within seconds instead of the next sync cycle")
+	rootCmd.Flags().BoolVar(&namespaceSummary, "namespace-summary", false, "Report the services and endpoints synced per remote namespace in status.namespaceSummary of ClusterLinks")
+	rootCmd.Flags().DurationVar(&syncedServiceRetention, "synced-service-retention", config.DefaultSyncedServiceRetention, "How long a Service created by --sync-services-to-local-cluster is kept once no remote cluster exposes it, before it is deleted (0 leaves it to --orphan-expiry)")
+	rootCmd.Flags().DurationVar(&orphanExpiry, "orphan-expiry", 0, "How long an imported service exposed by no remote cluster is kept before its managed Service and EndpointSlices are pruned (0 only reports orphans)")
+	rootCmd.Flags().DurationVar(&sliceGCInterval, "slice-gc-interval", 0, "How often managed EndpointSlices across all namespaces are garbage collected when their service is no longer imported from their source cluster (0 disables it)")
+	rootCmd.Flags().DurationVar(&capabilityCacheTTL, "capability-cache-ttl", config.DefaultCapabilityCacheTTL, "How long remote cluster version and API discovery results are cached")
+	rootCmd.PersistentFlags().StringVar(&execPluginDir, "exec-plugin-dir", defaultExecPluginDir(), "Directory searched for exec credential plugin binaries used by remote kubeconfigs")
+	rootCmd.PersistentFlags().StringSliceVar(&execPlugins, "exec-plugins", []string{}, "Exec credential plugin commands remote kubeconfigs are allowed to run (e.g. aws,gke-gcloud-auth-plugin)")
+	rootCmd.Flags().BoolVar(&allowCrossNamespaceSecrets, "allow-cross-namespace-secrets", false, "Allow ClusterLinks to reference Secrets and cert-manager Certificates in other namespaces than their own")
+	rootCmd.Flags().BoolVar(&prometheusMetadata, "prometheus-metadata", false, "Propagate Prometheus scrape annotations from remote services onto services synced to the local cluster")
+	rootCmd.Flags().StringSliceVar(&prometheusAnnotations, "prometheus-annotations", config.DefaultPrometheusAnnotations, "Annotation keys propagated when --prometheus-metadata is enabled")
+	rootCmd.Flags().StringSliceVar(&prometheusPorts, "prometheus-ports", []string{}, "Port names or numbers Prometheus may scrape on synced services (empty allows all ports)")
+	rootCmd.Flags().StringVar(&disabledClusterSlices, "disabled-cluster-slices", config.DisabledClusterSlicesRetain, "What happens to the EndpointSlices imported from ClusterLinks with spec.enabled false: retain or delete")
+	rootCmd.Flags().DurationVar(&staleEndpointTTL, "stale-endpoint-ttl", 0, "How long the EndpointSlices imported from an unreachable ClusterLink keep its last known endpoints, marked with the cloudpilot.ai/svclink-stale-since annotation, before they are deleted (0 drops them right away)")
+	rootCmd.Flags().StringVar(&publishNotReadyAddresses, "publish-not-ready-addresses", config.PublishNotReadyAddressesService, "Which remote services have their not-ready endpoints imported as ready: service (those setting spec.publishNotReadyAddresses), always or never")
+	rootCmd.Flags().StringVar(&portMergePolicy, "port-merge-policy", config.PortMergePolicyUnion, "How the ports of a service exported by several clusters with different ports are merged: union, intersection or first-cluster-wins")
+	rootCmd.Flags().StringVar(&preflight, "preflight", config.PreflightRefuse, "What to do when the startup preflight finds self-links, duplicate links or overlapping namespace mappings: refuse to start, degrade (skip those ClusterLinks) or off")
+	rootCmd.Flags().StringVar(&fixtureDir, "fixture-dir", "", "Directory of fixture files (one <cluster>.yaml per remote cluster with its Services and EndpointSlices) loaded instead of the ClusterLinks, for testing against the local cluster only")
+	rootCmd.Flags().StringVar(&debugConfigMap, "debug-configmap", "", "Namespace/name of a ConfigMap with 'verbosity' and 'debugTargets' keys to adjust logging at runtime")
+	rootCmd.Flags().BoolVar(&normalizeKubeconfigs, "normalize-kubeconfigs", false, "Rewrite ClusterLink kubeconfigs to a minimal form with only the selected cluster, user and context")
+	rootCmd.Flags().BoolVar(&capiDiscovery, "capi-discovery", false, "Create a ClusterLink for every Cluster API workload cluster, using its generated kubeconfig Secret")
+	rootCmd.Flags().StringSliceVar(&clusterDiscoveryProviders, "cluster-discovery-providers", []string{}, "Cloud provider scopes to discover clusters from and create ClusterLinks for (eks:<region>, gke:<project>, aks:<subscription>); the matching exec plugin (aws, gke-gcloud-auth-plugin, kubelogin) must be allowed by --exec-plugins")
+	rootCmd.Flags().StringVar(&clusterDiscoveryNamespace, "cluster-discovery-namespace", "cloudpilot", "Namespace discovered ClusterLinks are created in")
+	rootCmd.Flags().DurationVar(&clusterDiscoveryInterval, "cluster-discovery-interval", config.DefaultClusterDiscoveryInterval, "Interval between cloud provider cluster listings")
+	rootCmd.Flags().StringVar(&memoryLimit, "memory-limit", "", "Soft memory limit of the process (e.g. 256Mi). When set, services are processed one namespace at a time and remote lists are paginated")
+	rootCmd.Flags().Int64Var(&listPageSize, "list-page-size", config.DefaultListPageSize, "Number of objects per page of remote lists when --memory-limit is set")
+	rootCmd.Flags().DurationVar(&shutdownGracePeriod, "shutdown-grace-period", config.DefaultShutdownGracePeriod, "How long in-flight syncs are given to finish on SIGTERM before they are aborted; keep it below the terminationGracePeriodSeconds of the Pod")
+	rootCmd.Flags().BoolVar(&leaderElection, "leader-elect", false, "Enable leader election so that only one replica syncs at a time")
+	rootCmd.Flags().StringVar(&leaderElectionNamespace, "leader-elect-namespace", "", "Namespace of the leader election Lease (defaults to the namespace svclink runs in)")
+	rootCmd.Flags().StringVar(&leaderElectionID, "leader-elect-id", config.DefaultLeaderElectionID, "Name of the leader election Lease; replicas sharing it elect a single leader")
+	rootCmd.Flags().IntVar(&shardCount, "shard-count", 1, "Number of shards ClusterLinks are spread across, each replica syncing one shard (1 disables sharding)")
+	rootCmd.Flags().IntVar(&shardIndex, "shard-index", -1, "Shard of the ClusterLinks this replica syncs, from 0 to --shard-count minus 1 (defaults to the StatefulSet ordinal of the Pod hostname)")
+	rootCmd.Flags().BoolVar(&hotStandby, "hot-standby", true, "Keep remote clients warm on replicas that are not the leader (requires --leader-elect)")
+	rootCmd.Flags().StringVar(&metricsBindAddress, "metrics-bind-address", fmt.Sprintf(":%d", config.DefaultMetricsPort), "Address the metrics and admin endpoints are served on")
+	rootCmd.PersistentFlags().StringVar(&controllerNamespace, "controller-namespace", "cloudpilot", "Namespace of the svclink controller queried by admin commands")
+	rootCmd.PersistentFlags().StringVar(&controllerSelector, "controller-selector", "app=svclink", "Label selector of the svclink controller pods queried by admin commands")
+	rootCmd.PersistentFlags().StringVar(&controllerLease, "controller-lease", config.DefaultLeaderElectionID, "Leader election Lease of the svclink controller; admin commands query the pods holding it, or the Lease of each shard")
+	rootCmd.PersistentFlags().IntVar(&controllerPort, "controller-port", config.DefaultMetricsPort, "Port of the metrics and admin endpoints of the svclink controller queried by admin commands")
+	rootCmd.AddCommand(newClustersCommand())
+	rootCmd.AddCommand(newFilteredCommand())
+	rootCmd.AddCommand(newOrphansCommand())
+	rootCmd.AddCommand(newLinkCommand())
+	rootCmd.AddCommand(newPinCommand())
+	rootCmd.AddCommand(newUnpinCommand())
+	rootCmd.AddCommand(newSyncCommand())
+	rootCmd.AddCommand(newOwnersCommand())
+	rootCmd.AddCommand(newChaosCommand())
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -60,11 +208,194 @@ func runController(cmd *cobra.Command, args []string) error {
 		return errors.New("cannot include 'kube-system' namespace; it is always excluded")
 	}
 
+	for _, serviceType := range syncServiceTypes {
+		switch corev1.ServiceType(serviceType) {
+		case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeExternalName:
+		default:
+			return fmt.Errorf("invalid service type %q in --sync-service-types", serviceType)
+		}
+	}
+
+	if serviceNameTemplate != "" {
+		if !syncServicesToLocalCluster {
+			klog.Warning("--service-name-template only applies to services created by --sync-services-to-local-cluster")
+		}
+		if _, err := config.ParseServiceNameTemplate(serviceNameTemplate); err != nil {
+			return fmt.Errorf("invalid --service-name-template: %w", err)
+		}
+	}
+
+	switch disabledClusterSlices {
+	case config.DisabledClusterSlicesRetain, config.DisabledClusterSlicesDelete:
+	default:
+		return fmt.Errorf("invalid --disabled-cluster-slices %q, must be retain or delete", disabledClusterSlices)
+	}
+
+	switch preflight {
+	case config.PreflightRefuse, config.PreflightDegrade, config.PreflightOff:
+	default:
+		return fmt.Errorf("invalid --preflight %q, must be one of refuse, degrade or off", preflight)
+	}
+
+	switch publishNotReadyAddresses {
+	case config.PublishNotReadyAddressesService, config.PublishNotReadyAddressesAlways, config.PublishNotReadyAddressesNever:
+	default:
+		return fmt.Errorf("invalid --publish-not-ready-addresses %q, must be one of service, always or never", publishNotReadyAddresses)
+	}
+
+	switch portMergePolicy {
+	case config.PortMergePolicyUnion, config.PortMergePolicyIntersection, config.PortMergePolicyFirstCluster:
+	default:
+		return fmt.Errorf("invalid --port-merge-policy %q, must be one of union, intersection or first-cluster-wins", portMergePolicy)
+	}
+
+	if fixtureDir != "" {
+		if _, err := clusterlink.LoadFixtureClusters(fixtureDir); err != nil {
+			return fmt.Errorf("invalid --fixture-dir: %w", err)
+		}
+		klog.Warningf("Loading remote clusters from the fixtures in %s, ClusterLinks are ignored", fixtureDir)
+	}
+
+	if syncedServiceRetention < 0 {
+		return fmt.Errorf("invalid --synced-service-retention %s, must not be negative", syncedServiceRetention)
+	}
+	if syncServicesToLocalCluster && syncedServiceRetention == 0 && orphanExpiry == 0 {
+		klog.Info("Services created by --sync-services-to-local-cluster are kept when no remote cluster exposes them anymore, set --synced-service-retention to delete them")
+	}
+
+	if prometheusMetadata && !syncServicesToLocalCluster {
+		klog.Warning("--prometheus-metadata only applies to services created by --sync-services-to-local-cluster")
+	}
+
+	if maxEndpointsPerSlice < 1 || maxEndpointsPerSlice > config.MaxEndpointsPerSliceLimit {
+		return fmt.Errorf("invalid --max-endpoints-per-slice %d, must be between 1 and %d", maxEndpointsPerSlice, config.MaxEndpointsPerSliceLimit)
+	}
+
+	if sliceWriteQPS < 0 {
+		return fmt.Errorf("invalid --slice-write-qps %v, must not be negative", sliceWriteQPS)
+	}
+	if sliceWriteBurst < 1 {
+		return fmt.Errorf("invalid --slice-write-burst %d, must be at least 1", sliceWriteBurst)
+	}
+
+	if sliceGCInterval < 0 {
+		return fmt.Errorf("invalid --slice-gc-interval %s, must not be negative", sliceGCInterval)
+	}
+
+	if staleEndpointTTL < 0 {
+		return fmt.Errorf("invalid --stale-endpoint-ttl %s, must not be negative", staleEndpointTTL)
+	}
+
+	if syncJitter < 0 {
+		return fmt.Errorf("invalid --sync-jitter %v, must not be negative", syncJitter)
+	}
+	if syncSplay < 0 || syncSplay >= syncInterval {
+		return fmt.Errorf("invalid --sync-splay %s, must be lower than --sync-interval %s", syncSplay, syncInterval)
+	}
+
+	if shardCount < 1 {
+		return fmt.Errorf("invalid --shard-count %d, must be at least 1", shardCount)
+	}
+	if shardCount == 1 {
+		shardIndex = 0
+	} else {
+		if shardIndex < 0 {
+			ordinal, err := hostnameOrdinal()
+			if err != nil {
+				return fmt.Errorf("--shard-index is required with --shard-count outside of a StatefulSet: %w", err)
+			}
+			shardIndex = ordinal
+		}
+		if shardIndex >= shardCount {
+			return fmt.Errorf("invalid --shard-index %d, must be lower than --shard-count %d", shardIndex, shardCount)
+		}
+		klog.Infof("Syncing the ClusterLinks of shard %d of %d", shardIndex, shardCount)
+	}
+
+	if leaderElection {
+		leaderElectionID = leaderElectionLease(leaderElectionID, cmd.Flags().Changed("leader-elect-id"), shardIndex, shardCount)
+	}
+
+	var memoryLimitBytes int64
+	if memoryLimit != "" {
+		quantity, err := resource.ParseQuantity(memoryLimit)
+		if err != nil {
+			return fmt.Errorf("invalid --memory-limit: %w", err)
+		}
+		memoryLimitBytes = quantity.Value()
+		debug.SetMemoryLimit(memoryLimitBytes)
+		klog.Infof("Memory limit set to %s, processing services one namespace at a time", quantity.String())
+	}
+
 	// Build config
 	cfg := &config.Config{
-		SyncInterval:               syncInterval,
-		IncludedNamespaces:         includedNamespaces,
-		SyncServicesToLocalCluster: syncServicesToLocalCluster,
+		ClusterName:                 clusterName,
+		SyncInterval:                syncInterval,
+		SyncJitter:                  syncJitter,
+		SyncSplay:                   syncSplay,
+		SyncWorkers:                 syncWorkers,
+		DiscoveryWorkers:            discoveryWorkers,
+		ServiceFailureBudget:        serviceFailureBudget,
+		ServiceFailureRetryInterval: serviceFailureRetry,
+		ServiceRetryBaseDelay:       serviceRetryBaseDelay,
+		ServiceRetryMaxDelay:        serviceRetryMaxDelay,
+		VerificationInterval:        verificationInterval,
+		NamespaceEndpointQuota:      namespaceEndpointQuota,
+		MaxEndpointsPerSlice:        maxEndpointsPerSlice,
+		MirrorEndpoints:             mirrorEndpoints,
+		SliceWriteQPS:               sliceWriteQPS,
+		SliceWriteBurst:             sliceWriteBurst,
+		MaxClustersPerService:       maxClustersPerService,
+		LocalClusterPriority:        localClusterPriority,
+		OnboardingBatchSize:         onboardingBatchSize,
+		IncludedNamespaces:          includedNamespaces,
+		SyncServiceTypes:            syncServiceTypes,
+		SyncServicesToLocalCluster:  syncServicesToLocalCluster,
+		StripSelectors:              stripSelectors,
+		ServiceNameTemplate:         serviceNameTemplate,
+		CapabilityCacheTTL:          capabilityCacheTTL,
+		ExecPluginDir:               execPluginDir,
+		ExecPlugins:                 execPlugins,
+		AllowCrossNamespaceSecrets:  allowCrossNamespaceSecrets,
+		PrometheusMetadata:          prometheusMetadata,
+		PrometheusAnnotations:       prometheusAnnotations,
+		PrometheusPorts:             prometheusPorts,
+		DebugConfigMap:              debugConfigMap,
+		DisabledClusterSlices:       disabledClusterSlices,
+		StaleEndpointTTL:            staleEndpointTTL,
+		Preflight:                   preflight,
+		PublishNotReadyAddresses:    publishNotReadyAddresses,
+		PortMergePolicy:             portMergePolicy,
+		MetricsBindAddress:          metricsBindAddress,
+		MemoryLimit:                 memoryLimitBytes,
+		ListPageSize:                listPageSize,
+		ShutdownGracePeriod:         shutdownGracePeriod,
+		LeaderElection:              leaderElection,
+		LeaderElectionNamespace:     leaderElectionNamespace,
+		LeaderElectionID:            leaderElectionID,
+		ShardCount:                  shardCount,
+		ShardIndex:                  shardIndex,
+		HotStandby:                  hotStandby,
+		CredentialsExpiryWindow:     credentialsExpiryWindow,
+		StatusHistoryRetention:      statusHistoryRetention,
+		StatusHistoryLimit:          statusHistoryLimit,
+		StatusErrorLimit:            statusErrorLimit,
+		OrphanExpiry:                orphanExpiry,
+		SyncedServiceRetention:      syncedServiceRetention,
+		SliceGCInterval:             sliceGCInterval,
+		NamespaceSummary:            namespaceSummary,
+		StatusHeartbeatInterval:     statusHeartbeatInterval,
+		CircuitBreakerThreshold:     circuitBreakerThreshold,
+		CircuitBreakerProbeInterval: circuitBreakerProbe,
+		ReachabilitySampleSize:      reachabilitySampleSize,
+		ReachabilityTimeout:         reachabilityTimeout,
+		WatchRemoteEndpoints:        watchRemoteEndpoints,
+		NormalizeKubeconfigs:        normalizeKubeconfigs,
+		CAPIDiscovery:               capiDiscovery,
+		ClusterDiscoveryProviders:   clusterDiscoveryProviders,
+		ClusterDiscoveryNamespace:   clusterDiscoveryNamespace,
+		ClusterDiscoveryInterval:    clusterDiscoveryInterval,
+		FixtureDir:                  fixtureDir,
 	}
 
 	// Create Kubernetes client
@@ -94,12 +425,30 @@ func runController(cmd *cobra.Command, args []string) error {
 
 	// Run controller
 	if err := ctrl.Run(ctx); err != nil {
-		klog.Errorf("Controller error: %v", err)
+		return fmt.Errorf("controller error: %w", err)
 	}
 
 	return nil
 }
 
+// leaderElectionLease returns the name of the leader election Lease. Unless the name is set explicitly, every
+// shard elects its own leader among the replicas syncing it.
+func leaderElectionLease(id string, explicit bool, shardIndex, shardCount int) string {
+	if explicit || shardCount <= 1 {
+		return id
+	}
+	return fmt.Sprintf("%s-%d", id, shardIndex)
+}
+
+// defaultExecPluginDir returns the directory where plugin binaries baked into the image
+// through ko's kodata are available, if running from a ko-built image
+func defaultExecPluginDir() string {
+	if koDataPath := os.Getenv("KO_DATA_PATH"); koDataPath != "" {
+		return filepath.Join(koDataPath, "exec-plugins")
+	}
+	return ""
+}
+
 // buildRestConfig creates a REST config from kubeconfig or in-cluster config
 func buildRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	if kubeconfigPath != "" {
@@ -113,3 +462,17 @@ func buildRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	}
 	return config, nil
 }
+
+// hostnameOrdinal returns the ordinal of the StatefulSet Pod svclink runs in, the number its hostname ends with
+func hostnameOrdinal() (int, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, err
+	}
+	i := strings.LastIndex(hostname, "-")
+	ordinal, err := strconv.Atoi(hostname[i+1:])
+	if i < 0 || err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("hostname %q does not end with a StatefulSet ordinal", hostname)
+	}
+	return ordinal, nil
+}
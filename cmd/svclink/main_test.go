@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestLeaderElectionLease(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		explicit   bool
+		shardIndex int
+		shardCount int
+		want       string
+	}{
+		{name: "default lease", id: "svclink-leader", shardCount: 1, want: "svclink-leader"},
+		{name: "custom lease", id: "svclink-east", explicit: true, shardCount: 1, want: "svclink-east"},
+		{name: "lease per shard", id: "svclink-leader", shardIndex: 2, shardCount: 3, want: "svclink-leader-2"},
+		{name: "custom lease shared by shards", id: "svclink-east", explicit: true, shardIndex: 2, shardCount: 3, want: "svclink-east"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leaderElectionLease(tt.id, tt.explicit, tt.shardIndex, tt.shardCount); got != tt.want {
+				t.Errorf("leaderElectionLease() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/controller"
+)
+
+var orphansNamespace string
+
+// newOrphansCommand creates the "orphans" command listing imported services no remote cluster exposes anymore
+func newOrphansCommand() *cobra.Command {
+	orphansCmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "Show imported local services that no remote cluster exposes anymore",
+		RunE:  runOrphans,
+	}
+	orphansCmd.Flags().StringVarP(&orphansNamespace, "namespace", "n", "", "Only show the given namespace")
+	return orphansCmd
+}
+
+func runOrphans(cmd *cobra.Command, args []string) error {
+	responses, err := controllerGet(cmd.Context(), controller.OrphansPath)
+	if err != nil {
+		return err
+	}
+	report, err := mergeOrphanReports(responses)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tSERVICE\tCREATED\tENDPOINTSLICES\tORPHANED\tPRUNE IN")
+	for _, orphan := range report.Services {
+		if orphansNamespace != "" && orphan.Namespace != orphansNamespace {
+			continue
+		}
+		pruneIn := "never"
+		if orphan.PruneAt != nil {
+			pruneIn = duration.HumanDuration(max(orphan.PruneAt.Sub(now), 0))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%s\t%s\n", orphan.Namespace, orphan.Name, orphan.Created, orphan.EndpointSlices,
+			duration.HumanDuration(now.Sub(orphan.Since)), pruneIn)
+	}
+	return w.Flush()
+}
+
+// mergeOrphanReports merges the orphans reports of the queried controller pods, sorted by namespace/name. A
+// service reported by several pods is listed once.
+func mergeOrphanReports(responses [][]byte) (*apisdiscoverer.OrphanReport, error) {
+	merged := &apisdiscoverer.OrphanReport{}
+	seen := sets.New[string]()
+	for _, data := range responses {
+		var report apisdiscoverer.OrphanReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse orphans report: %w", err)
+		}
+		for _, orphan := range report.Services {
+			if key := orphan.Namespace + "/" + orphan.Name; !seen.Has(key) {
+				seen.Insert(key)
+				merged.Services = append(merged.Services, orphan)
+			}
+		}
+	}
+	sort.Slice(merged.Services, func(i, j int) bool {
+		a, b := merged.Services[i], merged.Services[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+	return merged, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// newOwnersCommand creates the "owners" command, which shows who owns which fields of an EndpointSlice
+func newOwnersCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "owners <namespace>/<endpointslice>",
+		Short: "Show which managers own the fields of an EndpointSlice",
+		Long: `Decode the managedFields of an EndpointSlice to show which controller or user last wrote its
+endpoints, ports, labels and annotations, to find out who keeps changing a slice managed by svclink.`,
+		Example: "  svclink owners payments/api-prod-us-ipv4",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runOwners,
+	}
+}
+
+func runOwners(cmd *cobra.Command, args []string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(args[0])
+	if err != nil || namespace == "" {
+		return fmt.Errorf("invalid EndpointSlice %q, expected <namespace>/<endpointslice>", args[0])
+	}
+
+	kubeClient, err := newCLIClient()
+	if err != nil {
+		return err
+	}
+	var slice discoveryv1.EndpointSlice
+	if err := kubeClient.Get(cmd.Context(), client.ObjectKey{Namespace: namespace, Name: name}, &slice); err != nil {
+		return fmt.Errorf("failed to get EndpointSlice %s: %w", args[0], err)
+	}
+
+	out := cmd.OutOrStdout()
+	if config.IsManagedByUs(&slice) {
+		sourceCluster, _ := config.SourceCluster(&slice)
+		fmt.Fprintf(out, "EndpointSlice %s is managed by svclink, imported from cluster %s\n\n", args[0], sourceCluster)
+	} else {
+		fmt.Fprintf(out, "EndpointSlice %s is not managed by svclink\n\n", args[0])
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "MANAGER\tOPERATION\tUPDATED\tFIELD")
+	for _, entry := range slice.ManagedFields {
+		fields, err := ownedFields(entry.FieldsV1)
+		if err != nil {
+			return fmt.Errorf("failed to decode the fields of manager %s: %w", entry.Manager, err)
+		}
+		updated := "-"
+		if entry.Time != nil {
+			updated = entry.Time.Format(time.RFC3339)
+		}
+		operation := string(entry.Operation)
+		if entry.Subresource != "" {
+			operation += "/" + entry.Subresource
+		}
+		for _, field := range fields {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Manager, operation, updated, field)
+		}
+	}
+	return w.Flush()
+}
+
+// ownedFields returns the paths of the fields owned in a managedFields entry, e.g. endpoints or
+// metadata.labels.kubernetes.io/service-name. Atomic lists such as the endpoints and ports of an
+// EndpointSlice are owned as a whole.
+func ownedFields(fieldsV1 *metav1.FieldsV1) ([]string, error) {
+	if fieldsV1 == nil {
+		return nil, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(fieldsV1.Raw, &fields); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	var walk func(prefix string, fields map[string]interface{})
+	walk = func(prefix string, fields map[string]interface{}) {
+		for key, value := range fields {
+			if key == "." {
+				paths = append(paths, prefix)
+				continue
+			}
+			// Fields are prefixed with f:, list items with k: for their keys or v: for their value
+			path := key[strings.Index(key, ":")+1:]
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			children, _ := value.(map[string]interface{})
+			if len(children) == 0 {
+				paths = append(paths, path)
+				continue
+			}
+			walk(path, children)
+		}
+	}
+	walk("", fields)
+
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOwnedFields(t *testing.T) {
+	fieldsV1 := &metav1.FieldsV1{Raw: []byte(`{
+		"f:addressType": {},
+		"f:endpoints": {},
+		"f:metadata": {
+			"f:annotations": {".": {}, "f:cloudpilot.ai/svclink-source-cluster": {}},
+			"f:labels": {"f:kubernetes.io/service-name": {}}
+		},
+		"f:ports": {}
+	}`)}
+
+	fields, err := ownedFields(fieldsV1)
+	if err != nil {
+		t.Fatalf("ownedFields() error = %v", err)
+	}
+	want := []string{
+		"addressType",
+		"endpoints",
+		"metadata.annotations",
+		"metadata.annotations.cloudpilot.ai/svclink-source-cluster",
+		"metadata.labels.kubernetes.io/service-name",
+		"ports",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("ownedFields() = %v, want %v", fields, want)
+	}
+
+	if fields, err := ownedFields(nil); fields != nil || err != nil {
+		t.Errorf("expected no fields without managedFields, got %v (err %v)", fields, err)
+	}
+	if _, err := ownedFields(&metav1.FieldsV1{Raw: []byte("not json")}); err == nil {
+		t.Error("expected an error for malformed fields")
+	}
+}
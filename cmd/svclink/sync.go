@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+var syncNamespace string
+
+// newSyncCommand creates the "sync" command, which requests a sync without waiting for the sync interval
+func newSyncCommand() *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync <cluster> | <namespace>/<service>",
+		Short: "Sync all services, or a single service, right away",
+		Long: `Request a sync without waiting for the sync interval, e.g. after fixing a misconfiguration.
+Given a cluster, the sync now annotation of its ClusterLink is bumped and the controller starts a
+sync cycle of all services. Given a local Service, only that Service is synced, from the clusters
+and services found by the last sync cycle.`,
+		Example: "  svclink sync prod-us\n  svclink sync payments/api",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runSync,
+	}
+	syncCmd.Flags().StringVarP(&syncNamespace, "namespace", "n", "", "Namespace of the ClusterLink, required when several namespaces have a ClusterLink of the same name")
+	return syncCmd
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	// ClusterLink names cannot contain a slash
+	if strings.Contains(args[0], "/") {
+		if err := patchService(cmd, args[0], func(svc *corev1.Service) {
+			config.RequestSync(svc, now)
+		}); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Requested a sync of service %s\n", args[0])
+		return nil
+	}
+
+	if err := patchClusterLink(cmd, syncNamespace, args[0], func(clusterLink *svclinkv1alpha1.ClusterLink) {
+		config.RequestSync(clusterLink, now)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Requested a sync cycle through ClusterLink %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,124 @@
+package aggregator
+
+import (
+	"fmt"
+	"net/netip"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// addressTranslator rewrites the addresses of endpoints imported from a cluster whose pod network is reached through NAT
+type addressTranslator struct {
+	static map[netip.Addr]netip.Addr
+	cidrs  []cidrTranslation
+}
+
+type cidrTranslation struct {
+	from, to netip.Prefix
+}
+
+// newAddressTranslator parses the address translations of a ClusterLink. It returns nil when there are none.
+func newAddressTranslator(translations []svclinkv1alpha1.AddressTranslation) (*addressTranslator, error) {
+	if len(translations) == 0 {
+		return nil, nil
+	}
+
+	at := &addressTranslator{static: make(map[netip.Addr]netip.Addr)}
+	for _, translation := range translations {
+		if translation.From != "" || translation.To != "" {
+			from, err := netip.ParseAddr(translation.From)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address translation from %q: %w", translation.From, err)
+			}
+			to, err := netip.ParseAddr(translation.To)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address translation to %q: %w", translation.To, err)
+			}
+			from, to = from.Unmap(), to.Unmap()
+			if from.Is4() != to.Is4() {
+				return nil, fmt.Errorf("address translation from %s to %s changes the IP family", from, to)
+			}
+			at.static[from] = to
+			continue
+		}
+
+		from, err := netip.ParsePrefix(translation.FromCIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address translation fromCIDR %q: %w", translation.FromCIDR, err)
+		}
+		to, err := netip.ParsePrefix(translation.ToCIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address translation toCIDR %q: %w", translation.ToCIDR, err)
+		}
+		if from.Addr().Is4() != to.Addr().Is4() || from.Bits() != to.Bits() {
+			return nil, fmt.Errorf("address translation from %s to %s must keep the IP family and prefix length", from, to)
+		}
+		at.cidrs = append(at.cidrs, cidrTranslation{from: from.Masked(), to: to.Masked()})
+	}
+	return at, nil
+}
+
+// translate returns the translated address, or the address itself when no translation matches
+func (at *addressTranslator) translate(address string) string {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return address
+	}
+	addr = addr.Unmap()
+
+	if to, ok := at.static[addr]; ok {
+		return to.String()
+	}
+	for _, cidr := range at.cidrs {
+		if cidr.from.Contains(addr) {
+			return translatePrefix(addr, cidr.from.Bits(), cidr.to.Addr()).String()
+		}
+	}
+	return address
+}
+
+// translatePrefix replaces the first bits of addr with those of to
+func translatePrefix(addr netip.Addr, bits int, to netip.Addr) netip.Addr {
+	if addr.Is4() {
+		bits += 96
+	}
+	from16, to16 := addr.As16(), to.As16()
+	var out [16]byte
+	for i := range out {
+		n := min(max(bits-8*i, 0), 8)
+		mask := byte(uint16(0xff00) >> n)
+		out[i] = to16[i]&mask | from16[i]&^mask
+	}
+
+	translated := netip.AddrFrom16(out)
+	if addr.Is4() {
+		return translated.Unmap()
+	}
+	return translated
+}
+
+// translateEndpoints rewrites the addresses of the endpoints, which are copied rather than modified in place
+func (at *addressTranslator) translateEndpoints(endpointsByType []ClusterEndpoints) []ClusterEndpoints {
+	if at == nil {
+		return endpointsByType
+	}
+
+	for i, ce := range endpointsByType {
+		if ce.AddressType == discoveryv1.AddressTypeFQDN {
+			continue
+		}
+		endpoints := make([]discoveryv1.Endpoint, len(ce.Endpoints))
+		for j, ep := range ce.Endpoints {
+			addresses := make([]string, len(ep.Addresses))
+			for k, address := range ep.Addresses {
+				addresses[k] = at.translate(address)
+			}
+			ep.Addresses = addresses
+			endpoints[j] = ep
+		}
+		endpointsByType[i].Endpoints = endpoints
+	}
+	return endpointsByType
+}
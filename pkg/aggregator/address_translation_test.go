@@ -0,0 +1,47 @@
+package aggregator
+
+import (
+	"testing"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestAddressTranslator(t *testing.T) {
+	translator, err := newAddressTranslator([]svclinkv1alpha1.AddressTranslation{
+		{FromCIDR: "10.0.0.0/16", ToCIDR: "100.64.0.0/16"},
+		{FromCIDR: "10.0.0.0/8", ToCIDR: "172.0.0.0/8"},
+		{From: "10.0.0.5", To: "192.168.1.5"},
+		{FromCIDR: "fd00::/64", ToCIDR: "fd01:0:0:1::/64"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{address: "10.0.3.4", want: "100.64.3.4"},
+		{address: "10.1.3.4", want: "172.1.3.4"},
+		{address: "10.0.0.5", want: "192.168.1.5"},
+		{address: "fd00::1:2", want: "fd01::1:0:0:1:2"},
+		{address: "192.168.0.1", want: "192.168.0.1"},
+		{address: "web.example.com", want: "web.example.com"},
+	}
+	for _, tt := range tests {
+		if got := translator.translate(tt.address); got != tt.want {
+			t.Errorf("translate(%s) = %s, want %s", tt.address, got, tt.want)
+		}
+	}
+
+	for _, invalid := range [][]svclinkv1alpha1.AddressTranslation{
+		{{FromCIDR: "10.0.0.0/16", ToCIDR: "100.64.0.0/24"}},
+		{{FromCIDR: "10.0.0.0/16", ToCIDR: "fd00::/16"}},
+		{{From: "10.0.0.1", To: "fd00::1"}},
+		{{FromCIDR: "10.0.0.0"}},
+	} {
+		if _, err := newAddressTranslator(invalid); err == nil {
+			t.Errorf("expected translations %v to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+// getClusterIPEndpointsFromCluster publishes the remote service ClusterIPs and service ports as
+// endpoints, for networks that route remote service CIDRs but not pod CIDRs. It returns an error
+// when the remote service has no ClusterIP (headless or ExternalName services).
+func (ea *EndpointAggregator) getClusterIPEndpointsFromCluster(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace, serviceName string,
+) ([]ClusterEndpoints, error) {
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeExternalName ||
+		svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return nil, fmt.Errorf("service %s/%s has no ClusterIP", namespace, serviceName)
+	}
+
+	clusterIPs := svc.Spec.ClusterIPs
+	if len(clusterIPs) == 0 {
+		clusterIPs = []string{svc.Spec.ClusterIP}
+	}
+
+	ports := make([]discoveryv1.EndpointPort, 0, len(svc.Spec.Ports))
+	for _, svcPort := range svc.Spec.Ports {
+		ports = append(ports, discoveryv1.EndpointPort{
+			Name:        ptr.To(svcPort.Name),
+			Protocol:    ptr.To(svcPort.Protocol),
+			Port:        ptr.To(svcPort.Port),
+			AppProtocol: svcPort.AppProtocol,
+		})
+	}
+
+	var results []ClusterEndpoints
+	for _, clusterIP := range clusterIPs {
+		ip := net.ParseIP(clusterIP)
+		if ip == nil {
+			continue
+		}
+
+		addressType := discoveryv1.AddressTypeIPv6
+		if ip.To4() != nil {
+			addressType = discoveryv1.AddressTypeIPv4
+		}
+
+		results = append(results, ClusterEndpoints{
+			AddressType: addressType,
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Addresses: []string{clusterIP},
+					Conditions: discoveryv1.EndpointConditions{
+						Ready:   ptr.To(true),
+						Serving: ptr.To(true),
+					},
+				},
+			},
+			Ports: ports,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,36 @@
+package aggregator
+
+import (
+	"sort"
+
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+// orderClusters returns the clusters of a service in preference order: higher spec.priority first, then
+// lower spec.costWeight, then lower probed latency with clusters without a probed latency after the probed
+// ones, and finally by name.
+// Clusters without ClusterInfo keep their relative order at the end.
+func orderClusters(clusters []string, clusterInfos map[string]*clusterlink.ClusterInfo) []string {
+	ordered := append([]string(nil), clusters...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, aok := clusterInfos[ordered[i]]
+		b, bok := clusterInfos[ordered[j]]
+		if !aok || !bok {
+			return aok && !bok
+		}
+		if a.ClusterLink.Spec.Priority != b.ClusterLink.Spec.Priority {
+			return a.ClusterLink.Spec.Priority > b.ClusterLink.Spec.Priority
+		}
+		if a.ClusterLink.Spec.CostWeight != b.ClusterLink.Spec.CostWeight {
+			return a.ClusterLink.Spec.CostWeight < b.ClusterLink.Spec.CostWeight
+		}
+		if (a.Latency > 0) != (b.Latency > 0) {
+			return a.Latency > 0
+		}
+		if a.Latency != b.Latency {
+			return a.Latency < b.Latency
+		}
+		return ordered[i] < ordered[j]
+	})
+	return ordered
+}
@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+func TestOrderClusters(t *testing.T) {
+	info := func(costWeight int32, latency time.Duration) *clusterlink.ClusterInfo {
+		return &clusterlink.ClusterInfo{
+			ClusterLink: svclinkv1alpha1.ClusterLink{Spec: svclinkv1alpha1.ClusterLinkSpec{CostWeight: costWeight}},
+			Latency:     latency,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		clusters     []string
+		clusterInfos map[string]*clusterlink.ClusterInfo
+		want         []string
+	}{
+		{
+			name:         "by name without preferences",
+			clusters:     []string{"c", "a", "b"},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"a": info(0, 0), "b": info(0, 0), "c": info(0, 0)},
+			want:         []string{"a", "b", "c"},
+		},
+		{
+			name:         "cost weight before latency",
+			clusters:     []string{"a", "b"},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"a": info(10, time.Millisecond), "b": info(1, time.Second)},
+			want:         []string{"b", "a"},
+		},
+		{
+			name:         "probed latency before unprobed",
+			clusters:     []string{"a", "b", "c"},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"a": info(0, 0), "b": info(0, 50*time.Millisecond), "c": info(0, 5*time.Millisecond)},
+			want:         []string{"c", "b", "a"},
+		},
+		{
+			name:         "unknown clusters last",
+			clusters:     []string{"x", "a"},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"a": info(100, 0)},
+			want:         []string{"a", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderClusters(tt.clusters, tt.clusterInfos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderClusters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
+)
+
+// dedupeAddresses drops the addresses published more than once for a service, whether by overlapping
+// EndpointSlices of a cluster or by several clusters reaching the same backends, e.g. shared VMs. Left in
+// place, a duplicate address receives a multiple of its share of the traffic. Results are in cluster
+// preference order, so the address is kept in the first, most preferred cluster publishing it. Addresses are
+// only duplicates when published with the same ports. Endpoints left without addresses, and clusters left
+// without endpoints, are dropped. Endpoints are copied rather than modified in place.
+func dedupeAddresses(svcInfo *discoverer.ServiceInfo, results []ClusterEndpoints) []ClusterEndpoints {
+	seen := make(map[string]string)
+	deduped := make([]ClusterEndpoints, 0, len(results))
+	for _, ce := range results {
+		ports := portsKey(ce.Ports)
+		endpoints := make([]discoveryv1.Endpoint, 0, len(ce.Endpoints))
+		dropped := 0
+		for _, ep := range ce.Endpoints {
+			addresses := make([]string, 0, len(ep.Addresses))
+			for _, address := range ep.Addresses {
+				key := string(ce.AddressType) + "/" + address + "/" + ports
+				if cluster, ok := seen[key]; ok {
+					logging.V(4, ce.ClusterName, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Dropping duplicate address %s of service %s/%s from cluster %s, already published from cluster %s",
+						address, svcInfo.Namespace, svcInfo.Name, ce.ClusterName, cluster)
+					dropped++
+					continue
+				}
+				seen[key] = ce.ClusterName
+				addresses = append(addresses, address)
+			}
+			if len(addresses) == 0 && len(ep.Addresses) > 0 {
+				continue
+			}
+			ep.Addresses = addresses
+			endpoints = append(endpoints, ep)
+		}
+		if dropped > 0 {
+			metrics.DuplicateAddresses.WithLabelValues(ce.ClusterName).Add(float64(dropped))
+		}
+		if len(endpoints) == 0 && len(ce.Endpoints) > 0 {
+			continue
+		}
+		ce.Endpoints = endpoints
+		deduped = append(deduped, ce)
+	}
+	return deduped
+}
+
+// portsKey identifies a list of ports regardless of their order
+func portsKey(ports []discoveryv1.EndpointPort) string {
+	keys := make([]string, 0, len(ports))
+	for _, port := range ports {
+		keys = append(keys, fmt.Sprintf("%s:%d/%s", ptr.Deref(port.Name, ""), ptr.Deref(port.Port, 0), ptr.Deref(port.Protocol, "")))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+func TestDedupeAddresses(t *testing.T) {
+	svcInfo := &discoverer.ServiceInfo{Namespace: "default", Name: "app"}
+	http := []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To[int32](8080)}}
+	other := []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To[int32](9090)}}
+	endpoint := func(addresses ...string) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{Addresses: addresses}
+	}
+
+	results := []ClusterEndpoints{
+		{ClusterName: "cluster-a", AddressType: discoveryv1.AddressTypeIPv4, Ports: http,
+			Endpoints: []discoveryv1.Endpoint{endpoint("10.0.0.1"), endpoint("10.0.0.2"), endpoint("10.0.0.1")}},
+		{ClusterName: "cluster-b", AddressType: discoveryv1.AddressTypeIPv4, Ports: http,
+			Endpoints: []discoveryv1.Endpoint{endpoint("10.0.0.2", "10.0.0.3"), endpoint("10.0.0.4")}},
+		{ClusterName: "cluster-c", AddressType: discoveryv1.AddressTypeIPv4, Ports: http,
+			Endpoints: []discoveryv1.Endpoint{endpoint("10.0.0.1")}},
+		{ClusterName: "cluster-d", AddressType: discoveryv1.AddressTypeIPv4, Ports: other,
+			Endpoints: []discoveryv1.Endpoint{endpoint("10.0.0.1")}},
+	}
+	deduped := dedupeAddresses(svcInfo, results)
+
+	want := map[string][][]string{
+		"cluster-a": {{"10.0.0.1"}, {"10.0.0.2"}},
+		"cluster-b": {{"10.0.0.3"}, {"10.0.0.4"}},
+		"cluster-d": {{"10.0.0.1"}},
+	}
+	if len(deduped) != len(want) {
+		t.Fatalf("expected clusters %v, got %d clusters", want, len(deduped))
+	}
+	for _, ce := range deduped {
+		wantEndpoints, ok := want[ce.ClusterName]
+		if !ok {
+			t.Errorf("expected cluster %s to be dropped", ce.ClusterName)
+			continue
+		}
+		if len(ce.Endpoints) != len(wantEndpoints) {
+			t.Errorf("expected endpoints %v for cluster %s, got %v", wantEndpoints, ce.ClusterName, ce.Endpoints)
+			continue
+		}
+		for i, ep := range ce.Endpoints {
+			if len(ep.Addresses) != len(wantEndpoints[i]) || ep.Addresses[0] != wantEndpoints[i][0] {
+				t.Errorf("expected addresses %v for endpoint %d of cluster %s, got %v", wantEndpoints[i], i, ce.ClusterName, ep.Addresses)
+			}
+		}
+	}
+	if len(results[1].Endpoints[0].Addresses) != 2 {
+		t.Error("expected the endpoints to be copied rather than modified in place")
+	}
+}
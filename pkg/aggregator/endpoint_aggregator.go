@@ -6,6 +6,7 @@ package aggregator
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,103 +14,290 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
 	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
 	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
 )
 
 // EndpointAggregator aggregates endpoints from multiple clusters
 type EndpointAggregator struct {
 	kubeClient client.Client
+	// links holds the EndpointSlice informers of the linked clusters, nil lists remote EndpointSlices every time
+	links *clusterlink.Links
+	// maxClusters limits the number of clusters contributing endpoints to a service; 0 disables the limit
+	maxClusters int
+	// localPriority is the failover priority of the local cluster
+	localPriority int32
+	// nodes caches the nodes of remote clusters for the node filters of ClusterLinks
+	nodes nodeInfoCache
+	// health remembers the health of the endpoints of remote clusters with a health check
+	health healthChecker
+	// publishNotReady is which services have their not-ready endpoints imported, one of the
+	// config.PublishNotReadyAddresses* values
+	publishNotReady string
+	// portMergePolicy is how the ports of a service exported by several clusters are merged, one of the
+	// config.PortMergePolicy* values
+	portMergePolicy string
 }
 
 // NewEndpointAggregator creates a new EndpointAggregator
-func NewEndpointAggregator(kubeClient client.Client) *EndpointAggregator {
+func NewEndpointAggregator(kubeClient client.Client, links *clusterlink.Links, cfg *config.Config) *EndpointAggregator {
 	return &EndpointAggregator{
-		kubeClient: kubeClient,
+		kubeClient:    kubeClient,
+		links:         links,
+		maxClusters:   cfg.MaxClustersPerService,
+		localPriority: cfg.LocalClusterPriority,
+
+		publishNotReady: cfg.PublishNotReadyAddresses,
+		portMergePolicy: cfg.PortMergePolicy,
 	}
 }
 
-// ClusterEndpoints represents endpoints from a specific cluster
+// ClusterEndpoints represents endpoints of a single address type from a specific cluster
 type ClusterEndpoints struct {
 	ClusterName string
+	AddressType discoveryv1.AddressType
 	Endpoints   []discoveryv1.Endpoint
 	Ports       []discoveryv1.EndpointPort
+	// SourceNamespace is the namespace of the service in the remote cluster
+	SourceNamespace string
+	// SourceSlices are the remote EndpointSlices the endpoints were read from, in name order
+	SourceSlices []SourceSlice
+}
+
+// SourceSlice identifies the version of a remote EndpointSlice endpoints were read from
+type SourceSlice struct {
+	Name            string
+	ResourceVersion string
+	Generation      int64
 }
 
-// AggregateEndpoints collects endpoints for a service from all clusters
-func (ea *EndpointAggregator) AggregateEndpoints(ctx context.Context, namespace, serviceName string, clusters []string, clusterInfos map[string]*clusterlink.ClusterInfo) ([]ClusterEndpoints, error) {
+// AggregateEndpoints collects endpoints for a service from all clusters.
+// Endpoints are read from the namespace and name of the service in each remote cluster. Clusters are
+// visited in preference order, and at most maxClusters clusters contribute endpoints when it is set.
+func (ea *EndpointAggregator) AggregateEndpoints(ctx context.Context, svcInfo *discoverer.ServiceInfo, clusterInfos map[string]*clusterlink.ClusterInfo) ([]ClusterEndpoints, error) {
 	var results []ClusterEndpoints
+	serviceName := svcInfo.SourceServiceName()
+	contributing := 0
 
-	for _, clusterName := range clusters {
+	for _, clusterName := range orderClusters(svcInfo.Clusters, clusterInfos) {
+		if ea.maxClusters > 0 && contributing >= ea.maxClusters {
+			logging.V(4, clusterName, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Skipping cluster %s for service %s/%s: %d clusters already contribute endpoints",
+				clusterName, svcInfo.Namespace, svcInfo.Name, contributing)
+			continue
+		}
 		clusterInfo, ok := clusterInfos[clusterName]
 		if !ok {
 			klog.V(4).Infof("Cluster %s not found or not enabled, skipping", clusterName)
 			continue
 		}
+		namespace := svcInfo.SourceNamespace(clusterName)
+
+		spec := clusterInfo.ClusterLink.Spec
+		addressTypes := spec.ToAddressTypeSet()
 
-		endpoints, ports, err := ea.getEndpointsFromCluster(ctx, clusterInfo.Client, namespace, serviceName)
+		var endpointsByType []ClusterEndpoints
+		var err error
+		switch spec.EndpointMode {
+		case svclinkv1alpha1.EndpointModeClusterIP:
+			endpointsByType, err = ea.getClusterIPEndpointsFromCluster(ctx, clusterInfo.Client, namespace, serviceName)
+		case svclinkv1alpha1.EndpointModeNodePort:
+			endpointsByType, err = ea.getNodePortEndpointsFromCluster(ctx, clusterInfo.Client, namespace, serviceName)
+		case svclinkv1alpha1.EndpointModeGateway:
+			endpointsByType, err = ea.getGatewayEndpointsFromCluster(ctx, clusterInfo.Client, namespace, serviceName, &spec)
+		default:
+			endpointsByType, err = ea.getEndpointsFromCluster(ctx, clusterInfo.Client, namespace, serviceName, spec.ReadinessPolicy,
+				ea.publishesNotReady(svcInfo, clusterName))
+			if err == nil && needsNodeFilter(&spec) {
+				endpointsByType, err = ea.filterByNodes(ctx, clusterInfo, &spec, endpointsByType)
+			}
+		}
+		if err == nil {
+			var translator *addressTranslator
+			if translator, err = newAddressTranslator(spec.AddressTranslations); err == nil {
+				endpointsByType = translator.translateEndpoints(endpointsByType)
+			}
+		}
 		if err != nil {
 			klog.Warningf("Failed to get endpoints from cluster %s for service %s/%s: %v",
 				clusterInfo.Name, namespace, serviceName, err)
 			continue
 		}
+		if spec.HealthCheck != nil {
+			endpointsByType = ea.health.filter(ctx, clusterInfo.Name, spec.HealthCheck, endpointsByType)
+		}
+		if !spec.PreserveNodeNames {
+			endpointsByType = stripNodeNames(endpointsByType)
+		}
+		endpointsByType = mapZones(endpointsByType, spec.ToZoneMap())
 
-		if len(endpoints) > 0 {
-			results = append(results, ClusterEndpoints{
-				ClusterName: clusterInfo.Name,
-				Endpoints:   endpoints,
-				Ports:       ports,
-			})
-			klog.V(4).Infof("Aggregated %d endpoints from cluster %s for service %s/%s",
-				len(endpoints), clusterInfo.Name, namespace, serviceName)
+		contributed := false
+		for _, ce := range endpointsByType {
+			if spec.ShouldExcludeAddressType(ce.AddressType, &addressTypes) {
+				logging.V(5, clusterInfo.Name, namespace, namespace+"/"+serviceName).Infof("Skipping %d %s endpoints from cluster %s for service %s/%s due to address type filter",
+					len(ce.Endpoints), ce.AddressType, clusterInfo.Name, namespace, serviceName)
+				continue
+			}
+
+			ce.ClusterName = clusterInfo.Name
+			ce.SourceNamespace = namespace
+			results = append(results, ce)
+			contributed = true
+			logging.V(4, clusterInfo.Name, namespace, namespace+"/"+serviceName).Infof("Aggregated %d %s endpoints from cluster %s for service %s/%s",
+				len(ce.Endpoints), ce.AddressType, clusterInfo.Name, namespace, serviceName)
+		}
+		if contributed {
+			contributing++
 		}
 	}
 
+	results = restrictPorts(svcInfo, results, ea.portMergePolicy)
+	results = dedupeAddresses(svcInfo, results)
+	reportHostnameConflicts(svcInfo, results)
+	results, err := ea.applyFailover(ctx, svcInfo, results, clusterInfos)
+	if err != nil {
+		return nil, err
+	}
+	return applyEndpointWeights(results, clusterInfos), nil
+}
+
+// filterByNodes applies the node filters of the spec to the endpoints of a cluster, dropping address
+// types left without endpoints. FQDN endpoints point outside of the cluster and are not filtered.
+func (ea *EndpointAggregator) filterByNodes(
+	ctx context.Context,
+	clusterInfo *clusterlink.ClusterInfo,
+	spec *svclinkv1alpha1.ClusterLinkSpec,
+	endpointsByType []ClusterEndpoints,
+) ([]ClusterEndpoints, error) {
+	nodes, err := ea.nodes.get(ctx, clusterInfo.Name, clusterInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var results []ClusterEndpoints
+	for _, ce := range endpointsByType {
+		if ce.AddressType == discoveryv1.AddressTypeFQDN {
+			results = append(results, ce)
+			continue
+		}
+		ce.Endpoints = filterNodeEndpoints(ce.Endpoints, spec, nodes)
+		if len(ce.Endpoints) > 0 {
+			results = append(results, ce)
+		}
+	}
 	return results, nil
 }
 
-// getEndpointsFromCluster retrieves endpoints from a single cluster
+// publishesNotReady reports whether the not-ready endpoints of a service are imported from a cluster
+func (ea *EndpointAggregator) publishesNotReady(svcInfo *discoverer.ServiceInfo, clusterName string) bool {
+	switch ea.publishNotReady {
+	case config.PublishNotReadyAddressesAlways:
+		return true
+	case config.PublishNotReadyAddressesNever:
+		return false
+	default:
+		return svcInfo.PublishNotReadyAddresses[clusterName]
+	}
+}
+
+// getEndpointsFromCluster retrieves the endpoints published under the readiness policy from a single
+// cluster, grouped by address type. Address types without any published endpoints are omitted. With
+// publishNotReady, not-ready endpoints are published as ready, like the EndpointSlice controller does for
+// services that set spec.publishNotReadyAddresses.
 func (ea *EndpointAggregator) getEndpointsFromCluster(
 	ctx context.Context,
 	client kubernetes.Interface,
 	namespace, serviceName string,
-) ([]discoveryv1.Endpoint, []discoveryv1.EndpointPort, error) {
+	policy svclinkv1alpha1.ReadinessPolicy,
+	publishNotReady bool,
+) ([]ClusterEndpoints, error) {
 	// Get EndpointSlices for the service
-	sliceList, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("kubernetes.io/service-name=%s", serviceName),
-	})
+	slices, err := ea.listEndpointSlices(ctx, client, namespace, serviceName)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	var allEndpoints []discoveryv1.Endpoint
-	var ports []discoveryv1.EndpointPort
+	// Slices are merged in name order, so that port conflicts are resolved the same way every sync
+	sort.Slice(slices, func(i, j int) bool { return slices[i].Name < slices[j].Name })
+
+	byType := make(map[discoveryv1.AddressType]*ClusterEndpoints)
+	var addressTypes []discoveryv1.AddressType
 
-	for _, slice := range sliceList.Items {
+	for _, slice := range slices {
 		// Skip EndpointSlices created by svclink to avoid circular synchronization
-		// These slices have the cloudpilot.ai/svclink-cluster label
-		if _, isSyncedSlice := slice.Labels[config.ClusterLabel]; isSyncedSlice {
+		if config.IsManagedByUs(&slice) {
+			sourceCluster, _ := config.SourceCluster(&slice)
 			klog.V(5).Infof("Skipping svclink managed EndpointSlice %s/%s (cluster: %s)",
-				slice.Namespace, slice.Name, slice.Labels[config.ClusterLabel])
+				slice.Namespace, slice.Name, sourceCluster)
 			continue
 		}
 
-		// Collect endpoints from native Kubernetes EndpointSlices only
-		allEndpoints = append(allEndpoints, slice.Endpoints...)
+		ce, ok := byType[slice.AddressType]
+		if !ok {
+			ce = &ClusterEndpoints{AddressType: slice.AddressType}
+			byType[slice.AddressType] = ce
+			addressTypes = append(addressTypes, slice.AddressType)
+		}
 
-		// Use ports from the first slice (they should be the same across slices)
-		if len(ports) == 0 && len(slice.Ports) > 0 {
-			ports = slice.Ports
+		// Ports are the union of the ports of all slices. A slice defining a port differently than the slices
+		// before it, e.g. during the rollout of a named port to another number, would have its endpoints
+		// published with the wrong port and is skipped.
+		ports, conflict := mergePorts(ce.Ports, slice.Ports)
+		if conflict != "" {
+			klog.Warningf("Skipping the %d endpoints of EndpointSlice %s/%s: %s", len(slice.Endpoints), slice.Namespace, slice.Name, conflict)
+			continue
+		}
+		ce.Ports = ports
+		ce.SourceSlices = append(ce.SourceSlices, SourceSlice{
+			Name:            slice.Name,
+			ResourceVersion: slice.ResourceVersion,
+			Generation:      slice.Generation,
+		})
+
+		// Collect endpoints from native Kubernetes EndpointSlices only
+		for _, ep := range slice.Endpoints {
+			if !matchesAddressType(ep, slice.AddressType) {
+				klog.V(4).Infof("Skipping endpoint %v of EndpointSlice %s/%s: addresses don't match address type %s",
+					ep.Addresses, slice.Namespace, slice.Name, slice.AddressType)
+				continue
+			}
+			if slice.AddressType == discoveryv1.AddressTypeFQDN {
+				ep = assumeReady(ep)
+			}
+			if publishNotReady {
+				ep = markReady(ep)
+			}
+			if published, ok := applyReadinessPolicy(ep, policy); ok {
+				ce.Endpoints = append(ce.Endpoints, published)
+			}
 		}
 	}
 
-	// Filter only ready endpoints
-	var readyEndpoints []discoveryv1.Endpoint
-	for _, ep := range allEndpoints {
-		if ep.Conditions.Ready != nil && *ep.Conditions.Ready {
-			readyEndpoints = append(readyEndpoints, ep)
+	var results []ClusterEndpoints
+	for _, addressType := range addressTypes {
+		if ce := byType[addressType]; len(ce.Endpoints) > 0 {
+			results = append(results, *ce)
 		}
 	}
 
-	return readyEndpoints, ports, nil
+	return results, nil
+}
+
+// listEndpointSlices returns the EndpointSlices of a remote service, read from the informer of the cluster when
+// remote EndpointSlices are watched and listed otherwise. Cached EndpointSlices exclude the ones svclink manages.
+func (ea *EndpointAggregator) listEndpointSlices(ctx context.Context, client kubernetes.Interface, namespace, serviceName string) ([]discoveryv1.EndpointSlice, error) {
+	if ea.links != nil {
+		if slices, ok := ea.links.CachedEndpointSlices(client, namespace, serviceName); ok {
+			return slices, nil
+		}
+	}
+	sliceList, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("kubernetes.io/service-name=%s", serviceName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sliceList.Items, nil
 }
@@ -2,12 +2,17 @@ package aggregator
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
 
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
 	"github.com/cloudpilot-ai/svclink/pkg/config"
 )
 
@@ -20,8 +25,9 @@ func TestGetEndpointsFromCluster_SkipsSyncedSlices(t *testing.T) {
 	// Create test EndpointSlices
 	nativeSlice := &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-service-abc123",
-			Namespace: "default",
+			Name:       "test-service-abc123",
+			Namespace:  "default",
+			Generation: 3,
 			Labels: map[string]string{
 				"kubernetes.io/service-name": "test-service",
 			},
@@ -81,10 +87,11 @@ func TestGetEndpointsFromCluster_SkipsSyncedSlices(t *testing.T) {
 	aggregator := &EndpointAggregator{}
 
 	// Get endpoints
-	endpoints, ports, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service")
+	results, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service", svclinkv1alpha1.ReadinessPolicyRespect, false)
 	if err != nil {
 		t.Fatalf("getEndpointsFromCluster failed: %v", err)
 	}
+	endpoints, ports := flattenClusterEndpoints(results)
 
 	// Verify only native endpoints are returned (synced slice should be skipped)
 	if len(endpoints) != 2 {
@@ -108,6 +115,12 @@ func TestGetEndpointsFromCluster_SkipsSyncedSlices(t *testing.T) {
 	if len(ports) != 1 {
 		t.Errorf("Expected 1 port, got %d", len(ports))
 	}
+
+	// Verify only the native slice is recorded as source
+	if len(results) != 1 || len(results[0].SourceSlices) != 1 ||
+		results[0].SourceSlices[0].Name != "test-service-abc123" || results[0].SourceSlices[0].Generation != 3 {
+		t.Errorf("Expected the native slice as only source, got %+v", results)
+	}
 }
 
 // TestGetEndpointsFromCluster_WithOnlySyncedSlices verifies behavior when
@@ -171,10 +184,11 @@ func TestGetEndpointsFromCluster_WithOnlySyncedSlices(t *testing.T) {
 	aggregator := &EndpointAggregator{}
 
 	// Get endpoints
-	endpoints, ports, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service")
+	results, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service", svclinkv1alpha1.ReadinessPolicyRespect, false)
 	if err != nil {
 		t.Fatalf("getEndpointsFromCluster failed: %v", err)
 	}
+	endpoints, ports := flattenClusterEndpoints(results)
 
 	// Should return empty (all slices are synced and should be skipped)
 	if len(endpoints) != 0 {
@@ -187,7 +201,391 @@ func TestGetEndpointsFromCluster_WithOnlySyncedSlices(t *testing.T) {
 	}
 }
 
+// TestGetEndpointsFromCluster_GroupsByAddressType verifies that endpoints from
+// dual-stack services are returned per address type instead of being merged.
+func TestGetEndpointsFromCluster_GroupsByAddressType(t *testing.T) {
+	ctx := context.Background()
+
+	ipv4Slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service-ipv4",
+			Namespace: "default",
+			Labels: map[string]string{
+				"kubernetes.io/service-name": "test-service",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"10.0.1.1"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready: boolPtr(true),
+				},
+			},
+		},
+	}
+
+	ipv6Slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service-ipv6",
+			Namespace: "default",
+			Labels: map[string]string{
+				"kubernetes.io/service-name": "test-service",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv6,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"fd00::1"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready: boolPtr(true),
+				},
+			},
+			{
+				Addresses: []string{"fd00::2"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready: boolPtr(true),
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(ipv4Slice, ipv6Slice)
+	aggregator := &EndpointAggregator{}
+
+	results, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service", svclinkv1alpha1.ReadinessPolicyRespect, false)
+	if err != nil {
+		t.Fatalf("getEndpointsFromCluster failed: %v", err)
+	}
+
+	counts := make(map[discoveryv1.AddressType]int)
+	for _, ce := range results {
+		counts[ce.AddressType] += len(ce.Endpoints)
+	}
+	if counts[discoveryv1.AddressTypeIPv4] != 1 {
+		t.Errorf("Expected 1 IPv4 endpoint, got %d", counts[discoveryv1.AddressTypeIPv4])
+	}
+	if counts[discoveryv1.AddressTypeIPv6] != 2 {
+		t.Errorf("Expected 2 IPv6 endpoints, got %d", counts[discoveryv1.AddressTypeIPv6])
+	}
+}
+
+func TestGetEndpointsFromCluster_FQDN(t *testing.T) {
+	ctx := context.Background()
+
+	fqdnSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service-fqdn",
+			Namespace: "default",
+			Labels: map[string]string{
+				"kubernetes.io/service-name": "test-service",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeFQDN,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				// External integrations often leave the conditions unset
+				Addresses: []string{"api.partner.example.com"},
+			},
+			{
+				Addresses: []string{"backup.partner.example.com"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready: boolPtr(false),
+				},
+			},
+		},
+	}
+	ipv4Slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service-ipv4",
+			Namespace: "default",
+			Labels: map[string]string{
+				"kubernetes.io/service-name": "test-service",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"10.0.1.1"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(fqdnSlice, ipv4Slice)
+	aggregator := &EndpointAggregator{}
+
+	results, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service", svclinkv1alpha1.ReadinessPolicyRespect, false)
+	if err != nil {
+		t.Fatalf("getEndpointsFromCluster failed: %v", err)
+	}
+
+	if len(results) != 1 || results[0].AddressType != discoveryv1.AddressTypeFQDN {
+		t.Fatalf("Expected only FQDN endpoints, got %+v", results)
+	}
+	if len(results[0].Endpoints) != 1 || results[0].Endpoints[0].Addresses[0] != "api.partner.example.com" {
+		t.Fatalf("Expected the FQDN endpoint without conditions to be published as ready, got %+v", results[0].Endpoints)
+	}
+	if !isReady(results[0].Endpoints[0]) {
+		t.Errorf("Expected the published FQDN endpoint to be ready")
+	}
+}
+
+func TestGetEndpointsFromCluster_PublishNotReady(t *testing.T) {
+	ctx := context.Background()
+
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-service-abc123",
+			Namespace: "default",
+			Labels: map[string]string{
+				"kubernetes.io/service-name": "test-service",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"10.0.1.1"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready: boolPtr(true),
+				},
+			},
+			{
+				Addresses: []string{"10.0.1.2"},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready:   boolPtr(false),
+					Serving: boolPtr(false),
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(slice)
+	aggregator := &EndpointAggregator{}
+
+	results, err := aggregator.getEndpointsFromCluster(ctx, fakeClient, "default", "test-service", svclinkv1alpha1.ReadinessPolicyRespect, true)
+	if err != nil {
+		t.Fatalf("getEndpointsFromCluster failed: %v", err)
+	}
+
+	endpoints, _ := flattenClusterEndpoints(results)
+	if len(endpoints) != 2 {
+		t.Fatalf("Expected the not-ready endpoint to be published, got %d endpoints", len(endpoints))
+	}
+	notReady := endpoints[1]
+	if !*notReady.Conditions.Ready || *notReady.Conditions.Serving {
+		t.Errorf("Expected the not-ready endpoint to be published as ready but not serving, got %+v", notReady.Conditions)
+	}
+
+	svcInfo := &discoverer.ServiceInfo{PublishNotReadyAddresses: map[string]bool{"cluster-a": true}}
+	for _, tt := range []struct {
+		mode    string
+		cluster string
+		want    bool
+	}{
+		{mode: config.PublishNotReadyAddressesService, cluster: "cluster-a", want: true},
+		{mode: config.PublishNotReadyAddressesService, cluster: "cluster-b", want: false},
+		{mode: config.PublishNotReadyAddressesAlways, cluster: "cluster-b", want: true},
+		{mode: config.PublishNotReadyAddressesNever, cluster: "cluster-a", want: false},
+	} {
+		aggregator := &EndpointAggregator{publishNotReady: tt.mode}
+		if got := aggregator.publishesNotReady(svcInfo, tt.cluster); got != tt.want {
+			t.Errorf("publishesNotReady(%s, %s) = %v, want %v", tt.mode, tt.cluster, got, tt.want)
+		}
+	}
+}
+
+// TestGetClusterIPEndpointsFromCluster verifies that ClusterIP mode publishes the remote
+// service ClusterIPs with service ports, and rejects services without a ClusterIP.
+func TestGetClusterIPEndpointsFromCluster(t *testing.T) {
+	ctx := context.Background()
+
+	dualStack := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "dual-stack", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10", "fd00:10:96::a"},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP},
+			},
+		},
+	}
+	headless := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "headless", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(dualStack, headless)
+	aggregator := &EndpointAggregator{}
+
+	results, err := aggregator.getClusterIPEndpointsFromCluster(ctx, fakeClient, "default", "dual-stack")
+	if err != nil {
+		t.Fatalf("getClusterIPEndpointsFromCluster failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 address families, got %d", len(results))
+	}
+	if results[0].AddressType != discoveryv1.AddressTypeIPv4 || results[0].Endpoints[0].Addresses[0] != "10.96.0.10" {
+		t.Errorf("Unexpected IPv4 endpoint: %+v", results[0])
+	}
+	if results[1].AddressType != discoveryv1.AddressTypeIPv6 {
+		t.Errorf("Expected IPv6 address type, got %s", results[1].AddressType)
+	}
+	if len(results[0].Ports) != 1 || *results[0].Ports[0].Port != 80 {
+		t.Errorf("Expected service port 80, got %+v", results[0].Ports)
+	}
+
+	if _, err := aggregator.getClusterIPEndpointsFromCluster(ctx, fakeClient, "default", "headless"); err == nil {
+		t.Error("Expected error for headless service without ClusterIP")
+	}
+}
+
+func TestGetNodePortEndpointsFromCluster(t *testing.T) {
+	ctx := context.Background()
+
+	node := func(name, ip string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	service := func(name string, policy corev1.ServiceExternalTrafficPolicy) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeNodePort,
+				ExternalTrafficPolicy: policy,
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP},
+				},
+			},
+		}
+	}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "local-abc",
+			Namespace: "default",
+			Labels:    map[string]string{"kubernetes.io/service-name": "local"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, NodeName: ptr.To("node-a"), Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+			{Addresses: []string{"10.0.0.2"}, NodeName: ptr.To("node-b"), Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+		},
+	}
+	clusterIP := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-ip", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(
+		node("node-a", "192.168.0.1", corev1.ConditionTrue),
+		node("node-b", "192.168.0.2", corev1.ConditionTrue),
+		node("node-c", "192.168.0.3", corev1.ConditionFalse),
+		service("cluster", corev1.ServiceExternalTrafficPolicyCluster),
+		service("local", corev1.ServiceExternalTrafficPolicyLocal),
+		slice,
+		clusterIP,
+	)
+	aggregator := &EndpointAggregator{}
+
+	tests := []struct {
+		name          string
+		service       string
+		expectedNodes []string
+		expectedErr   bool
+	}{
+		{name: "cluster policy publishes every ready node", service: "cluster", expectedNodes: []string{"192.168.0.1", "192.168.0.2"}},
+		{name: "local policy publishes nodes with ready endpoints", service: "local", expectedNodes: []string{"192.168.0.1"}},
+		{name: "service without node ports", service: "cluster-ip", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := aggregator.getNodePortEndpointsFromCluster(ctx, fakeClient, "default", tt.service)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr {
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 address family, got %d", len(results))
+			}
+			var addresses []string
+			for _, ep := range results[0].Endpoints {
+				addresses = append(addresses, ep.Addresses[0])
+			}
+			if !reflect.DeepEqual(addresses, tt.expectedNodes) {
+				t.Errorf("Expected node addresses %v, got %v", tt.expectedNodes, addresses)
+			}
+			if *results[0].Ports[0].Port != 30080 {
+				t.Errorf("Expected node port 30080, got %d", *results[0].Ports[0].Port)
+			}
+		})
+	}
+}
+
+func TestApplyReadinessPolicy(t *testing.T) {
+	ready := discoveryv1.Endpoint{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(true)}}
+	notReady := discoveryv1.Endpoint{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(false)}}
+	terminating := discoveryv1.Endpoint{Addresses: []string{"10.0.0.3"}, Conditions: discoveryv1.EndpointConditions{
+		Ready: boolPtr(false), Serving: boolPtr(true), Terminating: boolPtr(true)}}
+
+	tests := []struct {
+		name          string
+		policy        svclinkv1alpha1.ReadinessPolicy
+		endpoint      discoveryv1.Endpoint
+		wantPublished bool
+		wantReady     bool
+		wantServing   bool
+	}{
+		{name: "respect ready", policy: svclinkv1alpha1.ReadinessPolicyRespect, endpoint: ready, wantPublished: true, wantReady: true},
+		{name: "respect not ready", policy: svclinkv1alpha1.ReadinessPolicyRespect, endpoint: notReady, wantPublished: false},
+		{name: "respect terminating", policy: svclinkv1alpha1.ReadinessPolicyRespect, endpoint: terminating, wantPublished: false},
+		{name: "default policy", policy: "", endpoint: ready, wantPublished: true, wantReady: true},
+		{name: "force ready not ready", policy: svclinkv1alpha1.ReadinessPolicyForceReady, endpoint: notReady, wantPublished: true, wantReady: true},
+		{name: "serving only terminating", policy: svclinkv1alpha1.ReadinessPolicyForceServingOnly, endpoint: terminating, wantPublished: true, wantReady: true},
+		{name: "serving only not ready", policy: svclinkv1alpha1.ReadinessPolicyForceServingOnly, endpoint: notReady, wantPublished: false},
+		{name: "include terminating ready", policy: svclinkv1alpha1.ReadinessPolicyIncludeTerminating, endpoint: ready, wantPublished: true, wantReady: true},
+		{name: "include terminating terminating", policy: svclinkv1alpha1.ReadinessPolicyIncludeTerminating, endpoint: terminating, wantPublished: true, wantReady: false, wantServing: true},
+		{name: "include terminating not ready", policy: svclinkv1alpha1.ReadinessPolicyIncludeTerminating, endpoint: notReady, wantPublished: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			published, ok := applyReadinessPolicy(tt.endpoint, tt.policy)
+			if ok != tt.wantPublished {
+				t.Fatalf("expected published=%v, got %v", tt.wantPublished, ok)
+			}
+			if ok && (*published.Conditions.Ready != tt.wantReady) {
+				t.Errorf("expected ready=%v, got %v", tt.wantReady, *published.Conditions.Ready)
+			}
+			if ok && tt.wantServing && (published.Conditions.Serving == nil || !*published.Conditions.Serving ||
+				published.Conditions.Terminating == nil || !*published.Conditions.Terminating) {
+				t.Errorf("expected the serving and terminating conditions to be preserved, got %+v", published.Conditions)
+			}
+		})
+	}
+}
+
 // Helper functions
+func flattenClusterEndpoints(results []ClusterEndpoints) ([]discoveryv1.Endpoint, []discoveryv1.EndpointPort) {
+	var endpoints []discoveryv1.Endpoint
+	var ports []discoveryv1.EndpointPort
+	for _, ce := range results {
+		endpoints = append(endpoints, ce.Endpoints...)
+		ports = append(ports, ce.Ports...)
+	}
+	return endpoints, ports
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
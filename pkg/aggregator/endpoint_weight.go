@@ -0,0 +1,79 @@
+package aggregator
+
+import (
+	"math"
+	"sort"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+// defaultEndpointWeight is the weight of clusters without spec.endpointWeight
+const defaultEndpointWeight = 100
+
+// applyEndpointWeights subsets the endpoints of each address type so that every cluster's share of the
+// endpoints follows its spec.endpointWeight. The endpoints are scaled down to the largest counts the
+// clusters can provide in that proportion, and every cluster keeps at least one endpoint, so a weighted
+// cluster with few endpoints caps how finely the traffic can be split. Address types without any
+// weighted cluster are left unchanged.
+func applyEndpointWeights(results []ClusterEndpoints, clusterInfos map[string]*clusterlink.ClusterInfo) []ClusterEndpoints {
+	weight := func(ce ClusterEndpoints) (int32, bool) {
+		if info, ok := clusterInfos[ce.ClusterName]; ok && info.ClusterLink.Spec.EndpointWeight != nil {
+			return *info.ClusterLink.Spec.EndpointWeight, true
+		}
+		return defaultEndpointWeight, false
+	}
+
+	byType := make(map[discoveryv1.AddressType][]int)
+	for i, ce := range results {
+		byType[ce.AddressType] = append(byType[ce.AddressType], i)
+	}
+
+	for _, indexes := range byType {
+		weighted := false
+		scale := math.Inf(1)
+		for _, i := range indexes {
+			w, ok := weight(results[i])
+			weighted = weighted || ok
+			scale = math.Min(scale, float64(len(results[i].Endpoints))/float64(w))
+		}
+		if !weighted {
+			continue
+		}
+		for _, i := range indexes {
+			w, _ := weight(results[i])
+			count := int(math.Round(scale * float64(w)))
+			results[i].Endpoints = subsetEndpoints(results[i].Endpoints, max(count, 1))
+		}
+	}
+	return results
+}
+
+// subsetEndpoints returns count endpoints, preferring ready endpoints and then the lowest addresses so
+// that the same endpoints are kept across syncs
+func subsetEndpoints(endpoints []discoveryv1.Endpoint, count int) []discoveryv1.Endpoint {
+	if count >= len(endpoints) {
+		return endpoints
+	}
+	sorted := append([]discoveryv1.Endpoint(nil), endpoints...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iReady, jReady := isReady(sorted[i]), isReady(sorted[j])
+		if iReady != jReady {
+			return iReady
+		}
+		return firstAddress(sorted[i]) < firstAddress(sorted[j])
+	})
+	return sorted[:count]
+}
+
+func isReady(ep discoveryv1.Endpoint) bool {
+	return ep.Conditions.Ready == nil || *ep.Conditions.Ready
+}
+
+func firstAddress(ep discoveryv1.Endpoint) string {
+	if len(ep.Addresses) == 0 {
+		return ""
+	}
+	return ep.Addresses[0]
+}
@@ -0,0 +1,69 @@
+package aggregator
+
+import (
+	"fmt"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+func TestApplyEndpointWeights(t *testing.T) {
+	endpoints := func(cluster string, n int) ClusterEndpoints {
+		ce := ClusterEndpoints{ClusterName: cluster, AddressType: discoveryv1.AddressTypeIPv4}
+		for i := 0; i < n; i++ {
+			ce.Endpoints = append(ce.Endpoints, discoveryv1.Endpoint{Addresses: []string{fmt.Sprintf("10.0.0.%d", i)}})
+		}
+		return ce
+	}
+	info := func(weight *int32) *clusterlink.ClusterInfo {
+		return &clusterlink.ClusterInfo{
+			ClusterLink: svclinkv1alpha1.ClusterLink{Spec: svclinkv1alpha1.ClusterLinkSpec{EndpointWeight: weight}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		results      []ClusterEndpoints
+		clusterInfos map[string]*clusterlink.ClusterInfo
+		want         map[string]int
+	}{
+		{
+			name:         "unweighted clusters are unchanged",
+			results:      []ClusterEndpoints{endpoints("primary", 10), endpoints("dr", 4)},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"primary": info(nil), "dr": info(nil)},
+			want:         map[string]int{"primary": 10, "dr": 4},
+		},
+		{
+			name:         "90/10 split",
+			results:      []ClusterEndpoints{endpoints("primary", 9), endpoints("dr", 9)},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"primary": info(ptr.To[int32](90)), "dr": info(ptr.To[int32](10))},
+			want:         map[string]int{"primary": 9, "dr": 1},
+		},
+		{
+			name:         "unweighted cluster defaults to 100",
+			results:      []ClusterEndpoints{endpoints("primary", 4), endpoints("dr", 4)},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"primary": info(nil), "dr": info(ptr.To[int32](50))},
+			want:         map[string]int{"primary": 4, "dr": 2},
+		},
+		{
+			name:         "every cluster keeps one endpoint",
+			results:      []ClusterEndpoints{endpoints("primary", 2), endpoints("dr", 5)},
+			clusterInfos: map[string]*clusterlink.ClusterInfo{"primary": info(ptr.To[int32](99)), "dr": info(ptr.To[int32](1))},
+			want:         map[string]int{"primary": 2, "dr": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, ce := range applyEndpointWeights(tt.results, tt.clusterInfos) {
+				if got := len(ce.Endpoints); got != tt.want[ce.ClusterName] {
+					t.Errorf("cluster %s has %d endpoints, want %d", ce.ClusterName, got, tt.want[ce.ClusterName])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package aggregator
+
+import (
+	"context"
+	"math"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+)
+
+// applyFailover drops the endpoints of clusters with a lower spec.priority than the highest priority
+// with ready endpoints. The local cluster takes part with localPriority; its endpoints are only read
+// when a remote cluster of the service has a lower priority.
+func (ea *EndpointAggregator) applyFailover(
+	ctx context.Context,
+	svcInfo *discoverer.ServiceInfo,
+	results []ClusterEndpoints,
+	clusterInfos map[string]*clusterlink.ClusterInfo,
+) ([]ClusterEndpoints, error) {
+	priority := func(cluster string) int32 {
+		if info, ok := clusterInfos[cluster]; ok {
+			return info.ClusterLink.Spec.Priority
+		}
+		return 0
+	}
+
+	activePriority := int32(math.MinInt32)
+	lowest := int32(math.MaxInt32)
+	for _, ce := range results {
+		p := priority(ce.ClusterName)
+		lowest = min(lowest, p)
+		if p > activePriority && hasReadyEndpoints(ce.Endpoints) {
+			activePriority = p
+		}
+	}
+	if lowest < ea.localPriority && ea.localPriority > activePriority {
+		ready, err := ea.localReadyEndpoints(ctx, svcInfo.Namespace, svcInfo.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ready {
+			activePriority = ea.localPriority
+		}
+	}
+
+	var published []ClusterEndpoints
+	for _, ce := range results {
+		if priority(ce.ClusterName) < activePriority {
+			logging.V(4, ce.ClusterName, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Withholding %d %s endpoints from cluster %s for service %s/%s: clusters with priority %d have ready endpoints",
+				len(ce.Endpoints), ce.AddressType, ce.ClusterName, svcInfo.Namespace, svcInfo.Name, activePriority)
+			continue
+		}
+		published = append(published, ce)
+	}
+	return published, nil
+}
+
+// localReadyEndpoints reports whether the native EndpointSlices of the local service have ready endpoints
+func (ea *EndpointAggregator) localReadyEndpoints(ctx context.Context, namespace, name string) (bool, error) {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := ea.kubeClient.List(ctx, sliceList, client.InNamespace(namespace),
+		client.MatchingLabels{config.ServiceNameLabel: name}); err != nil {
+		return false, err
+	}
+	for i := range sliceList.Items {
+		if !config.IsManagedByUs(&sliceList.Items[i]) && hasReadyEndpoints(sliceList.Items[i].Endpoints) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func hasReadyEndpoints(endpoints []discoveryv1.Endpoint) bool {
+	for _, ep := range endpoints {
+		if isReady(ep) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package aggregator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+func TestApplyFailover(t *testing.T) {
+	endpoints := func(cluster string, ready ...bool) ClusterEndpoints {
+		ce := ClusterEndpoints{ClusterName: cluster, AddressType: discoveryv1.AddressTypeIPv4}
+		for _, r := range ready {
+			ce.Endpoints = append(ce.Endpoints, discoveryv1.Endpoint{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(r)},
+			})
+		}
+		return ce
+	}
+	clusterInfos := map[string]*clusterlink.ClusterInfo{}
+	for cluster, priority := range map[string]int32{"primary": 10, "secondary": 10, "dr": 1} {
+		clusterInfos[cluster] = &clusterlink.ClusterInfo{
+			ClusterLink: svclinkv1alpha1.ClusterLink{Spec: svclinkv1alpha1.ClusterLinkSpec{Priority: priority}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		results []ClusterEndpoints
+		want    []string
+	}{
+		{
+			name:    "lower priority withheld while higher priority is ready",
+			results: []ClusterEndpoints{endpoints("primary", true), endpoints("dr", true)},
+			want:    []string{"primary"},
+		},
+		{
+			name:    "same priority published together",
+			results: []ClusterEndpoints{endpoints("primary", false), endpoints("secondary", true), endpoints("dr", true)},
+			want:    []string{"primary", "secondary"},
+		},
+		{
+			name:    "fail over when higher priority has no ready endpoints",
+			results: []ClusterEndpoints{endpoints("primary", false), endpoints("dr", true)},
+			want:    []string{"primary", "dr"},
+		},
+		{
+			name:    "nothing ready publishes everything",
+			results: []ClusterEndpoints{endpoints("primary", false), endpoints("dr", false)},
+			want:    []string{"primary", "dr"},
+		},
+	}
+
+	ea := &EndpointAggregator{}
+	svcInfo := &discoverer.ServiceInfo{Name: "web", Namespace: "default"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			published, err := ea.applyFailover(context.Background(), svcInfo, tt.results, clusterInfos)
+			if err != nil {
+				t.Fatalf("applyFailover() error = %v", err)
+			}
+			var got []string
+			for _, ce := range published {
+				got = append(got, ce.ClusterName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyFailover() published %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
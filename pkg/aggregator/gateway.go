@@ -0,0 +1,94 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// getGatewayEndpointsFromCluster publishes the gateway address of the remote cluster as the single
+// endpoint of the service, with the service ports or node ports depending on the port allocation of
+// the spec. The gateway is only published while the remote service has ready endpoints, so that
+// failover and the cluster limit only count clusters that can serve the traffic.
+func (ea *EndpointAggregator) getGatewayEndpointsFromCluster(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace, serviceName string,
+	spec *svclinkv1alpha1.ClusterLinkSpec,
+) ([]ClusterEndpoints, error) {
+	ip := net.ParseIP(spec.GatewayAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid gateway address %q", spec.GatewayAddress)
+	}
+
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make([]discoveryv1.EndpointPort, 0, len(svc.Spec.Ports))
+	for _, svcPort := range svc.Spec.Ports {
+		port := svcPort.Port
+		if spec.GatewayPortAllocation == svclinkv1alpha1.GatewayPortAllocationNodePort {
+			if svcPort.NodePort == 0 {
+				continue
+			}
+			port = svcPort.NodePort
+		}
+		ports = append(ports, discoveryv1.EndpointPort{
+			Name:        ptr.To(svcPort.Name),
+			Protocol:    ptr.To(svcPort.Protocol),
+			Port:        ptr.To(port),
+			AppProtocol: svcPort.AppProtocol,
+		})
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("service %s/%s has no ports to publish on the gateway", namespace, serviceName)
+	}
+
+	ready, err := ea.remoteHasReadyEndpoints(ctx, client, namespace, serviceName)
+	if err != nil || !ready {
+		return nil, err
+	}
+
+	addressType := discoveryv1.AddressTypeIPv6
+	if ip.To4() != nil {
+		addressType = discoveryv1.AddressTypeIPv4
+	}
+	return []ClusterEndpoints{{
+		AddressType: addressType,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{ip.String()},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready:   ptr.To(true),
+					Serving: ptr.To(true),
+				},
+			},
+		},
+		Ports: ports,
+	}}, nil
+}
+
+// remoteHasReadyEndpoints reports whether the remote service has a ready endpoint in its native EndpointSlices
+func (ea *EndpointAggregator) remoteHasReadyEndpoints(ctx context.Context, client kubernetes.Interface, namespace, serviceName string) (bool, error) {
+	slices, err := ea.listEndpointSlices(ctx, client, namespace, serviceName)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range slices {
+		if !config.IsManagedByUs(&slices[i]) && hasReadyEndpoints(slices[i].Endpoints) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
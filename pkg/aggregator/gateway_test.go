@@ -0,0 +1,98 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestGetGatewayEndpointsFromCluster(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, NodePort: 30080}},
+		},
+	}
+	slice := func(ready bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-abc",
+				Namespace: "default",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		spec     svclinkv1alpha1.ClusterLinkSpec
+		ready    bool
+		wantPort int32
+		wantErr  bool
+	}{
+		{
+			name:     "service ports",
+			spec:     svclinkv1alpha1.ClusterLinkSpec{GatewayAddress: "203.0.113.10"},
+			ready:    true,
+			wantPort: 80,
+		},
+		{
+			name: "node ports",
+			spec: svclinkv1alpha1.ClusterLinkSpec{
+				GatewayAddress:        "203.0.113.10",
+				GatewayPortAllocation: svclinkv1alpha1.GatewayPortAllocationNodePort,
+			},
+			ready:    true,
+			wantPort: 30080,
+		},
+		{
+			name: "no ready endpoints",
+			spec: svclinkv1alpha1.ClusterLinkSpec{GatewayAddress: "203.0.113.10"},
+		},
+		{
+			name:    "invalid gateway address",
+			spec:    svclinkv1alpha1.ClusterLinkSpec{GatewayAddress: "gateway"},
+			ready:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(svc, slice(tt.ready))
+			ea := &EndpointAggregator{}
+			results, err := ea.getGatewayEndpointsFromCluster(context.Background(), client, "default", "web", &tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantPort == 0 {
+				if len(results) != 0 {
+					t.Errorf("expected no endpoints, got %+v", results)
+				}
+				return
+			}
+
+			if len(results) != 1 || len(results[0].Endpoints) != 1 || results[0].AddressType != discoveryv1.AddressTypeIPv4 {
+				t.Fatalf("expected a single IPv4 gateway endpoint, got %+v", results)
+			}
+			if address := results[0].Endpoints[0].Addresses[0]; address != "203.0.113.10" {
+				t.Errorf("expected gateway address, got %s", address)
+			}
+			if port := *results[0].Ports[0].Port; port != tt.wantPort {
+				t.Errorf("expected port %d, got %d", tt.wantPort, port)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+)
+
+const (
+	// defaultHealthCheckTimeout is how long a probe waits for an endpoint when the health check sets no timeout
+	defaultHealthCheckTimeout = 2 * time.Second
+	// defaultHealthCheckFailureThreshold is the number of consecutive failures after which an endpoint is
+	// unhealthy when the health check sets no threshold
+	defaultHealthCheckFailureThreshold = 3
+	// healthCheckConcurrency bounds the probes of a service running at the same time
+	healthCheckConcurrency = 32
+	// healthStateTTL is how long the health of an endpoint is remembered after it was last probed, so that the
+	// state of endpoints that went away is dropped
+	healthStateTTL = 10 * time.Minute
+)
+
+// endpointHealth is the outcome of the probes of an endpoint so far
+type endpointHealth struct {
+	healthy  bool
+	failures int32
+	probed   time.Time
+}
+
+// healthChecker probes the endpoints of clusters with a health check and remembers their health per cluster
+// and host:port across syncs. Services are synced concurrently, so the state is guarded by a mutex.
+type healthChecker struct {
+	// probe checks a single host:port, it is replaced in tests
+	probe func(ctx context.Context, check *svclinkv1alpha1.EndpointHealthCheck, address string) error
+
+	mu     sync.Mutex
+	health map[string]map[string]*endpointHealth
+}
+
+// filter probes the endpoints of a cluster and drops the unhealthy ones, along with address types left without
+// endpoints. Endpoints without a port to probe and FQDN endpoints are kept as they are.
+func (h *healthChecker) filter(
+	ctx context.Context,
+	cluster string,
+	check *svclinkv1alpha1.EndpointHealthCheck,
+	endpointsByType []ClusterEndpoints,
+) []ClusterEndpoints {
+	probe := h.probe
+	if probe == nil {
+		probe = probeEndpoint
+	}
+
+	// Probe the first address of every endpoint, at most once per sync
+	queued := make(map[string]bool)
+	outcomes := make(map[string]error)
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, healthCheckConcurrency)
+	)
+	for _, ce := range endpointsByType {
+		port, ok := healthCheckPort(check, ce)
+		if !ok {
+			continue
+		}
+		for _, ep := range ce.Endpoints {
+			if len(ep.Addresses) == 0 {
+				continue
+			}
+			address := net.JoinHostPort(ep.Addresses[0], strconv.Itoa(int(port)))
+			if queued[address] {
+				continue
+			}
+			queued[address] = true
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				err := probe(ctx, check, address)
+				mu.Lock()
+				outcomes[address] = err
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	healthy := h.record(cluster, check, outcomes)
+
+	var results []ClusterEndpoints
+	for _, ce := range endpointsByType {
+		port, ok := healthCheckPort(check, ce)
+		if !ok {
+			results = append(results, ce)
+			continue
+		}
+		endpoints := make([]discoveryv1.Endpoint, 0, len(ce.Endpoints))
+		for _, ep := range ce.Endpoints {
+			if len(ep.Addresses) > 0 && !healthy[net.JoinHostPort(ep.Addresses[0], strconv.Itoa(int(port)))] {
+				continue
+			}
+			endpoints = append(endpoints, ep)
+		}
+		if len(endpoints) > 0 {
+			ce.Endpoints = endpoints
+			results = append(results, ce)
+		}
+	}
+	return results
+}
+
+// record updates the health of the probed endpoints of a cluster and returns which of them are healthy. A new
+// endpoint is healthy once a probe succeeded, a healthy endpoint is unhealthy once FailureThreshold
+// consecutive probes failed.
+func (h *healthChecker) record(cluster string, check *svclinkv1alpha1.EndpointHealthCheck, outcomes map[string]error) map[string]bool {
+	threshold := ptr.Deref(check.FailureThreshold, defaultHealthCheckFailureThreshold)
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.health == nil {
+		h.health = make(map[string]map[string]*endpointHealth)
+	}
+	endpoints := h.health[cluster]
+	if endpoints == nil {
+		endpoints = make(map[string]*endpointHealth)
+		h.health[cluster] = endpoints
+	}
+
+	healthy := make(map[string]bool, len(outcomes))
+	for address, err := range outcomes {
+		state, known := endpoints[address]
+		if !known {
+			state = &endpointHealth{}
+			endpoints[address] = state
+		}
+		state.probed = now
+		switch {
+		case err == nil:
+			if known && !state.healthy {
+				logging.V(2, cluster).Infof("Endpoint %s of cluster %s passed its health check again", address, cluster)
+			}
+			state.healthy = true
+			state.failures = 0
+		case state.healthy:
+			state.failures++
+			if state.failures >= threshold {
+				state.healthy = false
+				logging.V(2, cluster).Infof("Endpoint %s of cluster %s failed %d health checks, it is no longer published: %v",
+					address, cluster, state.failures, err)
+			}
+		default:
+			state.failures++
+			logging.V(4, cluster).Infof("Endpoint %s of cluster %s is not published until it passes a health check: %v", address, cluster, err)
+		}
+		healthy[address] = state.healthy
+	}
+
+	for address, state := range endpoints {
+		if now.Sub(state.probed) > healthStateTTL {
+			delete(endpoints, address)
+		}
+	}
+	return healthy
+}
+
+// healthCheckPort returns the port probed for endpoints of an address type, which is the port of the health
+// check or else the first TCP port of the endpoints
+func healthCheckPort(check *svclinkv1alpha1.EndpointHealthCheck, ce ClusterEndpoints) (int32, bool) {
+	if ce.AddressType == discoveryv1.AddressTypeFQDN {
+		return 0, false
+	}
+	if check.Port != nil {
+		return *check.Port, true
+	}
+	for _, port := range ce.Ports {
+		if port.Port != nil && (port.Protocol == nil || *port.Protocol == corev1.ProtocolTCP) {
+			return *port.Port, true
+		}
+	}
+	return 0, false
+}
+
+// probeEndpoint connects to an endpoint, or sends it an HTTP GET request with the HTTP health check type
+func probeEndpoint(ctx context.Context, check *svclinkv1alpha1.EndpointHealthCheck, address string) error {
+	timeout := defaultHealthCheckTimeout
+	if check.TimeoutSeconds != nil {
+		timeout = time.Duration(*check.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if check.Type != svclinkv1alpha1.HealthCheckHTTP {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+path, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	return nil
+}
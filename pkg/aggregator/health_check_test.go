@@ -0,0 +1,100 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestHealthCheckerFilter(t *testing.T) {
+	failing := map[string]bool{}
+	h := &healthChecker{probe: func(_ context.Context, _ *svclinkv1alpha1.EndpointHealthCheck, address string) error {
+		if failing[address] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}}
+	check := &svclinkv1alpha1.EndpointHealthCheck{FailureThreshold: ptr.To[int32](2)}
+	endpointsByType := []ClusterEndpoints{
+		{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Port: ptr.To[int32](8080)}},
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}, {Addresses: []string{"10.0.0.2"}}},
+		},
+		{
+			AddressType: discoveryv1.AddressTypeFQDN,
+			Ports:       []discoveryv1.EndpointPort{{Port: ptr.To[int32](443)}},
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"db.example.com"}}},
+		},
+	}
+	published := func() []string {
+		var addresses []string
+		for _, ce := range h.filter(context.Background(), "cluster-a", check, endpointsByType) {
+			for _, ep := range ce.Endpoints {
+				addresses = append(addresses, ep.Addresses[0])
+			}
+		}
+		return addresses
+	}
+
+	// A new endpoint failing its first probe is not published, FQDN endpoints are not probed
+	failing["10.0.0.2:8080"] = true
+	if got := strings.Join(published(), ","); got != "10.0.0.1,db.example.com" {
+		t.Errorf("expected a new failing endpoint not to be published, got %s", got)
+	}
+
+	// A healthy endpoint is published until FailureThreshold consecutive probes failed
+	failing["10.0.0.1:8080"] = true
+	if got := strings.Join(published(), ","); got != "10.0.0.1,db.example.com" {
+		t.Errorf("expected a healthy endpoint to be published after a single failure, got %s", got)
+	}
+	if got := strings.Join(published(), ","); got != "db.example.com" {
+		t.Errorf("expected endpoints to be dropped after FailureThreshold failures, got %s", got)
+	}
+
+	// An unhealthy endpoint is published again once a probe succeeded
+	delete(failing, "10.0.0.1:8080")
+	delete(failing, "10.0.0.2:8080")
+	if got := strings.Join(published(), ","); got != "10.0.0.1,10.0.0.2,db.example.com" {
+		t.Errorf("expected recovered endpoints to be published, got %s", got)
+	}
+}
+
+func TestProbeEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	if err := probeEndpoint(context.Background(), &svclinkv1alpha1.EndpointHealthCheck{}, address); err != nil {
+		t.Errorf("expected the TCP probe to succeed, got %v", err)
+	}
+	if err := probeEndpoint(context.Background(), &svclinkv1alpha1.EndpointHealthCheck{Type: svclinkv1alpha1.HealthCheckHTTP, Path: "/healthz"}, address); err != nil {
+		t.Errorf("expected the HTTP probe to succeed, got %v", err)
+	}
+	if err := probeEndpoint(context.Background(), &svclinkv1alpha1.EndpointHealthCheck{Type: svclinkv1alpha1.HealthCheckHTTP}, address); err == nil {
+		t.Error("expected the HTTP probe to fail on an error status")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	_ = listener.Close()
+	if err := probeEndpoint(context.Background(), &svclinkv1alpha1.EndpointHealthCheck{}, closed); err == nil {
+		t.Error("expected the TCP probe of a closed port to fail")
+	}
+}
@@ -0,0 +1,52 @@
+package aggregator
+
+import (
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+)
+
+// stripNodeNames drops the node names and node hints of the endpoints, which name nodes of the remote cluster. Left in place,
+// kube-proxy would treat endpoints whose remote node shares the name of a local node as local endpoints of
+// that node for internalTrafficPolicy and externalTrafficPolicy Local. Endpoints are copied rather than
+// modified in place.
+func stripNodeNames(endpointsByType []ClusterEndpoints) []ClusterEndpoints {
+	for i, ce := range endpointsByType {
+		endpoints := make([]discoveryv1.Endpoint, 0, len(ce.Endpoints))
+		for _, ep := range ce.Endpoints {
+			ep.NodeName = nil
+			if ep.Hints != nil && len(ep.Hints.ForNodes) > 0 {
+				ep.Hints = &discoveryv1.EndpointHints{ForZones: ep.Hints.ForZones}
+			}
+			endpoints = append(endpoints, ep)
+		}
+		endpointsByType[i].Endpoints = endpoints
+	}
+	return endpointsByType
+}
+
+// reportHostnameConflicts logs the endpoint hostnames imported from several clusters. Per-pod DNS names of a
+// headless service resolve to the endpoints of every cluster publishing the hostname, e.g. the pods db-0 of a
+// StatefulSet deployed to several clusters.
+func reportHostnameConflicts(svcInfo *discoverer.ServiceInfo, results []ClusterEndpoints) {
+	clusters := make(map[string]sets.Set[string])
+	for _, ce := range results {
+		for _, ep := range ce.Endpoints {
+			if ep.Hostname == nil || *ep.Hostname == "" {
+				continue
+			}
+			if clusters[*ep.Hostname] == nil {
+				clusters[*ep.Hostname] = sets.New[string]()
+			}
+			clusters[*ep.Hostname].Insert(ce.ClusterName)
+		}
+	}
+	for _, hostname := range sets.List(sets.KeySet(clusters)) {
+		if clusters[hostname].Len() > 1 {
+			logging.V(2, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Hostname %s of service %s/%s is published by clusters %v, its per-pod DNS name resolves to all of them",
+				hostname, svcInfo.Namespace, svcInfo.Name, sets.List(clusters[hostname]))
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package aggregator
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestStripNodeNames(t *testing.T) {
+	endpoints := []discoveryv1.Endpoint{
+		{Addresses: []string{"10.0.0.1"}, Hostname: ptr.To("db-0"), NodeName: ptr.To("node-a")},
+	}
+	results := stripNodeNames([]ClusterEndpoints{{ClusterName: "cluster-a", Endpoints: endpoints}})
+
+	if results[0].Endpoints[0].NodeName != nil {
+		t.Errorf("expected the remote node name to be dropped, got %s", *results[0].Endpoints[0].NodeName)
+	}
+	if hostname := results[0].Endpoints[0].Hostname; hostname == nil || *hostname != "db-0" {
+		t.Errorf("expected the hostname to be kept, got %v", hostname)
+	}
+	if endpoints[0].NodeName == nil {
+		t.Error("expected the endpoints to be copied rather than modified in place")
+	}
+}
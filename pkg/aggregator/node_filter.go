@@ -0,0 +1,136 @@
+package aggregator
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// nodeInfoTTL is how long the node addresses and operating systems of a remote cluster are reused
+// across the services of that cluster
+const nodeInfoTTL = time.Minute
+
+// nodeInfo holds what the node filters need to know about the nodes of a remote cluster
+type nodeInfo struct {
+	addresses sets.Set[string]
+	os        map[string]string
+	fetched   time.Time
+}
+
+// nodeInfoCache caches nodeInfo per remote cluster
+type nodeInfoCache struct {
+	mu    sync.Mutex
+	nodes map[string]*nodeInfo
+}
+
+// get returns the node info of a cluster, listing its nodes when the cached info is older than nodeInfoTTL
+func (c *nodeInfoCache) get(ctx context.Context, cluster string, client kubernetes.Interface) (*nodeInfo, error) {
+	c.mu.Lock()
+	info, ok := c.nodes[cluster]
+	c.mu.Unlock()
+	if ok && time.Since(info.fetched) < nodeInfoTTL {
+		return info, nil
+	}
+
+	nodeList, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	info = &nodeInfo{addresses: sets.New[string](), os: make(map[string]string, len(nodeList.Items)), fetched: time.Now()}
+	for _, node := range nodeList.Items {
+		info.os[node.Name] = node.Labels[corev1.LabelOSStable]
+		for _, address := range node.Status.Addresses {
+			if ip := net.ParseIP(address.Address); ip != nil {
+				info.addresses.Insert(ip.String())
+			}
+		}
+	}
+
+	c.mu.Lock()
+	if c.nodes == nil {
+		c.nodes = make(map[string]*nodeInfo)
+	}
+	c.nodes[cluster] = info
+	c.mu.Unlock()
+	return info, nil
+}
+
+// needsNodeFilter reports whether the spec filters endpoints by the nodes they run on
+func needsNodeFilter(spec *svclinkv1alpha1.ClusterLinkSpec) bool {
+	return (spec.HostNetworkEndpoints != "" && spec.HostNetworkEndpoints != svclinkv1alpha1.HostNetworkEndpointsPublish) ||
+		len(spec.ExcludedNodeOperatingSystems) > 0
+}
+
+// filterNodeEndpoints applies the host-network policy and the excluded node operating systems of the spec.
+// Endpoints whose address is the address of a node are host-network endpoints.
+func filterNodeEndpoints(endpoints []discoveryv1.Endpoint, spec *svclinkv1alpha1.ClusterLinkSpec, nodes *nodeInfo) []discoveryv1.Endpoint {
+	excludedOS := sets.New(spec.ExcludedNodeOperatingSystems...)
+	published := sets.New[string]()
+
+	filtered := make([]discoveryv1.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.NodeName != nil && excludedOS.Has(nodes.os[*ep.NodeName]) {
+			continue
+		}
+		if len(ep.Addresses) > 0 && nodes.addresses.Has(canonicalAddress(ep.Addresses[0])) {
+			switch spec.HostNetworkEndpoints {
+			case svclinkv1alpha1.HostNetworkEndpointsExclude:
+				continue
+			case svclinkv1alpha1.HostNetworkEndpointsDeduplicate:
+				if published.Has(canonicalAddress(ep.Addresses[0])) {
+					continue
+				}
+				published.Insert(canonicalAddress(ep.Addresses[0]))
+			}
+		}
+		filtered = append(filtered, ep)
+	}
+	return filtered
+}
+
+// canonicalAddress returns the canonical form of an IP address, so that differently written IPv6
+// addresses compare equal
+func canonicalAddress(address string) string {
+	if ip := net.ParseIP(address); ip != nil {
+		return ip.String()
+	}
+	return address
+}
+
+// matchesAddressType reports whether all addresses of an endpoint belong to the address family of the
+// slice. Slices mixing families are rejected by the API server, e.g. IPv4-mapped IPv6 addresses reported
+// by some Windows nodes, and so are FQDN slices with addresses that are not fully qualified domain names.
+func matchesAddressType(ep discoveryv1.Endpoint, addressType discoveryv1.AddressType) bool {
+	for _, address := range ep.Addresses {
+		if addressType == discoveryv1.AddressTypeFQDN {
+			if len(validation.IsFullyQualifiedDomainName(field.NewPath("addresses"), address)) > 0 {
+				return false
+			}
+			continue
+		}
+		ip := net.ParseIP(address)
+		switch addressType {
+		case discoveryv1.AddressTypeIPv4:
+			if ip == nil || ip.To4() == nil || strings.Contains(address, ":") {
+				return false
+			}
+		case discoveryv1.AddressTypeIPv6:
+			if ip == nil || ip.To4() != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package aggregator
+
+import (
+	"reflect"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestFilterNodeEndpoints(t *testing.T) {
+	nodes := &nodeInfo{
+		addresses: sets.New("192.168.0.1", "192.168.0.2"),
+		os:        map[string]string{"linux-1": "linux", "win-1": "windows"},
+	}
+	endpoint := func(address, node string) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{Addresses: []string{address}, NodeName: ptr.To(node)}
+	}
+	endpoints := []discoveryv1.Endpoint{
+		endpoint("10.0.0.1", "linux-1"),
+		endpoint("192.168.0.1", "linux-1"),
+		endpoint("192.168.0.1", "linux-1"),
+		endpoint("10.0.1.1", "win-1"),
+	}
+
+	tests := []struct {
+		name string
+		spec svclinkv1alpha1.ClusterLinkSpec
+		want []string
+	}{
+		{
+			name: "publish host-network endpoints",
+			spec: svclinkv1alpha1.ClusterLinkSpec{HostNetworkEndpoints: svclinkv1alpha1.HostNetworkEndpointsPublish},
+			want: []string{"10.0.0.1", "192.168.0.1", "192.168.0.1", "10.0.1.1"},
+		},
+		{
+			name: "deduplicate host-network endpoints",
+			spec: svclinkv1alpha1.ClusterLinkSpec{HostNetworkEndpoints: svclinkv1alpha1.HostNetworkEndpointsDeduplicate},
+			want: []string{"10.0.0.1", "192.168.0.1", "10.0.1.1"},
+		},
+		{
+			name: "exclude host-network endpoints and windows nodes",
+			spec: svclinkv1alpha1.ClusterLinkSpec{
+				HostNetworkEndpoints:         svclinkv1alpha1.HostNetworkEndpointsExclude,
+				ExcludedNodeOperatingSystems: []string{"windows"},
+			},
+			want: []string{"10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ep := range filterNodeEndpoints(endpoints, &tt.spec, nodes) {
+				got = append(got, ep.Addresses[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterNodeEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesAddressType(t *testing.T) {
+	tests := []struct {
+		address     string
+		addressType discoveryv1.AddressType
+		want        bool
+	}{
+		{address: "10.0.0.1", addressType: discoveryv1.AddressTypeIPv4, want: true},
+		{address: "::ffff:10.0.0.1", addressType: discoveryv1.AddressTypeIPv4, want: false},
+		{address: "::ffff:10.0.0.1", addressType: discoveryv1.AddressTypeIPv6, want: false},
+		{address: "fd00::1", addressType: discoveryv1.AddressTypeIPv6, want: true},
+		{address: "fd00::1", addressType: discoveryv1.AddressTypeIPv4, want: false},
+		{address: "not-an-ip", addressType: discoveryv1.AddressTypeIPv4, want: false},
+		{address: "api.partner.example.com", addressType: discoveryv1.AddressTypeFQDN, want: true},
+		{address: "localhost", addressType: discoveryv1.AddressTypeFQDN, want: false},
+		{address: "Api.Example.com", addressType: discoveryv1.AddressTypeFQDN, want: false},
+	}
+
+	for _, tt := range tests {
+		ep := discoveryv1.Endpoint{Addresses: []string{tt.address}}
+		if got := matchesAddressType(ep, tt.addressType); got != tt.want {
+			t.Errorf("matchesAddressType(%s, %s) = %v, want %v", tt.address, tt.addressType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+// getNodePortEndpointsFromCluster publishes the remote node addresses and service node ports as
+// endpoints, for networks that only route remote node addresses. It honors the externalTrafficPolicy
+// of the remote service: with Local, only nodes hosting a ready endpoint are published, which is what
+// the health check node port of the service reports, as other nodes drop the traffic. It returns an
+// error when the remote service has no node ports.
+func (ea *EndpointAggregator) getNodePortEndpointsFromCluster(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace, serviceName string,
+) ([]ClusterEndpoints, error) {
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make([]discoveryv1.EndpointPort, 0, len(svc.Spec.Ports))
+	for _, svcPort := range svc.Spec.Ports {
+		if svcPort.NodePort == 0 {
+			continue
+		}
+		ports = append(ports, discoveryv1.EndpointPort{
+			Name:        ptr.To(svcPort.Name),
+			Protocol:    ptr.To(svcPort.Protocol),
+			Port:        ptr.To(svcPort.NodePort),
+			AppProtocol: svcPort.AppProtocol,
+		})
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("service %s/%s has no node ports", namespace, serviceName)
+	}
+
+	// With the Local policy, nodes without a ready endpoint fail the health check and drop the traffic
+	var localNodes sets.Set[string]
+	if svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+		localNodes, err = ea.nodesWithReadyEndpoints(ctx, client, namespace, serviceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nodeList, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[discoveryv1.AddressType]*ClusterEndpoints)
+	var addressTypes []discoveryv1.AddressType
+	for _, node := range nodeList.Items {
+		if !isNodeReady(&node) || (localNodes != nil && !localNodes.Has(node.Name)) {
+			continue
+		}
+
+		for _, address := range node.Status.Addresses {
+			if address.Type != corev1.NodeInternalIP {
+				continue
+			}
+			ip := net.ParseIP(address.Address)
+			if ip == nil {
+				continue
+			}
+
+			addressType := discoveryv1.AddressTypeIPv6
+			if ip.To4() != nil {
+				addressType = discoveryv1.AddressTypeIPv4
+			}
+			ce, ok := byType[addressType]
+			if !ok {
+				ce = &ClusterEndpoints{AddressType: addressType, Ports: ports}
+				byType[addressType] = ce
+				addressTypes = append(addressTypes, addressType)
+			}
+			ce.Endpoints = append(ce.Endpoints, discoveryv1.Endpoint{
+				Addresses: []string{address.Address},
+				Conditions: discoveryv1.EndpointConditions{
+					Ready:   ptr.To(true),
+					Serving: ptr.To(true),
+				},
+			})
+		}
+	}
+
+	results := make([]ClusterEndpoints, 0, len(addressTypes))
+	for _, addressType := range addressTypes {
+		results = append(results, *byType[addressType])
+	}
+	return results, nil
+}
+
+// nodesWithReadyEndpoints returns the nodes hosting a ready endpoint of the remote service
+func (ea *EndpointAggregator) nodesWithReadyEndpoints(ctx context.Context, client kubernetes.Interface, namespace, serviceName string) (sets.Set[string], error) {
+	slices, err := ea.listEndpointSlices(ctx, client, namespace, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := sets.New[string]()
+	for _, slice := range slices {
+		for _, ep := range slice.Endpoints {
+			if ep.NodeName != nil && ptr.Deref(ep.Conditions.Ready, false) {
+				nodes.Insert(*ep.NodeName)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package aggregator
+
+import (
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// mergePorts returns the union of the ports of EndpointSlices, ports being identified by their name. It
+// returns a description of the conflict, and the ports unchanged, when ports define a port of the same name
+// differently.
+func mergePorts(ports, slicePorts []discoveryv1.EndpointPort) ([]discoveryv1.EndpointPort, string) {
+	merged := ports
+	for _, port := range slicePorts {
+		name := ptr.Deref(port.Name, "")
+		known := false
+		for _, existing := range ports {
+			if ptr.Deref(existing.Name, "") != name {
+				continue
+			}
+			if !equality.Semantic.DeepEqual(existing, port) {
+				return ports, fmt.Sprintf("port %q is %s, other EndpointSlices define it as %s", name, describePort(port), describePort(existing))
+			}
+			known = true
+			break
+		}
+		if !known {
+			// Ports of the first slice are kept as they are, later ones are added to a copy
+			if len(merged) == len(ports) {
+				merged = append(make([]discoveryv1.EndpointPort, 0, len(ports)+len(slicePorts)), ports...)
+			}
+			merged = append(merged, port)
+		}
+	}
+	return merged, ""
+}
+
+func describePort(port discoveryv1.EndpointPort) string {
+	description := fmt.Sprintf("%d/%s", ptr.Deref(port.Port, 0), ptr.Deref(port.Protocol, "TCP"))
+	if port.AppProtocol != nil {
+		description += " (" + *port.AppProtocol + ")"
+	}
+	return description
+}
+
+// MergeServicePorts returns the ports of a service exported by several clusters, merged under the port merge
+// policy, along with a description of every disagreement between the ports of the clusters. Ports are identified
+// by their name and clusters are visited in name order; a port defined differently by several clusters keeps the
+// definition of the first one. It reports false when the ports of the clusters of the service are not known.
+func MergeServicePorts(svcInfo *discoverer.ServiceInfo, policy string) ([]corev1.ServicePort, []string, bool) {
+	clusters := sets.List(sets.New(svcInfo.Clusters...).Intersection(sets.KeySet(svcInfo.ClusterPorts)))
+	if len(clusters) == 0 {
+		return nil, nil, false
+	}
+
+	var merged []corev1.ServicePort
+	var conflicts []string
+	// exporters holds the clusters exporting each port, in name order
+	exporters := make(map[string][]string)
+	for _, clusterName := range clusters {
+		for _, port := range svcInfo.ClusterPorts[clusterName] {
+			exporters[port.Name] = append(exporters[port.Name], clusterName)
+			i := slices.IndexFunc(merged, func(p corev1.ServicePort) bool { return p.Name == port.Name })
+			if i < 0 {
+				merged = append(merged, port)
+				continue
+			}
+			if first := merged[i]; first.Port != port.Port || first.Protocol != port.Protocol {
+				conflicts = append(conflicts, fmt.Sprintf("port %q is %d/%s in cluster %s and %d/%s in cluster %s",
+					port.Name, first.Port, first.Protocol, exporters[port.Name][0], port.Port, port.Protocol, clusterName))
+			}
+		}
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(merged))
+	for _, port := range merged {
+		exported := exporters[port.Name]
+		if len(exported) == len(clusters) {
+			ports = append(ports, port)
+			continue
+		}
+		missing := sets.List(sets.New(clusters...).Delete(exported...))
+		switch policy {
+		case config.PortMergePolicyIntersection:
+			conflicts = append(conflicts, fmt.Sprintf("port %q is left out as clusters %v do not export it", port.Name, missing))
+		case config.PortMergePolicyFirstCluster:
+			if exported[0] != clusters[0] {
+				conflicts = append(conflicts, fmt.Sprintf("port %q of clusters %v is left out as cluster %s does not export it",
+					port.Name, exported, clusters[0]))
+				continue
+			}
+			ports = append(ports, port)
+			conflicts = append(conflicts, fmt.Sprintf("port %q is not exported by clusters %v", port.Name, missing))
+		default:
+			ports = append(ports, port)
+			conflicts = append(conflicts, fmt.Sprintf("port %q is not exported by clusters %v", port.Name, missing))
+		}
+	}
+	return ports, conflicts, true
+}
+
+// restrictPorts restricts the ports of the endpoints of every cluster to the ports of the service merged under the
+// port merge policy, so that EndpointSlices hold the ports of the local Service. Endpoints left without any port
+// are dropped. Every port is kept under the union policy.
+func restrictPorts(svcInfo *discoverer.ServiceInfo, results []ClusterEndpoints, policy string) []ClusterEndpoints {
+	if policy != config.PortMergePolicyIntersection && policy != config.PortMergePolicyFirstCluster {
+		return results
+	}
+	ports, _, ok := MergeServicePorts(svcInfo, policy)
+	if !ok {
+		return results
+	}
+	names := sets.New[string]()
+	for _, port := range ports {
+		names.Insert(port.Name)
+	}
+
+	restricted := make([]ClusterEndpoints, 0, len(results))
+	for _, ce := range results {
+		if len(ce.Ports) == 0 {
+			restricted = append(restricted, ce)
+			continue
+		}
+		ce.Ports = slices.DeleteFunc(slices.Clone(ce.Ports), func(port discoveryv1.EndpointPort) bool {
+			return !names.Has(ptr.Deref(port.Name, ""))
+		})
+		if len(ce.Ports) == 0 {
+			klog.V(4).Infof("Dropping the %d %s endpoints of cluster %s for service %s/%s: none of their ports is imported",
+				len(ce.Endpoints), ce.AddressType, ce.ClusterName, svcInfo.Namespace, svcInfo.Name)
+			continue
+		}
+		restricted = append(restricted, ce)
+	}
+	return restricted
+}
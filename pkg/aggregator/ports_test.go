@@ -0,0 +1,142 @@
+package aggregator
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestMergePorts(t *testing.T) {
+	http := discoveryv1.EndpointPort{Name: ptr.To("http"), Port: ptr.To[int32](8080), Protocol: ptr.To(corev1.ProtocolTCP)}
+	metrics := discoveryv1.EndpointPort{Name: ptr.To("metrics"), Port: ptr.To[int32](9090), Protocol: ptr.To(corev1.ProtocolTCP)}
+
+	tests := []struct {
+		name         string
+		ports        []discoveryv1.EndpointPort
+		slicePorts   []discoveryv1.EndpointPort
+		want         []string
+		wantConflict bool
+	}{
+		{name: "first slice", slicePorts: []discoveryv1.EndpointPort{http}, want: []string{"http"}},
+		{name: "same ports", ports: []discoveryv1.EndpointPort{http}, slicePorts: []discoveryv1.EndpointPort{http}, want: []string{"http"}},
+		{
+			name:       "additional port",
+			ports:      []discoveryv1.EndpointPort{http},
+			slicePorts: []discoveryv1.EndpointPort{metrics, http},
+			want:       []string{"http", "metrics"},
+		},
+		{
+			name:         "port number conflict",
+			ports:        []discoveryv1.EndpointPort{http},
+			slicePorts:   []discoveryv1.EndpointPort{metrics, {Name: ptr.To("http"), Port: ptr.To[int32](8081), Protocol: ptr.To(corev1.ProtocolTCP)}},
+			want:         []string{"http"},
+			wantConflict: true,
+		},
+		{
+			name:         "app protocol conflict",
+			ports:        []discoveryv1.EndpointPort{http},
+			slicePorts:   []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To[int32](8080), Protocol: ptr.To(corev1.ProtocolTCP), AppProtocol: ptr.To("h2c")}},
+			want:         []string{"http"},
+			wantConflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflict := mergePorts(tt.ports, tt.slicePorts)
+			if (conflict != "") != tt.wantConflict {
+				t.Errorf("expected conflict %v, got %q", tt.wantConflict, conflict)
+			}
+			if len(merged) != len(tt.want) {
+				t.Fatalf("expected ports %v, got %d ports", tt.want, len(merged))
+			}
+			for i, name := range tt.want {
+				if *merged[i].Name != name {
+					t.Errorf("expected port %d to be %s, got %s", i, name, *merged[i].Name)
+				}
+			}
+			if len(tt.ports) > 0 && len(merged) > len(tt.ports) && &merged[0] == &tt.ports[0] {
+				t.Error("expected the ports of earlier slices to be copied rather than appended to")
+			}
+		})
+	}
+}
+
+func TestMergeServicePorts(t *testing.T) {
+	http := corev1.ServicePort{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}
+	grpc := corev1.ServicePort{Name: "grpc", Port: 9090, Protocol: corev1.ProtocolTCP}
+	metrics := corev1.ServicePort{Name: "metrics", Port: 9100, Protocol: corev1.ProtocolTCP}
+	svcInfo := &discoverer.ServiceInfo{
+		Namespace: "payments",
+		Name:      "api",
+		Clusters:  []string{"west", "east"},
+		ClusterPorts: map[string][]corev1.ServicePort{
+			"east": {http, grpc},
+			"west": {{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}, metrics},
+		},
+	}
+
+	tests := []struct {
+		policy    string
+		want      []string
+		conflicts []string
+	}{
+		{
+			policy:    config.PortMergePolicyUnion,
+			want:      []string{"http", "grpc", "metrics"},
+			conflicts: []string{`port "grpc" is not exported by clusters [west]`, `port "metrics" is not exported by clusters [east]`},
+		},
+		{
+			policy:    config.PortMergePolicyIntersection,
+			want:      []string{"http"},
+			conflicts: []string{`port "grpc" is left out as clusters [west] do not export it`, `port "metrics" is left out as clusters [east] do not export it`},
+		},
+		{
+			policy:    config.PortMergePolicyFirstCluster,
+			want:      []string{"http", "grpc"},
+			conflicts: []string{`port "grpc" is not exported by clusters [west]`, `port "metrics" of clusters [west] is left out as cluster east does not export it`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			ports, conflicts, ok := MergeServicePorts(svcInfo, tt.policy)
+			if !ok {
+				t.Fatal("expected the ports to be merged")
+			}
+			var names []string
+			for _, port := range ports {
+				names = append(names, port.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected ports %v, got %v", tt.want, names)
+			}
+			if ports[0].Port != 80 {
+				t.Errorf("expected the http port of the first cluster, got %d", ports[0].Port)
+			}
+			// The http port is defined differently whatever the policy
+			want := append([]string{`port "http" is 80/TCP in cluster east and 8080/TCP in cluster west`}, tt.conflicts...)
+			if strings.Join(conflicts, "\n") != strings.Join(want, "\n") {
+				t.Errorf("expected conflicts %q, got %q", want, conflicts)
+			}
+		})
+	}
+
+	if _, _, ok := MergeServicePorts(&discoverer.ServiceInfo{Clusters: []string{"east"}}, config.PortMergePolicyUnion); ok {
+		t.Error("expected the ports not to be merged without the ports of the clusters")
+	}
+
+	results := restrictPorts(svcInfo, []ClusterEndpoints{
+		{ClusterName: "east", Ports: []discoveryv1.EndpointPort{{Name: ptr.To("http")}, {Name: ptr.To("grpc")}}},
+		{ClusterName: "west", Ports: []discoveryv1.EndpointPort{{Name: ptr.To("metrics")}}},
+	}, config.PortMergePolicyIntersection)
+	if len(results) != 1 || results[0].ClusterName != "east" || len(results[0].Ports) != 1 || *results[0].Ports[0].Name != "http" {
+		t.Errorf("expected the http port of east only, got %+v", results)
+	}
+}
@@ -0,0 +1,74 @@
+package aggregator
+
+import (
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// applyReadinessPolicy returns the endpoint as it should be published under the readiness policy,
+// and whether it should be published at all. Unless the policy forces them, the ready, serving and
+// terminating conditions of the remote endpoint are published as they are.
+func applyReadinessPolicy(ep discoveryv1.Endpoint, policy svclinkv1alpha1.ReadinessPolicy) (discoveryv1.Endpoint, bool) {
+	switch policy {
+	case svclinkv1alpha1.ReadinessPolicyForceReady:
+		ep.Conditions = discoveryv1.EndpointConditions{
+			Ready:       ptr.To(true),
+			Serving:     ptr.To(true),
+			Terminating: ptr.To(false),
+		}
+		return ep, true
+
+	case svclinkv1alpha1.ReadinessPolicyForceServingOnly:
+		// Serving is unset by controllers predating it, in which case it mirrors Ready
+		serving := ep.Conditions.Serving
+		if serving == nil {
+			serving = ep.Conditions.Ready
+		}
+		if serving == nil || !*serving {
+			return ep, false
+		}
+		ep.Conditions = discoveryv1.EndpointConditions{
+			Ready:       ptr.To(true),
+			Serving:     ptr.To(true),
+			Terminating: ptr.To(false),
+		}
+		return ep, true
+
+	case svclinkv1alpha1.ReadinessPolicyIncludeTerminating:
+		if ep.Conditions.Ready != nil && *ep.Conditions.Ready {
+			return ep, true
+		}
+		// Consumers fall back to serving terminating endpoints once no endpoint is ready
+		terminating := ep.Conditions.Terminating != nil && *ep.Conditions.Terminating
+		serving := ep.Conditions.Serving != nil && *ep.Conditions.Serving
+		return ep, terminating && serving
+
+	default:
+		return ep, ep.Conditions.Ready != nil && *ep.Conditions.Ready
+	}
+}
+
+// markReady publishes a not-ready endpoint as ready. Serving and terminating keep the remote conditions, so
+// consumers still tell the endpoints that are actually serving apart.
+func markReady(ep discoveryv1.Endpoint) discoveryv1.Endpoint {
+	if ep.Conditions.Serving == nil {
+		// Serving is unset by controllers predating it, in which case it mirrors Ready
+		ep.Conditions.Serving = ep.Conditions.Ready
+	}
+	ep.Conditions.Ready = ptr.To(true)
+	return ep
+}
+
+// assumeReady treats the unknown conditions of an endpoint as ready and serving, as the EndpointSlice API asks
+// consumers to. External integrations commonly publish FQDN endpoints without any conditions.
+func assumeReady(ep discoveryv1.Endpoint) discoveryv1.Endpoint {
+	if ep.Conditions.Ready == nil {
+		ep.Conditions.Ready = ptr.To(true)
+	}
+	if ep.Conditions.Serving == nil {
+		ep.Conditions.Serving = ptr.To(*ep.Conditions.Ready)
+	}
+	return ep
+}
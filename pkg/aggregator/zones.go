@@ -0,0 +1,40 @@
+package aggregator
+
+import (
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// mapZones renames the zones of the endpoints and of their topology hints with the zone mappings of a
+// ClusterLink. Endpoints are copied rather than modified in place.
+func mapZones(endpointsByType []ClusterEndpoints, zoneMap map[string]string) []ClusterEndpoints {
+	if len(zoneMap) == 0 {
+		return endpointsByType
+	}
+
+	for i, ce := range endpointsByType {
+		endpoints := make([]discoveryv1.Endpoint, 0, len(ce.Endpoints))
+		for _, ep := range ce.Endpoints {
+			if ep.Zone != nil {
+				if target, ok := zoneMap[*ep.Zone]; ok {
+					ep.Zone = &target
+				}
+			}
+			if ep.Hints != nil {
+				hints := &discoveryv1.EndpointHints{
+					ForZones: make([]discoveryv1.ForZone, 0, len(ep.Hints.ForZones)),
+					ForNodes: ep.Hints.ForNodes,
+				}
+				for _, forZone := range ep.Hints.ForZones {
+					if target, ok := zoneMap[forZone.Name]; ok {
+						forZone.Name = target
+					}
+					hints.ForZones = append(hints.ForZones, forZone)
+				}
+				ep.Hints = hints
+			}
+			endpoints = append(endpoints, ep)
+		}
+		endpointsByType[i].Endpoints = endpoints
+	}
+	return endpointsByType
+}
@@ -0,0 +1,36 @@
+package aggregator
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestMapZones(t *testing.T) {
+	endpoints := []discoveryv1.Endpoint{
+		{
+			Addresses: []string{"10.0.0.1"},
+			Zone:      ptr.To("us-east-1a"),
+			Hints:     &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: "us-east-1a"}}},
+		},
+		{Addresses: []string{"10.0.0.2"}, Zone: ptr.To("us-east-1c")},
+		{Addresses: []string{"10.0.0.3"}},
+	}
+	results := mapZones([]ClusterEndpoints{{ClusterName: "cluster-a", Endpoints: endpoints}},
+		map[string]string{"us-east-1a": "use1-az1"})
+
+	mapped := results[0].Endpoints
+	if *mapped[0].Zone != "use1-az1" || mapped[0].Hints.ForZones[0].Name != "use1-az1" {
+		t.Errorf("expected the zone and hints of the first endpoint to be mapped, got %s %v", *mapped[0].Zone, mapped[0].Hints.ForZones)
+	}
+	if *mapped[1].Zone != "us-east-1c" {
+		t.Errorf("expected an unmapped zone to be kept, got %s", *mapped[1].Zone)
+	}
+	if mapped[2].Zone != nil {
+		t.Errorf("expected an endpoint without zone to stay without zone, got %s", *mapped[2].Zone)
+	}
+	if *endpoints[0].Zone != "us-east-1a" || endpoints[0].Hints.ForZones[0].Name != "us-east-1a" {
+		t.Error("expected the endpoints to be copied rather than modified in place")
+	}
+}
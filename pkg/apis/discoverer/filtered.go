@@ -0,0 +1,46 @@
+package discoverer
+
+// FilterReason explains why a remote service or namespace was not synced
+type FilterReason string
+
+const (
+	// FilterReasonIncludedNamespaces means the namespace is not in the global --included-namespaces
+	FilterReasonIncludedNamespaces FilterReason = "IncludedNamespaces"
+	// FilterReasonNamespace means the namespace is excluded by the ClusterLink namespace filters
+	FilterReasonNamespace FilterReason = "NamespaceFilter"
+	// FilterReasonNamespaceSelector means the namespace labels do not match the ClusterLink namespaceSelector
+	FilterReasonNamespaceSelector FilterReason = "NamespaceSelector"
+	// FilterReasonService means the service is excluded by the ClusterLink service filters
+	FilterReasonService FilterReason = "ServiceFilter"
+	// FilterReasonOptOut means the remote service opted out with the cloudpilot.ai/svclink: "false" annotation
+	FilterReasonOptOut FilterReason = "OptOut"
+	// FilterReasonTargetClusters means the target clusters annotation of the remote service does not list this deployment
+	FilterReasonTargetClusters FilterReason = "TargetClusters"
+	// FilterReasonServiceType means the service type is not in the ClusterLink syncServiceTypes or the global --sync-service-types
+	FilterReasonServiceType FilterReason = "ServiceType"
+	// FilterReasonServiceSelector means the service labels do not match the ClusterLink serviceSelector
+	FilterReasonServiceSelector FilterReason = "ServiceSelector"
+)
+
+// FilteredService is a service, or a whole namespace when Name is empty, that was seen on a remote
+// cluster but not synced because of a filter
+type FilteredService struct {
+	Namespace string       `json:"namespace"`
+	Name      string       `json:"name,omitempty"`
+	Reason    FilterReason `json:"reason"`
+}
+
+// FilteredCluster summarizes what was filtered on one remote cluster in the last sync cycle
+type FilteredCluster struct {
+	// Namespaces counts filtered namespaces by reason
+	Namespaces map[FilterReason]int `json:"namespaces,omitempty"`
+	// Services counts filtered services by reason
+	Services map[FilterReason]int `json:"services,omitempty"`
+	// Sample lists a bounded number of the filtered namespaces and services
+	Sample []FilteredService `json:"sample,omitempty"`
+}
+
+// FilteredReport is the filtered services of every remote cluster, keyed by cluster name
+type FilteredReport struct {
+	Clusters map[string]*FilteredCluster `json:"clusters"`
+}
@@ -0,0 +1,22 @@
+package discoverer
+
+import "time"
+
+// OrphanedService is a local service imported by svclink that no connected remote cluster exposes anymore
+type OrphanedService struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Created is set when the local Service was created by svclink
+	Created bool `json:"created"`
+	// EndpointSlices is the number of EndpointSlices svclink manages for the service
+	EndpointSlices int `json:"endpointSlices"`
+	// Since is when the service was first found orphaned
+	Since time.Time `json:"since"`
+	// PruneAt is when the managed resources of the service are pruned, unset when pruning is disabled
+	PruneAt *time.Time `json:"pruneAt,omitempty"`
+}
+
+// OrphanReport is the orphaned services found in the last sync cycle
+type OrphanReport struct {
+	Services []OrphanedService `json:"services"`
+}
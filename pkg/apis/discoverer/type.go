@@ -13,4 +13,29 @@ type ServiceInfo struct {
 	Namespace string
 	Clusters  []string        // List of cluster names where this service exists
 	Service   *corev1.Service // The service object itself
+	// SourceNamespaces holds the remote namespace of the service for the clusters whose
+	// namespace mappings import it from a namespace other than Namespace
+	SourceNamespaces map[string]string
+	// SourceName is the name of the service in the remote clusters, when it is imported under another name
+	SourceName string
+	// PublishNotReadyAddresses holds the clusters whose service sets spec.publishNotReadyAddresses
+	PublishNotReadyAddresses map[string]bool
+	// ClusterPorts holds the ports of the service in each cluster exporting it
+	ClusterPorts map[string][]corev1.ServicePort
+}
+
+// SourceServiceName returns the name of the service in the remote clusters
+func (si *ServiceInfo) SourceServiceName() string {
+	if si.SourceName != "" {
+		return si.SourceName
+	}
+	return si.Name
+}
+
+// SourceNamespace returns the namespace of the service in the given remote cluster
+func (si *ServiceInfo) SourceNamespace(clusterName string) string {
+	if namespace, ok := si.SourceNamespaces[clusterName]; ok {
+		return namespace
+	}
+	return si.Namespace
 }
@@ -0,0 +1,90 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// NameMatcher matches names against a list of filter entries. Plain entries are matched exactly,
+// entries wrapped in slashes such as `/^canary-.*/` are regular expressions and entries containing
+// `*` or `?` such as `team-*` are glob patterns. Patterns are compiled once when the matcher is built.
+// +k8s:deepcopy-gen=false
+type NameMatcher struct {
+	sets.Set[string]
+	patterns []*regexp.Regexp
+}
+
+// NewNameMatcher builds a NameMatcher from filter entries. Entries that are not valid patterns are
+// ignored, use ValidateNamePatterns to report them.
+func NewNameMatcher(entries ...string) NameMatcher {
+	m := NameMatcher{Set: sets.New[string]()}
+	for _, entry := range entries {
+		re, isPattern, err := compileNamePattern(entry)
+		switch {
+		case !isPattern:
+			m.Insert(entry)
+		case err == nil:
+			m.patterns = append(m.patterns, re)
+		}
+	}
+	return m
+}
+
+// Len returns the number of exact names and patterns in the matcher
+func (m *NameMatcher) Len() int {
+	return m.Set.Len() + len(m.patterns)
+}
+
+// Matches reports whether the name is listed exactly or matches one of the patterns
+func (m *NameMatcher) Matches(name string) bool {
+	if m.Has(name) {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateNamePatterns returns an error for the first entry that is not a valid pattern
+func ValidateNamePatterns(entries []string) error {
+	for _, entry := range entries {
+		if _, _, err := compileNamePattern(entry); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", entry, err)
+		}
+	}
+	return nil
+}
+
+// compileNamePattern compiles a regex or glob filter entry. isPattern is false for plain names.
+func compileNamePattern(entry string) (re *regexp.Regexp, isPattern bool, err error) {
+	if len(entry) >= 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+		re, err = regexp.Compile(entry[1 : len(entry)-1])
+		return re, true, err
+	}
+	if !strings.ContainsAny(entry, "*?") {
+		return nil, false, nil
+	}
+
+	// Globs never match across the namespace/name separator
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range entry {
+		switch r {
+		case '*':
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	re, err = regexp.Compile(expr.String())
+	return re, true, err
+}
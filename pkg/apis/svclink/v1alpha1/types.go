@@ -1,7 +1,12 @@
 package v1alpha1
 
 import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	api "k8s.io/kubernetes/pkg/apis/core"
 )
@@ -11,14 +16,21 @@ import (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Enabled",type=boolean,JSONPath=`.spec.enabled`
+// +kubebuilder:printcolumn:name="Paused",type=boolean,JSONPath=`.spec.paused`
 // +kubebuilder:printcolumn:name="Included NS",type=string,JSONPath=`.spec.includedNamespaces`,priority=1
 // +kubebuilder:printcolumn:name="Excluded NS",type=string,JSONPath=`.spec.excludedNamespaces`,priority=1
 // +kubebuilder:printcolumn:name="Excluded Services",type=string,JSONPath=`.spec.excludedServices`,priority=1
 // +kubebuilder:printcolumn:name="Excluded Service Names",type=string,JSONPath=`.spec.excludedServiceNames`,priority=1
 // +kubebuilder:printcolumn:name="Connected",type=boolean,JSONPath=`.status.connected`
 // +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`
+// +kubebuilder:printcolumn:name="Services",type=integer,JSONPath=`.status.syncedServices`
+// +kubebuilder:printcolumn:name="Endpoints",type=integer,JSONPath=`.status.syncedEndpoints`
+// +kubebuilder:printcolumn:name="Skipped",type=integer,JSONPath=`.status.skippedServices`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Sync Duration",type=string,JSONPath=`.status.lastSyncDuration`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="Last Connected",type=date,JSONPath=`.status.lastConnected`
+// +kubebuilder:printcolumn:name="Cert Expiry",type=date,JSONPath=`.status.certificateExpiry`,priority=1
 
 // ClusterLink is a specification for a linked Kubernetes cluster
 type ClusterLink struct {
@@ -31,19 +43,128 @@ type ClusterLink struct {
 }
 
 // ClusterLinkSpec defines the desired state of ClusterLink
+// +kubebuilder:validation:XValidation:rule="has(self.kubeconfig) || has(self.kubeconfigSecretRef) || (has(self.apiServerURL) && (has(self.serviceAccountTokenSecretRef) || (has(self.auth) && has(self.auth.certificateRef))))",message="either kubeconfig, kubeconfigSecretRef or apiServerURL with serviceAccountTokenSecretRef or auth.certificateRef must be specified"
+// +kubebuilder:validation:XValidation:rule="!has(self.endpointMode) || self.endpointMode != 'Gateway' || has(self.gatewayAddress)",message="gatewayAddress must be specified with the Gateway endpointMode"
 type ClusterLinkSpec struct {
 	// Enabled indicates whether this cluster should be actively synced
 	// +optional
 	// +kubebuilder:default=true
 	Enabled bool `json:"enabled"`
 
-	// Kubeconfig is the base64 encoded kubeconfig for accessing the remote cluster
-	// +required
-	Kubeconfig string `json:"kubeconfig"`
+	// Paused freezes the sync of this cluster, e.g. during a maintenance window of the remote cluster.
+	// svclink does not connect to a paused cluster and leaves the EndpointSlices imported from it as they are.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Kubeconfig is the base64 encoded kubeconfig for accessing the remote cluster.
+	// Either Kubeconfig, KubeconfigSecretRef or APIServerURL with ServiceAccountTokenSecretRef must be specified.
+	// +optional
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// KubeconfigSecretRef references a Secret in the local cluster holding the kubeconfig for accessing
+	// the remote cluster, as an alternative to embedding it in Kubeconfig. The key defaults to "kubeconfig".
+	// +optional
+	KubeconfigSecretRef *SecretKeyReference `json:"kubeconfigSecretRef,omitempty"`
+
+	// KubeconfigContext is the context of Kubeconfig or KubeconfigSecretRef used to connect to the remote cluster.
+	// If empty, the kubeconfig's current-context is used.
+	// +optional
+	KubeconfigContext string `json:"kubeconfigContext,omitempty"`
+
+	// APIServerURL is the URL of the remote cluster's API server.
+	// It is used together with ServiceAccountTokenSecretRef as an alternative to Kubeconfig,
+	// so that a scoped ServiceAccount token can be used instead of a full kubeconfig.
+	// Example: "https://api.remote-cluster.example.com:6443"
+	// +optional
+	APIServerURL string `json:"apiServerURL,omitempty"`
+
+	// CABundle is the PEM encoded CA bundle used to verify the remote API server certificate
+	// when connecting through APIServerURL. If empty, the system trust store is used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// ServiceAccountTokenSecretRef references a Secret in the local cluster holding the ServiceAccount
+	// bearer token used to authenticate against APIServerURL. The token is re-read every sync,
+	// so rotating it only requires updating the Secret.
+	// +optional
+	ServiceAccountTokenSecretRef *SecretKeyReference `json:"serviceAccountTokenSecretRef,omitempty"`
+
+	// Auth configures other ways to authenticate against APIServerURL than ServiceAccountTokenSecretRef
+	// +optional
+	Auth *ClusterLinkAuth `json:"auth,omitempty"`
+
+	// ProxyURL is the HTTP, HTTPS or SOCKS5 proxy used to reach the remote API server.
+	// It overrides any proxy configured in the kubeconfig or the controller environment.
+	// Example: "socks5://proxy.corp.example.com:1080"
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(https?|socks5)://`
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// ClientQPS is the sustained number of requests per second svclink sends to the remote API server,
+	// to throttle the list-heavy sync against large clusters. Defaults to the client-go default of 5.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ClientQPS *int32 `json:"clientQPS,omitempty"`
+
+	// ClientBurst is the number of requests svclink may send to the remote API server in a burst
+	// above ClientQPS. Defaults to the client-go default of 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ClientBurst *int32 `json:"clientBurst,omitempty"`
+
+	// LatencyProbe enables measuring the round-trip time to the remote API server every sync.
+	// The latency is reported in the status and used to prefer nearby clusters when the number
+	// of clusters contributing endpoints to a service is limited.
+	// +optional
+	LatencyProbe bool `json:"latencyProbe,omitempty"`
+
+	// ReachabilityProbe enables dialing a sample of the endpoints imported from this cluster from the
+	// controller every sync, over TCP. The outcome is reported in the NetworkReachable condition, which
+	// tells whether the pod network of the cluster is routable from the local cluster.
+	// +optional
+	ReachabilityProbe bool `json:"reachabilityProbe,omitempty"`
+
+	// HealthCheck enables probing every endpoint imported from this cluster from the controller before it is
+	// published. Ready on the remote side does not guarantee that the endpoint is reachable from the local
+	// cluster. A new endpoint is published once a probe succeeded, and an endpoint is no longer published
+	// once FailureThreshold consecutive probes failed. FQDN endpoints are not probed.
+	// +optional
+	HealthCheck *EndpointHealthCheck `json:"healthCheck,omitempty"`
+
+	// CostWeight is a hint of the relative cost of sending traffic to this cluster, e.g. cross-region
+	// egress. When the number of clusters contributing endpoints to a service is limited, clusters with
+	// a lower CostWeight are preferred, then clusters with a lower probed latency.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	CostWeight int32 `json:"costWeight,omitempty"`
+
+	// EndpointWeight is the relative share of traffic sent to endpoints imported from this cluster.
+	// Services spread traffic evenly across endpoints, so svclink publishes a subset of the endpoints
+	// of weighted clusters to make their share of the endpoints match their share of the weight, e.g.
+	// 90 and 10 for a primary and a DR cluster. Clusters without an EndpointWeight have a weight of 100
+	// when other clusters of the service are weighted.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	EndpointWeight *int32 `json:"endpointWeight,omitempty"`
+
+	// Priority orders clusters for failover. Endpoints of a cluster are only published while every
+	// cluster with a higher Priority, including the local cluster with --local-cluster-priority, has
+	// no ready endpoints for the service. Clusters with the same Priority are published together.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// MaxServices caps the number of services imported from this cluster, so that a misconfigured
+	// remote cluster cannot flood the local cluster with imported services. Once the quota is reached
+	// no further services are imported, services imported earlier keep their place, and the
+	// QuotaExceeded condition is set. Unset means no limit.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxServices *int32 `json:"maxServices,omitempty"`
 
 	// ExcludedNamespaces is a list of namespaces that should not be synced.
 	// Services in these namespaces will be ignored.
 	// Note: kube-system is always excluded by default and does not need to be specified here.
+	// Entries may be glob patterns such as "team-*" or regular expressions wrapped in slashes such as "/^canary-.*/".
 	// +optional
 	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
 
@@ -51,22 +172,333 @@ type ClusterLinkSpec struct {
 	// If specified, only services in these namespaces will be synced.
 	// If empty, all namespaces except kube-system and ExcludedNamespaces will be synced.
 	// Note: kube-system is always excluded even if listed here.
+	// Entries may be glob or regex patterns, as in ExcludedNamespaces.
 	// +optional
 	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
 
+	// NamespaceSelector selects which namespaces of the remote cluster are synced by their labels.
+	// It is evaluated in addition to ExcludedNamespaces and IncludedNamespaces.
+	// Example: {"matchLabels": {"svclink.cloudpilot.ai/export": "true"}}
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// NamespaceMappings import the services of a remote namespace into a differently named local namespace.
+	// Remote namespaces without a mapping are imported into the local namespace of the same name.
+	// Namespace and service filters always refer to the remote namespace.
+	// Example: [{"source": "prod-eu", "target": "prod"}]
+	// +optional
+	// +listType=map
+	// +listMapKey=source
+	NamespaceMappings []NamespaceMapping `json:"namespaceMappings,omitempty"`
+
+	// AddressTranslations rewrite the addresses of the endpoints imported from this cluster, for pod
+	// networks that overlap with other clusters and are reached through NAT gateways. Static mappings
+	// take precedence, then the first CIDR translation containing the address applies. Addresses
+	// without a matching translation are imported as they are.
+	// Example: [{"fromCIDR": "10.0.0.0/16", "toCIDR": "100.64.0.0/16"}]
+	// +optional
+	AddressTranslations []AddressTranslation `json:"addressTranslations,omitempty"`
+
 	// ExcludedServices is a list of service names (in format namespace/service-name) that should not be synced.
 	// This allows fine-grained control to exclude specific services in specific namespaces.
 	// Note: Services in kube-system are always excluded regardless of this setting.
-	// Example: ["default/internal-db", "production/admin-api"]
+	// Entries may be glob patterns, where "*" does not match "/", or regular expressions wrapped in slashes.
+	// Example: ["default/internal-db", "production/admin-api", "team-*/debug-*"]
 	// +optional
 	ExcludedServices []string `json:"excludedServices,omitempty"`
 
+	// IncludedServices is a list of service names (in format namespace/service-name) that should be synced.
+	// If specified, only these services will be synced. A service listed here is synced even if it is
+	// also excluded by ExcludedServices or ExcludedServiceNames.
+	// Entries may be glob or regex patterns, as in ExcludedServices.
+	// Example: ["default/web", "production/checkout"]
+	// +optional
+	IncludedServices []string `json:"includedServices,omitempty"`
+
 	// ExcludedServiceNames is a list of service names that should not be synced in ALL namespaces.
 	// This is more efficient than listing the same service in multiple namespaces in ExcludedServices.
 	// Note: The 'kubernetes' service is always excluded by default and does not need to be specified here.
-	// Example: ["admin-service", "internal-cache", "debug-tool"]
+	// Entries may be glob or regex patterns, as in ExcludedNamespaces.
+	// Example: ["admin-service", "internal-cache", "debug-*", "/^canary-.*/"]
 	// +optional
 	ExcludedServiceNames []string `json:"excludedServiceNames,omitempty"`
+
+	// ServiceSelector selects which services of the remote cluster are synced by their labels.
+	// If not specified, all services that are not excluded by the other filters are synced.
+	// Example: {"matchLabels": {"svclink.cloudpilot.ai/export": "true"}}
+	// +optional
+	ServiceSelector *metav1.LabelSelector `json:"serviceSelector,omitempty"`
+
+	// SyncServiceTypes restricts which types of remote services are synced.
+	// If empty, services of all types are synced.
+	// Example: ["ClusterIP"] to keep NodePort, LoadBalancer and ExternalName services in their cluster
+	// +optional
+	// +kubebuilder:validation:items:Enum=ClusterIP;NodePort;LoadBalancer;ExternalName
+	SyncServiceTypes []corev1.ServiceType `json:"syncServiceTypes,omitempty"`
+
+	// AddressTypes restricts which endpoint address types are imported from this cluster.
+	// If empty, endpoints of all address types are imported.
+	// Example: ["IPv6"] to only publish IPv6 endpoints from a dual-stack cluster
+	// +optional
+	// +kubebuilder:validation:items:Enum=IPv4;IPv6;FQDN
+	AddressTypes []discoveryv1.AddressType `json:"addressTypes,omitempty"`
+
+	// EndpointMode selects which addresses are published for services of this cluster.
+	// PodIP (default) publishes the remote pod endpoints and requires a flat pod network.
+	// ClusterIP publishes the remote service ClusterIP and service ports instead, for networks
+	// that route remote service CIDRs but not pod CIDRs. Services without a ClusterIP are skipped.
+	// NodePort publishes the remote node addresses and service node ports, for networks that only route
+	// node addresses. With externalTrafficPolicy Local, only nodes hosting ready endpoints are published.
+	// Services without node ports are skipped.
+	// Gateway publishes GatewayAddress as the single endpoint of every service, for non-flat networks
+	// where a gateway of the remote cluster forwards the traffic to its services. The gateway endpoint
+	// is only published while the remote service has ready endpoints.
+	// +optional
+	// +kubebuilder:default=PodIP
+	EndpointMode EndpointMode `json:"endpointMode,omitempty"`
+
+	// GatewayAddress is the IP address of the gateway of the remote cluster, published with the Gateway EndpointMode
+	// +optional
+	GatewayAddress string `json:"gatewayAddress,omitempty"`
+
+	// GatewayPortAllocation selects the ports published with the gateway address.
+	// ServicePort (default) publishes the ports of the remote service, for gateways that route by
+	// service, e.g. with a listener per service. NodePort publishes the node ports of the remote
+	// service, which are unique per service, for gateways forwarding ports to the remote nodes.
+	// Services without node ports are skipped.
+	// +optional
+	// +kubebuilder:default=ServicePort
+	GatewayPortAllocation GatewayPortAllocation `json:"gatewayPortAllocation,omitempty"`
+
+	// ReadinessPolicy controls how remote endpoint conditions are published.
+	// Respect (default) publishes only ready endpoints with their remote conditions.
+	// ForceReady publishes every endpoint as ready, for setups that health-check at the load balancer.
+	// ForceServingOnly publishes serving endpoints, including terminating ones, as ready.
+	// IncludeTerminating publishes ready endpoints and serving terminating ones with their remote conditions,
+	// for consumers that drain terminating endpoints gracefully.
+	// +optional
+	// +kubebuilder:default=Respect
+	ReadinessPolicy ReadinessPolicy `json:"readinessPolicy,omitempty"`
+
+	// HostNetworkEndpoints controls the endpoints of host-network pods, whose address is the address of their node.
+	// Publish (default) publishes them like other endpoints. Deduplicate publishes a single endpoint per node
+	// address, as host-network pods replacing each other on a node share the address. Exclude drops them, for
+	// networks that route remote pod addresses but not node addresses.
+	// +optional
+	// +kubebuilder:default=Publish
+	HostNetworkEndpoints HostNetworkEndpointPolicy `json:"hostNetworkEndpoints,omitempty"`
+
+	// ExcludedNodeOperatingSystems drops the endpoints of pods on nodes with these kubernetes.io/os labels,
+	// e.g. windows for clusters whose Windows pod network is not routable from the local cluster.
+	// +optional
+	ExcludedNodeOperatingSystems []string `json:"excludedNodeOperatingSystems,omitempty"`
+
+	// PreserveNodeNames keeps the remote node names of imported endpoints. They are dropped by default, as
+	// kube-proxy would take endpoints on a remote node named like a local node for endpoints of that node.
+	// Endpoint hostnames are always kept, for the per-pod DNS names of headless services.
+	// +optional
+	PreserveNodeNames bool `json:"preserveNodeNames,omitempty"`
+
+	// ZoneMappings rename the zones of the endpoints imported from this cluster, and the zones of their
+	// topology hints, to zones of the local cluster, so that Topology Aware Routing treats remote endpoints
+	// as endpoints of the local zone they are closest to. Zones without a mapping are imported as they are.
+	// Example: [{"source": "us-east-1a", "target": "use1-az1"}]
+	// +optional
+	// +listType=map
+	// +listMapKey=source
+	ZoneMappings []ZoneMapping `json:"zoneMappings,omitempty"`
+}
+
+// EndpointMode defines which addresses are published for remote services
+// +kubebuilder:validation:Enum=PodIP;ClusterIP;NodePort;Gateway
+type EndpointMode string
+
+const (
+	// EndpointModePodIP publishes the remote pod endpoints
+	EndpointModePodIP EndpointMode = "PodIP"
+
+	// EndpointModeClusterIP publishes the remote service ClusterIP
+	EndpointModeClusterIP EndpointMode = "ClusterIP"
+
+	// EndpointModeNodePort publishes the remote node addresses and service node ports
+	EndpointModeNodePort EndpointMode = "NodePort"
+
+	// EndpointModeGateway publishes the gateway address of the remote cluster
+	EndpointModeGateway EndpointMode = "Gateway"
+)
+
+// GatewayPortAllocation defines which ports are published with the gateway address
+// +kubebuilder:validation:Enum=ServicePort;NodePort
+type GatewayPortAllocation string
+
+const (
+	// GatewayPortAllocationServicePort publishes the ports of the remote service
+	GatewayPortAllocationServicePort GatewayPortAllocation = "ServicePort"
+
+	// GatewayPortAllocationNodePort publishes the node ports of the remote service
+	GatewayPortAllocationNodePort GatewayPortAllocation = "NodePort"
+)
+
+// ReadinessPolicy defines how remote endpoint conditions are published
+// +kubebuilder:validation:Enum=Respect;ForceReady;ForceServingOnly;IncludeTerminating
+type ReadinessPolicy string
+
+const (
+	// ReadinessPolicyRespect publishes only ready endpoints with their remote conditions
+	ReadinessPolicyRespect ReadinessPolicy = "Respect"
+
+	// ReadinessPolicyForceReady publishes every endpoint as ready
+	ReadinessPolicyForceReady ReadinessPolicy = "ForceReady"
+
+	// ReadinessPolicyForceServingOnly publishes serving endpoints as ready
+	ReadinessPolicyForceServingOnly ReadinessPolicy = "ForceServingOnly"
+
+	// ReadinessPolicyIncludeTerminating publishes ready endpoints and serving terminating endpoints with their
+	// remote conditions
+	ReadinessPolicyIncludeTerminating ReadinessPolicy = "IncludeTerminating"
+)
+
+// HostNetworkEndpointPolicy defines how endpoints of host-network pods are published
+// +kubebuilder:validation:Enum=Publish;Deduplicate;Exclude
+type HostNetworkEndpointPolicy string
+
+const (
+	// HostNetworkEndpointsPublish publishes endpoints of host-network pods like other endpoints
+	HostNetworkEndpointsPublish HostNetworkEndpointPolicy = "Publish"
+
+	// HostNetworkEndpointsDeduplicate publishes a single endpoint per node address
+	HostNetworkEndpointsDeduplicate HostNetworkEndpointPolicy = "Deduplicate"
+
+	// HostNetworkEndpointsExclude drops endpoints of host-network pods
+	HostNetworkEndpointsExclude HostNetworkEndpointPolicy = "Exclude"
+)
+
+// EndpointHealthCheck configures the probes of the endpoints imported from a cluster
+type EndpointHealthCheck struct {
+	// Type is the kind of probe. TCP (default) connects to the endpoint, HTTP sends a GET request to Path
+	// and expects a status code below 400.
+	// +optional
+	// +kubebuilder:default=TCP
+	Type HealthCheckType `json:"type,omitempty"`
+
+	// Path is the path requested by HTTP probes. Defaults to "/".
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Port is the port probed. Defaults to the first TCP port of the endpoints.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// TimeoutSeconds is how long a probe waits for the endpoint. Defaults to 2.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed probes after which an endpoint is no longer
+	// published. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// HealthCheckType defines how imported endpoints are probed
+// +kubebuilder:validation:Enum=TCP;HTTP
+type HealthCheckType string
+
+const (
+	// HealthCheckTCP probes endpoints by connecting to them
+	HealthCheckTCP HealthCheckType = "TCP"
+
+	// HealthCheckHTTP probes endpoints with an HTTP GET request
+	HealthCheckHTTP HealthCheckType = "HTTP"
+)
+
+// AddressTranslation translates either a CIDR or a single address of a remote cluster to the address
+// the local cluster reaches it at
+// +kubebuilder:validation:XValidation:rule="(has(self.fromCIDR) && has(self.toCIDR) && !has(self.from) && !has(self.to)) || (has(self.from) && has(self.to) && !has(self.fromCIDR) && !has(self.toCIDR))",message="either fromCIDR and toCIDR or from and to must be specified"
+type AddressTranslation struct {
+	// FromCIDR is translated to ToCIDR, keeping the offset of each address within the CIDR.
+	// Both CIDRs must be of the same IP family and prefix length.
+	// +optional
+	FromCIDR string `json:"fromCIDR,omitempty"`
+
+	// ToCIDR is the CIDR FromCIDR is translated to
+	// +optional
+	ToCIDR string `json:"toCIDR,omitempty"`
+
+	// From is a single address translated to To
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// To is the address From is translated to
+	// +optional
+	To string `json:"to,omitempty"`
+}
+
+// NamespaceMapping maps a namespace of the remote cluster to a namespace of the local cluster
+type NamespaceMapping struct {
+	// Source is the namespace of the remote cluster
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// Target is the namespace of the local cluster the services of Source are imported into
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Target string `json:"target"`
+}
+
+// ZoneMapping maps a zone of the remote cluster to a zone of the local cluster
+type ZoneMapping struct {
+	// Source is the zone of the remote endpoints
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// Target is the zone of the local cluster the endpoints of Source are published in
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Target string `json:"target"`
+}
+
+// ClusterLinkAuth configures the authentication against the remote API server
+type ClusterLinkAuth struct {
+	// CertificateRef references a cert-manager Certificate in the local cluster issuing the client certificate
+	// used to authenticate against APIServerURL. The certificate and key are read from the Secret of the
+	// Certificate every sync, so renewals are picked up without a restart. When CABundle is empty, the
+	// ca.crt of the Secret verifies the remote API server.
+	// +optional
+	CertificateRef *CertificateReference `json:"certificateRef,omitempty"`
+}
+
+// CertificateReference references a cert-manager Certificate in the local cluster
+type CertificateReference struct {
+	// Name of the Certificate
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the Certificate. Defaults to the namespace of the ClusterLink, other namespaces are only
+	// allowed with --allow-cross-namespace-secrets.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret in the local cluster
+type SecretKeyReference struct {
+	// Name of the Secret
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the Secret. Defaults to the namespace of the ClusterLink, other namespaces are only
+	// allowed with --allow-cross-namespace-secrets.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key within the Secret data. Defaults to "token" for tokens and "kubeconfig" for kubeconfigs.
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // ClusterLinkStatus defines the observed state of ClusterLink
@@ -79,17 +511,194 @@ type ClusterLinkStatus struct {
 	// +optional
 	LastConnected *metav1.Time `json:"lastConnected,omitempty"`
 
-	// Error contains the last error message if connection failed
+	// Error contains the current connection or discovery error of the cluster. Errors keeps the recent
+	// errors of every sync phase.
 	// +optional
 	Error string `json:"error,omitempty"`
 
+	// Errors lists the recent distinct errors of the cluster, most recent last. An error seen again is counted
+	// rather than listed twice. It is bounded by the status error limit of the controller.
+	// +optional
+	Errors []StatusError `json:"errors,omitempty"`
+
 	// Version is the Kubernetes version of the remote cluster
 	// +optional
 	Version string `json:"version,omitempty"`
 
+	// CertificateExpiry is the expiry time of the client certificate used to connect to the remote cluster.
+	// It is only set when the credentials authenticate with a client certificate.
+	// +optional
+	CertificateExpiry *metav1.Time `json:"certificateExpiry,omitempty"`
+
+	// Latency is the measured round-trip time to the remote API server.
+	// It is only set when LatencyProbe is enabled, and only updated when the measured latency
+	// changes by more than a quarter, and at least 10ms.
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty"`
+
+	// ObservedGeneration is the generation of the ClusterLink spec the status was computed from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions represent the latest available observations of the cluster's state
 	// +optional
+	// +listType=map
+	// +listMapKey=type
 	Conditions []ClusterLinkCondition `json:"conditions,omitempty"`
+
+	// FailingServices lists the services of this cluster that exhausted their failure budget
+	// and are only retried periodically until they sync successfully again
+	// +optional
+	FailingServices []FailingService `json:"failingServices,omitempty"`
+
+	// Onboarding reports the progress of the initial sync of the cluster, which imports its namespaces in
+	// batches across several sync cycles. It is removed once every namespace is imported.
+	// +optional
+	Onboarding *OnboardingStatus `json:"onboarding,omitempty"`
+
+	// SyncedServices is the number of services of the cluster synced in the last sync cycle
+	// +optional
+	SyncedServices int32 `json:"syncedServices"`
+
+	// SyncedEndpoints is the number of endpoints of the cluster published in the last sync cycle
+	// +optional
+	SyncedEndpoints int32 `json:"syncedEndpoints"`
+
+	// SkippedServices is the number of services of the cluster not synced in the last sync cycle because of
+	// filters or spec.maxServices
+	// +optional
+	SkippedServices int32 `json:"skippedServices"`
+
+	// LastSyncTime is when the last successful sync of the cluster completed
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// LastSyncDuration is how long the discovery and aggregation of the last successful sync took
+	// +optional
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+
+	// NamespaceSummary lists, per exported namespace of the cluster, the services synced and endpoints published
+	// in the last sync cycle. It is only reported when the controller runs with --namespace-summary.
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	NamespaceSummary []NamespaceSyncSummary `json:"namespaceSummary,omitempty"`
+
+	// History lists the recent disconnect and sync error episodes of the cluster, oldest first.
+	// It is bounded by the status history retention and limit of the controller.
+	// +optional
+	History []StatusEpisode `json:"history,omitempty"`
+}
+
+// NamespaceSyncSummary is what was imported from one namespace of a remote cluster in the last sync cycle
+type NamespaceSyncSummary struct {
+	// Namespace is the namespace of the services in the remote cluster
+	Namespace string `json:"namespace"`
+
+	// Services is the number of services of the namespace synced
+	Services int32 `json:"services"`
+
+	// Endpoints is the number of endpoints of the namespace published
+	Endpoints int32 `json:"endpoints"`
+}
+
+// StatusEpisode is a period during which a cluster was disconnected or failed to sync
+type StatusEpisode struct {
+	// Type of the episode
+	Type StatusEpisodeType `json:"type"`
+
+	// Count is the number of sync cycles the episode was observed in
+	Count int32 `json:"count"`
+
+	// FirstSeen is when the episode started
+	FirstSeen metav1.Time `json:"firstSeen"`
+
+	// LastSeen is the last time the episode was observed
+	LastSeen metav1.Time `json:"lastSeen"`
+
+	// Resolved is when the episode ended, unset while it is ongoing
+	// +optional
+	Resolved *metav1.Time `json:"resolved,omitempty"`
+
+	// Message is the last error observed during the episode
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// StatusEpisodeType defines the type of a status episode
+type StatusEpisodeType string
+
+const (
+	// StatusEpisodeDisconnected is an episode during which the cluster could not be connected to
+	StatusEpisodeDisconnected StatusEpisodeType = "Disconnected"
+
+	// StatusEpisodeSyncError is an episode during which the services of the cluster failed to sync
+	StatusEpisodeSyncError StatusEpisodeType = "SyncError"
+)
+
+// StatusError is an error a cluster failed with in a sync phase
+type StatusError struct {
+	// Phase is the sync phase the error occurred in
+	Phase SyncPhase `json:"phase"`
+
+	// Message is the error
+	Message string `json:"message"`
+
+	// Count is the number of times the error was seen
+	Count int32 `json:"count"`
+
+	// FirstSeen is when the error was first seen
+	FirstSeen metav1.Time `json:"firstSeen"`
+
+	// LastSeen is when the error was last seen
+	LastSeen metav1.Time `json:"lastSeen"`
+}
+
+// SyncPhase defines a phase of the sync of a cluster
+// +kubebuilder:validation:Enum=Connect;Discover;Aggregate;Update
+type SyncPhase string
+
+const (
+	// SyncPhaseConnect is the loading of the credentials of the cluster and the connection to it
+	SyncPhaseConnect SyncPhase = "Connect"
+
+	// SyncPhaseDiscover is the discovery of the services exported by the cluster
+	SyncPhaseDiscover SyncPhase = "Discover"
+
+	// SyncPhaseAggregate is the aggregation of the endpoints of the services imported from the cluster
+	SyncPhaseAggregate SyncPhase = "Aggregate"
+
+	// SyncPhaseUpdate is the update of the local EndpointSlices of the services imported from the cluster
+	SyncPhaseUpdate SyncPhase = "Update"
+)
+
+// OnboardingStatus is the progress of the initial sync of a cluster
+type OnboardingStatus struct {
+	// LastNamespace is the last local namespace, in lexical order, the services of the cluster are imported into
+	// +optional
+	LastNamespace string `json:"lastNamespace,omitempty"`
+
+	// ImportedNamespaces is the number of namespaces imported so far
+	ImportedNamespaces int32 `json:"importedNamespaces"`
+
+	// TotalNamespaces is the number of namespaces the cluster exports services into
+	TotalNamespaces int32 `json:"totalNamespaces"`
+}
+
+// FailingService describes a service that keeps failing to sync
+type FailingService struct {
+	// Service is the namespace/name of the service
+	Service string `json:"service"`
+
+	// Failures is the number of consecutive failed syncs
+	Failures int32 `json:"failures"`
+
+	// LastError is the error of the last failed sync
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// Since is the time of the first of the consecutive failures
+	Since metav1.Time `json:"since"`
 }
 
 // ClusterLinkCondition describes the state of a linked cluster
@@ -100,7 +709,11 @@ type ClusterLinkCondition struct {
 	// Status of the condition (True, False, Unknown)
 	Status metav1.ConditionStatus `json:"status"`
 
-	// LastTransitionTime is the last time the condition transitioned
+	// ObservedGeneration is the generation of the ClusterLink spec the condition was set for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastTransitionTime is the last time the condition changed from one status to another
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 
@@ -122,6 +735,43 @@ const (
 
 	// ClusterLinkError indicates there's an error with the cluster
 	ClusterLinkError ClusterLinkConditionType = "Error"
+
+	// ClusterLinkCredentialsExpiring indicates the client certificate is expired or about to expire
+	ClusterLinkCredentialsExpiring ClusterLinkConditionType = "CredentialsExpiring"
+
+	// ClusterLinkNewerSchemaDetected indicates the ClusterLink has fields written by a newer version of svclink,
+	// which are preserved but not honored
+	ClusterLinkNewerSchemaDetected ClusterLinkConditionType = "NewerSchemaDetected"
+
+	// ClusterLinkPaused indicates the sync of the cluster is paused with spec.paused
+	ClusterLinkPaused ClusterLinkConditionType = "Paused"
+
+	// ClusterLinkDisabled indicates the cluster is not synced because of spec.enabled
+	ClusterLinkDisabled ClusterLinkConditionType = "Disabled"
+
+	// ClusterLinkConfigurationHazard indicates the startup preflight found the ClusterLink would create sync
+	// loops or collisions, and the cluster is not synced
+	ClusterLinkConfigurationHazard ClusterLinkConditionType = "ConfigurationHazard"
+
+	// ClusterLinkQuotaExceeded indicates the cluster exports more services than spec.maxServices allows
+	// and some of them are not imported
+	ClusterLinkQuotaExceeded ClusterLinkConditionType = "QuotaExceeded"
+
+	// ClusterLinkDegraded indicates the cluster failed repeatedly and is only probed periodically
+	// until it recovers
+	ClusterLinkDegraded ClusterLinkConditionType = "Degraded"
+
+	// ClusterLinkPermissionsInsufficient indicates the credentials of the ClusterLink miss permissions svclink
+	// needs on the remote cluster
+	ClusterLinkPermissionsInsufficient ClusterLinkConditionType = "PermissionsInsufficient"
+
+	// ClusterLinkNetworkReachable indicates whether the endpoints imported from the cluster are reachable
+	// from the controller. It is only reported when ReachabilityProbe is enabled.
+	ClusterLinkNetworkReachable ClusterLinkConditionType = "NetworkReachable"
+
+	// ClusterLinkVersionSkew indicates the remote cluster is too old to be synced, as it does not serve
+	// discovery.k8s.io/v1 EndpointSlices
+	ClusterLinkVersionSkew ClusterLinkConditionType = "VersionSkew"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -134,40 +784,127 @@ type ClusterLinkList struct {
 	Items []ClusterLink `json:"items"`
 }
 
-func (cls *ClusterLinkSpec) ToExcludedNamespaceSet() sets.Set[string] {
-	excludedNS := sets.New(cls.ExcludedNamespaces...)
+func (cls *ClusterLinkSpec) ToExcludedNamespaceSet() NameMatcher {
+	excludedNS := NewNameMatcher(cls.ExcludedNamespaces...)
 	excludedNS.Insert(api.NamespaceSystem) // Always exclude kube-system
 	return excludedNS
 }
 
-func (cls *ClusterLinkSpec) ToIncludedNamespaceSet() sets.Set[string] {
-	return sets.New(cls.IncludedNamespaces...)
+func (cls *ClusterLinkSpec) ToIncludedNamespaceSet() NameMatcher {
+	return NewNameMatcher(cls.IncludedNamespaces...)
+}
+
+func (cls *ClusterLinkSpec) ToExcludedServiceSet() NameMatcher {
+	return NewNameMatcher(cls.ExcludedServices...)
 }
 
-func (cls *ClusterLinkSpec) ToExcludedServiceSet() sets.Set[string] {
-	return sets.New(cls.ExcludedServices...)
+func (cls *ClusterLinkSpec) ToIncludedServiceSet() NameMatcher {
+	return NewNameMatcher(cls.IncludedServices...)
 }
 
-func (cls *ClusterLinkSpec) ToExcludedServiceNameSet() sets.Set[string] {
-	excludedSvcNames := sets.New(cls.ExcludedServiceNames...)
+func (cls *ClusterLinkSpec) ToExcludedServiceNameSet() NameMatcher {
+	excludedSvcNames := NewNameMatcher(cls.ExcludedServiceNames...)
 	excludedSvcNames.Insert("kubernetes") // Always exclude the kubernetes service
 	return excludedSvcNames
 }
 
+// ValidateFilterPatterns returns an error if any glob or regex pattern in the namespace and service filters is invalid
+func (cls *ClusterLinkSpec) ValidateFilterPatterns() error {
+	for _, filter := range []struct {
+		field   string
+		entries []string
+	}{
+		{"excludedNamespaces", cls.ExcludedNamespaces},
+		{"includedNamespaces", cls.IncludedNamespaces},
+		{"excludedServices", cls.ExcludedServices},
+		{"includedServices", cls.IncludedServices},
+		{"excludedServiceNames", cls.ExcludedServiceNames},
+	} {
+		if err := ValidateNamePatterns(filter.entries); err != nil {
+			return fmt.Errorf("%s: %w", filter.field, err)
+		}
+	}
+	return nil
+}
+
+// ToNamespaceLabelSelector converts NamespaceSelector into a labels.Selector.
+// All namespaces are selected when NamespaceSelector is not specified.
+func (cls *ClusterLinkSpec) ToNamespaceLabelSelector() (labels.Selector, error) {
+	if cls.NamespaceSelector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(cls.NamespaceSelector)
+}
+
+// ToServiceLabelSelector converts ServiceSelector into a labels.Selector.
+// All services are selected when ServiceSelector is not specified.
+func (cls *ClusterLinkSpec) ToServiceLabelSelector() (labels.Selector, error) {
+	if cls.ServiceSelector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(cls.ServiceSelector)
+}
+
+// ToZoneMap returns the local zone of each mapped remote zone
+func (cls *ClusterLinkSpec) ToZoneMap() map[string]string {
+	zoneMap := make(map[string]string, len(cls.ZoneMappings))
+	for _, mapping := range cls.ZoneMappings {
+		zoneMap[mapping.Source] = mapping.Target
+	}
+	return zoneMap
+}
+
+func (cls *ClusterLinkSpec) ToNamespaceMap() map[string]string {
+	namespaceMap := make(map[string]string, len(cls.NamespaceMappings))
+	for _, mapping := range cls.NamespaceMappings {
+		namespaceMap[mapping.Source] = mapping.Target
+	}
+	return namespaceMap
+}
+
+// MapNamespace returns the local namespace the services of a remote namespace are imported into.
+// Parameters accept the pre-computed map of ToNamespaceMap.
+func (cls *ClusterLinkSpec) MapNamespace(namespace string, namespaceMap map[string]string) string {
+	if target, ok := namespaceMap[namespace]; ok {
+		return target
+	}
+	return namespace
+}
+
+func (cls *ClusterLinkSpec) ToServiceTypeSet() sets.Set[corev1.ServiceType] {
+	return sets.New(cls.SyncServiceTypes...)
+}
+
+// ShouldExcludeServiceType determines whether services of the given type should be excluded
+// from synchronization. All service types are included when SyncServiceTypes is not specified.
+func (cls *ClusterLinkSpec) ShouldExcludeServiceType(serviceType corev1.ServiceType, serviceTypes *sets.Set[corev1.ServiceType]) bool {
+	return serviceTypes.Len() > 0 && !serviceTypes.Has(serviceType)
+}
+
+func (cls *ClusterLinkSpec) ToAddressTypeSet() sets.Set[discoveryv1.AddressType] {
+	return sets.New(cls.AddressTypes...)
+}
+
+// ShouldExcludeAddressType determines whether endpoints of the given address type should be excluded
+// from synchronization. All address types are included when AddressTypes is not specified.
+func (cls *ClusterLinkSpec) ShouldExcludeAddressType(addressType discoveryv1.AddressType, addressTypes *sets.Set[discoveryv1.AddressType]) bool {
+	return addressTypes.Len() > 0 && !addressTypes.Has(addressType)
+}
+
 // ShouldExcludeNamespace determines whether a namespace should be excluded from synchronization.
 // It evaluates exclusion/inclusion rules in the following order:
 // 1. Namespace is explicitly excluded
 // 2. Namespace is not in the included list (if IncludedNamespaces is specified)
-// Parameters accept pre-computed sets for efficient O(1) lookups.
+// Parameters accept pre-computed matchers, exact names are looked up in O(1).
 // Returns true if the namespace should be excluded, false otherwise.
-func (cls *ClusterLinkSpec) ShouldExcludeNamespace(namespace string, excludedNS, includedNS *sets.Set[string]) bool {
+func (cls *ClusterLinkSpec) ShouldExcludeNamespace(namespace string, excludedNS, includedNS *NameMatcher) bool {
 	// Exclude if namespace is in the exclusion list
-	if excludedNS.Has(namespace) {
+	if excludedNS.Matches(namespace) {
 		return true
 	}
 
 	// Exclude if namespace is not in the inclusion list (when inclusion list is specified)
-	if includedNS.Len() > 0 && !includedNS.Has(namespace) {
+	if includedNS.Len() > 0 && !includedNS.Matches(namespace) {
 		return true
 	}
 
@@ -176,20 +913,28 @@ func (cls *ClusterLinkSpec) ShouldExcludeNamespace(namespace string, excludedNS,
 
 // ShouldExcludeService determines whether a service should be excluded from synchronization.
 // It evaluates exclusion/inclusion rules in the following order:
-//  1. Service is explicitly excluded by namespace/name combination
-//  2. Service name is globally excluded across all namespaces
+//  1. Service is explicitly included by namespace/name combination (the allowlist takes precedence)
+//  2. Service is not in the included list (if IncludedServices is specified)
+//  3. Service is explicitly excluded by namespace/name combination
+//  4. Service name is globally excluded across all namespaces
 //
-// Parameters accept pre-computed sets for efficient O(1) lookups.
+// Parameters accept pre-computed matchers, exact names are looked up in O(1).
 // Returns true if the service should be excluded, false otherwise.
-func (cls *ClusterLinkSpec) ShouldExcludeService(namespace, serviceName string, excludedSvcSet, excludedSvcNameSet *sets.Set[string]) bool {
-	// Exclude if exact namespace/service combination matches
+func (cls *ClusterLinkSpec) ShouldExcludeService(namespace, serviceName string, excludedSvcSet, excludedSvcNameSet, includedSvcSet *NameMatcher) bool {
 	fullName := namespace + "/" + serviceName
-	if excludedSvcSet.Has(fullName) {
+
+	// Include if the service is explicitly allowlisted, exclude every other service when an allowlist is specified
+	if includedSvcSet.Len() > 0 {
+		return !includedSvcSet.Matches(fullName)
+	}
+
+	// Exclude if exact namespace/service combination matches
+	if excludedSvcSet.Matches(fullName) {
 		return true
 	}
 
 	// Exclude if service name is globally excluded
-	if excludedSvcNameSet.Has(serviceName) {
+	if excludedSvcNameSet.Matches(serviceName) {
 		return true
 	}
 
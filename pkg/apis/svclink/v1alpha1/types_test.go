@@ -3,6 +3,10 @@ package v1alpha1
 import (
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	api "k8s.io/kubernetes/pkg/apis/core"
 )
@@ -88,6 +92,24 @@ func TestClusterLinkSpec_ShouldExcludeNamespace(t *testing.T) {
 			expectedExcluded: true,
 			description:      "should handle multiple excluded namespaces",
 		},
+		{
+			name: "exclude namespace matching glob",
+			spec: ClusterLinkSpec{
+				ExcludedNamespaces: []string{"team-*"},
+			},
+			namespace:        "team-payments",
+			expectedExcluded: true,
+			description:      "namespace matching a glob in ExcludedNamespaces should be excluded",
+		},
+		{
+			name: "include namespace matching regex",
+			spec: ClusterLinkSpec{
+				IncludedNamespaces: []string{"/^prod-[a-z]+$/"},
+			},
+			namespace:        "prod-eu",
+			expectedExcluded: false,
+			description:      "namespace matching a regex in IncludedNamespaces should be included",
+		},
 		{
 			name: "empty namespace string",
 			spec: ClusterLinkSpec{
@@ -239,14 +261,77 @@ func TestClusterLinkSpec_ShouldExcludeService(t *testing.T) {
 			expectedExcluded: true,
 			description:      "kubernetes service in kube-system should be excluded",
 		},
+		{
+			name: "exclude service name matching regex",
+			spec: ClusterLinkSpec{
+				ExcludedServiceNames: []string{"/^canary-.*/"},
+			},
+			namespace:        "default",
+			serviceName:      "canary-web",
+			expectedExcluded: true,
+			description:      "service name matching a regex in ExcludedServiceNames should be excluded",
+		},
+		{
+			name: "glob does not match across namespace separator",
+			spec: ClusterLinkSpec{
+				ExcludedServices: []string{"team-*"},
+			},
+			namespace:        "team-a",
+			serviceName:      "web",
+			expectedExcluded: false,
+			description:      "a glob in ExcludedServices should not match the namespace/name separator",
+		},
+		{
+			name: "include service matching glob",
+			spec: ClusterLinkSpec{
+				IncludedServices: []string{"production/api-*"},
+			},
+			namespace:        "production",
+			serviceName:      "api-v2",
+			expectedExcluded: false,
+			description:      "service matching a glob in IncludedServices should be included",
+		},
+		{
+			name: "include service in allowlist",
+			spec: ClusterLinkSpec{
+				IncludedServices: []string{"default/web", "production/checkout"},
+			},
+			namespace:        "production",
+			serviceName:      "checkout",
+			expectedExcluded: false,
+			description:      "service in IncludedServices should be included",
+		},
+		{
+			name: "exclude service not in allowlist",
+			spec: ClusterLinkSpec{
+				IncludedServices: []string{"default/web"},
+			},
+			namespace:        "default",
+			serviceName:      "api",
+			expectedExcluded: true,
+			description:      "service not in IncludedServices should be excluded when an allowlist is specified",
+		},
+		{
+			name: "allowlist takes precedence over exclusions",
+			spec: ClusterLinkSpec{
+				IncludedServices:     []string{"default/web"},
+				ExcludedServices:     []string{"default/web"},
+				ExcludedServiceNames: []string{"web"},
+			},
+			namespace:        "default",
+			serviceName:      "web",
+			expectedExcluded: false,
+			description:      "service in IncludedServices should be included even if it is excluded",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			excludedSvcSet := tt.spec.ToExcludedServiceSet()
 			excludedSvcNameSet := tt.spec.ToExcludedServiceNameSet()
+			includedSvcSet := tt.spec.ToIncludedServiceSet()
 
-			result := tt.spec.ShouldExcludeService(tt.namespace, tt.serviceName, &excludedSvcSet, &excludedSvcNameSet)
+			result := tt.spec.ShouldExcludeService(tt.namespace, tt.serviceName, &excludedSvcSet, &excludedSvcNameSet, &includedSvcSet)
 
 			if result != tt.expectedExcluded {
 				t.Errorf("%s: expected excluded=%v, got excluded=%v", tt.description, tt.expectedExcluded, result)
@@ -255,6 +340,134 @@ func TestClusterLinkSpec_ShouldExcludeService(t *testing.T) {
 	}
 }
 
+func TestClusterLinkSpec_ShouldExcludeAddressType(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             ClusterLinkSpec
+		addressType      discoveryv1.AddressType
+		expectedExcluded bool
+	}{
+		{
+			name:             "all address types included by default",
+			spec:             ClusterLinkSpec{},
+			addressType:      discoveryv1.AddressTypeFQDN,
+			expectedExcluded: false,
+		},
+		{
+			name: "include listed address type",
+			spec: ClusterLinkSpec{
+				AddressTypes: []discoveryv1.AddressType{discoveryv1.AddressTypeIPv6},
+			},
+			addressType:      discoveryv1.AddressTypeIPv6,
+			expectedExcluded: false,
+		},
+		{
+			name: "exclude address type not listed",
+			spec: ClusterLinkSpec{
+				AddressTypes: []discoveryv1.AddressType{discoveryv1.AddressTypeIPv6},
+			},
+			addressType:      discoveryv1.AddressTypeIPv4,
+			expectedExcluded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addressTypes := tt.spec.ToAddressTypeSet()
+
+			result := tt.spec.ShouldExcludeAddressType(tt.addressType, &addressTypes)
+
+			if result != tt.expectedExcluded {
+				t.Errorf("expected excluded=%v, got excluded=%v", tt.expectedExcluded, result)
+			}
+		})
+	}
+}
+
+func TestClusterLinkSpec_ShouldExcludeServiceType(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             ClusterLinkSpec
+		serviceType      corev1.ServiceType
+		expectedExcluded bool
+	}{
+		{
+			name:             "all service types included by default",
+			spec:             ClusterLinkSpec{},
+			serviceType:      corev1.ServiceTypeLoadBalancer,
+			expectedExcluded: false,
+		},
+		{
+			name: "include listed service type",
+			spec: ClusterLinkSpec{
+				SyncServiceTypes: []corev1.ServiceType{corev1.ServiceTypeClusterIP},
+			},
+			serviceType:      corev1.ServiceTypeClusterIP,
+			expectedExcluded: false,
+		},
+		{
+			name: "exclude service type not listed",
+			spec: ClusterLinkSpec{
+				SyncServiceTypes: []corev1.ServiceType{corev1.ServiceTypeClusterIP},
+			},
+			serviceType:      corev1.ServiceTypeNodePort,
+			expectedExcluded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceTypes := tt.spec.ToServiceTypeSet()
+
+			result := tt.spec.ShouldExcludeServiceType(tt.serviceType, &serviceTypes)
+
+			if result != tt.expectedExcluded {
+				t.Errorf("expected excluded=%v, got excluded=%v", tt.expectedExcluded, result)
+			}
+		})
+	}
+}
+
+func TestClusterLinkSpec_MapNamespace(t *testing.T) {
+	spec := ClusterLinkSpec{
+		NamespaceMappings: []NamespaceMapping{
+			{Source: "prod-eu", Target: "prod"},
+			{Source: "staging-eu", Target: "staging"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		expected  string
+	}{
+		{
+			name:      "mapped namespace",
+			namespace: "prod-eu",
+			expected:  "prod",
+		},
+		{
+			name:      "unmapped namespace keeps its name",
+			namespace: "default",
+			expected:  "default",
+		},
+		{
+			name:      "mappings are not applied to targets",
+			namespace: "prod",
+			expected:  "prod",
+		},
+	}
+
+	namespaceMap := spec.ToNamespaceMap()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := spec.MapNamespace(tt.namespace, namespaceMap); result != tt.expected {
+				t.Errorf("expected namespace %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestClusterLinkSpec_ToExcludedNamespaceSet(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -404,3 +617,86 @@ func TestClusterLinkSpec_ToExcludedServiceNameSet(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterLinkSpec_ToServiceLabelSelector(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      *metav1.LabelSelector
+		serviceLabels map[string]string
+		expectedMatch bool
+		expectedErr   bool
+	}{
+		{
+			name:          "no selector matches every service",
+			serviceLabels: map[string]string{"app": "web"},
+			expectedMatch: true,
+		},
+		{
+			name:          "matching labels",
+			selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"svclink.cloudpilot.ai/export": "true"}},
+			serviceLabels: map[string]string{"svclink.cloudpilot.ai/export": "true", "app": "web"},
+			expectedMatch: true,
+		},
+		{
+			name:          "missing label",
+			selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"svclink.cloudpilot.ai/export": "true"}},
+			serviceLabels: map[string]string{"app": "web"},
+			expectedMatch: false,
+		},
+		{
+			name: "invalid operator",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: "Matches", Values: []string{"web"}},
+			}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := ClusterLinkSpec{ServiceSelector: tt.selector}
+			selector, err := spec.ToServiceLabelSelector()
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if match := selector.Matches(labels.Set(tt.serviceLabels)); match != tt.expectedMatch {
+				t.Errorf("expected match %v, got %v", tt.expectedMatch, match)
+			}
+		})
+	}
+}
+
+func TestClusterLinkSpec_ValidateFilterPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        ClusterLinkSpec
+		expectedErr bool
+	}{
+		{
+			name: "plain names, globs and regexes",
+			spec: ClusterLinkSpec{
+				ExcludedNamespaces:   []string{"dev", "team-*"},
+				ExcludedServiceNames: []string{"/^canary-.*/"},
+			},
+		},
+		{
+			name: "invalid regex",
+			spec: ClusterLinkSpec{
+				IncludedServices: []string{"/(default/"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.ValidateFilterPatterns()
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -22,9 +22,43 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressTranslation) DeepCopyInto(out *AddressTranslation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressTranslation.
+func (in *AddressTranslation) DeepCopy() *AddressTranslation {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressTranslation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateReference) DeepCopyInto(out *CertificateReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateReference.
+func (in *CertificateReference) DeepCopy() *CertificateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterLink) DeepCopyInto(out *ClusterLink) {
 	*out = *in
@@ -53,6 +87,27 @@ func (in *ClusterLink) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterLinkAuth) DeepCopyInto(out *ClusterLinkAuth) {
+	*out = *in
+	if in.CertificateRef != nil {
+		in, out := &in.CertificateRef, &out.CertificateRef
+		*out = new(CertificateReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterLinkAuth.
+func (in *ClusterLinkAuth) DeepCopy() *ClusterLinkAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterLinkAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterLinkCondition) DeepCopyInto(out *ClusterLinkCondition) {
 	*out = *in
@@ -106,6 +161,51 @@ func (in *ClusterLinkList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterLinkSpec) DeepCopyInto(out *ClusterLinkSpec) {
 	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.KubeconfigSecretRef != nil {
+		in, out := &in.KubeconfigSecretRef, &out.KubeconfigSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.ServiceAccountTokenSecretRef != nil {
+		in, out := &in.ServiceAccountTokenSecretRef, &out.ServiceAccountTokenSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(ClusterLinkAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientQPS != nil {
+		in, out := &in.ClientQPS, &out.ClientQPS
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ClientBurst != nil {
+		in, out := &in.ClientBurst, &out.ClientBurst
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(EndpointHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EndpointWeight != nil {
+		in, out := &in.EndpointWeight, &out.EndpointWeight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxServices != nil {
+		in, out := &in.MaxServices, &out.MaxServices
+		*out = new(int32)
+		**out = **in
+	}
 	if in.ExcludedNamespaces != nil {
 		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
 		*out = make([]string, len(*in))
@@ -116,16 +216,61 @@ func (in *ClusterLinkSpec) DeepCopyInto(out *ClusterLinkSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceMappings != nil {
+		in, out := &in.NamespaceMappings, &out.NamespaceMappings
+		*out = make([]NamespaceMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.AddressTranslations != nil {
+		in, out := &in.AddressTranslations, &out.AddressTranslations
+		*out = make([]AddressTranslation, len(*in))
+		copy(*out, *in)
+	}
 	if in.ExcludedServices != nil {
 		in, out := &in.ExcludedServices, &out.ExcludedServices
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.IncludedServices != nil {
+		in, out := &in.IncludedServices, &out.IncludedServices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ExcludedServiceNames != nil {
 		in, out := &in.ExcludedServiceNames, &out.ExcludedServiceNames
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ServiceSelector != nil {
+		in, out := &in.ServiceSelector, &out.ServiceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncServiceTypes != nil {
+		in, out := &in.SyncServiceTypes, &out.SyncServiceTypes
+		*out = make([]corev1.ServiceType, len(*in))
+		copy(*out, *in)
+	}
+	if in.AddressTypes != nil {
+		in, out := &in.AddressTypes, &out.AddressTypes
+		*out = make([]discoveryv1.AddressType, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedNodeOperatingSystems != nil {
+		in, out := &in.ExcludedNodeOperatingSystems, &out.ExcludedNodeOperatingSystems
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ZoneMappings != nil {
+		in, out := &in.ZoneMappings, &out.ZoneMappings
+		*out = make([]ZoneMapping, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -146,6 +291,22 @@ func (in *ClusterLinkStatus) DeepCopyInto(out *ClusterLinkStatus) {
 		in, out := &in.LastConnected, &out.LastConnected
 		*out = (*in).DeepCopy()
 	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]StatusError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CertificateExpiry != nil {
+		in, out := &in.CertificateExpiry, &out.CertificateExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]ClusterLinkCondition, len(*in))
@@ -153,6 +314,39 @@ func (in *ClusterLinkStatus) DeepCopyInto(out *ClusterLinkStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.FailingServices != nil {
+		in, out := &in.FailingServices, &out.FailingServices
+		*out = make([]FailingService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Onboarding != nil {
+		in, out := &in.Onboarding, &out.Onboarding
+		*out = new(OnboardingStatus)
+		**out = **in
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]StatusEpisode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncDuration != nil {
+		in, out := &in.LastSyncDuration, &out.LastSyncDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NamespaceSummary != nil {
+		in, out := &in.NamespaceSummary, &out.NamespaceSummary
+		*out = make([]NamespaceSyncSummary, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -165,3 +359,167 @@ func (in *ClusterLinkStatus) DeepCopy() *ClusterLinkStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointHealthCheck) DeepCopyInto(out *EndpointHealthCheck) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointHealthCheck.
+func (in *EndpointHealthCheck) DeepCopy() *EndpointHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailingService) DeepCopyInto(out *FailingService) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailingService.
+func (in *FailingService) DeepCopy() *FailingService {
+	if in == nil {
+		return nil
+	}
+	out := new(FailingService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceMapping) DeepCopyInto(out *NamespaceMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMapping.
+func (in *NamespaceMapping) DeepCopy() *NamespaceMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSyncSummary) DeepCopyInto(out *NamespaceSyncSummary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSyncSummary.
+func (in *NamespaceSyncSummary) DeepCopy() *NamespaceSyncSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSyncSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnboardingStatus) DeepCopyInto(out *OnboardingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnboardingStatus.
+func (in *OnboardingStatus) DeepCopy() *OnboardingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OnboardingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusError) DeepCopyInto(out *StatusError) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	in.LastSeen.DeepCopyInto(&out.LastSeen)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusError.
+func (in *StatusError) DeepCopy() *StatusError {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusEpisode) DeepCopyInto(out *StatusEpisode) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	in.LastSeen.DeepCopyInto(&out.LastSeen)
+	if in.Resolved != nil {
+		in, out := &in.Resolved, &out.Resolved
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusEpisode.
+func (in *StatusEpisode) DeepCopy() *StatusEpisode {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusEpisode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneMapping) DeepCopyInto(out *ZoneMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneMapping.
+func (in *ZoneMapping) DeepCopy() *ZoneMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneMapping)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,95 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CAPISource is the discovery source label value of ClusterLinks created for Cluster API clusters
+	CAPISource = "capi"
+	// capiKubeconfigSecretSuffix is appended to the Cluster name to get the name of its kubeconfig Secret
+	capiKubeconfigSecretSuffix = "-kubeconfig"
+	// capiKubeconfigSecretKey is the key of the kubeconfig in the Cluster API kubeconfig Secret
+	capiKubeconfigSecretKey = "value"
+)
+
+// capiClusterGVK is the Cluster API Cluster kind
+var capiClusterGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+
+// CAPIDiscoverer creates a ClusterLink for every Cluster API workload cluster of the management cluster,
+// using the kubeconfig Secret Cluster API generates for it. ClusterLinks are owned by their Cluster,
+// so deleting the Cluster garbage-collects the ClusterLink.
+type CAPIDiscoverer struct {
+	ctrlClient client.Client
+	// apiReader reads kubeconfig Secrets directly so that Secrets are not cached cluster-wide
+	apiReader client.Reader
+}
+
+// NewCAPIDiscoverer creates a CAPIDiscoverer
+func NewCAPIDiscoverer(ctrlClient client.Client, apiReader client.Reader) *CAPIDiscoverer {
+	return &CAPIDiscoverer{
+		ctrlClient: ctrlClient,
+		apiReader:  apiReader,
+	}
+}
+
+// Reconcile creates or updates the ClusterLinks of all Cluster API clusters
+func (d *CAPIDiscoverer) Reconcile(ctx context.Context) error {
+	// Only the Cluster metadata is needed, which avoids depending on the Cluster API types
+	clusters := &metav1.PartialObjectMetadataList{}
+	clusters.SetGroupVersionKind(capiClusterGVK.GroupVersion().WithKind(capiClusterGVK.Kind + "List"))
+	if err := d.ctrlClient.List(ctx, clusters); err != nil {
+		if meta.IsNoMatchError(err) {
+			klog.V(4).Info("Cluster API is not installed, skipping Cluster API discovery")
+			return nil
+		}
+		return fmt.Errorf("failed to list Cluster API clusters: %w", err)
+	}
+
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		if cluster.DeletionTimestamp != nil {
+			continue
+		}
+
+		key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}
+		secret := &corev1.Secret{}
+		secretKey := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name + capiKubeconfigSecretSuffix}
+		if err := d.apiReader.Get(ctx, secretKey, secret); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				// The kubeconfig is generated once the control plane is initialized
+				klog.V(4).Infof("Kubeconfig Secret of Cluster API cluster %s does not exist yet", key)
+				continue
+			}
+			klog.Errorf("Failed to get kubeconfig Secret of Cluster API cluster %s: %v", key, err)
+			continue
+		}
+		kubeconfig, ok := secret.Data[capiKubeconfigSecretKey]
+		if !ok || len(kubeconfig) == 0 {
+			klog.Warningf("Kubeconfig Secret %s has no %q key", secretKey, capiKubeconfigSecretKey)
+			continue
+		}
+
+		ownerReferences := []metav1.OwnerReference{{
+			APIVersion: capiClusterGVK.GroupVersion().String(),
+			Kind:       capiClusterGVK.Kind,
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+			Controller: ptr.To(true),
+		}}
+		if err := upsertClusterLink(ctx, d.ctrlClient, key, CAPISource, kubeconfig, ownerReferences); err != nil {
+			klog.Errorf("Failed to apply ClusterLink for Cluster API cluster %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// capiClient serves Cluster API clusters, kubeconfig Secrets and ClusterLinks keyed by namespace/name
+type capiClient struct {
+	client.Client
+	listErr      error
+	clusters     []metav1.PartialObjectMetadata
+	secrets      map[string]*corev1.Secret
+	clusterLinks map[string]*svclinkv1alpha1.ClusterLink
+	created      []*svclinkv1alpha1.ClusterLink
+	patched      []*svclinkv1alpha1.ClusterLink
+}
+
+func (c *capiClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	if c.listErr != nil {
+		return c.listErr
+	}
+	list.(*metav1.PartialObjectMetadataList).Items = c.clusters
+	return nil
+}
+
+func (c *capiClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	switch obj := obj.(type) {
+	case *corev1.Secret:
+		if secret, ok := c.secrets[key.String()]; ok {
+			secret.DeepCopyInto(obj)
+			return nil
+		}
+	case *svclinkv1alpha1.ClusterLink:
+		if clusterLink, ok := c.clusterLinks[key.String()]; ok {
+			clusterLink.DeepCopyInto(obj)
+			return nil
+		}
+	}
+	return apierrors.NewNotFound(corev1.Resource("objects"), key.Name)
+}
+
+func (c *capiClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.created = append(c.created, obj.(*svclinkv1alpha1.ClusterLink))
+	return nil
+}
+
+func (c *capiClient) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	c.patched = append(c.patched, obj.(*svclinkv1alpha1.ClusterLink))
+	return nil
+}
+
+func TestCAPIDiscoverer(t *testing.T) {
+	cluster := func(name string) metav1.PartialObjectMetadata {
+		return metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet", Name: name, UID: types.UID("uid-" + name)}}
+	}
+	kubeconfig := func(value string) *corev1.Secret {
+		return &corev1.Secret{Data: map[string][]byte{capiKubeconfigSecretKey: []byte(value)}}
+	}
+	deleting := cluster("deleting")
+	deleting.DeletionTimestamp = &metav1.Time{}
+
+	fake := &capiClient{
+		clusters: []metav1.PartialObjectMetadata{cluster("east"), cluster("west"), cluster("provisioning"), cluster("manual"), deleting},
+		secrets: map[string]*corev1.Secret{
+			"fleet/east-kubeconfig":     kubeconfig("east-kubeconfig"),
+			"fleet/west-kubeconfig":     kubeconfig("west-rotated"),
+			"fleet/manual-kubeconfig":   kubeconfig("manual-kubeconfig"),
+			"fleet/deleting-kubeconfig": kubeconfig("deleting-kubeconfig"),
+		},
+		clusterLinks: map[string]*svclinkv1alpha1.ClusterLink{
+			"fleet/west": {
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet", Name: "west", Labels: map[string]string{config.DiscoverySourceLabel: CAPISource}},
+				Spec:       svclinkv1alpha1.ClusterLinkSpec{Kubeconfig: base64.StdEncoding.EncodeToString([]byte("west-kubeconfig"))},
+			},
+			// Created by hand, never taken over
+			"fleet/manual": {ObjectMeta: metav1.ObjectMeta{Namespace: "fleet", Name: "manual"}},
+		},
+	}
+
+	if err := NewCAPIDiscoverer(fake, fake).Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(fake.created) != 1 {
+		t.Fatalf("expected a ClusterLink for east only, got %d created", len(fake.created))
+	}
+	created := fake.created[0]
+	if created.Name != "east" || created.Labels[config.DiscoverySourceLabel] != CAPISource ||
+		created.Spec.Kubeconfig != base64.StdEncoding.EncodeToString([]byte("east-kubeconfig")) {
+		t.Errorf("unexpected ClusterLink %+v", created)
+	}
+	if owners := created.OwnerReferences; len(owners) != 1 || owners[0].Kind != "Cluster" || owners[0].UID != "uid-east" {
+		t.Errorf("expected the ClusterLink to be owned by its Cluster, got %+v", owners)
+	}
+
+	if len(fake.patched) != 1 || fake.patched[0].Name != "west" ||
+		fake.patched[0].Spec.Kubeconfig != base64.StdEncoding.EncodeToString([]byte("west-rotated")) {
+		t.Errorf("expected the rotated kubeconfig of west to be patched, got %v", fake.patched)
+	}
+}
+
+func TestCAPIDiscovererWithoutClusterAPI(t *testing.T) {
+	fake := &capiClient{listErr: &meta.NoKindMatchError{GroupKind: capiClusterGVK.GroupKind()}}
+	if err := NewCAPIDiscoverer(fake, fake).Reconcile(context.Background()); err != nil {
+		t.Errorf("expected no error without Cluster API installed, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package clusterdiscovery materializes ClusterLinks for clusters listed by cloud provider APIs.
+// Providers list the managed Kubernetes clusters of an account (EKS region, GKE project or AKS
+// subscription) and the Discoverer creates, updates and prunes ClusterLinks for them, using
+// kubeconfigs that authenticate through the provider's exec credential plugin.
+package clusterdiscovery
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// Cluster is a managed Kubernetes cluster discovered from a cloud provider
+type Cluster struct {
+	// Name is the cluster name in the cloud provider
+	Name string
+	// Server is the API server URL
+	Server string
+	// CAData is the PEM encoded API server CA bundle
+	CAData []byte
+}
+
+// Provider lists the clusters of one cloud provider scope and describes how to authenticate to them
+type Provider interface {
+	// Source identifies the provider scope, e.g. "eks.us-east-1". It is used as the discovery source label value.
+	Source() string
+	// ListClusters returns the clusters currently present in the provider scope
+	ListClusters(ctx context.Context) ([]Cluster, error)
+	// Kubeconfig returns a kubeconfig for the cluster using the provider's exec credential plugin
+	Kubeconfig(cluster Cluster) ([]byte, error)
+}
+
+// Discoverer keeps ClusterLinks in sync with the clusters listed by the configured providers
+type Discoverer struct {
+	ctrlClient client.Client
+	namespace  string
+	providers  []Provider
+}
+
+// NewDiscoverer creates a Discoverer that materializes ClusterLinks in the given namespace
+func NewDiscoverer(ctrlClient client.Client, namespace string, providers []Provider) *Discoverer {
+	return &Discoverer{
+		ctrlClient: ctrlClient,
+		namespace:  namespace,
+		providers:  providers,
+	}
+}
+
+// Run reconciles discovered clusters periodically until the context is cancelled
+func (d *Discoverer) Run(ctx context.Context, interval time.Duration) {
+	wait.UntilWithContext(ctx, d.Reconcile, interval)
+}
+
+// Reconcile creates or updates a ClusterLink for every discovered cluster and deletes ClusterLinks
+// of clusters that disappeared. A provider that fails to list its clusters is skipped entirely,
+// so a transient cloud API error never deletes ClusterLinks.
+func (d *Discoverer) Reconcile(ctx context.Context) {
+	for _, provider := range d.providers {
+		if err := d.reconcileProvider(ctx, provider); err != nil {
+			klog.Errorf("Failed to reconcile clusters discovered from %s: %v", provider.Source(), err)
+		}
+	}
+}
+
+func (d *Discoverer) reconcileProvider(ctx context.Context, provider Provider) error {
+	clusters, err := provider.ListClusters(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+	klog.V(4).Infof("Discovered %d clusters from %s", len(clusters), provider.Source())
+
+	discovered := make(map[string]struct{}, len(clusters))
+	for _, cluster := range clusters {
+		name := clusterLinkName(cluster.Name)
+		if name == "" {
+			klog.Warningf("Skipping cluster %q discovered from %s: name is not a valid ClusterLink name", cluster.Name, provider.Source())
+			continue
+		}
+		discovered[name] = struct{}{}
+
+		if err := d.applyClusterLink(ctx, provider, name, cluster); err != nil {
+			klog.Errorf("Failed to apply ClusterLink %s for cluster discovered from %s: %v", name, provider.Source(), err)
+		}
+	}
+
+	var existing svclinkv1alpha1.ClusterLinkList
+	if err := d.ctrlClient.List(ctx, &existing, client.InNamespace(d.namespace),
+		client.MatchingLabels{config.DiscoverySourceLabel: provider.Source()}); err != nil {
+		return fmt.Errorf("failed to list discovered ClusterLinks: %w", err)
+	}
+	for i := range existing.Items {
+		clusterLink := &existing.Items[i]
+		if _, ok := discovered[clusterLink.Name]; ok {
+			continue
+		}
+		if err := d.ctrlClient.Delete(ctx, clusterLink); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to delete ClusterLink %s of removed cluster: %v", clusterLink.Name, err)
+			continue
+		}
+		klog.Infof("Deleted ClusterLink %s, cluster no longer exists in %s", clusterLink.Name, provider.Source())
+	}
+
+	return nil
+}
+
+// applyClusterLink creates the ClusterLink of a discovered cluster or refreshes its kubeconfig
+func (d *Discoverer) applyClusterLink(ctx context.Context, provider Provider, name string, cluster Cluster) error {
+	kubeconfig, err := provider.Kubeconfig(cluster)
+	if err != nil {
+		return fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+	return upsertClusterLink(ctx, d.ctrlClient, client.ObjectKey{Namespace: d.namespace, Name: name}, provider.Source(), kubeconfig, nil)
+}
+
+// upsertClusterLink creates a ClusterLink labeled with the discovery source, or refreshes the kubeconfig
+// of an existing one. Other spec fields are left untouched so that filters set by users are preserved,
+// and ClusterLinks created by hand or by another source are never taken over.
+func upsertClusterLink(ctx context.Context, ctrlClient client.Client, key client.ObjectKey, source string, kubeconfig []byte, ownerReferences []metav1.OwnerReference) error {
+	encoded := base64.StdEncoding.EncodeToString(kubeconfig)
+
+	clusterLink := &svclinkv1alpha1.ClusterLink{}
+	err := ctrlClient.Get(ctx, key, clusterLink)
+	if apierrors.IsNotFound(err) {
+		clusterLink = &svclinkv1alpha1.ClusterLink{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels: map[string]string{
+					config.DiscoverySourceLabel: source,
+				},
+				OwnerReferences: ownerReferences,
+			},
+			Spec: svclinkv1alpha1.ClusterLinkSpec{
+				Enabled:    true,
+				Kubeconfig: encoded,
+			},
+		}
+		if err := ctrlClient.Create(ctx, clusterLink); err != nil {
+			return err
+		}
+		klog.Infof("Created ClusterLink %s for cluster discovered from %s", key, source)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if clusterLink.Labels[config.DiscoverySourceLabel] != source {
+		klog.Warningf("Skipping cluster discovered from %s: ClusterLink %s already exists and is not managed by this source", source, key)
+		return nil
+	}
+
+	if clusterLink.Spec.Kubeconfig == encoded {
+		return nil
+	}
+	original := clusterLink.DeepCopy()
+	clusterLink.Spec.Kubeconfig = encoded
+	if err := ctrlClient.Patch(ctx, clusterLink, client.MergeFrom(original)); err != nil {
+		return err
+	}
+	klog.Infof("Updated kubeconfig of ClusterLink %s", key)
+	return nil
+}
+
+// clusterLinkName converts a cloud cluster name into a valid ClusterLink name,
+// returning an empty string if that is not possible
+func clusterLinkName(clusterName string) string {
+	name := strings.ToLower(strings.ReplaceAll(clusterName, "_", "-"))
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,233 @@
+package clusterdiscovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// execCredentialAPIVersion is the client.authentication.k8s.io version used by generated kubeconfigs
+	execCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+	// aksServerID is the well-known application ID of the AKS AAD server
+	aksServerID = "6dae42f8-4368-4678-94ff-3960e28e3630"
+)
+
+// commandRunner runs a cloud CLI command and returns its standard output
+type commandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// runCommand runs a cloud CLI command found in PATH
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// NewProvider creates a provider from a "<provider>:<scope>" specification:
+// "eks:<region>", "gke:<project>" or "aks:<subscription>"
+func NewProvider(spec string) (Provider, error) {
+	kind, scope, ok := strings.Cut(spec, ":")
+	if !ok || scope == "" {
+		return nil, fmt.Errorf("invalid cluster discovery provider %q, expected <provider>:<scope>", spec)
+	}
+
+	switch kind {
+	case "eks":
+		return &eksProvider{region: scope, run: runCommand}, nil
+	case "gke":
+		return &gkeProvider{project: scope, run: runCommand}, nil
+	case "aks":
+		return &aksProvider{subscription: scope, run: runCommand}, nil
+	default:
+		return nil, fmt.Errorf("unknown cluster discovery provider %q, must be one of eks, gke, aks", kind)
+	}
+}
+
+// eksProvider discovers EKS clusters of one AWS region using the aws CLI
+type eksProvider struct {
+	region string
+	run    commandRunner
+}
+
+func (p *eksProvider) Source() string {
+	return "eks." + p.region
+}
+
+func (p *eksProvider) ListClusters(ctx context.Context) ([]Cluster, error) {
+	out, err := p.run(ctx, "aws", "eks", "list-clusters", "--region", p.region, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Clusters []string `json:"clusters"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse EKS cluster list: %w", err)
+	}
+
+	clusters := make([]Cluster, 0, len(list.Clusters))
+	for _, name := range list.Clusters {
+		out, err := p.run(ctx, "aws", "eks", "describe-cluster", "--name", name, "--region", p.region, "--output", "json")
+		if err != nil {
+			return nil, err
+		}
+		var described struct {
+			Cluster struct {
+				Endpoint             string `json:"endpoint"`
+				CertificateAuthority struct {
+					Data string `json:"data"`
+				} `json:"certificateAuthority"`
+			} `json:"cluster"`
+		}
+		if err := json.Unmarshal(out, &described); err != nil {
+			return nil, fmt.Errorf("failed to parse EKS cluster %s: %w", name, err)
+		}
+		// Clusters that are still being created have no endpoint yet
+		if described.Cluster.Endpoint == "" {
+			continue
+		}
+		caData, err := base64.StdEncoding.DecodeString(described.Cluster.CertificateAuthority.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode CA of EKS cluster %s: %w", name, err)
+		}
+		clusters = append(clusters, Cluster{Name: name, Server: described.Cluster.Endpoint, CAData: caData})
+	}
+	return clusters, nil
+}
+
+func (p *eksProvider) Kubeconfig(cluster Cluster) ([]byte, error) {
+	return buildKubeconfig(cluster, &clientcmdapi.ExecConfig{
+		APIVersion: execCredentialAPIVersion,
+		Command:    "aws",
+		Args:       []string{"eks", "get-token", "--cluster-name", cluster.Name, "--region", p.region, "--output", "json"},
+	})
+}
+
+// gkeProvider discovers GKE clusters of one GCP project using the gcloud CLI
+type gkeProvider struct {
+	project string
+	run     commandRunner
+}
+
+func (p *gkeProvider) Source() string {
+	return "gke." + p.project
+}
+
+func (p *gkeProvider) ListClusters(ctx context.Context) ([]Cluster, error) {
+	out, err := p.run(ctx, "gcloud", "container", "clusters", "list", "--project", p.project, "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	var list []struct {
+		Name       string `json:"name"`
+		Endpoint   string `json:"endpoint"`
+		MasterAuth struct {
+			ClusterCACertificate string `json:"clusterCaCertificate"`
+		} `json:"masterAuth"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse GKE cluster list: %w", err)
+	}
+
+	clusters := make([]Cluster, 0, len(list))
+	for _, item := range list {
+		if item.Endpoint == "" {
+			continue
+		}
+		caData, err := base64.StdEncoding.DecodeString(item.MasterAuth.ClusterCACertificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode CA of GKE cluster %s: %w", item.Name, err)
+		}
+		clusters = append(clusters, Cluster{Name: item.Name, Server: "https://" + item.Endpoint, CAData: caData})
+	}
+	return clusters, nil
+}
+
+func (p *gkeProvider) Kubeconfig(cluster Cluster) ([]byte, error) {
+	return buildKubeconfig(cluster, &clientcmdapi.ExecConfig{
+		APIVersion:         execCredentialAPIVersion,
+		Command:            "gke-gcloud-auth-plugin",
+		ProvideClusterInfo: true,
+	})
+}
+
+// aksProvider discovers AKS clusters of one Azure subscription using the az CLI
+type aksProvider struct {
+	subscription string
+	run          commandRunner
+}
+
+func (p *aksProvider) Source() string {
+	return "aks." + p.subscription
+}
+
+func (p *aksProvider) ListClusters(ctx context.Context) ([]Cluster, error) {
+	out, err := p.run(ctx, "az", "aks", "list", "--subscription", p.subscription, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	var list []struct {
+		Name          string `json:"name"`
+		ResourceGroup string `json:"resourceGroup"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse AKS cluster list: %w", err)
+	}
+
+	clusters := make([]Cluster, 0, len(list))
+	for _, item := range list {
+		// The cluster list does not include the CA, read it from the user kubeconfig
+		out, err := p.run(ctx, "az", "aks", "get-credentials", "--subscription", p.subscription,
+			"--resource-group", item.ResourceGroup, "--name", item.Name, "--file", "-")
+		if err != nil {
+			return nil, err
+		}
+		kubeconfig, err := clientcmd.Load(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig of AKS cluster %s: %w", item.Name, err)
+		}
+		for _, kubeCluster := range kubeconfig.Clusters {
+			clusters = append(clusters, Cluster{Name: item.Name, Server: kubeCluster.Server, CAData: kubeCluster.CertificateAuthorityData})
+			break
+		}
+	}
+	return clusters, nil
+}
+
+func (p *aksProvider) Kubeconfig(cluster Cluster) ([]byte, error) {
+	return buildKubeconfig(cluster, &clientcmdapi.ExecConfig{
+		APIVersion: execCredentialAPIVersion,
+		Command:    "kubelogin",
+		Args:       []string{"get-token", "--login", "workloadidentity", "--server-id", aksServerID},
+	})
+}
+
+// buildKubeconfig returns a single-context kubeconfig authenticating with the given exec plugin
+func buildKubeconfig(cluster Cluster, execConfig *clientcmdapi.ExecConfig) ([]byte, error) {
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[cluster.Name] = &clientcmdapi.Cluster{
+		Server:                   cluster.Server,
+		CertificateAuthorityData: cluster.CAData,
+	}
+	kubeconfig.AuthInfos[cluster.Name] = &clientcmdapi.AuthInfo{
+		Exec: execConfig,
+	}
+	kubeconfig.Contexts[cluster.Name] = &clientcmdapi.Context{
+		Cluster:  cluster.Name,
+		AuthInfo: cluster.Name,
+	}
+	kubeconfig.CurrentContext = cluster.Name
+	return clientcmd.Write(*kubeconfig)
+}
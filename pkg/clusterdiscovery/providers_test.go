@@ -0,0 +1,86 @@
+package clusterdiscovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func fakeRunner(outputs map[string]string) commandRunner {
+	return func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		cmd := name + " " + strings.Join(args, " ")
+		out, ok := outputs[cmd]
+		if !ok {
+			return nil, fmt.Errorf("unexpected command %q", cmd)
+		}
+		return []byte(out), nil
+	}
+}
+
+func TestEKSProvider(t *testing.T) {
+	provider := &eksProvider{
+		region: "us-east-1",
+		run: fakeRunner(map[string]string{
+			"aws eks list-clusters --region us-east-1 --output json": `{"clusters": ["prod", "creating"]}`,
+			"aws eks describe-cluster --name prod --region us-east-1 --output json": `{"cluster": {"endpoint": "https://prod.eks.example.com",
+				"certificateAuthority": {"data": "Y2EtZGF0YQ=="}}}`,
+			"aws eks describe-cluster --name creating --region us-east-1 --output json": `{"cluster": {}}`,
+		}),
+	}
+
+	clusters, err := provider.ListClusters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 cluster, got %d", len(clusters))
+	}
+	if clusters[0].Name != "prod" || clusters[0].Server != "https://prod.eks.example.com" || string(clusters[0].CAData) != "ca-data" {
+		t.Errorf("unexpected cluster: %+v", clusters[0])
+	}
+
+	data, err := provider.Kubeconfig(clusters[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		t.Fatalf("failed to load generated kubeconfig: %v", err)
+	}
+	authInfo := kubeconfig.AuthInfos["prod"]
+	if authInfo == nil || authInfo.Exec == nil || authInfo.Exec.Command != "aws" {
+		t.Fatalf("expected aws exec plugin, got %+v", authInfo)
+	}
+	if kubeconfig.CurrentContext != "prod" {
+		t.Errorf("expected current context prod, got %q", kubeconfig.CurrentContext)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantSource string
+		wantErr    bool
+	}{
+		{spec: "eks:us-east-1", wantSource: "eks.us-east-1"},
+		{spec: "gke:my-project", wantSource: "gke.my-project"},
+		{spec: "aks:0000-1111", wantSource: "aks.0000-1111"},
+		{spec: "eks", wantErr: true},
+		{spec: "openstack:region", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			provider, err := NewProvider(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && provider.Source() != tt.wantSource {
+				t.Errorf("expected source %q, got %q", tt.wantSource, provider.Source())
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package clusterlink
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// discoveryGroupVersion is the API group version serving EndpointSlices
+	discoveryGroupVersion = "discovery.k8s.io/v1"
+	// authorizationGroupVersion is the API group version serving SelfSubjectAccessReviews
+	authorizationGroupVersion = "authorization.k8s.io/v1"
+)
+
+// Capabilities describes the version and API support of a remote cluster
+type Capabilities struct {
+	// Version is the GitVersion reported by the remote API server
+	Version string
+	// EndpointSliceV1 indicates whether discovery.k8s.io/v1 is served
+	EndpointSliceV1 bool
+	// SelfSubjectAccessReview indicates whether authorization.k8s.io/v1 is served
+	SelfSubjectAccessReview bool
+	// DiscoveredAt is when the capabilities were fetched from the remote cluster
+	DiscoveredAt time.Time
+}
+
+// DiscoverCapabilities queries the remote API server for its version and served API groups
+func DiscoverCapabilities(client discovery.DiscoveryInterface) (*Capabilities, error) {
+	versionInfo, err := client.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server groups: %w", err)
+	}
+
+	served := sets.New[string]()
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			served.Insert(version.GroupVersion)
+		}
+	}
+
+	return &Capabilities{
+		Version:                 versionInfo.GitVersion,
+		EndpointSliceV1:         served.Has(discoveryGroupVersion),
+		SelfSubjectAccessReview: served.Has(authorizationGroupVersion),
+		DiscoveredAt:            time.Now(),
+	}, nil
+}
+
+// capabilityCacheEntry is a cached Capabilities tied to the ClusterLink generation it was fetched for
+type capabilityCacheEntry struct {
+	generation   int64
+	capabilities *Capabilities
+}
+
+// capabilityCache caches remote cluster capabilities with a TTL so that
+// ServerVersion and discovery calls are not issued on every sync cycle
+type capabilityCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]capabilityCacheEntry
+}
+
+func newCapabilityCache(ttl time.Duration) *capabilityCache {
+	return &capabilityCache{
+		ttl:     ttl,
+		entries: make(map[string]capabilityCacheEntry),
+	}
+}
+
+// get returns the cached capabilities for a cluster if they are still fresh
+// and were fetched for the same ClusterLink generation
+func (cc *capabilityCache) get(clusterName string, generation int64) (*Capabilities, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	entry, ok := cc.entries[clusterName]
+	if !ok || entry.generation != generation {
+		return nil, false
+	}
+	if time.Since(entry.capabilities.DiscoveredAt) > cc.ttl {
+		return nil, false
+	}
+	return entry.capabilities, true
+}
+
+func (cc *capabilityCache) set(clusterName string, generation int64, capabilities *Capabilities) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.entries[clusterName] = capabilityCacheEntry{
+		generation:   generation,
+		capabilities: capabilities,
+	}
+}
+
+// prune drops entries for clusters that no longer have a ClusterLink
+func (cc *capabilityCache) prune(active sets.Set[string]) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for name := range cc.entries {
+		if !active.Has(name) {
+			delete(cc.entries, name)
+		}
+	}
+}
+
+// forget drops the state of a cluster whose ClusterLink was deleted
+func (cc *capabilityCache) forget(name string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delete(cc.entries, name)
+}
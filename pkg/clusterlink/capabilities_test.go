@@ -0,0 +1,65 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscoverCapabilities(t *testing.T) {
+	discovery := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.29.4"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: discoveryGroupVersion},
+	}
+
+	capabilities, err := DiscoverCapabilities(discovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.Version != "v1.29.4" || !capabilities.EndpointSliceV1 || capabilities.SelfSubjectAccessReview {
+		t.Errorf("DiscoverCapabilities() = %+v, want v1.29.4 with EndpointSlice v1 only", capabilities)
+	}
+	if capabilities.DiscoveredAt.IsZero() {
+		t.Error("expected the discovery time to be set")
+	}
+}
+
+func TestCapabilityCache(t *testing.T) {
+	cache := newCapabilityCache(time.Hour)
+	fresh := &Capabilities{Version: "v1.30.0", DiscoveredAt: time.Now()}
+	stale := &Capabilities{Version: "v1.29.0", DiscoveredAt: time.Now().Add(-2 * time.Hour)}
+	cache.set("east", 3, fresh)
+	cache.set("west", 1, stale)
+
+	if got, ok := cache.get("east", 3); !ok || got != fresh {
+		t.Errorf("get(east, 3) = %v, %v, want the cached capabilities", got, ok)
+	}
+	if _, ok := cache.get("east", 4); ok {
+		t.Error("expected a miss for a newer ClusterLink generation")
+	}
+	if _, ok := cache.get("west", 1); ok {
+		t.Error("expected a miss for capabilities older than the TTL")
+	}
+
+	longer := newCapabilityCache(3 * time.Hour)
+	longer.set("west", 1, stale)
+	if _, ok := longer.get("west", 1); !ok {
+		t.Error("expected a hit once the TTL covers the capabilities")
+	}
+
+	longer.prune(sets.New("east"))
+	if _, ok := longer.get("west", 1); ok {
+		t.Error("expected capabilities of a removed cluster to be pruned")
+	}
+	cache.forget("east")
+	if _, ok := cache.get("east", 3); ok {
+		t.Error("expected capabilities of a forgotten cluster to be dropped")
+	}
+}
@@ -0,0 +1,77 @@
+package clusterlink
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// certificateGVK is the cert-manager Certificate, read unstructured so that cert-manager is not a dependency
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// loadCertificateCredentials builds the rest.Config of a ClusterLink authenticating against APIServerURL with
+// the client certificate issued by the cert-manager Certificate of spec.auth.certificateRef. The Secret is read
+// every sync, so a renewed certificate changes the fingerprint and the client is rebuilt. Like the other
+// credentials, the Certificate and its Secret are read from the API server rather than a cache.
+func (l *Links) loadCertificateCredentials(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink) (*rest.Config, string, error) {
+	ref := clusterLink.Spec.Auth.CertificateRef
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = clusterLink.Namespace
+	}
+	if err := l.checkNamespace(clusterLink, "certificate", namespace, ref.Name); err != nil {
+		return nil, "", err
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	if err := l.apiReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, certificate); err != nil {
+		return nil, "", fmt.Errorf("failed to get certificate %s/%s: %w", namespace, ref.Name, err)
+	}
+	secretName, _, err := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	if err != nil || secretName == "" {
+		return nil, "", fmt.Errorf("certificate %s/%s has no spec.secretName", namespace, ref.Name)
+	}
+
+	secret := &corev1.Secret{}
+	if err := l.apiReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, secret); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return nil, "", fmt.Errorf("certificate %s/%s has not been issued yet, secret %s not found", namespace, ref.Name, secretName)
+		}
+		return nil, "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, secretName, err)
+	}
+
+	return certificateRESTConfig(clusterLink.Spec.APIServerURL, clusterLink.Spec.CABundle, secret)
+}
+
+// certificateRESTConfig builds the rest.Config authenticating with the client certificate of a cert-manager
+// Secret. The CA bundle verifies the API server, or the ca.crt of the Secret when the bundle is empty.
+func certificateRESTConfig(host string, caBundle []byte, secret *corev1.Secret) (*rest.Config, string, error) {
+	certData := secret.Data[corev1.TLSCertKey]
+	keyData := secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certData) == 0 || len(keyData) == 0 {
+		return nil, "", fmt.Errorf("secret %s/%s has no %s and %s", secret.Namespace, secret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	caData := caBundle
+	if len(caData) == 0 {
+		caData = secret.Data[corev1.ServiceAccountRootCAKey]
+	}
+
+	restConfig := &rest.Config{
+		Host: host,
+		TLSClientConfig: rest.TLSClientConfig{
+			CertData: certData,
+			KeyData:  keyData,
+			CAData:   caData,
+		},
+	}
+	return restConfig, fingerprint([]byte(host), caData, certData, keyData), nil
+}
@@ -0,0 +1,118 @@
+package clusterlink
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// certificatesClient serves cert-manager Certificates keyed by namespace/name along with the Secrets of
+// secretsClient
+type certificatesClient struct {
+	*secretsClient
+	certificates map[string]*unstructured.Unstructured
+}
+
+func (c *certificatesClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	certificate, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return c.secretsClient.Get(ctx, key, obj, opts...)
+	}
+	c.gets = append(c.gets, key.String())
+	issued, ok := c.certificates[key.String()]
+	if !ok {
+		return apierrors.NewNotFound(certificateGVK.GroupVersion().WithResource("certificates").GroupResource(), key.Name)
+	}
+	certificate.Object = issued.DeepCopy().Object
+	return nil
+}
+
+func TestLoadCertificateCredentials(t *testing.T) {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	if err := unstructured.SetNestedField(certificate.Object, "east-client-tls", "spec", "secretName"); err != nil {
+		t.Fatal(err)
+	}
+	reader := &certificatesClient{
+		secretsClient: &secretsClient{secrets: map[string]*corev1.Secret{
+			"cloudpilot/east-client-tls": {Data: map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")}},
+		}},
+		certificates: map[string]*unstructured.Unstructured{"cloudpilot/east-client": certificate},
+	}
+	clusterLink := &svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east"},
+		Spec: svclinkv1alpha1.ClusterLinkSpec{
+			APIServerURL: "https://east:6443",
+			Auth:         &svclinkv1alpha1.ClusterLinkAuth{CertificateRef: &svclinkv1alpha1.CertificateReference{Name: "east-client"}},
+		},
+	}
+	links := &Links{apiReader: reader}
+
+	restConfig, _, err := links.loadCertificateCredentials(context.Background(), clusterLink)
+	if err != nil {
+		t.Fatalf("loadCertificateCredentials() error = %v", err)
+	}
+	if string(restConfig.CertData) != "cert" || string(restConfig.KeyData) != "key" {
+		t.Errorf("unexpected TLS config %+v", restConfig.TLSClientConfig)
+	}
+	if len(reader.gets) != 2 {
+		t.Errorf("expected the Certificate and its Secret to be read, got %v", reader.gets)
+	}
+
+	// A Certificate of another namespace is refused without being read
+	clusterLink.Spec.Auth.CertificateRef.Namespace = "other"
+	if _, _, err := links.loadCertificateCredentials(context.Background(), clusterLink); err == nil || !strings.Contains(err.Error(), "not in the namespace") {
+		t.Errorf("expected a Certificate of another namespace to be refused, got %v", err)
+	}
+	if len(reader.gets) != 2 {
+		t.Errorf("expected the refused Certificate not to be read, got %v", reader.gets)
+	}
+	links.allowCrossNamespaceSecrets = true
+	if _, _, err := links.loadCertificateCredentials(context.Background(), clusterLink); err == nil || !apierrors.IsNotFound(errors.Unwrap(err)) {
+		t.Errorf("expected an error for a missing Certificate, got %v", err)
+	}
+}
+
+func TestCertificateRESTConfig(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east-client"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:              []byte("cert"),
+			corev1.TLSPrivateKeyKey:        []byte("key"),
+			corev1.ServiceAccountRootCAKey: []byte("issuer-ca"),
+		},
+	}
+
+	restConfig, hash, err := certificateRESTConfig("https://east:6443", nil, secret)
+	if err != nil {
+		t.Fatalf("certificateRESTConfig() error = %v", err)
+	}
+	if string(restConfig.CertData) != "cert" || string(restConfig.KeyData) != "key" || string(restConfig.CAData) != "issuer-ca" {
+		t.Errorf("unexpected TLS config %+v", restConfig.TLSClientConfig)
+	}
+
+	restConfig, _, err = certificateRESTConfig("https://east:6443", []byte("server-ca"), secret)
+	if err != nil || string(restConfig.CAData) != "server-ca" {
+		t.Errorf("expected the CA bundle to take precedence, got %q (err %v)", restConfig.CAData, err)
+	}
+
+	// A renewed certificate rebuilds the client
+	secret.Data[corev1.TLSCertKey] = []byte("renewed")
+	if _, renewed, _ := certificateRESTConfig("https://east:6443", nil, secret); renewed == hash {
+		t.Error("expected the fingerprint to change on renewal")
+	}
+
+	delete(secret.Data, corev1.TLSPrivateKeyKey)
+	if _, _, err := certificateRESTConfig("https://east:6443", nil, secret); err == nil {
+		t.Error("expected an error for a secret without a key")
+	}
+}
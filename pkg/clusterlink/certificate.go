@@ -0,0 +1,74 @@
+package clusterlink
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// clientCertificateExpiry returns the expiry of the client certificate used by the rest.Config,
+// or nil if the config does not authenticate with a client certificate
+func clientCertificateExpiry(restConfig *rest.Config) (*metav1.Time, error) {
+	certData := restConfig.CertData
+	if len(certData) == 0 && restConfig.CertFile != "" {
+		var err error
+		certData, err = os.ReadFile(restConfig.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+	}
+	if len(certData) == 0 {
+		return nil, nil
+	}
+
+	// The first certificate in the chain is the client (leaf) certificate
+	block, _ := pem.Decode(certData)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode client certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+
+	expiry := metav1.NewTime(cert.NotAfter)
+	return &expiry, nil
+}
+
+// credentialsExpiringCondition returns the CredentialsExpiring condition for the given client
+// certificate expiry, or nil if the ClusterLink does not use a client certificate
+func (l *Links) credentialsExpiringCondition(certificateExpiry *metav1.Time, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	if certificateExpiry == nil {
+		return nil
+	}
+
+	remaining := certificateExpiry.Sub(now.Time)
+
+	condition := &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkCredentialsExpiring,
+		LastTransitionTime: now,
+	}
+	switch {
+	case remaining <= 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CertificateExpired"
+		condition.Message = fmt.Sprintf("Client certificate expired at %s", certificateExpiry.UTC().Format(time.RFC3339))
+	case remaining <= l.credentialsExpiryWindow:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CertificateExpiring"
+		condition.Message = fmt.Sprintf("Client certificate expires at %s", certificateExpiry.UTC().Format(time.RFC3339))
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "CertificateValid"
+		condition.Message = fmt.Sprintf("Client certificate is valid until %s", certificateExpiry.UTC().Format(time.RFC3339))
+	}
+	return condition
+}
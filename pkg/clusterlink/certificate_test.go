@@ -0,0 +1,93 @@
+package clusterlink
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// selfSignedCertificate returns a PEM encoded certificate expiring at notAfter
+func selfSignedCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "svclink"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestClientCertificateExpiry(t *testing.T) {
+	notAfter := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cert := selfSignedCertificate(t, notAfter)
+	certFile := filepath.Join(t.TempDir(), "client.crt")
+	if err := os.WriteFile(certFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, restConfig := range map[string]*rest.Config{
+		"certificate data": {TLSClientConfig: rest.TLSClientConfig{CertData: cert}},
+		"certificate file": {TLSClientConfig: rest.TLSClientConfig{CertFile: certFile}},
+	} {
+		expiry, err := clientCertificateExpiry(restConfig)
+		if err != nil || expiry == nil || !expiry.Time.Equal(notAfter) {
+			t.Errorf("%s: clientCertificateExpiry() = %v (err %v), want %v", name, expiry, err, notAfter)
+		}
+	}
+
+	if expiry, err := clientCertificateExpiry(&rest.Config{BearerToken: "token"}); expiry != nil || err != nil {
+		t.Errorf("expected no expiry for token auth, got %v (err %v)", expiry, err)
+	}
+	if _, err := clientCertificateExpiry(&rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: []byte("cert")}}); err == nil {
+		t.Error("expected an error for a malformed certificate")
+	}
+}
+
+func TestCredentialsExpiringCondition(t *testing.T) {
+	links := &Links{credentialsExpiryWindow: 7 * 24 * time.Hour}
+
+	now := metav1.NewTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name       string
+		expiry     time.Duration
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{name: "valid", expiry: 30 * 24 * time.Hour, wantStatus: metav1.ConditionFalse, wantReason: "CertificateValid"},
+		{name: "within the window", expiry: 3 * 24 * time.Hour, wantStatus: metav1.ConditionTrue, wantReason: "CertificateExpiring"},
+		{name: "expired", expiry: -time.Hour, wantStatus: metav1.ConditionTrue, wantReason: "CertificateExpired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiry := metav1.NewTime(now.Add(tt.expiry))
+			condition := links.credentialsExpiringCondition(&expiry, now)
+			if condition == nil || condition.Status != tt.wantStatus || condition.Reason != tt.wantReason {
+				t.Errorf("credentialsExpiringCondition() = %+v, want %s %s", condition, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+
+	if condition := links.credentialsExpiringCondition(nil, now); condition != nil {
+		t.Errorf("expected no condition without a client certificate, got %+v", condition)
+	}
+}
@@ -0,0 +1,132 @@
+package clusterlink
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// circuitBreaker is the failure state of a cluster under a ClusterLink generation
+type circuitBreaker struct {
+	generation int64
+	failures   int
+	lastError  string
+	// nextProbe is when an open breaker lets the cluster be synced again
+	nextProbe time.Time
+}
+
+// circuitBreakers track the consecutive connection and discovery failures of every cluster. After threshold
+// failures in a row the breaker of the cluster opens: the cluster is left out of the sync cycles and only
+// probed every probeInterval, until a successful discovery or a spec change closes the breaker again.
+type circuitBreakers struct {
+	mu            sync.Mutex
+	threshold     int
+	probeInterval time.Duration
+	clusters      map[string]*circuitBreaker
+}
+
+// newCircuitBreakers creates the circuit breakers degrading a cluster after threshold consecutive failures and
+// probing it every probeInterval. A threshold of 0 disables the circuit breaker.
+func newCircuitBreakers(threshold int, probeInterval time.Duration) *circuitBreakers {
+	return &circuitBreakers{
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		clusters:      make(map[string]*circuitBreaker),
+	}
+}
+
+// allow reports whether a cluster is synced at now, which is the case unless its breaker is open and not
+// due for a probe. A ClusterLink whose spec changed since its last failure is always synced.
+func (cb *circuitBreakers) allow(name string, generation int64, now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, ok := cb.clusters[name]
+	if !ok || breaker.generation != generation || !cb.isOpen(breaker) {
+		return true
+	}
+	return !now.Before(breaker.nextProbe)
+}
+
+// recordFailure counts a failure of a cluster, opening its breaker or scheduling its next probe once the
+// threshold is reached
+func (cb *circuitBreakers) recordFailure(name string, generation int64, message string, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, ok := cb.clusters[name]
+	if !ok || breaker.generation != generation {
+		breaker = &circuitBreaker{generation: generation}
+		cb.clusters[name] = breaker
+	}
+	breaker.failures++
+	breaker.lastError = message
+	if cb.isOpen(breaker) {
+		breaker.nextProbe = now.Add(cb.probeInterval)
+	}
+}
+
+// recordSuccess closes the breaker of a cluster
+func (cb *circuitBreakers) recordSuccess(name string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.clusters, name)
+}
+
+// open returns the state of the breaker of a cluster if it is open
+func (cb *circuitBreakers) open(name string) (circuitBreaker, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	breaker, ok := cb.clusters[name]
+	if !ok || !cb.isOpen(breaker) {
+		return circuitBreaker{}, false
+	}
+	return *breaker, true
+}
+
+func (cb *circuitBreakers) isOpen(breaker *circuitBreaker) bool {
+	return cb.threshold > 0 && breaker.failures >= cb.threshold
+}
+
+// prune drops the breakers of clusters that no longer have a ClusterLink
+func (cb *circuitBreakers) prune(active sets.Set[string]) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	for name := range cb.clusters {
+		if !active.Has(name) {
+			delete(cb.clusters, name)
+		}
+	}
+}
+
+// forget drops the state of a cluster whose ClusterLink was deleted
+func (cb *circuitBreakers) forget(name string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.clusters, name)
+}
+
+// degradedCondition returns the Degraded condition of a ClusterLink whose breaker is open
+func (l *Links) degradedCondition(name string, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	breaker, ok := l.breakers.open(name)
+	if !ok {
+		return nil
+	}
+	return &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkDegraded,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "RepeatedFailures",
+		Message: fmt.Sprintf("%d consecutive failures, the cluster is only probed until it recovers, next probe at %s: %s",
+			breaker.failures, breaker.nextProbe.UTC().Format(time.RFC3339), breaker.lastError),
+	}
+}
@@ -0,0 +1,63 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	cb := &circuitBreakers{threshold: 3, probeInterval: 5 * time.Minute, clusters: make(map[string]*circuitBreaker)}
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		cb.recordFailure("east", 1, "timeout", now)
+	}
+	if _, ok := cb.open("east"); ok {
+		t.Fatal("expected the breaker to stay closed below the threshold")
+	}
+	if !cb.allow("east", 1, now) {
+		t.Fatal("expected a cluster below the threshold to be synced")
+	}
+
+	cb.recordFailure("east", 1, "timeout", now)
+	breaker, ok := cb.open("east")
+	if !ok || breaker.failures != 3 || !breaker.nextProbe.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("expected the breaker to open with the next probe in 5m, got %+v", breaker)
+	}
+	if cb.allow("east", 1, now.Add(time.Minute)) {
+		t.Error("expected a degraded cluster not to be synced before its next probe")
+	}
+	if !cb.allow("east", 1, now.Add(5*time.Minute)) {
+		t.Error("expected a degraded cluster to be probed once due")
+	}
+	if !cb.allow("east", 2, now.Add(time.Minute)) {
+		t.Error("expected a degraded cluster to be synced right away after a spec change")
+	}
+
+	// A failed probe schedules the next one
+	cb.recordFailure("east", 1, "timeout", now.Add(5*time.Minute))
+	if cb.allow("east", 1, now.Add(6*time.Minute)) {
+		t.Error("expected a failed probe to schedule the next probe")
+	}
+
+	cb.recordSuccess("east")
+	if _, ok := cb.open("east"); ok || !cb.allow("east", 1, now) {
+		t.Error("expected a successful sync to close the breaker")
+	}
+
+	cb.recordFailure("west", 1, "timeout", now)
+	cb.prune(sets.New("east"))
+	if _, ok := cb.clusters["west"]; ok {
+		t.Error("expected the breaker of a removed ClusterLink to be pruned")
+	}
+
+	cb.threshold = 0
+	for i := 0; i < 10; i++ {
+		cb.recordFailure("east", 1, "timeout", now)
+	}
+	if !cb.allow("east", 1, now) {
+		t.Error("expected a disabled circuit breaker to always sync")
+	}
+}
@@ -0,0 +1,69 @@
+package clusterlink
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clientCacheEntry is a remote client tied to the credentials it was built from
+type clientCacheEntry struct {
+	credentialsHash string
+	client          kubernetes.Interface
+}
+
+// clientCache keeps remote clients alive across sync cycles so that transports and
+// credentials issued by exec plugins (e.g. `aws eks get-token`) are reused until they expire
+type clientCache struct {
+	mu      sync.Mutex
+	entries map[string]clientCacheEntry
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{
+		entries: make(map[string]clientCacheEntry),
+	}
+}
+
+// get returns the cached client for a cluster if it was built from the same credentials
+func (cc *clientCache) get(clusterName, credentialsHash string) (kubernetes.Interface, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	entry, ok := cc.entries[clusterName]
+	if !ok || entry.credentialsHash != credentialsHash {
+		return nil, false
+	}
+	return entry.client, true
+}
+
+func (cc *clientCache) set(clusterName, credentialsHash string, client kubernetes.Interface) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.entries[clusterName] = clientCacheEntry{
+		credentialsHash: credentialsHash,
+		client:          client,
+	}
+}
+
+// prune drops clients for clusters that no longer have a ClusterLink
+func (cc *clientCache) prune(active sets.Set[string]) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for name := range cc.entries {
+		if !active.Has(name) {
+			delete(cc.entries, name)
+		}
+	}
+}
+
+// forget drops the state of a cluster whose ClusterLink was deleted
+func (cc *clientCache) forget(name string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delete(cc.entries, name)
+}
@@ -0,0 +1,40 @@
+package clusterlink
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestClientCache(t *testing.T) {
+	cc := newClientCache()
+	east := kubernetes.NewForConfigOrDie(&rest.Config{Host: "https://east.example.com"})
+	west := kubernetes.NewForConfigOrDie(&rest.Config{Host: "https://west.example.com"})
+
+	if _, ok := cc.get("east", "creds-1"); ok {
+		t.Fatal("expected an empty cache")
+	}
+	cc.set("east", "creds-1", east)
+	cc.set("west", "creds-1", west)
+	if client, ok := cc.get("east", "creds-1"); !ok || client != east {
+		t.Error("expected the client of east to be reused with the same credentials")
+	}
+	if _, ok := cc.get("east", "creds-2"); ok {
+		t.Error("expected rotated credentials to need a new client")
+	}
+
+	cc.prune(sets.New("west"))
+	if _, ok := cc.get("east", "creds-1"); ok {
+		t.Error("expected the client of a removed cluster to be pruned")
+	}
+	if _, ok := cc.get("west", "creds-1"); !ok {
+		t.Error("expected the client of an active cluster to be kept")
+	}
+
+	cc.forget("west")
+	if _, ok := cc.get("west", "creds-1"); ok {
+		t.Error("expected the client of a deleted cluster to be forgotten")
+	}
+}
@@ -1,56 +1,240 @@
 // Package clusterlink manages ClusterLink CRDs and builds remote cluster clients.
-// It handles listing ClusterLink resources, decoding embedded kubeconfigs,
-// creating Kubernetes clients for remote clusters, and updating connection status.
+// It handles listing ClusterLink resources, loading embedded kubeconfigs or ServiceAccount
+// token credentials, creating Kubernetes clients for remote clusters, and updating connection status.
 package clusterlink
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
 )
 
-func ListClusterInfo(ctx context.Context, kubeClient client.Client) (map[string]*ClusterInfo, error) {
-	var cks svclinkv1alpha1.ClusterLinkList
-	if err := kubeClient.List(ctx, &cks); err != nil {
-		return nil, err
+// InactiveClusters holds the linked clusters that are not synced
+type InactiveClusters struct {
+	// Paused holds the clusters paused with spec.paused
+	Paused sets.Set[string]
+	// Disabled holds the clusters disabled with spec.enabled
+	Disabled sets.Set[string]
+	// Unowned holds the clusters of other shards, whose EndpointSlices are left to the replicas syncing them
+	Unowned sets.Set[string]
+	// Unreachable holds the enabled clusters that could not be connected to, with the time since which they
+	// are unreachable
+	Unreachable map[string]time.Time
+	// VersionSkews holds the connected clusters whose VersionSkew condition was raised in this cycle. Skewed
+	// clusters are too old to be synced and are not retained.
+	VersionSkews []VersionSkew
+}
+
+func newInactiveClusters() *InactiveClusters {
+	return &InactiveClusters{
+		Paused:      sets.New[string](),
+		Disabled:    sets.New[string](),
+		Unowned:     sets.New[string](),
+		Unreachable: make(map[string]time.Time),
 	}
+}
 
-	clusterInfos := make(map[string]*ClusterInfo, len(cks.Items))
-	for _, clusterLink := range cks.Items {
-		clusterInfo := &ClusterInfo{
-			Name:        clusterLink.Name,
-			Enabled:     clusterLink.Spec.Enabled,
-			ClusterLink: clusterLink,
-		}
+// markUnreachable records that an enabled cluster could not be connected to
+func (l *Links) markUnreachable(inactive *InactiveClusters, name string) {
+	inactive.Unreachable[name] = l.unreachable.mark(name, time.Now())
+}
 
-		kubeconfigData, err := base64.StdEncoding.DecodeString(clusterLink.Spec.Kubeconfig)
-		if err != nil {
-			klog.Errorf("Failed to decode kubeconfig for cluster %s: %v", clusterLink.Name, err)
-			updateClusterStatus(ctx, kubeClient, &clusterLink, false, "", fmt.Sprintf("Failed to decode kubeconfig: %v", err))
+// ListClusterInfo connects to every enabled and unpaused linked cluster and records the connection state in the
+// ClusterLink status. The other clusters are not connected to and are returned separately.
+func (l *Links) ListClusterInfo(ctx context.Context, kubeClient client.Client) (map[string]*ClusterInfo, *InactiveClusters, error) {
+	return l.listClusterInfo(ctx, kubeClient, true)
+}
+
+// WarmClusterClients builds and caches the clients and capabilities of every linked cluster without
+// writing anything, so that a standby replica can take over without connecting to every cluster first
+func (l *Links) WarmClusterClients(ctx context.Context, kubeClient client.Client) (map[string]*ClusterInfo, error) {
+	clusterInfos, _, err := l.listClusterInfo(ctx, kubeClient, false)
+	return clusterInfos, err
+}
+
+func (l *Links) listClusterInfo(ctx context.Context, kubeClient client.Client, updateStatus bool) (map[string]*ClusterInfo, *InactiveClusters, error) {
+	clusterLinks, err := l.listClusterLinks(ctx, kubeClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clusterInfos := make(map[string]*ClusterInfo, len(clusterLinks))
+	inactive := newInactiveClusters()
+	activeClusters := sets.New[string]()
+	for i := range clusterLinks {
+		// The ClusterLinks of other shards are neither connected to nor written
+		if !l.shard.owns(&clusterLinks[i]) {
+			inactive.Unowned.Insert(clusterLinks[i].Name)
 			continue
 		}
+		activeClusters.Insert(clusterLinks[i].Name)
+		if clusterInfo := l.connectClusterLink(ctx, kubeClient, &clusterLinks[i], inactive, updateStatus); clusterInfo != nil {
+			clusterInfos[clusterInfo.Name] = clusterInfo
+		}
+	}
+
+	l.prune(activeClusters)
+	return clusterInfos, inactive, nil
+}
+
+// connectClusterLink connects to a linked cluster and records the connection state in its status when
+// updateStatus is set. It returns nil when the cluster is not synced, adding inactive clusters to inactive.
+func (l *Links) connectClusterLink(ctx context.Context, kubeClient client.Client, linked *svclinkv1alpha1.ClusterLink,
+	inactive *InactiveClusters, updateStatus bool) *ClusterInfo {
+	// The status is patched against the ClusterLink as listed
+	listed := linked.DeepCopy()
+	if !listed.Spec.Enabled {
+		klog.V(4).Infof("Cluster %s is disabled", listed.Name)
+		inactive.Disabled.Insert(listed.Name)
+		if updateStatus {
+			updateInactiveStatus(ctx, kubeClient, listed, svclinkv1alpha1.ClusterLinkDisabled, "Disabled",
+				"Sync is disabled with spec.enabled")
+		}
+		return nil
+	}
+	if listed.Spec.Paused {
+		klog.V(4).Infof("Sync of cluster %s is paused", listed.Name)
+		inactive.Paused.Insert(listed.Name)
+		if updateStatus {
+			updateInactiveStatus(ctx, kubeClient, listed, svclinkv1alpha1.ClusterLinkPaused, "Paused",
+				"Sync is paused with spec.paused, imported EndpointSlices are left as they are")
+		}
+		return nil
+	}
+
+	clusterInfo := &ClusterInfo{
+		Name:        listed.Name,
+		Enabled:     listed.Spec.Enabled,
+		ClusterLink: *listed.DeepCopy(),
+	}
+	clusterLink := &clusterInfo.ClusterLink
+
+	until, disconnected, err := config.SimulatedDisconnect(clusterLink, time.Now())
+	if err != nil {
+		klog.Errorf("Ignoring the simulated disconnect of cluster %s: %v", clusterLink.Name, err)
+	}
+	if disconnected {
+		klog.Warningf("Treating cluster %s as unreachable, simulated disconnect until %s", clusterLink.Name, until.Format(time.RFC3339))
+		l.markUnreachable(inactive, clusterLink.Name)
+		if updateStatus {
+			l.updateClusterStatus(ctx, kubeClient, listed, clusterLink, false, "",
+				fmt.Sprintf("Simulated disconnect until %s", until.Format(time.RFC3339)))
+		}
+		return nil
+	}
+
+	if hazard, ok := l.hazards.get(clusterLink.Name); ok {
+		klog.V(4).Infof("Not syncing cluster %s, excluded by the startup preflight: %s", clusterLink.Name, hazard)
+		if updateStatus {
+			l.updateClusterStatus(ctx, kubeClient, listed, clusterLink, false, "", fmt.Sprintf("Excluded by the startup preflight: %s", hazard))
+		}
+		return nil
+	}
+
+	if !l.breakers.allow(clusterLink.Name, clusterLink.Generation, time.Now()) {
+		klog.V(4).Infof("Not syncing cluster %s, degraded after repeated failures until its next probe", clusterLink.Name)
+		l.markUnreachable(inactive, clusterLink.Name)
+		return nil
+	}
+
+	restConfig, credentialsHash, err := l.loadRESTConfig(ctx, clusterLink)
+	if err != nil {
+		klog.Errorf("Failed to load credentials for cluster %s: %v", clusterLink.Name, err)
+		l.markUnreachable(inactive, clusterLink.Name)
+		if updateStatus {
+			errorMsg := fmt.Sprintf("Failed to load credentials: %v", err)
+			l.breakers.recordFailure(clusterLink.Name, clusterLink.Generation, errorMsg, time.Now())
+			l.updateClusterStatus(ctx, kubeClient, listed, clusterLink, false, "", errorMsg)
+		}
+		return nil
+	}
+
+	certificateExpiry, err := clientCertificateExpiry(restConfig)
+	if err != nil {
+		klog.Warningf("Failed to determine client certificate expiry for cluster %s: %v", clusterLink.Name, err)
+	}
+	clusterLink.Status.CertificateExpiry = certificateExpiry
+
+	client, capabilities, err := l.buildClientWithVersion(clusterLink, restConfig, credentialsHash)
+	if err != nil {
+		klog.Errorf("Failed to build client for cluster %s: %v", clusterLink.Name, err)
+		l.markUnreachable(inactive, clusterLink.Name)
+		if updateStatus {
+			errorMsg := fmt.Sprintf("Failed to build client: %v", err)
+			l.breakers.recordFailure(clusterLink.Name, clusterLink.Generation, errorMsg, time.Now())
+			l.updateClusterStatus(ctx, kubeClient, listed, clusterLink, false, "", errorMsg)
+		}
+		return nil
+	}
+
+	clusterInfo.Client = client
+	clusterInfo.Capabilities = capabilities
+	l.unreachable.clear(clusterLink.Name)
+
+	// Clusters too old for discovery.k8s.io/v1 would only fail later with opaque list errors
+	message := versionSkew(capabilities)
+	if updateStatus && l.versionSkews.set(clusterLink.Name, message) && message != "" {
+		klog.Warningf("ClusterLink %s: %s", clusterLink.Name, message)
+		inactive.VersionSkews = append(inactive.VersionSkews, VersionSkew{ClusterLink: clusterLink, Message: message})
+	}
+	if message != "" {
+		if updateStatus {
+			l.updateClusterStatus(ctx, kubeClient, listed, clusterLink, true, capabilities.Version, "")
+		}
+		return nil
+	}
 
-		client, version, err := buildClientWithVersion(kubeconfigData)
+	if updateStatus {
+		l.slices.ensure(clusterLink.Name, client)
+	}
+
+	if updateStatus && l.permissions.due(clusterLink.Name, credentialsHash, clusterLink.Generation) {
+		l.reviewPermissions(ctx, clusterLink, client, credentialsHash)
+	}
+
+	reported := clusterLink.Status.Latency
+	clusterLink.Status.Latency = nil
+	if clusterLink.Spec.LatencyProbe {
+		latency, err := probeLatency(client)
 		if err != nil {
-			klog.Errorf("Failed to build client for cluster %s: %v", clusterLink.Name, err)
-			updateClusterStatus(ctx, kubeClient, &clusterLink, false, "", fmt.Sprintf("Failed to build client: %v", err))
-			continue
+			klog.V(4).Infof("Failed to probe latency of cluster %s: %v", clusterLink.Name, err)
+		} else {
+			clusterInfo.Latency = latency
+			clusterLink.Status.Latency = reportedLatency(reported, latency)
 		}
+	}
 
-		clusterInfo.Client = client
-		clusterInfos[clusterLink.Name] = clusterInfo
-		updateClusterStatus(ctx, kubeClient, &clusterInfo.ClusterLink, true, version, "")
+	if updateStatus {
+		l.updateClusterStatus(ctx, kubeClient, listed, clusterLink, true, capabilities.Version, "")
 	}
-	return clusterInfos, nil
+	return clusterInfo
+}
+
+// ClusterCapabilities builds a client for the ClusterLink and discovers the remote
+// cluster's capabilities directly, bypassing the cache
+func (l *Links) ClusterCapabilities(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink) (*Capabilities, error) {
+	restConfig, _, err := l.loadRESTConfig(ctx, clusterLink)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := l.newClient(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiscoverCapabilities(client.Discovery())
 }
 
 // ClusterInfo holds information about a remote cluster
@@ -59,47 +243,89 @@ type ClusterInfo struct {
 	Enabled     bool
 	Client      kubernetes.Interface
 	ClusterLink svclinkv1alpha1.ClusterLink
+	// Capabilities is the (possibly cached) version and API support of the remote cluster
+	Capabilities *Capabilities
+	// Latency is the round-trip time to the remote API server measured in this sync, 0 when not probed
+	Latency time.Duration
 }
 
-// buildClientWithVersion creates a Kubernetes client from kubeconfig data and fetches the cluster version
-func buildClientWithVersion(kubeconfigData []byte) (kubernetes.Interface, string, error) {
-	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfigData)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+// buildClientWithVersion creates a Kubernetes client from the remote rest.Config and fetches the cluster
+// capabilities, reusing the cached client while the credentials are unchanged and cached capabilities
+// while they are fresh
+func (l *Links) buildClientWithVersion(clusterLink *svclinkv1alpha1.ClusterLink, restConfig *rest.Config, credentialsHash string) (kubernetes.Interface, *Capabilities, error) {
+	client, ok := l.clients.get(clusterLink.Name, credentialsHash)
+	if !ok {
+		var err error
+		client, err = l.newClient(restConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		l.clients.set(clusterLink.Name, credentialsHash, client)
 	}
 
-	client, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create client: %w", err)
+	if capabilities, ok := l.capabilities.get(clusterLink.Name, clusterLink.Generation); ok {
+		return client, capabilities, nil
 	}
 
-	// Try to get the cluster version
-	version := ""
-	versionInfo, err := client.Discovery().ServerVersion()
+	capabilities, err := DiscoverCapabilities(client.Discovery())
 	if err != nil {
-		klog.V(4).Infof("Failed to get cluster version: %v", err)
-	} else {
-		version = versionInfo.GitVersion
+		return nil, nil, fmt.Errorf("failed to discover capabilities: %w", err)
 	}
+	l.capabilities.set(clusterLink.Name, clusterLink.Generation, capabilities)
 
-	return client, version, nil
+	return client, capabilities, nil
 }
 
-func updateClusterStatus(ctx context.Context, kubeClient client.Client, cluster *svclinkv1alpha1.ClusterLink, connected bool, version, errorMsg string) {
+// newClient creates a Kubernetes client from a remote rest.Config
+func (l *Links) newClient(restConfig *rest.Config) (kubernetes.Interface, error) {
+	if err := l.execPlugins.configureExecProvider(restConfig); err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return client, nil
+}
+
+// updateClusterStatus patches the connection status of a ClusterLink, computing the patch against original.
+// Nothing is written when the status is unchanged, which is the case for most sync cycles of a healthy cluster.
+func (l *Links) updateClusterStatus(ctx context.Context, kubeClient client.Client, original, cluster *svclinkv1alpha1.ClusterLink, connected bool, version, errorMsg string) {
 	cluster.Status.Connected = connected
 	cluster.Status.Version = version
 	cluster.Status.Error = errorMsg
 
 	if connected {
-		now := metav1.NewTime(time.Now())
-		cluster.Status.LastConnected = &now
+		// LastConnected is refreshed on reconnects and at the heartbeat interval, not on every sync cycle
+		if now := time.Now(); !original.Status.Connected || l.heartbeatDue(original.Status.LastConnected, now) {
+			lastConnected := metav1.NewTime(now)
+			cluster.Status.LastConnected = &lastConnected
+		}
+		// Clusters connected for the first time are onboarded in batches of namespaces
+		if original.Status.LastConnected == nil && cluster.Status.Onboarding == nil {
+			cluster.Status.Onboarding = &svclinkv1alpha1.OnboardingStatus{}
+		}
 	}
 
-	// Update conditions
-	cluster.Status.Conditions = buildConditions(connected, errorMsg)
+	cluster.Status.ObservedGeneration = cluster.Generation
+	l.setConditions(cluster, connected, errorMsg)
+	cluster.Status.History = l.recordEpisode(cluster.Status.History, svclinkv1alpha1.StatusEpisodeDisconnected,
+		!connected, errorMsg, metav1.NewTime(time.Now()))
+	// Errors are listed per phase, so that a discovery error does not hide the connection error preceding it
+	if errorMsg != "" {
+		phase := svclinkv1alpha1.SyncPhaseDiscover
+		if !connected {
+			phase = svclinkv1alpha1.SyncPhaseConnect
+		}
+		cluster.Status.Errors = l.recordError(cluster.Status.Errors, SyncError{Phase: phase, Message: errorMsg}, metav1.NewTime(time.Now()))
+	}
+	if equality.Semantic.DeepEqual(original.Status, cluster.Status) {
+		return
+	}
 
-	// Apply status update using controller-runtime client
-	if err := kubeClient.Status().Update(ctx, cluster); err != nil {
+	// Patch rather than update the status, so that fields unknown to this version are preserved
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
 		// Ignore not found errors - the resource may have been deleted
 		if client.IgnoreNotFound(err) != nil {
 			klog.Errorf("Failed to update status for ClusterLink %s: %v", cluster.Name, err)
@@ -110,46 +336,196 @@ func updateClusterStatus(ctx context.Context, kubeClient client.Client, cluster
 	klog.V(4).Infof("Updated status for ClusterLink %s (connected=%v)", cluster.Name, connected)
 }
 
-func buildConditions(connected bool, errorMsg string) []svclinkv1alpha1.ClusterLinkCondition {
+// setConditions updates the conditions of a synced ClusterLink, keeping the LastTransitionTime of the conditions
+// whose status is unchanged
+func (l *Links) setConditions(cluster *svclinkv1alpha1.ClusterLink, connected bool, errorMsg string) {
 	now := metav1.NewTime(time.Now())
-	var conditions []svclinkv1alpha1.ClusterLinkCondition
+	conditions := &cluster.Status.Conditions
+	generation := cluster.Generation
 
 	if connected {
-		conditions = append(conditions, svclinkv1alpha1.ClusterLinkCondition{
+		setCondition(conditions, svclinkv1alpha1.ClusterLinkCondition{
 			Type:               svclinkv1alpha1.ClusterLinkReady,
 			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
 			LastTransitionTime: now,
 			Reason:             "Connected",
 			Message:            "Successfully connected to remote cluster",
 		})
 	} else {
-		conditions = append(conditions, svclinkv1alpha1.ClusterLinkCondition{
+		setCondition(conditions, svclinkv1alpha1.ClusterLinkCondition{
 			Type:               svclinkv1alpha1.ClusterLinkReady,
 			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
 			LastTransitionTime: now,
 			Reason:             "ConnectionFailed",
 			Message:            "Failed to connect to remote cluster",
 		})
+	}
 
-		if errorMsg != "" {
-			conditions = append(conditions, svclinkv1alpha1.ClusterLinkCondition{
-				Type:               svclinkv1alpha1.ClusterLinkError,
-				Status:             metav1.ConditionTrue,
-				LastTransitionTime: now,
-				Reason:             "Error",
-				Message:            errorMsg,
-			})
-		}
+	if !connected && errorMsg != "" {
+		setCondition(conditions, svclinkv1alpha1.ClusterLinkCondition{
+			Type:               svclinkv1alpha1.ClusterLinkError,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			LastTransitionTime: now,
+			Reason:             "Error",
+			Message:            errorMsg,
+		})
+	} else {
+		removeCondition(conditions, svclinkv1alpha1.ClusterLinkError)
 	}
 
-	return conditions
+	// The cluster is synced again
+	removeCondition(conditions, svclinkv1alpha1.ClusterLinkPaused)
+	removeCondition(conditions, svclinkv1alpha1.ClusterLinkDisabled)
+
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkCredentialsExpiring,
+		l.credentialsExpiringCondition(cluster.Status.CertificateExpiry, now), generation)
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkNewerSchemaDetected,
+		l.newerSchemaCondition(cluster.Name, now), generation)
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkConfigurationHazard,
+		l.configurationHazardCondition(cluster.Name, now), generation)
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkQuotaExceeded,
+		l.quotaExceededCondition(cluster.Name, now), generation)
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkPermissionsInsufficient,
+		l.permissionsInsufficientCondition(cluster.Name, now), generation)
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkDegraded,
+		l.degradedCondition(cluster.Name, now), generation)
+	setOptionalCondition(conditions, svclinkv1alpha1.ClusterLinkVersionSkew,
+		l.versionSkewCondition(cluster.Name, now), generation)
 }
 
-func UpdateClusterSyncError(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, clusterName string, syncError error) {
+// updateInactiveStatus adds the condition of a paused or disabled ClusterLink and leaves the rest of its status
+// as it was when the cluster became inactive. The condition is dropped again by the next status update once
+// the cluster is synced again.
+func updateInactiveStatus(ctx context.Context, kubeClient client.Client, listed *svclinkv1alpha1.ClusterLink,
+	conditionType svclinkv1alpha1.ClusterLinkConditionType, reason, message string) {
+	if findCondition(listed.Status.Conditions, conditionType) != nil {
+		return
+	}
+
+	cluster := listed.DeepCopy()
+	cluster.Status.ObservedGeneration = cluster.Generation
+	setCondition(&cluster.Status.Conditions, svclinkv1alpha1.ClusterLinkCondition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cluster.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(listed)); err != nil {
+		klog.Errorf("Failed to update status for inactive cluster %s: %v", cluster.Name, err)
+	}
+}
+
+func (l *Links) UpdateClusterSyncError(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, clusterName string, syncError error) {
 	var errorMsg string
 	if syncError != nil {
 		errorMsg = fmt.Sprintf("Service sync error: %v", syncError)
 	}
-	// Always update status - either with error or clear it (empty string)
-	updateClusterStatus(ctx, kubeClient, &clusterInfo.ClusterLink, true, clusterInfo.ClusterLink.Status.Version, errorMsg)
+	if syncError != nil {
+		l.breakers.recordFailure(clusterName, clusterInfo.ClusterLink.Generation, errorMsg, time.Now())
+	} else {
+		l.breakers.recordSuccess(clusterName)
+	}
+
+	original := clusterInfo.ClusterLink.DeepCopy()
+	clusterInfo.ClusterLink.Status.History = l.recordEpisode(clusterInfo.ClusterLink.Status.History,
+		svclinkv1alpha1.StatusEpisodeSyncError, syncError != nil, errorMsg, metav1.NewTime(time.Now()))
+	// Either set the error or clear it (empty string), the status is only written when it changed
+	l.updateClusterStatus(ctx, kubeClient, original, &clusterInfo.ClusterLink, true, clusterInfo.ClusterLink.Status.Version, errorMsg)
+}
+
+// UpdateOnboarding records the onboarding progress of a cluster, a nil progress completes the onboarding
+func UpdateOnboarding(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, progress *svclinkv1alpha1.OnboardingStatus) {
+	cluster := &clusterInfo.ClusterLink
+	if equality.Semantic.DeepEqual(cluster.Status.Onboarding, progress) {
+		return
+	}
+
+	original := cluster.DeepCopy()
+	cluster.Status.Onboarding = progress
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to update onboarding progress for ClusterLink %s: %v", cluster.Name, err)
+		}
+		return
+	}
+	if progress == nil {
+		klog.Infof("Completed onboarding of ClusterLink %s", cluster.Name)
+	}
+}
+
+// UpdateFailingServices records the services of a cluster that exhausted their failure budget in its status.
+// The status is only written when the list changed.
+func UpdateFailingServices(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, failing []svclinkv1alpha1.FailingService) {
+	cluster := &clusterInfo.ClusterLink
+	if equality.Semantic.DeepEqual(cluster.Status.FailingServices, failing) {
+		return
+	}
+
+	original := cluster.DeepCopy()
+	cluster.Status.FailingServices = failing
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to update failing services for ClusterLink %s: %v", cluster.Name, err)
+		}
+		return
+	}
+	klog.V(4).Infof("Updated failing services for ClusterLink %s (%d failing)", cluster.Name, len(failing))
+}
+
+// SyncResult is the outcome of a sync cycle for a cluster
+type SyncResult struct {
+	// Services is the number of services synced
+	Services int
+	// Endpoints is the number of endpoints published
+	Endpoints int
+	// Skipped is the number of services not synced because of filters or spec.maxServices
+	Skipped int
+	// NamespaceSummary is the services and endpoints per remote namespace, nil when not reported
+	NamespaceSummary []svclinkv1alpha1.NamespaceSyncSummary
+	// Errors are the aggregation and update errors of the services of the cluster
+	Errors []SyncError
+	// Completed is when the sync completed, zero when it was not successful
+	Completed time.Time
+	// Duration is how long the discovery and aggregation of a successful sync took
+	Duration time.Duration
+}
+
+// UpdateSyncResult records the outcome of the last sync cycle in the status of a cluster. The completion time
+// and duration are only updated by successful syncs, and the status is only written when the result changed or
+// the completion time is due for a heartbeat.
+func (l *Links) UpdateSyncResult(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, result SyncResult) {
+	cluster := &clusterInfo.ClusterLink
+	original := cluster.DeepCopy()
+	cluster.Status.SyncedServices = int32(result.Services)
+	cluster.Status.SyncedEndpoints = int32(result.Endpoints)
+	cluster.Status.SkippedServices = int32(result.Skipped)
+	cluster.Status.NamespaceSummary = result.NamespaceSummary
+	for _, syncError := range result.Errors {
+		cluster.Status.Errors = l.recordError(cluster.Status.Errors, syncError, metav1.NewTime(time.Now()))
+	}
+	// Like LastConnected, the completion time and duration, which differ on every sync, alone only get written
+	// at the heartbeat interval. They are refreshed along with any other change of the sync result.
+	changed := !equality.Semantic.DeepEqual(original.Status, cluster.Status)
+	if !result.Completed.IsZero() && (changed || l.heartbeatDue(cluster.Status.LastSyncTime, result.Completed)) {
+		completed := metav1.NewTime(result.Completed)
+		cluster.Status.LastSyncTime = &completed
+		cluster.Status.LastSyncDuration = &metav1.Duration{Duration: result.Duration.Round(time.Millisecond)}
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to update sync result for ClusterLink %s: %v", cluster.Name, err)
+		}
+		return
+	}
+	klog.V(4).Infof("Updated sync result for ClusterLink %s (%d services, %d endpoints, %d skipped)",
+		cluster.Name, result.Services, result.Endpoints, result.Skipped)
 }
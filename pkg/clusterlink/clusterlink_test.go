@@ -0,0 +1,199 @@
+package clusterlink
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// kubeconfigTemplate is the kubeconfig of a remote cluster with the server left out
+const kubeconfigTemplate = `apiVersion: v1
+kind: Config
+current-context: remote
+clusters:
+- name: remote
+  cluster:
+    server: %s
+users:
+- name: remote
+  user:
+    token: token
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+`
+
+// unreachableKubeconfig points at a closed local port, so that capability discovery fails right away
+var unreachableKubeconfig = fmt.Sprintf(kubeconfigTemplate, "https://127.0.0.1:1")
+
+// remoteCluster serves the discovery of a remote cluster that serves discovery.k8s.io/v1 and returns its
+// kubeconfig
+func remoteCluster(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version":
+			fmt.Fprint(w, `{"gitVersion":"v1.30.0"}`)
+		case "/api":
+			fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+		case "/apis":
+			fmt.Fprint(w, `{"kind":"APIGroupList","groups":[{"name":"discovery.k8s.io","versions":[{"groupVersion":"discovery.k8s.io/v1","version":"v1"}]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return fmt.Sprintf(kubeconfigTemplate, server.URL)
+}
+
+// linkedCluster returns an enabled ClusterLink with a kubeconfig
+func linkedCluster(name, kubeconfig string) svclinkv1alpha1.ClusterLink {
+	clusterLink := svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 1},
+		Spec: svclinkv1alpha1.ClusterLinkSpec{
+			Enabled:    true,
+			Kubeconfig: base64.StdEncoding.EncodeToString([]byte(kubeconfig)),
+		},
+	}
+	clusterLink.APIVersion, clusterLink.Kind = svclinkv1alpha1.SchemeGroupVersion.String(), "ClusterLink"
+	return clusterLink
+}
+
+// newTestLinks returns the Links of a controller running with the default status settings
+func newTestLinks() *Links {
+	return NewLinks(nil, &config.Config{
+		StatusHistoryRetention:      config.DefaultStatusHistoryRetention,
+		StatusHistoryLimit:          config.DefaultStatusHistoryLimit,
+		StatusErrorLimit:            config.DefaultStatusErrorLimit,
+		StatusHeartbeatInterval:     config.DefaultStatusHeartbeatInterval,
+		CircuitBreakerThreshold:     config.DefaultCircuitBreakerThreshold,
+		CircuitBreakerProbeInterval: config.DefaultCircuitBreakerProbeInterval,
+	})
+}
+
+func TestWarmClusterClients(t *testing.T) {
+	kubeconfig := remoteCluster(t)
+	disabled := linkedCluster("west", kubeconfig)
+	disabled.Spec.Enabled = false
+	fake := &patchingClient{clusterLinks: []svclinkv1alpha1.ClusterLink{linkedCluster("east", kubeconfig), disabled}}
+	links := newTestLinks()
+
+	clusterInfos, err := links.WarmClusterClients(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("WarmClusterClients() error = %v", err)
+	}
+	if len(clusterInfos) != 1 || clusterInfos["east"] == nil || clusterInfos["east"].Client == nil {
+		t.Fatalf("expected a client for east only, got %v", clusterInfos)
+	}
+	if fake.patches != 0 {
+		t.Errorf("expected a standby to write no status, got %d patches", fake.patches)
+	}
+
+	// The leader reuses the warm client once elected
+	warm := clusterInfos["east"].Client
+	clusterInfos, _, err = links.ListClusterInfo(context.Background(), fake)
+	if err != nil || clusterInfos["east"] == nil || clusterInfos["east"].Client != warm {
+		t.Errorf("expected the leader to reuse the warm client (err %v)", err)
+	}
+	if fake.patches == 0 {
+		t.Error("expected the leader to write the status")
+	}
+}
+
+func TestListClusterInfoPaused(t *testing.T) {
+	paused := linkedCluster("east", remoteCluster(t))
+	paused.Spec.Paused = true
+	fake := &patchingClient{clusterLinks: []svclinkv1alpha1.ClusterLink{paused}}
+	links := newTestLinks()
+
+	clusterInfos, inactive, err := links.ListClusterInfo(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("ListClusterInfo() error = %v", err)
+	}
+	if len(clusterInfos) != 0 || !inactive.Paused.Has("east") {
+		t.Fatalf("expected east to be paused and not connected, got %v and %+v", clusterInfos, inactive)
+	}
+	if len(fake.patched) != 1 || findCondition(fake.patched[0].Status.Conditions, svclinkv1alpha1.ClusterLinkPaused) == nil {
+		t.Fatalf("expected the Paused condition to be written, got %d patches", len(fake.patched))
+	}
+
+	// The condition is only written once
+	fake.clusterLinks[0].Status = fake.patched[0].Status
+	if _, _, err := links.ListClusterInfo(context.Background(), fake); err != nil || len(fake.patched) != 1 {
+		t.Errorf("expected no write for a paused cluster with the condition, got %d patches (err %v)", len(fake.patched), err)
+	}
+
+	// Resuming connects to the cluster and drops the condition
+	fake.clusterLinks[0].Spec.Paused = false
+	clusterInfos, inactive, err = links.ListClusterInfo(context.Background(), fake)
+	if err != nil || clusterInfos["east"] == nil || inactive.Paused.Len() != 0 {
+		t.Fatalf("expected east to be connected once resumed, got %v (err %v)", clusterInfos, err)
+	}
+	if last := fake.patched[len(fake.patched)-1]; findCondition(last.Status.Conditions, svclinkv1alpha1.ClusterLinkPaused) != nil {
+		t.Error("expected the Paused condition to be dropped once resumed")
+	}
+}
+
+func TestListClusterInfoDisabled(t *testing.T) {
+	kubeconfig := remoteCluster(t)
+	disabled := linkedCluster("west", kubeconfig)
+	disabled.Spec.Enabled = false
+	fake := &patchingClient{clusterLinks: []svclinkv1alpha1.ClusterLink{linkedCluster("east", kubeconfig), disabled}}
+
+	clusterInfos, inactive, err := newTestLinks().ListClusterInfo(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("ListClusterInfo() error = %v", err)
+	}
+	if clusterInfos["west"] != nil || clusterInfos["east"] == nil {
+		t.Errorf("expected only east to be connected, got %v", clusterInfos)
+	}
+	if !inactive.Disabled.Equal(sets.New("west")) || inactive.Paused.Len() != 0 {
+		t.Errorf("expected west to be disabled, got %+v", inactive)
+	}
+
+	var condition *svclinkv1alpha1.ClusterLinkCondition
+	for _, patched := range fake.patched {
+		if patched.Name == "west" {
+			condition = findCondition(patched.Status.Conditions, svclinkv1alpha1.ClusterLinkDisabled)
+		}
+	}
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected the Disabled condition on west, got %+v", condition)
+	}
+}
+
+func TestListClusterInfoDiscoveryFailure(t *testing.T) {
+	fake := &patchingClient{clusterLinks: []svclinkv1alpha1.ClusterLink{linkedCluster("east", unreachableKubeconfig)}}
+	links := newTestLinks()
+
+	clusterInfos, inactive, err := links.ListClusterInfo(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("ListClusterInfo() error = %v", err)
+	}
+	if clusterInfos["east"] != nil {
+		t.Fatalf("expected east not to be synced without its capabilities, got %+v", clusterInfos["east"])
+	}
+	if _, ok := inactive.Unreachable["east"]; !ok {
+		t.Errorf("expected east to be unreachable, got %+v", inactive)
+	}
+	if len(fake.patched) != 1 || !strings.Contains(fake.patched[0].Status.Error, "failed to discover capabilities") {
+		t.Fatalf("expected the discovery error in the status, got %d patches", len(fake.patched))
+	}
+
+	// Unknown capabilities are not cached, the discovery is retried in the next sync cycle
+	if _, ok := links.capabilities.get("east", 1); ok {
+		t.Error("expected no cached capabilities after a failed discovery")
+	}
+}
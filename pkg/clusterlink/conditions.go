@@ -0,0 +1,63 @@
+package clusterlink
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// setCondition sets condition in conditions the way meta.SetStatusCondition does: an existing condition of the
+// same type is updated in place and keeps its LastTransitionTime unless its status changes
+func setCondition(conditions *[]svclinkv1alpha1.ClusterLinkCondition, condition svclinkv1alpha1.ClusterLinkCondition) {
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.NewTime(time.Now())
+	}
+
+	existing := findCondition(*conditions, condition.Type)
+	if existing == nil {
+		*conditions = append(*conditions, condition)
+		return
+	}
+
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = condition.LastTransitionTime
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+	existing.ObservedGeneration = condition.ObservedGeneration
+}
+
+// removeCondition removes the condition of the given type from conditions
+func removeCondition(conditions *[]svclinkv1alpha1.ClusterLinkCondition, conditionType svclinkv1alpha1.ClusterLinkConditionType) {
+	var kept []svclinkv1alpha1.ClusterLinkCondition
+	for _, condition := range *conditions {
+		if condition.Type != conditionType {
+			kept = append(kept, condition)
+		}
+	}
+	*conditions = kept
+}
+
+// findCondition returns the condition of the given type, or nil if conditions has none
+func findCondition(conditions []svclinkv1alpha1.ClusterLinkCondition, conditionType svclinkv1alpha1.ClusterLinkConditionType) *svclinkv1alpha1.ClusterLinkCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setOptionalCondition sets condition of the given type, or removes it when condition is nil
+func setOptionalCondition(conditions *[]svclinkv1alpha1.ClusterLinkCondition, conditionType svclinkv1alpha1.ClusterLinkConditionType,
+	condition *svclinkv1alpha1.ClusterLinkCondition, generation int64) {
+	if condition == nil {
+		removeCondition(conditions, conditionType)
+		return
+	}
+	condition.ObservedGeneration = generation
+	setCondition(conditions, *condition)
+}
@@ -0,0 +1,62 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestSetCondition(t *testing.T) {
+	first := metav1.NewTime(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	later := metav1.NewTime(first.Add(time.Minute))
+
+	conditions := []svclinkv1alpha1.ClusterLinkCondition{{
+		Type:               svclinkv1alpha1.ClusterLinkReady,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: first,
+		Reason:             "Connected",
+	}}
+
+	setCondition(&conditions, svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 2,
+		LastTransitionTime: later,
+		Reason:             "Connected",
+		Message:            "still connected",
+	})
+	ready := findCondition(conditions, svclinkv1alpha1.ClusterLinkReady)
+	if !ready.LastTransitionTime.Equal(&first) {
+		t.Errorf("LastTransitionTime changed without a status change: %v", ready.LastTransitionTime)
+	}
+	if ready.Message != "still connected" || ready.ObservedGeneration != 2 {
+		t.Errorf("condition not updated: %+v", ready)
+	}
+
+	setCondition(&conditions, svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkReady,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: later,
+		Reason:             "ConnectionFailed",
+	})
+	ready = findCondition(conditions, svclinkv1alpha1.ClusterLinkReady)
+	if !ready.LastTransitionTime.Equal(&later) || ready.Status != metav1.ConditionFalse {
+		t.Errorf("status change not recorded: %+v", ready)
+	}
+	if len(conditions) != 1 {
+		t.Errorf("expected 1 condition, got %d", len(conditions))
+	}
+
+	setCondition(&conditions, svclinkv1alpha1.ClusterLinkCondition{Type: svclinkv1alpha1.ClusterLinkError, Status: metav1.ConditionTrue})
+	if errCondition := findCondition(conditions, svclinkv1alpha1.ClusterLinkError); errCondition == nil || errCondition.LastTransitionTime.IsZero() {
+		t.Errorf("new condition not added with a transition time: %+v", errCondition)
+	}
+
+	removeCondition(&conditions, svclinkv1alpha1.ClusterLinkReady)
+	if findCondition(conditions, svclinkv1alpha1.ClusterLinkReady) != nil || len(conditions) != 1 {
+		t.Errorf("condition not removed: %+v", conditions)
+	}
+}
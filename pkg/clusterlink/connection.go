@@ -0,0 +1,56 @@
+package clusterlink
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// applyConnectionSettings applies the per-ClusterLink connection settings on top of the
+// rest.Config built from the remote credentials
+func applyConnectionSettings(restConfig *rest.Config, spec *svclinkv1alpha1.ClusterLinkSpec) error {
+	if spec.ProxyURL != "" {
+		proxyURL, err := parseProxyURL(spec.ProxyURL)
+		if err != nil {
+			return err
+		}
+		restConfig.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if spec.ClientQPS != nil {
+		restConfig.QPS = float32(*spec.ClientQPS)
+	}
+	if spec.ClientBurst != nil {
+		restConfig.Burst = int(*spec.ClientBurst)
+	}
+
+	return nil
+}
+
+// connectionSettingsFingerprint returns the connection settings that require rebuilding the client when changed
+func connectionSettingsFingerprint(spec *svclinkv1alpha1.ClusterLinkSpec) []byte {
+	return []byte(fmt.Sprintf("%s/%d/%d", spec.ProxyURL, ptr.Deref(spec.ClientQPS, 0), ptr.Deref(spec.ClientBurst, 0)))
+}
+
+// parseProxyURL parses and validates an HTTP(S) or SOCKS5 proxy URL
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxyURL %q: %w", rawURL, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxyURL %q: unsupported scheme %q, must be http, https or socks5", rawURL, proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxyURL %q: missing host", rawURL)
+	}
+	return proxyURL, nil
+}
@@ -0,0 +1,80 @@
+package clusterlink
+
+import (
+	"net/http"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestParseProxyURL(t *testing.T) {
+	tests := []struct {
+		rawURL  string
+		wantErr bool
+	}{
+		{rawURL: "http://proxy.internal:3128"},
+		{rawURL: "https://proxy.internal"},
+		{rawURL: "socks5://bastion:1080"},
+		{rawURL: "ftp://proxy.internal", wantErr: true},
+		{rawURL: "proxy.internal:3128", wantErr: true},
+		{rawURL: "http://", wantErr: true},
+		{rawURL: "http://proxy internal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			if _, err := parseProxyURL(tt.rawURL); (err != nil) != tt.wantErr {
+				t.Errorf("parseProxyURL(%q) error = %v, want error %v", tt.rawURL, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyConnectionSettingsProxy(t *testing.T) {
+	restConfig := &rest.Config{Host: "https://east:6443"}
+	spec := &svclinkv1alpha1.ClusterLinkSpec{ProxyURL: "socks5://bastion:1080"}
+	if err := applyConnectionSettings(restConfig, spec); err != nil {
+		t.Fatalf("applyConnectionSettings() error = %v", err)
+	}
+	if restConfig.Proxy == nil {
+		t.Fatal("expected the proxy to be set")
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://east:6443/version", nil)
+	if proxy, err := restConfig.Proxy(req); err != nil || proxy.String() != "socks5://bastion:1080" {
+		t.Errorf("Proxy() = %v (err %v), want socks5://bastion:1080", proxy, err)
+	}
+
+	direct := &svclinkv1alpha1.ClusterLinkSpec{}
+	if string(connectionSettingsFingerprint(spec)) == string(connectionSettingsFingerprint(direct)) {
+		t.Error("expected a proxy change to rebuild the client")
+	}
+
+	if err := applyConnectionSettings(&rest.Config{}, &svclinkv1alpha1.ClusterLinkSpec{ProxyURL: "ftp://proxy"}); err == nil {
+		t.Error("expected an error for an unsupported proxy scheme")
+	}
+}
+
+func TestApplyConnectionSettingsRateLimits(t *testing.T) {
+	restConfig := &rest.Config{QPS: 5, Burst: 10}
+	if err := applyConnectionSettings(restConfig, &svclinkv1alpha1.ClusterLinkSpec{}); err != nil {
+		t.Fatal(err)
+	}
+	if restConfig.QPS != 5 || restConfig.Burst != 10 {
+		t.Errorf("expected the client-go defaults to be kept, got QPS %v burst %d", restConfig.QPS, restConfig.Burst)
+	}
+
+	spec := &svclinkv1alpha1.ClusterLinkSpec{ClientQPS: ptr.To[int32](50), ClientBurst: ptr.To[int32](100)}
+	if err := applyConnectionSettings(restConfig, spec); err != nil {
+		t.Fatal(err)
+	}
+	if restConfig.QPS != 50 || restConfig.Burst != 100 {
+		t.Errorf("expected QPS 50 and burst 100, got QPS %v burst %d", restConfig.QPS, restConfig.Burst)
+	}
+
+	raised := &svclinkv1alpha1.ClusterLinkSpec{ClientQPS: ptr.To[int32](100), ClientBurst: ptr.To[int32](100)}
+	if string(connectionSettingsFingerprint(spec)) == string(connectionSettingsFingerprint(raised)) {
+		t.Error("expected a rate limit change to rebuild the client")
+	}
+}
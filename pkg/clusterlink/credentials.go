@@ -0,0 +1,181 @@
+package clusterlink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// KubeconfigSecretKey is the default key of the kubeconfig in the Secret referenced by kubeconfigSecretRef
+const KubeconfigSecretKey = "kubeconfig"
+
+// loadRESTConfig builds the rest.Config for a remote cluster from the ClusterLink credentials and
+// connection settings. It also returns a fingerprint of everything the client was built from, used to
+// detect changes such as token rotation.
+func (l *Links) loadRESTConfig(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink) (*rest.Config, string, error) {
+	restConfig, credentialsHash, err := l.loadCredentials(ctx, clusterLink)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := applyConnectionSettings(restConfig, &clusterLink.Spec); err != nil {
+		return nil, "", err
+	}
+
+	return restConfig, fingerprint([]byte(credentialsHash), connectionSettingsFingerprint(&clusterLink.Spec)), nil
+}
+
+// loadCredentials builds the rest.Config for a remote cluster from the ClusterLink credentials, either the
+// embedded kubeconfig, a kubeconfig Secret or an API server URL with a ServiceAccount token Secret or a
+// client certificate issued by cert-manager.
+func (l *Links) loadCredentials(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink) (*rest.Config, string, error) {
+	spec := clusterLink.Spec
+
+	if spec.Kubeconfig != "" {
+		kubeconfigData, err := base64.StdEncoding.DecodeString(spec.Kubeconfig)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode kubeconfig: %w", err)
+		}
+
+		restConfig, err := restConfigFromKubeconfig(kubeconfigData, spec.KubeconfigContext)
+		if err != nil {
+			return nil, "", err
+		}
+		return restConfig, fingerprint(kubeconfigData, []byte(spec.KubeconfigContext)), nil
+	}
+
+	if spec.KubeconfigSecretRef != nil {
+		kubeconfigData, err := l.readSecretKey(ctx, clusterLink, spec.KubeconfigSecretRef, KubeconfigSecretKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		restConfig, err := restConfigFromKubeconfig(kubeconfigData, spec.KubeconfigContext)
+		if err != nil {
+			return nil, "", err
+		}
+		return restConfig, fingerprint(kubeconfigData, []byte(spec.KubeconfigContext)), nil
+	}
+
+	if spec.APIServerURL == "" {
+		return nil, "", errors.New("either kubeconfig, kubeconfigSecretRef or apiServerURL must be specified")
+	}
+	if spec.ServiceAccountTokenSecretRef == nil {
+		if spec.Auth != nil && spec.Auth.CertificateRef != nil {
+			return l.loadCertificateCredentials(ctx, clusterLink)
+		}
+		return nil, "", errors.New("serviceAccountTokenSecretRef or auth.certificateRef is required when apiServerURL is specified")
+	}
+
+	token, err := l.readServiceAccountToken(ctx, clusterLink)
+	if err != nil {
+		return nil, "", err
+	}
+
+	restConfig := &rest.Config{
+		Host:        spec.APIServerURL,
+		BearerToken: token,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: spec.CABundle,
+		},
+	}
+	return restConfig, fingerprint([]byte(spec.APIServerURL), spec.CABundle, []byte(token)), nil
+}
+
+// restConfigFromKubeconfig builds a rest.Config from kubeconfig data using the given context,
+// or the kubeconfig's current-context when contextName is empty
+func restConfigFromKubeconfig(kubeconfigData []byte, contextName string) (*rest.Config, error) {
+	if contextName == "" {
+		restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfigData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+		}
+		return restConfig, nil
+	}
+
+	kubeconfig, err := clientcmd.Load(kubeconfigData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	if _, ok := kubeconfig.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*kubeconfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config for context %q: %w", contextName, err)
+	}
+	return restConfig, nil
+}
+
+// readServiceAccountToken reads the bearer token referenced by the ClusterLink from a local Secret
+func (l *Links) readServiceAccountToken(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink) (string, error) {
+	token, err := l.readSecretKey(ctx, clusterLink, clusterLink.Spec.ServiceAccountTokenSecretRef, corev1.ServiceAccountTokenKey)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// readSecretKey reads the value referenced by a ClusterLink from a local Secret. The Secret is read from the API
+// server rather than a cache, as svclink may only get the Secrets it is referred to.
+func (l *Links) readSecretKey(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink, ref *svclinkv1alpha1.SecretKeyReference, defaultKey string) ([]byte, error) {
+	namespace, key := SecretKeyRef(clusterLink, ref, defaultKey)
+	if err := l.checkNamespace(clusterLink, "secret", namespace, ref.Name); err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := l.apiReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("secret %s/%s has no %q key", namespace, ref.Name, key)
+	}
+	return value, nil
+}
+
+// checkNamespace refuses the reference of a ClusterLink to an object in another namespace unless cross-namespace
+// references are allowed, so that creating a ClusterLink does not give access to the credentials of every namespace
+func (l *Links) checkNamespace(clusterLink *svclinkv1alpha1.ClusterLink, kind, namespace, name string) error {
+	if namespace == clusterLink.Namespace || l.allowCrossNamespaceSecrets {
+		return nil
+	}
+	return fmt.Errorf("%s %s/%s is not in the namespace of the ClusterLink, cross-namespace references need --allow-cross-namespace-secrets",
+		kind, namespace, name)
+}
+
+// SecretKeyRef resolves the namespace and key of a Secret referenced by a ClusterLink
+func SecretKeyRef(clusterLink *svclinkv1alpha1.ClusterLink, ref *svclinkv1alpha1.SecretKeyReference, defaultKey string) (namespace, key string) {
+	namespace = ref.Namespace
+	if namespace == "" {
+		namespace = clusterLink.Namespace
+	}
+	key = ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+	return namespace, key
+}
+
+// fingerprint returns a stable hash of credential material
+func fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,172 @@
+package clusterlink
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// secretsClient serves Secrets keyed by namespace/name
+type secretsClient struct {
+	client.Client
+	secrets map[string]*corev1.Secret
+	// gets records the keys of the Secrets read
+	gets []string
+}
+
+func (c *secretsClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	c.gets = append(c.gets, key.String())
+	secret, ok := c.secrets[key.String()]
+	if !ok {
+		return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+	}
+	secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func TestLoadServiceAccountTokenCredentials(t *testing.T) {
+	secrets := &secretsClient{secrets: map[string]*corev1.Secret{
+		"cloudpilot/east-token": {Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte("token-1")}},
+	}}
+	clusterLink := &svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east"},
+		Spec: svclinkv1alpha1.ClusterLinkSpec{
+			APIServerURL:                 "https://east:6443",
+			CABundle:                     []byte("ca"),
+			ServiceAccountTokenSecretRef: &svclinkv1alpha1.SecretKeyReference{Name: "east-token"},
+		},
+	}
+	links := &Links{apiReader: secrets}
+
+	restConfig, hash, err := links.loadCredentials(context.Background(), clusterLink)
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if restConfig.Host != "https://east:6443" || restConfig.BearerToken != "token-1" || string(restConfig.CAData) != "ca" {
+		t.Errorf("unexpected rest config host %q token %q CA %q", restConfig.Host, restConfig.BearerToken, restConfig.CAData)
+	}
+
+	// A rotated token rebuilds the client
+	secrets.secrets["cloudpilot/east-token"].Data[corev1.ServiceAccountTokenKey] = []byte("token-2")
+	if _, rotated, err := links.loadCredentials(context.Background(), clusterLink); err != nil || rotated == hash {
+		t.Errorf("expected the fingerprint to change on rotation (err %v)", err)
+	}
+
+	clusterLink.Spec.ServiceAccountTokenSecretRef = &svclinkv1alpha1.SecretKeyReference{Name: "east-token", Key: "other"}
+	if _, _, err := links.loadCredentials(context.Background(), clusterLink); err == nil {
+		t.Error("expected an error for a secret without the key")
+	}
+
+	clusterLink.Spec.ServiceAccountTokenSecretRef = &svclinkv1alpha1.SecretKeyReference{Name: "east-token", Namespace: "other"}
+	if _, _, err := links.loadCredentials(context.Background(), clusterLink); err == nil || !strings.Contains(err.Error(), "not in the namespace") {
+		t.Errorf("expected a Secret of another namespace to be refused, got %v", err)
+	}
+	links.allowCrossNamespaceSecrets = true
+	if _, _, err := links.loadCredentials(context.Background(), clusterLink); err == nil || !apierrors.IsNotFound(errors.Unwrap(err)) {
+		t.Errorf("expected an error for a missing secret, got %v", err)
+	}
+
+	clusterLink.Spec.ServiceAccountTokenSecretRef = nil
+	if _, _, err := links.loadCredentials(context.Background(), clusterLink); err == nil {
+		t.Error("expected an error for an API server URL without credentials")
+	}
+}
+
+func TestSecretKeyRef(t *testing.T) {
+	clusterLink := &svclinkv1alpha1.ClusterLink{ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east"}}
+
+	namespace, key := SecretKeyRef(clusterLink, &svclinkv1alpha1.SecretKeyReference{Name: "east-token"}, "token")
+	if namespace != "cloudpilot" || key != "token" {
+		t.Errorf("SecretKeyRef() = %s, %s, want the ClusterLink namespace and default key", namespace, key)
+	}
+	namespace, key = SecretKeyRef(clusterLink, &svclinkv1alpha1.SecretKeyReference{Name: "east-token", Namespace: "vault", Key: "sa"}, "token")
+	if namespace != "vault" || key != "sa" {
+		t.Errorf("SecretKeyRef() = %s, %s, want vault, sa", namespace, key)
+	}
+}
+
+func TestLoadKubeconfigContext(t *testing.T) {
+	clusterLink := &svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east"},
+		Spec: svclinkv1alpha1.ClusterLinkSpec{
+			Kubeconfig: base64.StdEncoding.EncodeToString([]byte(multiContextKubeconfig)),
+		},
+	}
+
+	tests := []struct {
+		contextName string
+		wantServer  string
+		wantToken   string
+		wantErr     bool
+	}{
+		{wantServer: "https://prod.example.com", wantToken: "prod-token"},
+		{contextName: "dev", wantServer: "https://dev.example.com", wantToken: "dev-token"},
+		{contextName: "staging", wantErr: true},
+	}
+	hashes := make(map[string]string)
+	for _, tt := range tests {
+		t.Run(tt.contextName, func(t *testing.T) {
+			clusterLink.Spec.KubeconfigContext = tt.contextName
+			restConfig, hash, err := (&Links{apiReader: &secretsClient{}}).loadCredentials(context.Background(), clusterLink)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadCredentials() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if restConfig.Host != tt.wantServer || restConfig.BearerToken != tt.wantToken {
+				t.Errorf("loadCredentials() = %s with token %q, want %s with token %q", restConfig.Host, restConfig.BearerToken,
+					tt.wantServer, tt.wantToken)
+			}
+			hashes[tt.contextName] = hash
+		})
+	}
+	if hashes[""] == hashes["dev"] {
+		t.Error("expected a context change to rebuild the client")
+	}
+}
+
+func TestLoadKubeconfigSecretCredentials(t *testing.T) {
+	secrets := &secretsClient{secrets: map[string]*corev1.Secret{
+		"svclink/east-kubeconfig": {Data: map[string][]byte{KubeconfigSecretKey: []byte(multiContextKubeconfig)}},
+	}}
+	clusterLink := &svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cloudpilot", Name: "east"},
+		Spec: svclinkv1alpha1.ClusterLinkSpec{
+			KubeconfigSecretRef: &svclinkv1alpha1.SecretKeyReference{Name: "east-kubeconfig", Namespace: "svclink"},
+			KubeconfigContext:   "dev",
+		},
+	}
+	links := &Links{apiReader: secrets, allowCrossNamespaceSecrets: true}
+
+	restConfig, _, err := links.loadCredentials(context.Background(), clusterLink)
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if restConfig.Host != "https://dev.example.com" || restConfig.BearerToken != "dev-token" {
+		t.Errorf("loadCredentials() = %s with token %q, want the dev context", restConfig.Host, restConfig.BearerToken)
+	}
+
+	// Only the Secrets of the ClusterLink namespace are read unless cross-namespace references are allowed
+	links.allowCrossNamespaceSecrets = false
+	if _, _, err := links.loadCredentials(context.Background(), clusterLink); err == nil {
+		t.Error("expected a Secret of another namespace to be refused")
+	}
+	if len(secrets.gets) != 1 {
+		t.Errorf("expected the refused Secret not to be read, got %d reads", len(secrets.gets))
+	}
+
+	clusterLink.Spec.KubeconfigSecretRef.Namespace = ""
+	if _, _, err := links.loadCredentials(context.Background(), clusterLink); err == nil {
+		t.Error("expected an error for a Secret missing from the ClusterLink namespace")
+	}
+}
@@ -0,0 +1,54 @@
+package clusterlink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+)
+
+// execPluginSettings controls which exec credential plugins remote kubeconfigs may use
+// and where their binaries are looked up
+type execPluginSettings struct {
+	// dir is searched first for plugin binaries (e.g. binaries baked into the image)
+	dir string
+	// allowed are the command names of the plugins that may be executed
+	allowed sets.Set[string]
+}
+
+// configureExecProvider validates and resolves the exec credential plugin of a remote
+// rest.Config, if any. Plugins must be allowlisted because the kubeconfig is user supplied,
+// and are named by their bare command name so that a kubeconfig cannot point the allowlisted
+// name at another binary, e.g. /tmp/x/aws.
+func (ep *execPluginSettings) configureExecProvider(restConfig *rest.Config) error {
+	execConfig := restConfig.ExecProvider
+	if execConfig == nil {
+		return nil
+	}
+
+	pluginName := execConfig.Command
+	if strings.ContainsAny(pluginName, `/\`) {
+		return fmt.Errorf("exec credential plugin %q must be a command name without a path, it is looked up in --exec-plugin-dir and PATH", pluginName)
+	}
+	if !ep.allowed.Has(pluginName) {
+		return fmt.Errorf("exec credential plugin %q is not allowed, add it to --exec-plugins to enable it", pluginName)
+	}
+
+	// Prefer binaries shipped in the plugin directory over the PATH lookup
+	if ep.dir != "" {
+		candidate := filepath.Join(ep.dir, pluginName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			klog.V(4).Infof("Using exec credential plugin %s from %s", pluginName, candidate)
+			execConfig.Command = candidate
+		}
+	}
+
+	// The controller never has a terminal attached, so plugins must not prompt
+	execConfig.InteractiveMode = clientcmdapi.NeverExecInteractiveMode
+	return nil
+}
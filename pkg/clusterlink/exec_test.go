@@ -0,0 +1,59 @@
+package clusterlink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestConfigureExecProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "aws"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	execPlugins := &execPluginSettings{dir: dir, allowed: sets.New("aws", "kubelogin")}
+
+	tests := []struct {
+		name     string
+		command  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "shipped plugin", command: "aws", expected: filepath.Join(dir, "aws")},
+		{name: "plugin from PATH", command: "kubelogin", expected: "kubelogin"},
+		{name: "not allowed", command: "gke-gcloud-auth-plugin", wantErr: true},
+		{name: "absolute path with an allowed name", command: "/tmp/x/aws", wantErr: true},
+		{name: "relative path with an allowed name", command: "./aws", wantErr: true},
+		{name: "windows path with an allowed name", command: `x\aws`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restConfig := &rest.Config{ExecProvider: &clientcmdapi.ExecConfig{
+				Command:         tt.command,
+				InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+			}}
+			err := execPlugins.configureExecProvider(restConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if restConfig.ExecProvider.Command != tt.expected {
+				t.Errorf("expected command %q, got %q", tt.expected, restConfig.ExecProvider.Command)
+			}
+			if restConfig.ExecProvider.InteractiveMode != clientcmdapi.NeverExecInteractiveMode {
+				t.Errorf("expected plugins never to prompt, got %q", restConfig.ExecProvider.InteractiveMode)
+			}
+		})
+	}
+
+	if err := execPlugins.configureExecProvider(&rest.Config{}); err != nil {
+		t.Errorf("expected configs without exec plugin to be accepted: %v", err)
+	}
+}
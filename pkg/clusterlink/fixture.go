@@ -0,0 +1,155 @@
+package clusterlink
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/fake"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// fixtureVersion is the version reported by the clusters loaded from fixtures
+const fixtureVersion = "fixture"
+
+// LoadFixtureClusters builds the remote clusters from the fixture files of dir instead of live ClusterLinks.
+// Every .yaml, .yml or .json file is one cluster named after the file, holding the Services, EndpointSlices
+// and Namespaces the cluster serves, either as separate documents or in a List. A ClusterLink in the file
+// provides the spec of the cluster, which defaults to an enabled ClusterLink without filters.
+func LoadFixtureClusters(dir string) (map[string]*ClusterInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture directory: %w", err)
+	}
+
+	clusterInfos := make(map[string]*ClusterInfo)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ext)
+		clusterInfo, err := loadFixtureCluster(name, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fixture %s: %w", entry.Name(), err)
+		}
+		clusterInfos[name] = clusterInfo
+	}
+	return clusterInfos, nil
+}
+
+// loadFixtureCluster builds a cluster backed by a fake clientset holding the objects of a fixture file
+func loadFixtureCluster(name, path string) (*ClusterInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := decodeFixtureObjects(data)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterLink := svclinkv1alpha1.ClusterLink{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       svclinkv1alpha1.ClusterLinkSpec{Enabled: true},
+	}
+	var clientObjects []runtime.Object
+	namespaces := sets.New[string]()
+	referencedNamespaces := sets.New[string]()
+	for _, object := range objects {
+		switch object.GetKind() {
+		case "ClusterLink":
+			if _, err := decodeClusterLink(object.Object, &clusterLink); err != nil {
+				return nil, fmt.Errorf("invalid ClusterLink: %w", err)
+			}
+			// The cluster is named after the file, so that fixtures are found by the cluster name
+			clusterLink.Name = name
+		case "Namespace":
+			namespace := &corev1.Namespace{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, namespace); err != nil {
+				return nil, fmt.Errorf("invalid Namespace %s: %w", object.GetName(), err)
+			}
+			namespaces.Insert(namespace.Name)
+			clientObjects = append(clientObjects, namespace)
+		case "Service":
+			service := &corev1.Service{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, service); err != nil {
+				return nil, fmt.Errorf("invalid Service %s: %w", object.GetName(), err)
+			}
+			referencedNamespaces.Insert(service.Namespace)
+			clientObjects = append(clientObjects, service)
+		case "EndpointSlice":
+			slice := &discoveryv1.EndpointSlice{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, slice); err != nil {
+				return nil, fmt.Errorf("invalid EndpointSlice %s: %w", object.GetName(), err)
+			}
+			referencedNamespaces.Insert(slice.Namespace)
+			clientObjects = append(clientObjects, slice)
+		default:
+			return nil, fmt.Errorf("unsupported kind %q, fixtures hold ClusterLinks, Namespaces, Services and EndpointSlices",
+				object.GetKind())
+		}
+	}
+
+	// Namespaces only referenced by the objects of the fixture are created, so that they are discovered
+	for _, namespace := range sets.List(referencedNamespaces.Difference(namespaces)) {
+		clientObjects = append(clientObjects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+	}
+
+	return &ClusterInfo{
+		Name:        name,
+		Enabled:     clusterLink.Spec.Enabled,
+		Client:      fake.NewClientset(clientObjects...),
+		ClusterLink: clusterLink,
+		Capabilities: &Capabilities{
+			Version:         fixtureVersion,
+			EndpointSliceV1: true,
+			DiscoveredAt:    time.Now(),
+		},
+	}, nil
+}
+
+// decodeFixtureObjects decodes the YAML or JSON documents of a fixture file, flattening Lists
+func decodeFixtureObjects(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+
+		if !object.IsList() {
+			objects = append(objects, object)
+			continue
+		}
+		if err := object.EachListItem(func(item runtime.Object) error {
+			objects = append(objects, item.(*unstructured.Unstructured))
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+}
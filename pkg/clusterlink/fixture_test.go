@@ -0,0 +1,92 @@
+package clusterlink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const fixture = `apiVersion: svclink.cloudpilot.ai/v1alpha1
+kind: ClusterLink
+metadata:
+  name: ignored
+spec:
+  enabled: true
+  excludedNamespaces: ["internal"]
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: nginx
+    namespace: default
+    annotations:
+      cloudpilot.ai/svclink: "true"
+  spec:
+    ports:
+    - port: 80
+- apiVersion: discovery.k8s.io/v1
+  kind: EndpointSlice
+  metadata:
+    name: nginx-abc
+    namespace: default
+    labels:
+      kubernetes.io/service-name: nginx
+  addressType: IPv4
+  endpoints:
+  - addresses: ["10.0.0.1"]
+`
+
+func TestLoadFixtureClusters(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "east.yaml"), []byte(fixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a fixture"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clusterInfos, err := LoadFixtureClusters(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtureClusters() error = %v", err)
+	}
+	if len(clusterInfos) != 1 {
+		t.Fatalf("expected 1 cluster, got %d", len(clusterInfos))
+	}
+
+	east := clusterInfos["east"]
+	if east == nil || east.ClusterLink.Name != "east" || !east.Enabled {
+		t.Fatalf("expected an enabled cluster named after the file, got %+v", east)
+	}
+	if got := east.ClusterLink.Spec.ExcludedNamespaces; len(got) != 1 || got[0] != "internal" {
+		t.Errorf("expected the spec of the fixture ClusterLink, got excludedNamespaces %v", got)
+	}
+
+	ctx := context.Background()
+	if _, err := east.Client.CoreV1().Namespaces().Get(ctx, "default", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the referenced namespace to be created: %v", err)
+	}
+	slices, err := east.Client.DiscoveryV1().EndpointSlices("default").List(ctx, metav1.ListOptions{
+		LabelSelector: "kubernetes.io/service-name=nginx",
+	})
+	if err != nil || len(slices.Items) != 1 {
+		t.Errorf("expected the fixture EndpointSlice, got %v (err %v)", slices, err)
+	}
+}
+
+func TestLoadFixtureClustersUnsupportedKind(t *testing.T) {
+	dir := t.TempDir()
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: nginx\n"
+	if err := os.WriteFile(filepath.Join(dir, "east.yaml"), []byte(deployment), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFixtureClusters(dir); err == nil {
+		t.Error("expected an error for a Deployment in a fixture")
+	}
+}
@@ -0,0 +1,14 @@
+package clusterlink
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// heartbeatDue reports whether a status timestamp last set at last is to be refreshed at now. LastConnected and
+// LastSyncTime are only refreshed at the heartbeat interval while nothing else changes, refreshing them every sync
+// cycle would write every status every cycle.
+func (l *Links) heartbeatDue(last *metav1.Time, now time.Time) bool {
+	return last == nil || now.Sub(last.Time) >= l.heartbeatInterval
+}
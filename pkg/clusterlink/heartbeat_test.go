@@ -0,0 +1,128 @@
+package clusterlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestHeartbeatDue(t *testing.T) {
+	links := &Links{heartbeatInterval: 5 * time.Minute}
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(now.Add(-time.Minute))
+	stale := metav1.NewTime(now.Add(-5 * time.Minute))
+
+	if !links.heartbeatDue(nil, now) {
+		t.Error("expected a heartbeat for an unset timestamp")
+	}
+	if links.heartbeatDue(&recent, now) {
+		t.Error("expected no heartbeat for a timestamp within the interval")
+	}
+	if !links.heartbeatDue(&stale, now) {
+		t.Error("expected a heartbeat for a timestamp as old as the interval")
+	}
+
+	links.heartbeatInterval = 0
+	if !links.heartbeatDue(&recent, now) {
+		t.Error("expected a heartbeat every cycle with a zero interval")
+	}
+}
+
+// patchingClient lists clusterLinks and records the status patches of ClusterLinks
+type patchingClient struct {
+	client.Client
+	clusterLinks []svclinkv1alpha1.ClusterLink
+	patches      int
+	patched      []*svclinkv1alpha1.ClusterLink
+}
+
+func (c *patchingClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	items := list.(*unstructured.UnstructuredList)
+	for i := range c.clusterLinks {
+		object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&c.clusterLinks[i])
+		if err != nil {
+			return err
+		}
+		items.Items = append(items.Items, unstructured.Unstructured{Object: object})
+	}
+	return nil
+}
+
+func (c *patchingClient) Status() client.SubResourceWriter {
+	return &patchingStatusWriter{c: c}
+}
+
+type patchingStatusWriter struct {
+	client.SubResourceWriter
+	c *patchingClient
+}
+
+func (w *patchingStatusWriter) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+	w.c.patches++
+	w.c.patched = append(w.c.patched, obj.(*svclinkv1alpha1.ClusterLink).DeepCopy())
+	return nil
+}
+
+func TestUpdateSyncResultHeartbeat(t *testing.T) {
+	links := newTestLinks()
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	fake := &patchingClient{}
+	clusterInfo := &ClusterInfo{}
+	result := SyncResult{Services: 3, Completed: now, Duration: 2 * time.Second}
+
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, result)
+	if fake.patches != 1 || !clusterInfo.ClusterLink.Status.LastSyncTime.Time.Equal(now) {
+		t.Fatalf("expected the first sync result to be written, got %d patches", fake.patches)
+	}
+
+	// The same result a minute later only moves the completion time, which waits for the heartbeat
+	result.Completed = now.Add(time.Minute)
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, result)
+	if fake.patches != 1 {
+		t.Errorf("expected the completion time alone not to be written, got %d patches", fake.patches)
+	}
+
+	// Syncs taking a different time every cycle wait for the heartbeat as well
+	result.Completed = now.Add(2 * time.Minute)
+	result.Duration = 40 * time.Second
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, result)
+	result.Completed = now.Add(3 * time.Minute)
+	result.Duration = 3*time.Second + 417*time.Millisecond
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, result)
+	if fake.patches != 1 || clusterInfo.ClusterLink.Status.LastSyncDuration.Duration != 2*time.Second {
+		t.Errorf("expected a different duration alone not to be written, got %d patches", fake.patches)
+	}
+
+	// A failed sync keeps the last successful completion time and duration
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, SyncResult{Services: 3})
+	if fake.patches != 1 || clusterInfo.ClusterLink.Status.LastSyncDuration.Duration != 2*time.Second {
+		t.Errorf("expected a failed sync with the same counts not to be written, got %d patches", fake.patches)
+	}
+
+	// The latest duration is written with the completion time at the heartbeat interval
+	result.Completed = now.Add(5 * time.Minute)
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, result)
+	status := clusterInfo.ClusterLink.Status
+	if fake.patches != 2 || status.LastSyncDuration.Duration != result.Duration || !status.LastSyncTime.Time.Equal(result.Completed) {
+		t.Errorf("expected the duration to be written with the completion time at the heartbeat, got %d patches and %+v", fake.patches, status)
+	}
+
+	// Any other change of the sync result writes the current duration right away
+	result.Completed = now.Add(6 * time.Minute)
+	result.Services = 4
+	result.Duration = 40 * time.Second
+	links.UpdateSyncResult(context.Background(), fake, clusterInfo, result)
+	status = clusterInfo.ClusterLink.Status
+	if fake.patches != 3 || status.LastSyncDuration.Duration != 40*time.Second || !status.LastSyncTime.Time.Equal(result.Completed) {
+		t.Errorf("expected the duration to be written with a changed result, got %d patches and %+v", fake.patches, status)
+	}
+}
@@ -0,0 +1,62 @@
+package clusterlink
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// recordEpisode records whether an episode of the given type is active at now: an active episode extends the
+// ongoing episode of that type or starts a new one, an inactive one resolves the ongoing episode. The history
+// is then pruned to the configured retention and limit.
+func (l *Links) recordEpisode(history []svclinkv1alpha1.StatusEpisode, episodeType svclinkv1alpha1.StatusEpisodeType,
+	active bool, message string, now metav1.Time) []svclinkv1alpha1.StatusEpisode {
+	if l.historyRetention <= 0 {
+		return nil
+	}
+
+	history = append([]svclinkv1alpha1.StatusEpisode(nil), history...)
+	ongoing := -1
+	for i := range history {
+		if history[i].Type == episodeType && history[i].Resolved == nil {
+			ongoing = i
+		}
+	}
+
+	switch {
+	case active && ongoing >= 0:
+		history[ongoing].Count++
+		history[ongoing].LastSeen = now
+		history[ongoing].Message = message
+	case active:
+		history = append(history, svclinkv1alpha1.StatusEpisode{
+			Type:      episodeType,
+			Count:     1,
+			FirstSeen: now,
+			LastSeen:  now,
+			Message:   message,
+		})
+	case ongoing >= 0:
+		resolved := now
+		history[ongoing].Resolved = &resolved
+	}
+
+	return pruneHistory(history, l.historyRetention, l.historyLimit, now)
+}
+
+// pruneHistory drops the episodes resolved longer than retention ago, then the oldest episodes beyond limit
+func pruneHistory(history []svclinkv1alpha1.StatusEpisode, retention time.Duration, limit int, now metav1.Time) []svclinkv1alpha1.StatusEpisode {
+	var kept []svclinkv1alpha1.StatusEpisode
+	for _, episode := range history {
+		if episode.Resolved != nil && now.Sub(episode.Resolved.Time) > retention {
+			continue
+		}
+		kept = append(kept, episode)
+	}
+	if limit > 0 && len(kept) > limit {
+		kept = kept[len(kept)-limit:]
+	}
+	return kept
+}
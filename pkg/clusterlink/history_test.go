@@ -0,0 +1,59 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestRecordEpisode(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	at := func(d time.Duration) metav1.Time { return metav1.NewTime(start.Add(d)) }
+	disconnected := svclinkv1alpha1.StatusEpisodeDisconnected
+	links := newTestLinks()
+
+	var history []svclinkv1alpha1.StatusEpisode
+	history = links.recordEpisode(history, disconnected, false, "", at(0))
+	if len(history) != 0 {
+		t.Fatalf("expected no episode while connected, got %+v", history)
+	}
+
+	history = links.recordEpisode(history, disconnected, true, "timeout", at(time.Minute))
+	history = links.recordEpisode(history, disconnected, true, "refused", at(2*time.Minute))
+	if len(history) != 1 || history[0].Count != 2 || history[0].Message != "refused" || !history[0].FirstSeen.Equal(&metav1.Time{Time: start.Add(time.Minute)}) {
+		t.Fatalf("expected one ongoing episode seen twice, got %+v", history)
+	}
+
+	history = links.recordEpisode(history, disconnected, false, "", at(3*time.Minute))
+	if history[0].Resolved == nil {
+		t.Fatalf("expected the episode to be resolved, got %+v", history[0])
+	}
+
+	history = links.recordEpisode(history, disconnected, true, "timeout", at(4*time.Minute))
+	if len(history) != 2 || history[1].Resolved != nil || history[1].Count != 1 {
+		t.Fatalf("expected a new ongoing episode, got %+v", history)
+	}
+
+	// The first episode is resolved longer than the retention ago
+	history = links.recordEpisode(history, disconnected, true, "timeout", at(25*time.Hour))
+	if len(history) != 1 || history[0].Count != 2 {
+		t.Fatalf("expected the resolved episode to be pruned, got %+v", history)
+	}
+}
+
+func TestPruneHistoryLimit(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	history := []svclinkv1alpha1.StatusEpisode{
+		{Type: svclinkv1alpha1.StatusEpisodeDisconnected, Message: "first", Resolved: &now},
+		{Type: svclinkv1alpha1.StatusEpisodeSyncError, Message: "second", Resolved: &now},
+		{Type: svclinkv1alpha1.StatusEpisodeDisconnected, Message: "third"},
+	}
+
+	got := pruneHistory(history, time.Hour, 2, now)
+	if len(got) != 2 || got[0].Message != "second" || got[1].Message != "third" {
+		t.Errorf("expected the two most recent episodes, got %+v", got)
+	}
+}
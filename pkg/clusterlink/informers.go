@@ -0,0 +1,171 @@
+package clusterlink
+
+import (
+	"fmt"
+	"sync"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// sliceInformer is the informer of the native EndpointSlices of a remote cluster, tied to the client it was
+// started with
+type sliceInformer struct {
+	client   kubernetes.Interface
+	factory  informers.SharedInformerFactory
+	informer cache.SharedIndexInformer
+	lister   discoverylisters.EndpointSliceLister
+	stop     chan struct{}
+}
+
+// sliceInformers watch the native EndpointSlices of the connected clusters, so that endpoints are read from a
+// cache rather than listed every sync cycle, and so that endpoint changes are reported as they happen. The
+// EndpointSlices svclink publishes are left out of the watch.
+type sliceInformers struct {
+	mu       sync.Mutex
+	enabled  bool
+	onChange func(cluster, namespace, serviceName string)
+	clusters map[string]*sliceInformer
+}
+
+// OnEndpointSliceChange calls onChange with the remote namespace and name of a service whose EndpointSlices
+// changed after the initial list of the informer of its cluster. It only applies to the informers started
+// afterwards.
+func (l *Links) OnEndpointSliceChange(onChange func(cluster, namespace, serviceName string)) {
+	l.slices.mu.Lock()
+	defer l.slices.mu.Unlock()
+
+	l.slices.onChange = onChange
+}
+
+// ensure starts the informer of a cluster, or restarts it when the client of the cluster was rebuilt
+func (si *sliceInformers) ensure(name string, client kubernetes.Interface) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	if !si.enabled {
+		return
+	}
+	if current, ok := si.clusters[name]; ok {
+		if current.client == client {
+			return
+		}
+		current.shutdown()
+	}
+
+	selector := fmt.Sprintf("%s!=%s,!%s", config.ManagedByLabel, config.ManagedByValue, config.ClusterLabel)
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}),
+		informers.WithTransform(stripManagedFields))
+	sliceInformer := &sliceInformer{
+		client:   client,
+		factory:  factory,
+		informer: factory.Discovery().V1().EndpointSlices().Informer(),
+		lister:   factory.Discovery().V1().EndpointSlices().Lister(),
+		stop:     make(chan struct{}),
+	}
+	onChange := si.onChange
+	if onChange != nil {
+		notify := func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if slice, ok := obj.(*discoveryv1.EndpointSlice); ok && slice.Labels[config.ServiceNameLabel] != "" {
+				onChange(name, slice.Namespace, slice.Labels[config.ServiceNameLabel])
+			}
+		}
+		_, err := sliceInformer.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj interface{}, isInInitialList bool) {
+				if !isInInitialList {
+					notify(obj)
+				}
+			},
+			UpdateFunc: func(_, obj interface{}) { notify(obj) },
+			DeleteFunc: notify,
+		})
+		if err != nil {
+			klog.Errorf("Failed to watch the EndpointSlices of cluster %s: %v", name, err)
+		}
+	}
+	factory.Start(sliceInformer.stop)
+	si.clusters[name] = sliceInformer
+	klog.V(4).Infof("Started the EndpointSlice informer of cluster %s", name)
+}
+
+func (s *sliceInformer) shutdown() {
+	close(s.stop)
+	// Shutdown waits for the informer goroutines, so that they are not leaked with the client
+	go s.factory.Shutdown()
+}
+
+// stripManagedFields drops the managed fields of cached EndpointSlices, which svclink never reads
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+// CachedEndpointSlices returns the native EndpointSlices of a remote service from the informer of the cluster
+// client belongs to. It reports false when the cluster has no informer or the informer is not synced yet.
+func (l *Links) CachedEndpointSlices(client kubernetes.Interface, namespace, serviceName string) ([]discoveryv1.EndpointSlice, bool) {
+	l.slices.mu.Lock()
+	var lister discoverylisters.EndpointSliceLister
+	for _, sliceInformer := range l.slices.clusters {
+		if sliceInformer.client == client && sliceInformer.informer.HasSynced() {
+			lister = sliceInformer.lister
+			break
+		}
+	}
+	l.slices.mu.Unlock()
+	if lister == nil {
+		return nil, false
+	}
+
+	cached, err := lister.EndpointSlices(namespace).List(labels.SelectorFromSet(labels.Set{config.ServiceNameLabel: serviceName}))
+	if err != nil {
+		return nil, false
+	}
+	// Cached objects are shared, copy them so that callers may modify them
+	slices := make([]discoveryv1.EndpointSlice, 0, len(cached))
+	for _, slice := range cached {
+		slices = append(slices, *slice.DeepCopy())
+	}
+	return slices, true
+}
+
+// forget stops the informer of a cluster whose ClusterLink was deleted
+func (si *sliceInformers) forget(name string) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	if sliceInformer, ok := si.clusters[name]; ok {
+		sliceInformer.shutdown()
+		delete(si.clusters, name)
+	}
+}
+
+// prune stops the informers of clusters that no longer have a ClusterLink
+func (si *sliceInformers) prune(active sets.Set[string]) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	for name, sliceInformer := range si.clusters {
+		if !active.Has(name) {
+			sliceInformer.shutdown()
+			delete(si.clusters, name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package clusterlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func endpointSlice(name string, extraLabels map[string]string) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "payments",
+			Name:      name,
+			Labels:    map[string]string{config.ServiceNameLabel: "api"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	for key, value := range extraLabels {
+		slice.Labels[key] = value
+	}
+	return slice
+}
+
+func TestEndpointSliceInformers(t *testing.T) {
+	changes := make(chan string, 10)
+	links := NewLinks(nil, &config.Config{WatchRemoteEndpoints: true})
+	links.OnEndpointSliceChange(func(cluster, namespace, serviceName string) {
+		changes <- cluster + "/" + namespace + "/" + serviceName
+	})
+
+	client := fake.NewClientset(
+		endpointSlice("api-native", nil),
+		endpointSlice("api-svclink-west", map[string]string{config.ManagedByLabel: config.ManagedByValue}),
+	)
+	if _, ok := links.CachedEndpointSlices(client, "payments", "api"); ok {
+		t.Fatal("expected no cache before the informer is started")
+	}
+
+	links.slices.ensure("east", client)
+	defer links.slices.prune(sets.New[string]())
+
+	var slices []discoveryv1.EndpointSlice
+	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, 5*time.Second, true,
+		func(context.Context) (bool, error) {
+			var ok bool
+			slices, ok = links.CachedEndpointSlices(client, "payments", "api")
+			return ok, nil
+		})
+	if err != nil {
+		t.Fatalf("informer did not sync: %v", err)
+	}
+	if len(slices) != 1 || slices[0].Name != "api-native" {
+		t.Errorf("expected only the native EndpointSlice to be cached, got %v", slices)
+	}
+	select {
+	case change := <-changes:
+		t.Errorf("expected the initial list not to be reported, got %s", change)
+	default:
+	}
+
+	if _, err := client.DiscoveryV1().EndpointSlices("payments").Create(context.Background(),
+		endpointSlice("api-native-2", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case change := <-changes:
+		if change != "east/payments/api" {
+			t.Errorf("expected a change of east/payments/api, got %s", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the new EndpointSlice to be reported")
+	}
+
+	links.slices.forget("east")
+	if _, ok := links.CachedEndpointSlices(client, "payments", "api"); ok {
+		t.Error("expected the informer to be stopped")
+	}
+}
@@ -0,0 +1,34 @@
+package clusterlink
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// latencyTolerance is the least change of the measured latency that is written to the status
+const latencyTolerance = 10 * time.Millisecond
+
+// probeLatency measures the round-trip time of a version request to the remote API server
+func probeLatency(client kubernetes.Interface) (time.Duration, error) {
+	start := time.Now()
+	if _, err := client.Discovery().ServerVersion(); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// reportedLatency returns the latency to report in the status of a cluster. The reported latency is kept while
+// the measured one stays within a quarter of it, or within latencyTolerance, so that jitter does not write the
+// status every sync.
+func reportedLatency(reported *metav1.Duration, latency time.Duration) *metav1.Duration {
+	latency = latency.Round(time.Millisecond)
+	if reported != nil {
+		tolerance := max(reported.Duration/4, latencyTolerance)
+		if delta := latency - reported.Duration; delta < tolerance && delta > -tolerance {
+			return reported
+		}
+	}
+	return &metav1.Duration{Duration: latency}
+}
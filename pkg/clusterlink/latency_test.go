@@ -0,0 +1,38 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReportedLatency(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported time.Duration
+		latency  time.Duration
+		want     time.Duration
+	}{
+		{name: "first measurement", latency: 12*time.Millisecond + 400*time.Microsecond, want: 12 * time.Millisecond},
+		{name: "jitter within the tolerance", reported: 12 * time.Millisecond, latency: 19 * time.Millisecond, want: 12 * time.Millisecond},
+		{name: "jitter within a quarter", reported: 200 * time.Millisecond, latency: 160 * time.Millisecond, want: 200 * time.Millisecond},
+		{name: "slower by more than the tolerance", reported: 12 * time.Millisecond, latency: 22 * time.Millisecond, want: 22 * time.Millisecond},
+		{name: "faster by more than a quarter", reported: 200 * time.Millisecond, latency: 150 * time.Millisecond, want: 150 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported *metav1.Duration
+			if tt.reported != 0 {
+				reported = &metav1.Duration{Duration: tt.reported}
+			}
+			got := reportedLatency(reported, tt.latency)
+			if got.Duration != tt.want {
+				t.Errorf("reportedLatency() = %v, want %v", got.Duration, tt.want)
+			}
+			if tt.want == tt.reported && got != reported {
+				t.Error("expected the reported latency to be kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package clusterlink
+
+import (
+	"context"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConnectClusterLink connects to the cluster of a single ClusterLink and records the connection state in its
+// status, as ListClusterInfo does for every ClusterLink. A paused, disabled or version skewed ClusterLink, or one
+// of another shard, is reported in the returned clusters. A NotFound error is returned when the ClusterLink does not exist.
+func (l *Links) ConnectClusterLink(ctx context.Context, kubeClient client.Client, name string) (*InactiveClusters, error) {
+	clusterLink, err := l.getClusterLink(ctx, kubeClient, name)
+	if err != nil {
+		return nil, err
+	}
+
+	inactive := newInactiveClusters()
+	if !l.shard.owns(clusterLink) {
+		inactive.Unowned.Insert(name)
+		return inactive, nil
+	}
+	if l.connectClusterLink(ctx, kubeClient, clusterLink, inactive, true) != nil {
+		klog.V(4).Infof("Connected to cluster %s", name)
+	}
+	return inactive, nil
+}
+
+// ForgetClusterLink drops the remote client, EndpointSlice informer, cached capabilities, failure, reachability and permission state of a deleted
+// ClusterLink, so that a ClusterLink created again with the same name starts over
+func (l *Links) ForgetClusterLink(name string) {
+	l.clients.forget(name)
+	l.capabilities.forget(name)
+	l.breakers.forget(name)
+	l.permissions.forget(name)
+	l.versionSkews.forget(name)
+	l.quotas.forget(name)
+	l.slices.forget(name)
+	l.unreachable.forget(name)
+}
@@ -0,0 +1,40 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestForgetClusterLink(t *testing.T) {
+	now := time.Now()
+	links := newTestLinks()
+	links.clients.set("east", "hash", fake.NewClientset())
+	links.capabilities.set("east", 1, &Capabilities{Version: "v1.30.0", DiscoveredAt: now})
+	links.breakers.recordFailure("east", 1, "timeout", now)
+	links.permissions.set("east", permissionReview{credentialsHash: "hash", generation: 1})
+	links.versionSkews.set("east", "too old")
+	links.clients.set("west", "hash", fake.NewClientset())
+
+	links.ForgetClusterLink("east")
+
+	if _, ok := links.clients.get("east", "hash"); ok {
+		t.Error("expected the remote client to be dropped")
+	}
+	if _, ok := links.capabilities.get("east", 1); ok {
+		t.Error("expected the cached capabilities to be dropped")
+	}
+	if _, ok := links.breakers.clusters["east"]; ok {
+		t.Error("expected the circuit breaker to be dropped")
+	}
+	if !links.permissions.due("east", "hash", 1) {
+		t.Error("expected the permission review to be dropped")
+	}
+	if _, ok := links.versionSkews.get("east"); ok {
+		t.Error("expected the version skew to be dropped")
+	}
+	if _, ok := links.clients.get("west", "hash"); !ok {
+		t.Error("expected the other clusters to be kept")
+	}
+}
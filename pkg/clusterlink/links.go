@@ -0,0 +1,98 @@
+package clusterlink
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// Links connects to the linked clusters and keeps what is learned about them across sync cycles: the remote
+// clients and capabilities, the EndpointSlice informers, the circuit breakers and the other per-cluster state
+// reported in the status of the ClusterLinks. A controller owns a single Links, configured when it is created.
+type Links struct {
+	// apiReader reads the credentials of ClusterLinks directly, so that Secrets are not cached cluster-wide
+	apiReader client.Reader
+	// allowCrossNamespaceSecrets allows ClusterLinks to reference credentials in other namespaces than their own
+	allowCrossNamespaceSecrets bool
+	// credentialsExpiryWindow is how long before client certificate expiry the CredentialsExpiring condition is raised
+	credentialsExpiryWindow time.Duration
+	// heartbeatInterval is how often status timestamps that advance every sync cycle are refreshed
+	heartbeatInterval time.Duration
+	// historyRetention is how long resolved episodes are kept in status.history, 0 disables the history
+	historyRetention time.Duration
+	// historyLimit is the maximum number of episodes kept in status.history
+	historyLimit int
+	// errorLimit is the maximum number of errors kept in status.errors, 0 disables the error list
+	errorLimit  int
+	execPlugins execPluginSettings
+	shard       shard
+
+	// capabilities caches capabilities per cluster across sync cycles
+	capabilities *capabilityCache
+	// clients caches clients per cluster across sync cycles
+	clients      *clientCache
+	breakers     *circuitBreakers
+	permissions  *permissionReviews
+	slices       *sliceInformers
+	unreachable  *unreachableClusters
+	schemaFields *unknownFields
+	// hazards records, per ClusterLink, the hazard found by the preflight when running degraded. Hazardous
+	// ClusterLinks are not synced and report the hazard through the ConfigurationHazard condition.
+	hazards *hazards
+	// quotas records, per ClusterLink, why services it exports are not imported because of spec.maxServices.
+	// The message is reported through the QuotaExceeded condition.
+	quotas *hazards
+	// versionSkews records, per ClusterLink, why its cluster is too old to be synced. Skewed clusters are
+	// connected but not synced, and report the skew through the VersionSkew condition.
+	versionSkews *hazards
+}
+
+// NewLinks creates the Links of a controller running with cfg, reading the credentials of ClusterLinks with
+// apiReader
+func NewLinks(apiReader client.Reader, cfg *config.Config) *Links {
+	capabilityCacheTTL := config.DefaultCapabilityCacheTTL
+	if cfg.CapabilityCacheTTL > 0 {
+		capabilityCacheTTL = cfg.CapabilityCacheTTL
+	}
+	credentialsExpiryWindow := config.DefaultCredentialsExpiryWindow
+	if cfg.CredentialsExpiryWindow > 0 {
+		credentialsExpiryWindow = cfg.CredentialsExpiryWindow
+	}
+
+	return &Links{
+		apiReader:                  apiReader,
+		allowCrossNamespaceSecrets: cfg.AllowCrossNamespaceSecrets,
+		credentialsExpiryWindow:    credentialsExpiryWindow,
+		heartbeatInterval:          cfg.StatusHeartbeatInterval,
+		historyRetention:           cfg.StatusHistoryRetention,
+		historyLimit:               cfg.StatusHistoryLimit,
+		errorLimit:                 cfg.StatusErrorLimit,
+		execPlugins:                execPluginSettings{dir: cfg.ExecPluginDir, allowed: sets.New(cfg.ExecPlugins...)},
+		shard:                      shard{index: cfg.ShardIndex, count: max(cfg.ShardCount, 1)},
+
+		capabilities: newCapabilityCache(capabilityCacheTTL),
+		clients:      newClientCache(),
+		breakers:     newCircuitBreakers(cfg.CircuitBreakerThreshold, cfg.CircuitBreakerProbeInterval),
+		permissions:  &permissionReviews{reviews: make(map[string]permissionReview)},
+		slices:       &sliceInformers{enabled: cfg.WatchRemoteEndpoints, clusters: make(map[string]*sliceInformer)},
+		unreachable:  &unreachableClusters{since: make(map[string]time.Time)},
+		schemaFields: &unknownFields{fields: make(map[string][]string)},
+		hazards:      &hazards{messages: make(map[string]string)},
+		quotas:       &hazards{messages: make(map[string]string)},
+		versionSkews: &hazards{messages: make(map[string]string)},
+	}
+}
+
+// prune drops the state of the clusters that no longer have a ClusterLink
+func (l *Links) prune(active sets.Set[string]) {
+	l.capabilities.prune(active)
+	l.clients.prune(active)
+	l.breakers.prune(active)
+	l.permissions.prune(active)
+	l.versionSkews.prune(active)
+	l.slices.prune(active)
+	l.unreachable.prune(active)
+}
@@ -0,0 +1,125 @@
+package clusterlink
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// minimizedContextName is the name of the single context of a minimized kubeconfig
+const minimizedContextName = "svclink"
+
+// NormalizeKubeconfigs rewrites the kubeconfig of every ClusterLink to its minimal form,
+// keeping only the cluster and user of the selected context. This reduces the size of the
+// ClusterLink and the credentials exposed to anyone allowed to read it.
+func NormalizeKubeconfigs(ctx context.Context, kubeClient client.Client) error {
+	var clusterLinks svclinkv1alpha1.ClusterLinkList
+	if err := kubeClient.List(ctx, &clusterLinks); err != nil {
+		return fmt.Errorf("failed to list ClusterLinks: %w", err)
+	}
+
+	for i := range clusterLinks.Items {
+		clusterLink := &clusterLinks.Items[i]
+		// Discovered ClusterLinks are rewritten by their discovery source every cycle
+		if clusterLink.Spec.Kubeconfig == "" || clusterLink.Labels[config.DiscoverySourceLabel] != "" {
+			continue
+		}
+
+		kubeconfigData, err := base64.StdEncoding.DecodeString(clusterLink.Spec.Kubeconfig)
+		if err != nil {
+			// Reported through the ClusterLink status when the client is built
+			continue
+		}
+		minimized, err := MinimizeKubeconfig(kubeconfigData, clusterLink.Spec.KubeconfigContext)
+		if err != nil {
+			klog.V(4).Infof("Not normalizing kubeconfig of ClusterLink %s: %v", clusterLink.Name, err)
+			continue
+		}
+		if bytes.Equal(minimized, kubeconfigData) {
+			continue
+		}
+
+		original := clusterLink.DeepCopy()
+		clusterLink.Spec.Kubeconfig = base64.StdEncoding.EncodeToString(minimized)
+		clusterLink.Spec.KubeconfigContext = ""
+		if err := kubeClient.Patch(ctx, clusterLink, client.MergeFrom(original)); err != nil {
+			klog.Errorf("Failed to normalize kubeconfig of ClusterLink %s: %v", clusterLink.Name, err)
+			continue
+		}
+		klog.Infof("Normalized kubeconfig of ClusterLink %s (%d -> %d bytes)", clusterLink.Name, len(kubeconfigData), len(minimized))
+	}
+
+	return nil
+}
+
+// MinimizeKubeconfig returns a kubeconfig holding only the cluster and user of the given context,
+// or of the current-context when contextName is empty. Referenced files are inlined, and
+// preferences, extensions and unused clusters, users and contexts are dropped.
+func MinimizeKubeconfig(kubeconfigData []byte, contextName string) ([]byte, error) {
+	kubeconfig, err := clientcmd.Load(kubeconfigData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	if contextName == "" {
+		contextName = kubeconfig.CurrentContext
+	}
+	kubeContext, ok := kubeconfig.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	cluster, ok := kubeconfig.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig", kubeContext.Cluster)
+	}
+	authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("user %q not found in kubeconfig", kubeContext.AuthInfo)
+	}
+
+	minimized := clientcmdapi.NewConfig()
+	minimized.Clusters[minimizedContextName] = &clientcmdapi.Cluster{
+		Server:                   cluster.Server,
+		TLSServerName:            cluster.TLSServerName,
+		InsecureSkipTLSVerify:    cluster.InsecureSkipTLSVerify,
+		CertificateAuthority:     cluster.CertificateAuthority,
+		CertificateAuthorityData: cluster.CertificateAuthorityData,
+		ProxyURL:                 cluster.ProxyURL,
+	}
+	minimized.AuthInfos[minimizedContextName] = &clientcmdapi.AuthInfo{
+		ClientCertificate:     authInfo.ClientCertificate,
+		ClientCertificateData: authInfo.ClientCertificateData,
+		ClientKey:             authInfo.ClientKey,
+		ClientKeyData:         authInfo.ClientKeyData,
+		Token:                 authInfo.Token,
+		TokenFile:             authInfo.TokenFile,
+		Impersonate:           authInfo.Impersonate,
+		ImpersonateUID:        authInfo.ImpersonateUID,
+		ImpersonateGroups:     authInfo.ImpersonateGroups,
+		ImpersonateUserExtra:  authInfo.ImpersonateUserExtra,
+		Username:              authInfo.Username,
+		Password:              authInfo.Password,
+		AuthProvider:          authInfo.AuthProvider,
+		Exec:                  authInfo.Exec,
+	}
+	minimized.Contexts[minimizedContextName] = &clientcmdapi.Context{
+		Cluster:  minimizedContextName,
+		AuthInfo: minimizedContextName,
+	}
+	minimized.CurrentContext = minimizedContextName
+
+	// File references point into the filesystem of whoever created the kubeconfig
+	if err := clientcmdapi.FlattenConfig(minimized); err != nil {
+		return nil, fmt.Errorf("failed to inline kubeconfig files: %w", err)
+	}
+
+	return clientcmd.Write(*minimized)
+}
@@ -0,0 +1,89 @@
+package clusterlink
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const multiContextKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+preferences:
+  colors: true
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com
+    certificate-authority-data: Y2E=
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com
+users:
+- name: prod-user
+  user:
+    token: prod-token
+- name: dev-user
+  user:
+    token: dev-token
+contexts:
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: prod-user
+    namespace: default
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-user
+`
+
+func TestMinimizeKubeconfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		contextName string
+		wantServer  string
+		wantToken   string
+		wantErr     bool
+	}{
+		{name: "current context", wantServer: "https://prod.example.com", wantToken: "prod-token"},
+		{name: "explicit context", contextName: "dev", wantServer: "https://dev.example.com", wantToken: "dev-token"},
+		{name: "missing context", contextName: "staging", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MinimizeKubeconfig([]byte(multiContextKubeconfig), tt.contextName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			kubeconfig, err := clientcmd.Load(data)
+			if err != nil {
+				t.Fatalf("failed to load minimized kubeconfig: %v", err)
+			}
+			if len(kubeconfig.Clusters) != 1 || len(kubeconfig.AuthInfos) != 1 || len(kubeconfig.Contexts) != 1 {
+				t.Fatalf("expected a single cluster, user and context, got %d/%d/%d",
+					len(kubeconfig.Clusters), len(kubeconfig.AuthInfos), len(kubeconfig.Contexts))
+			}
+			if server := kubeconfig.Clusters[minimizedContextName].Server; server != tt.wantServer {
+				t.Errorf("expected server %s, got %s", tt.wantServer, server)
+			}
+			if token := kubeconfig.AuthInfos[minimizedContextName].Token; token != tt.wantToken {
+				t.Errorf("expected token %s, got %s", tt.wantToken, token)
+			}
+
+			// Minimizing is idempotent so normalized ClusterLinks are not rewritten every cycle
+			again, err := MinimizeKubeconfig(data, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("minimizing a minimized kubeconfig changed it")
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package clusterlink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// permissionRule is a resource permission the credentials of a ClusterLink need on its cluster
+type permissionRule struct {
+	group    string
+	resource string
+	verbs    []string
+}
+
+// String formats the rule as "<verbs> <resource>[.<group>]", e.g. "list endpointslices.discovery.k8s.io"
+func (r permissionRule) String() string {
+	resource := r.resource
+	if r.group != "" {
+		resource += "." + r.group
+	}
+	return strings.Join(r.verbs, ",") + " " + resource
+}
+
+// requiredPermissions returns the permissions svclink needs on a cluster to sync it with the given spec, and to
+// watch its EndpointSlices when watchSlices is set
+func requiredPermissions(spec *svclinkv1alpha1.ClusterLinkSpec, watchSlices bool) []permissionRule {
+	rules := []permissionRule{
+		{resource: "namespaces", verbs: []string{"list"}},
+		{resource: "services", verbs: []string{"get", "list"}},
+		{group: "discovery.k8s.io", resource: "endpointslices", verbs: []string{"list"}},
+	}
+	// EndpointSlices are watched by the informers of the connected clusters
+	if watchSlices {
+		rules[2].verbs = append(rules[2].verbs, "watch")
+	}
+	// Node addresses are read for node ports and the node filters
+	if spec.EndpointMode == svclinkv1alpha1.EndpointModeNodePort ||
+		(spec.HostNetworkEndpoints != "" && spec.HostNetworkEndpoints != svclinkv1alpha1.HostNetworkEndpointsPublish) ||
+		len(spec.ExcludedNodeOperatingSystems) > 0 {
+		rules = append(rules, permissionRule{resource: "nodes", verbs: []string{"list"}})
+	}
+	return rules
+}
+
+// missingPermissions reviews the required permissions with SelfSubjectAccessReviews and returns the rules
+// restricted to the verbs that are not allowed
+func missingPermissions(ctx context.Context, client kubernetes.Interface, required []permissionRule) ([]permissionRule, error) {
+	var missing []permissionRule
+	for _, rule := range required {
+		denied := permissionRule{group: rule.group, resource: rule.resource}
+		for _, verb := range rule.verbs {
+			review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Group:    rule.group,
+						Resource: rule.resource,
+						Verb:     verb,
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to review permission to %s %s: %w", verb, rule.resource, err)
+			}
+			if !review.Status.Allowed {
+				denied.verbs = append(denied.verbs, verb)
+			}
+		}
+		if len(denied.verbs) > 0 {
+			missing = append(missing, denied)
+		}
+	}
+	return missing, nil
+}
+
+// reviewPermissions reviews the permissions of a connected cluster and records the outcome. Clusters that
+// cannot be reviewed keep the outcome of their last review.
+func (l *Links) reviewPermissions(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink, client kubernetes.Interface, credentialsHash string) {
+	missing, err := missingPermissions(ctx, client, requiredPermissions(&clusterLink.Spec, l.slices.enabled))
+	if err != nil {
+		klog.V(4).Infof("Failed to review the permissions of cluster %s: %v", clusterLink.Name, err)
+		return
+	}
+	if len(missing) > 0 {
+		klog.Warningf("The credentials of cluster %s are missing permissions: %v", clusterLink.Name, missing)
+	}
+	l.permissions.set(clusterLink.Name, permissionReview{
+		credentialsHash: credentialsHash,
+		generation:      clusterLink.Generation,
+		missing:         missing,
+	})
+}
+
+// permissionReview is the outcome of the permission review of a cluster, tied to the credentials and
+// ClusterLink generation it was made with
+type permissionReview struct {
+	credentialsHash string
+	generation      int64
+	missing         []permissionRule
+}
+
+// permissionReviews records the last permission review of every cluster. Clusters are reviewed when they
+// connect with new credentials or a new spec, and on every sync cycle while permissions are missing, so that
+// granting them is picked up right away.
+type permissionReviews struct {
+	mu      sync.Mutex
+	reviews map[string]permissionReview
+}
+
+// due reports whether a cluster is to be reviewed
+func (pr *permissionReviews) due(name, credentialsHash string, generation int64) bool {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	review, ok := pr.reviews[name]
+	return !ok || len(review.missing) > 0 || review.credentialsHash != credentialsHash || review.generation != generation
+}
+
+func (pr *permissionReviews) set(name string, review permissionReview) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.reviews[name] = review
+}
+
+func (pr *permissionReviews) get(name string) []permissionRule {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	return pr.reviews[name].missing
+}
+
+// prune drops the reviews of clusters that no longer have a ClusterLink
+func (pr *permissionReviews) prune(active sets.Set[string]) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	for name := range pr.reviews {
+		if !active.Has(name) {
+			delete(pr.reviews, name)
+		}
+	}
+}
+
+// forget drops the state of a cluster whose ClusterLink was deleted
+func (pr *permissionReviews) forget(name string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	delete(pr.reviews, name)
+}
+
+// permissionsInsufficientCondition returns the PermissionsInsufficient condition of a ClusterLink whose
+// credentials miss permissions on its cluster
+func (l *Links) permissionsInsufficientCondition(name string, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	missing := l.permissions.get(name)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	rules := make([]string, 0, len(missing))
+	for _, rule := range missing {
+		rules = append(rules, rule.String())
+	}
+	return &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkPermissionsInsufficient,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "MissingPermissions",
+		Message:            "The credentials are missing permissions on the remote cluster: " + strings.Join(rules, "; "),
+	}
+}
@@ -0,0 +1,66 @@
+package clusterlink
+
+import (
+	"context"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	allowed := map[string]bool{
+		"list namespaces":                      true,
+		"get services":                         true,
+		"list endpointslices.discovery.k8s.io": true,
+	}
+	client := fake.NewClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		rule := permissionRule{group: attributes.Group, resource: attributes.Resource, verbs: []string{attributes.Verb}}
+		review.Status.Allowed = allowed[rule.String()]
+		return true, review, nil
+	})
+
+	missing, err := missingPermissions(context.Background(), client, requiredPermissions(&svclinkv1alpha1.ClusterLinkSpec{
+		EndpointMode: svclinkv1alpha1.EndpointModeNodePort,
+	}, false))
+	if err != nil {
+		t.Fatalf("missingPermissions() error = %v", err)
+	}
+
+	var got []string
+	for _, rule := range missing {
+		got = append(got, rule.String())
+	}
+	want := []string{"list services", "list nodes"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("missingPermissions() = %v, want %v", got, want)
+	}
+}
+
+func TestPermissionReviewsDue(t *testing.T) {
+	pr := &permissionReviews{reviews: make(map[string]permissionReview)}
+	if !pr.due("east", "hash", 1) {
+		t.Error("expected an unreviewed cluster to be due")
+	}
+
+	pr.set("east", permissionReview{credentialsHash: "hash", generation: 1})
+	if pr.due("east", "hash", 1) {
+		t.Error("expected a cluster with sufficient permissions not to be reviewed again")
+	}
+	if !pr.due("east", "rotated", 1) || !pr.due("east", "hash", 2) {
+		t.Error("expected new credentials or a new spec to be reviewed")
+	}
+
+	pr.set("east", permissionReview{credentialsHash: "hash", generation: 1,
+		missing: []permissionRule{{resource: "services", verbs: []string{"list"}}}})
+	if !pr.due("east", "hash", 1) {
+		t.Error("expected a cluster missing permissions to be reviewed every cycle")
+	}
+}
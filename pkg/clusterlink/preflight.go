@@ -0,0 +1,157 @@
+package clusterlink
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// Hazard is a ClusterLink configuration that would create sync loops or collisions at runtime
+type Hazard struct {
+	ClusterLink string
+	Message     string
+}
+
+func (h Hazard) String() string {
+	return fmt.Sprintf("ClusterLink %s: %s", h.ClusterLink, h.Message)
+}
+
+// hazards records a message per ClusterLink
+type hazards struct {
+	mu       sync.Mutex
+	messages map[string]string
+}
+
+func (h *hazards) get(name string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	message, ok := h.messages[name]
+	return message, ok
+}
+
+// SetHazards excludes the ClusterLinks of the hazards from sync
+func (l *Links) SetHazards(found []Hazard) {
+	l.hazards.mu.Lock()
+	defer l.hazards.mu.Unlock()
+
+	l.hazards.messages = make(map[string]string, len(found))
+	for _, hazard := range found {
+		if message, ok := l.hazards.messages[hazard.ClusterLink]; ok {
+			hazard.Message = message + "; " + hazard.Message
+		}
+		l.hazards.messages[hazard.ClusterLink] = hazard.Message
+	}
+}
+
+// Preflight looks for ClusterLinks that link the local cluster itself, link the same cluster as another
+// ClusterLink, or map several remote namespaces onto the same local namespace. Clusters are identified by
+// the UID of their kube-system namespace; ClusterLinks that cannot be connected to are only checked for
+// their namespace mappings.
+func (l *Links) Preflight(ctx context.Context, kubeClient client.Client) ([]Hazard, error) {
+	clusterLinks, err := l.listClusterLinks(ctx, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(clusterLinks, func(i, j int) bool { return clusterLinks[i].Name < clusterLinks[j].Name })
+
+	localSystem := &corev1.Namespace{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, localSystem); err != nil {
+		return nil, fmt.Errorf("failed to identify the local cluster: %w", err)
+	}
+
+	var found []Hazard
+	linked := make(map[types.UID]string)
+	for i := range clusterLinks {
+		clusterLink := &clusterLinks[i]
+		if !clusterLink.Spec.Enabled || clusterLink.Spec.Paused {
+			continue
+		}
+
+		for _, message := range namespaceMappingHazards(clusterLink.Spec.NamespaceMappings) {
+			found = append(found, Hazard{ClusterLink: clusterLink.Name, Message: message})
+		}
+
+		uid, err := l.remoteClusterUID(ctx, clusterLink)
+		if err != nil {
+			klog.Warningf("Preflight: failed to identify cluster %s, skipping its identity checks: %v", clusterLink.Name, err)
+			continue
+		}
+		switch other, ok := linked[uid]; {
+		case uid == localSystem.UID:
+			found = append(found, Hazard{ClusterLink: clusterLink.Name, Message: "links the local cluster itself"})
+		case ok:
+			found = append(found, Hazard{ClusterLink: clusterLink.Name, Message: fmt.Sprintf("links the same cluster as ClusterLink %s", other)})
+		default:
+			linked[uid] = clusterLink.Name
+		}
+	}
+	return found, nil
+}
+
+// remoteClusterUID returns the UID of the kube-system namespace of the remote cluster
+func (l *Links) remoteClusterUID(ctx context.Context, clusterLink *svclinkv1alpha1.ClusterLink) (types.UID, error) {
+	restConfig, credentialsHash, err := l.loadRESTConfig(ctx, clusterLink)
+	if err != nil {
+		return "", err
+	}
+	remote, _, err := l.buildClientWithVersion(clusterLink, restConfig, credentialsHash)
+	if err != nil {
+		return "", err
+	}
+	namespace, err := remote.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return "", fmt.Errorf("missing permission to get the kube-system namespace: %w", err)
+		}
+		return "", err
+	}
+	return namespace.UID, nil
+}
+
+// namespaceMappingHazards reports local namespaces that several remote namespaces are mapped onto
+func namespaceMappingHazards(mappings []svclinkv1alpha1.NamespaceMapping) []string {
+	bySource := make(map[string][]string)
+	var targets []string
+	for _, mapping := range mappings {
+		if len(bySource[mapping.Target]) == 0 {
+			targets = append(targets, mapping.Target)
+		}
+		bySource[mapping.Target] = append(bySource[mapping.Target], mapping.Source)
+	}
+
+	var messages []string
+	for _, target := range targets {
+		if sources := bySource[target]; len(sources) > 1 {
+			messages = append(messages, fmt.Sprintf("remote namespaces %s are all mapped onto local namespace %s",
+				strings.Join(sources, ", "), target))
+		}
+	}
+	return messages
+}
+
+// configurationHazardCondition returns the ConfigurationHazard condition of a ClusterLink excluded by the preflight
+func (l *Links) configurationHazardCondition(name string, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	message, ok := l.hazards.get(name)
+	if !ok {
+		return nil
+	}
+	return &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkConfigurationHazard,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "PreflightFailed",
+		Message:            fmt.Sprintf("Not synced, %s", message),
+	}
+}
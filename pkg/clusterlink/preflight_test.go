@@ -0,0 +1,41 @@
+package clusterlink
+
+import (
+	"reflect"
+	"testing"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestNamespaceMappingHazards(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []svclinkv1alpha1.NamespaceMapping
+		want     []string
+	}{
+		{
+			name: "distinct targets",
+			mappings: []svclinkv1alpha1.NamespaceMapping{
+				{Source: "prod-eu", Target: "prod"},
+				{Source: "staging-eu", Target: "staging"},
+			},
+		},
+		{
+			name: "two sources onto one target",
+			mappings: []svclinkv1alpha1.NamespaceMapping{
+				{Source: "prod-eu", Target: "prod"},
+				{Source: "staging-eu", Target: "staging"},
+				{Source: "prod-us", Target: "prod"},
+			},
+			want: []string{"remote namespaces prod-eu, prod-us are all mapped onto local namespace prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namespaceMappingHazards(tt.mappings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("namespaceMappingHazards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
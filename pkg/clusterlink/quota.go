@@ -0,0 +1,62 @@
+package clusterlink
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// set records the message of a ClusterLink, an empty message clears it, and reports whether it changed
+func (h *hazards) set(name, message string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.messages[name] == message {
+		return false
+	}
+	if message == "" {
+		delete(h.messages, name)
+	} else {
+		h.messages[name] = message
+	}
+	return true
+}
+
+// SetQuotaExceeded sets the QuotaExceeded condition of a ClusterLink with message, or removes it when message
+// is empty, and reports whether the condition changed
+func (l *Links) SetQuotaExceeded(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, message string) bool {
+	if !l.quotas.set(clusterInfo.ClusterLink.Name, message) {
+		return false
+	}
+
+	cluster := &clusterInfo.ClusterLink
+	original := cluster.DeepCopy()
+	setOptionalCondition(&cluster.Status.Conditions, svclinkv1alpha1.ClusterLinkQuotaExceeded,
+		l.quotaExceededCondition(cluster.Name, metav1.Now()), cluster.Generation)
+
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to update quota condition for ClusterLink %s: %v", cluster.Name, err)
+		}
+	}
+	return true
+}
+
+// quotaExceededCondition returns the QuotaExceeded condition of a ClusterLink exporting more services than its quota
+func (l *Links) quotaExceededCondition(name string, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	message, ok := l.quotas.get(name)
+	if !ok {
+		return nil
+	}
+	return &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkQuotaExceeded,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "MaxServicesReached",
+		Message:            message,
+	}
+}
@@ -0,0 +1,76 @@
+package clusterlink
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// Reachability is the outcome of dialing a sample of the endpoints imported from a cluster
+type Reachability struct {
+	// Sampled is the number of endpoints dialed
+	Sampled int
+	// Reached is the number of endpoints that accepted the connection
+	Reached int
+	// Failure is the error of one of the endpoints that could not be reached
+	Failure string
+}
+
+// UpdateNetworkReachability sets the NetworkReachable condition of a cluster from the outcome of its reachability
+// probe, or removes it when reachability is nil. The status is only written when the condition changed.
+func UpdateNetworkReachability(ctx context.Context, kubeClient client.Client, clusterInfo *ClusterInfo, reachability *Reachability) {
+	cluster := &clusterInfo.ClusterLink
+	original := cluster.DeepCopy()
+	setOptionalCondition(&cluster.Status.Conditions, svclinkv1alpha1.ClusterLinkNetworkReachable,
+		networkReachableCondition(reachability, metav1.Now()), cluster.Generation)
+	if equality.Semantic.DeepEqual(original.Status, cluster.Status) {
+		return
+	}
+
+	if err := kubeClient.Status().Patch(ctx, cluster, client.MergeFrom(original)); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to update network reachability for ClusterLink %s: %v", cluster.Name, err)
+		}
+	}
+}
+
+// networkReachableCondition returns the NetworkReachable condition for the outcome of a reachability probe. The
+// condition is False only when none of the sampled endpoints could be reached, as some endpoints may legitimately
+// be going away while they are dialed.
+func networkReachableCondition(reachability *Reachability, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	if reachability == nil {
+		return nil
+	}
+
+	condition := &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkNetworkReachable,
+		LastTransitionTime: now,
+	}
+	switch {
+	case reachability.Sampled == 0:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "NoEndpoints"
+		condition.Message = "No endpoints imported from the cluster to probe"
+	case reachability.Reached == reachability.Sampled:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Reachable"
+		condition.Message = fmt.Sprintf("Reached all %d sampled endpoints", reachability.Sampled)
+	case reachability.Reached == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Unreachable"
+		condition.Message = fmt.Sprintf("None of the %d sampled endpoints could be reached, the pod network of the cluster "+
+			"is likely not routable from the local cluster: %s", reachability.Sampled, reachability.Failure)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PartiallyReachable"
+		condition.Message = fmt.Sprintf("Reached %d of %d sampled endpoints: %s",
+			reachability.Reached, reachability.Sampled, reachability.Failure)
+	}
+	return condition
+}
@@ -0,0 +1,126 @@
+package clusterlink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// unknownFields records, per ClusterLink, the fields written by a newer version of svclink
+// that this version does not know. They are preserved, as ClusterLinks are only ever patched,
+// but not honored, which is surfaced through the NewerSchemaDetected condition.
+type unknownFields struct {
+	mu     sync.Mutex
+	fields map[string][]string
+}
+
+// set records the unknown fields of a ClusterLink and reports whether they changed
+func (uf *unknownFields) set(name string, fields []string) bool {
+	uf.mu.Lock()
+	defer uf.mu.Unlock()
+
+	if strings.Join(uf.fields[name], ",") == strings.Join(fields, ",") {
+		return false
+	}
+	if len(fields) == 0 {
+		delete(uf.fields, name)
+	} else {
+		uf.fields[name] = fields
+	}
+	return true
+}
+
+func (uf *unknownFields) get(name string) []string {
+	uf.mu.Lock()
+	defer uf.mu.Unlock()
+
+	return uf.fields[name]
+}
+
+// listClusterLinks lists the ClusterLinks and records the fields of each of them this version does not know.
+// ClusterLinks are read unstructured, as the typed client silently drops unknown fields.
+func (l *Links) listClusterLinks(ctx context.Context, kubeClient client.Client) ([]svclinkv1alpha1.ClusterLink, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(svclinkv1alpha1.SchemeGroupVersion.WithKind("ClusterLinkList"))
+	if err := kubeClient.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	clusterLinks := make([]svclinkv1alpha1.ClusterLink, 0, len(list.Items))
+	for i := range list.Items {
+		var clusterLink svclinkv1alpha1.ClusterLink
+		fields, err := decodeClusterLink(list.Items[i].Object, &clusterLink)
+		if err != nil {
+			klog.Errorf("Failed to decode ClusterLink %s: %v", list.Items[i].GetName(), err)
+			continue
+		}
+		if l.schemaFields.set(clusterLink.Name, fields) && len(fields) > 0 {
+			klog.Warningf("ClusterLink %s has fields written by a newer version of svclink, they are preserved but ignored: %s",
+				clusterLink.Name, strings.Join(fields, ", "))
+		}
+		clusterLinks = append(clusterLinks, clusterLink)
+	}
+	return clusterLinks, nil
+}
+
+// getClusterLink reads a single ClusterLink the way listClusterLinks does
+func (l *Links) getClusterLink(ctx context.Context, kubeClient client.Client, name string) (*svclinkv1alpha1.ClusterLink, error) {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(svclinkv1alpha1.SchemeGroupVersion.WithKind("ClusterLink"))
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: name}, object); err != nil {
+		return nil, err
+	}
+
+	clusterLink := &svclinkv1alpha1.ClusterLink{}
+	fields, err := decodeClusterLink(object.Object, clusterLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ClusterLink %s: %w", name, err)
+	}
+	if l.schemaFields.set(clusterLink.Name, fields) && len(fields) > 0 {
+		klog.Warningf("ClusterLink %s has fields written by a newer version of svclink, they are preserved but ignored: %s",
+			clusterLink.Name, strings.Join(fields, ", "))
+	}
+	return clusterLink, nil
+}
+
+// decodeClusterLink converts an unstructured ClusterLink and returns the paths of its unknown fields
+func decodeClusterLink(object map[string]interface{}, clusterLink *svclinkv1alpha1.ClusterLink) ([]string, error) {
+	err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(object, clusterLink, true)
+	if err == nil {
+		return nil, nil
+	}
+	strictErr, ok := runtime.AsStrictDecodingError(err)
+	if !ok {
+		return nil, err
+	}
+
+	var fields []string
+	for _, fieldErr := range strictErr.Errors() {
+		fields = append(fields, strings.TrimSuffix(strings.TrimPrefix(fieldErr.Error(), `unknown field "`), `"`))
+	}
+	return fields, nil
+}
+
+// newerSchemaCondition returns the NewerSchemaDetected condition of a ClusterLink with unknown fields
+func (l *Links) newerSchemaCondition(name string, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	fields := l.schemaFields.get(name)
+	if len(fields) == 0 {
+		return nil
+	}
+	return &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkNewerSchemaDetected,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "UnknownFields",
+		Message:            fmt.Sprintf("Fields written by a newer version of svclink are preserved but ignored: %s", strings.Join(fields, ", ")),
+	}
+}
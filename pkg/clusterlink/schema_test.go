@@ -0,0 +1,43 @@
+package clusterlink
+
+import (
+	"reflect"
+	"testing"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestDecodeClusterLink(t *testing.T) {
+	object := map[string]interface{}{
+		"apiVersion": "svclink.cloudpilot.ai/v1alpha1",
+		"kind":       "ClusterLink",
+		"metadata":   map[string]interface{}{"name": "c1"},
+		"spec": map[string]interface{}{
+			"enabled":    true,
+			"kubeconfig": "a3ViZWNvbmZpZw==",
+			"futureMode": "Mesh",
+		},
+		"status": map[string]interface{}{
+			"connected":   true,
+			"futureState": map[string]interface{}{"phase": "Ready"},
+		},
+	}
+
+	var clusterLink svclinkv1alpha1.ClusterLink
+	fields, err := decodeClusterLink(object, &clusterLink)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"spec.futureMode", "status.futureState"}; !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected unknown fields %v, got %v", expected, fields)
+	}
+	if clusterLink.Name != "c1" || !clusterLink.Spec.Enabled || clusterLink.Spec.Kubeconfig == "" || !clusterLink.Status.Connected {
+		t.Errorf("known fields were not decoded: %+v", clusterLink)
+	}
+
+	delete(object["spec"].(map[string]interface{}), "futureMode")
+	delete(object["status"].(map[string]interface{}), "futureState")
+	if fields, err := decodeClusterLink(object, &svclinkv1alpha1.ClusterLink{}); err != nil || len(fields) != 0 {
+		t.Errorf("expected no unknown fields, got %v, %v", fields, err)
+	}
+}
@@ -0,0 +1,61 @@
+package clusterlink
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// shard is the share of the ClusterLinks this replica syncs when ClusterLinks are sharded across replicas
+type shard struct {
+	index int
+	// count is the number of shards, 1 disables sharding
+	count int
+}
+
+// owns reports whether the ClusterLink belongs to the shard of this replica
+func (s shard) owns(clusterLink *svclinkv1alpha1.ClusterLink) bool {
+	if s.count <= 1 {
+		return true
+	}
+	return ShardOf(clusterLink, s.count) == s.index
+}
+
+// ShardOf returns the shard a ClusterLink belongs to out of count shards. The shard label pins a ClusterLink to a
+// shard, other ClusterLinks are assigned by rendezvous hashing of their name, so that changing the number of
+// shards only moves the ClusterLinks of the shards added or removed.
+func ShardOf(clusterLink *svclinkv1alpha1.ClusterLink, count int) int {
+	if value, ok := clusterLink.Labels[config.ShardLabel]; ok {
+		index, err := strconv.Atoi(value)
+		if err == nil && index >= 0 && index < count {
+			return index
+		}
+		klog.Warningf("Ignoring label %s=%q of ClusterLink %s, not a shard between 0 and %d",
+			config.ShardLabel, value, clusterLink.Name, count-1)
+	}
+
+	var owner int
+	var highest uint64
+	for index := 0; index < count; index++ {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(clusterLink.Name + "/" + strconv.Itoa(index)))
+		if weight := mix64(h.Sum64()); index == 0 || weight > highest {
+			owner, highest = index, weight
+		}
+	}
+	return owner
+}
+
+// mix64 spreads the bits of an FNV hash, whose high bits barely depend on the last bytes hashed
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,55 @@
+package clusterlink
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestShardOf(t *testing.T) {
+	clusterLinks := make([]*svclinkv1alpha1.ClusterLink, 200)
+	for i := range clusterLinks {
+		clusterLinks[i] = &svclinkv1alpha1.ClusterLink{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cluster-%d", i)}}
+	}
+
+	counts := make(map[int]int)
+	moved := 0
+	for _, clusterLink := range clusterLinks {
+		shard := ShardOf(clusterLink, 4)
+		if shard != ShardOf(clusterLink, 4) {
+			t.Fatalf("expected the shard of %s to be stable", clusterLink.Name)
+		}
+		counts[shard]++
+		// Adding a shard only moves ClusterLinks to the new shard
+		if grown := ShardOf(clusterLink, 5); grown != shard {
+			moved++
+			if grown != 4 {
+				t.Errorf("expected %s to move to the new shard, got shard %d", clusterLink.Name, grown)
+			}
+		}
+	}
+	for shard := 0; shard < 4; shard++ {
+		if counts[shard] < 25 {
+			t.Errorf("expected ClusterLinks to be spread across shards, got %v", counts)
+		}
+	}
+	if moved == 0 || moved > 80 {
+		t.Errorf("expected about a fifth of the ClusterLinks to move to a fifth shard, %d moved", moved)
+	}
+
+	pinned := &svclinkv1alpha1.ClusterLink{ObjectMeta: metav1.ObjectMeta{
+		Name:   "pinned",
+		Labels: map[string]string{config.ShardLabel: "3"},
+	}}
+	if shard := ShardOf(pinned, 4); shard != 3 {
+		t.Errorf("expected the shard label to pin the ClusterLink to shard 3, got %d", shard)
+	}
+	pinned.Labels[config.ShardLabel] = "7"
+	if shard := ShardOf(pinned, 4); shard != ShardOf(&svclinkv1alpha1.ClusterLink{ObjectMeta: metav1.ObjectMeta{Name: "pinned"}}, 4) {
+		t.Errorf("expected an out of range shard label to be ignored, got shard %d", shard)
+	}
+}
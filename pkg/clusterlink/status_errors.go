@@ -0,0 +1,44 @@
+package clusterlink
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// SyncError is an error a cluster failed with in a phase of a sync cycle
+type SyncError struct {
+	Phase   svclinkv1alpha1.SyncPhase
+	Message string
+}
+
+// recordError records an error of a cluster at now. An error already listed with the same phase and message
+// is counted and moved to the end of the list, otherwise it is appended. The oldest errors beyond the limit
+// are dropped.
+func (l *Links) recordError(errs []svclinkv1alpha1.StatusError, syncError SyncError, now metav1.Time) []svclinkv1alpha1.StatusError {
+	if l.errorLimit <= 0 {
+		return nil
+	}
+
+	recorded := svclinkv1alpha1.StatusError{
+		Phase:     syncError.Phase,
+		Message:   syncError.Message,
+		Count:     1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+	kept := make([]svclinkv1alpha1.StatusError, 0, len(errs)+1)
+	for _, statusError := range errs {
+		if statusError.Phase == syncError.Phase && statusError.Message == syncError.Message {
+			recorded.Count += statusError.Count
+			recorded.FirstSeen = statusError.FirstSeen
+			continue
+		}
+		kept = append(kept, statusError)
+	}
+	kept = append(kept, recorded)
+	if len(kept) > l.errorLimit {
+		kept = kept[len(kept)-l.errorLimit:]
+	}
+	return kept
+}
@@ -0,0 +1,42 @@
+package clusterlink
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+func TestRecordError(t *testing.T) {
+	links := &Links{errorLimit: 2}
+
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) metav1.Time { return metav1.NewTime(start.Add(time.Duration(minutes) * time.Minute)) }
+	connect := SyncError{Phase: svclinkv1alpha1.SyncPhaseConnect, Message: "connection refused"}
+	discover := SyncError{Phase: svclinkv1alpha1.SyncPhaseDiscover, Message: "timeout"}
+
+	errs := links.recordError(nil, connect, at(0))
+	errs = links.recordError(errs, discover, at(1))
+	// A discovery error does not overwrite the connection error that preceded it
+	if len(errs) != 2 || errs[0].Phase != svclinkv1alpha1.SyncPhaseConnect || errs[1].Phase != svclinkv1alpha1.SyncPhaseDiscover {
+		t.Fatalf("expected the connect and discover errors, got %+v", errs)
+	}
+
+	errs = links.recordError(errs, connect, at(2))
+	if len(errs) != 2 || errs[1].Message != connect.Message || errs[1].Count != 2 ||
+		!errs[1].FirstSeen.Time.Equal(at(0).Time) || !errs[1].LastSeen.Time.Equal(at(2).Time) {
+		t.Fatalf("expected the repeated error to be counted and moved last, got %+v", errs)
+	}
+
+	errs = links.recordError(errs, SyncError{Phase: svclinkv1alpha1.SyncPhaseUpdate, Message: "conflict"}, at(3))
+	if len(errs) != 2 || errs[0].Phase != svclinkv1alpha1.SyncPhaseConnect || errs[1].Phase != svclinkv1alpha1.SyncPhaseUpdate {
+		t.Errorf("expected the oldest error to be dropped beyond the limit, got %+v", errs)
+	}
+
+	links.errorLimit = 0
+	if errs = links.recordError(errs, connect, at(4)); errs != nil {
+		t.Errorf("expected a limit of 0 to disable the error list, got %+v", errs)
+	}
+}
@@ -0,0 +1,53 @@
+package clusterlink
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// unreachableClusters records, per ClusterLink, since when its cluster could not be connected to, so that the
+// endpoints imported from it can be kept through transient outages
+type unreachableClusters struct {
+	mu    sync.Mutex
+	since map[string]time.Time
+}
+
+// mark records that a cluster could not be connected to and returns since when it is unreachable
+func (u *unreachableClusters) mark(name string, now time.Time) time.Time {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	since, ok := u.since[name]
+	if !ok {
+		since = now
+		u.since[name] = since
+	}
+	return since
+}
+
+// clear records that a cluster was connected to
+func (u *unreachableClusters) clear(name string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.since, name)
+}
+
+// prune drops the clusters that no longer have a ClusterLink
+func (u *unreachableClusters) prune(active sets.Set[string]) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for name := range u.since {
+		if !active.Has(name) {
+			delete(u.since, name)
+		}
+	}
+}
+
+// forget drops a ClusterLink that was deleted
+func (u *unreachableClusters) forget(name string) {
+	u.clear(name)
+}
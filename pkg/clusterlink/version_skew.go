@@ -0,0 +1,73 @@
+package clusterlink
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+)
+
+// minimumSupportedVersion is the oldest Kubernetes version serving discovery.k8s.io/v1 EndpointSlices
+var minimumSupportedVersion = utilversion.MustParseGeneric("v1.21.0")
+
+// VersionSkew is a connected cluster that is too old to be synced
+type VersionSkew struct {
+	ClusterLink *svclinkv1alpha1.ClusterLink
+	Message     string
+}
+
+// versionSkew returns why a cluster with the given capabilities cannot be synced, or an empty string when it
+// can. Clusters whose capabilities could not be discovered are assumed to be supported.
+func versionSkew(capabilities *Capabilities) string {
+	if capabilities == nil || capabilities.Version == "" {
+		return ""
+	}
+	version, err := utilversion.ParseGeneric(capabilities.Version)
+	if err == nil && version.LessThan(minimumSupportedVersion) {
+		return fmt.Sprintf("Remote cluster version %s is not supported, svclink needs Kubernetes %s or later for discovery.k8s.io/v1 EndpointSlices",
+			capabilities.Version, minimumSupportedVersion)
+	}
+	if !capabilities.EndpointSliceV1 {
+		return fmt.Sprintf("Remote cluster version %s does not serve %s, which svclink needs to discover endpoints",
+			capabilities.Version, discoveryGroupVersion)
+	}
+	return ""
+}
+
+// prune drops the messages of clusters that no longer have a ClusterLink
+func (h *hazards) prune(active sets.Set[string]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for name := range h.messages {
+		if !active.Has(name) {
+			delete(h.messages, name)
+		}
+	}
+}
+
+// forget drops the message of a ClusterLink that was deleted
+func (h *hazards) forget(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.messages, name)
+}
+
+// versionSkewCondition returns the VersionSkew condition of a ClusterLink whose cluster is too old to be synced
+func (l *Links) versionSkewCondition(name string, now metav1.Time) *svclinkv1alpha1.ClusterLinkCondition {
+	message, ok := l.versionSkews.get(name)
+	if !ok {
+		return nil
+	}
+	return &svclinkv1alpha1.ClusterLinkCondition{
+		Type:               svclinkv1alpha1.ClusterLinkVersionSkew,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "UnsupportedVersion",
+		Message:            message,
+	}
+}
@@ -0,0 +1,24 @@
+package clusterlink
+
+import "testing"
+
+func TestVersionSkew(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities *Capabilities
+		skewed       bool
+	}{
+		{name: "undiscovered", capabilities: &Capabilities{}},
+		{name: "supported", capabilities: &Capabilities{Version: "v1.30.2", EndpointSliceV1: true}},
+		{name: "provider suffix", capabilities: &Capabilities{Version: "v1.21.14-eks-49d8fe8", EndpointSliceV1: true}},
+		{name: "too old", capabilities: &Capabilities{Version: "v1.20.15", EndpointSliceV1: false}, skewed: true},
+		{name: "discovery.k8s.io/v1 disabled", capabilities: &Capabilities{Version: "v1.25.0"}, skewed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := versionSkew(tt.capabilities); (got != "") != tt.skewed {
+				t.Errorf("versionSkew() = %q, want skewed %v", got, tt.skewed)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// clusterLabelHashLength is the number of hex characters of the hash of cluster names too long for a label value
+const clusterLabelHashLength = 10
+
+// managedSliceLabels are the labels svclink owns on the EndpointSlices it manages.
+// All other labels on managed objects belong to users or other controllers and are never modified.
+var managedSliceLabels = []string{ServiceNameLabel, ClusterLabel, ManagedByLabel}
+
+// IsManagedByUs reports whether the object is an EndpointSlice managed by svclink.
+// Slices created before the managed-by label was introduced are recognized by the cluster label.
+func IsManagedByUs(obj metav1.Object) bool {
+	objLabels := obj.GetLabels()
+	if objLabels[ManagedByLabel] == ManagedByValue {
+		return true
+	}
+	_, hasCluster := objLabels[ClusterLabel]
+	return hasCluster
+}
+
+// SourceCluster returns the remote cluster whose endpoints a managed EndpointSlice holds. Slices written before
+// the source cluster annotation was introduced are recognized by the cluster label.
+func SourceCluster(obj metav1.Object) (string, bool) {
+	if cluster, ok := obj.GetAnnotations()[SourceClusterAnnotation]; ok {
+		return cluster, true
+	}
+	cluster, ok := obj.GetLabels()[ClusterLabel]
+	return cluster, ok
+}
+
+// ClusterLabelValue returns the value of the cluster label of the EndpointSlices holding the endpoints of a
+// cluster. ClusterLink names too long for a label value are truncated and suffixed with a hash of the full name.
+func ClusterLabelValue(cluster string) string {
+	if len(validation.IsValidLabelValue(cluster)) == 0 {
+		return cluster
+	}
+	sum := sha256.Sum256([]byte(cluster))
+	hash := hex.EncodeToString(sum[:])[:clusterLabelHashLength]
+	prefix := cluster[:min(len(cluster), validation.LabelValueMaxLength-clusterLabelHashLength-1)]
+	return strings.TrimRight(prefix, "-._") + "-" + hash
+}
+
+// MarkManaged sets the labels svclink owns on an EndpointSlice holding the endpoints of serviceName
+// from cluster, and records the full name of the cluster in the source cluster annotation. It refuses to take over a slice managed by another controller, so that a name
+// collision never clobbers third-party labels or endpoints.
+func MarkManaged(obj metav1.Object, serviceName, cluster string) error {
+	objLabels := obj.GetLabels()
+	if managedBy, ok := objLabels[ManagedByLabel]; ok && managedBy != ManagedByValue {
+		return fmt.Errorf("%s/%s is managed by %q", obj.GetNamespace(), obj.GetName(), managedBy)
+	}
+
+	if objLabels == nil {
+		objLabels = make(map[string]string, len(managedSliceLabels))
+	}
+	objLabels[ServiceNameLabel] = serviceName
+	objLabels[ClusterLabel] = ClusterLabelValue(cluster)
+	objLabels[ManagedByLabel] = ManagedByValue
+	obj.SetLabels(objLabels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SourceClusterAnnotation] = cluster
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// MarkSourceNamespace records the remote namespace of the endpoints of a managed EndpointSlice.
+// The annotation is only kept while it differs from the namespace of the slice.
+func MarkSourceNamespace(obj metav1.Object, sourceNamespace string) {
+	annotations := obj.GetAnnotations()
+	if sourceNamespace == "" || sourceNamespace == obj.GetNamespace() {
+		if _, ok := annotations[SourceNamespaceAnnotation]; ok {
+			delete(annotations, SourceNamespaceAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SourceNamespaceAnnotation] = sourceNamespace
+	obj.SetAnnotations(annotations)
+}
+
+// MarkSourceSlices records the remote EndpointSlices the endpoints of a managed EndpointSlice were read from.
+// The annotation is removed when the endpoints were not read from EndpointSlices.
+func MarkSourceSlices(obj metav1.Object, sourceSlices string) {
+	annotations := obj.GetAnnotations()
+	if sourceSlices == "" {
+		if _, ok := annotations[SourceSlicesAnnotation]; ok {
+			delete(annotations, SourceSlicesAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SourceSlicesAnnotation] = sourceSlices
+	obj.SetAnnotations(annotations)
+}
+
+// MarkLastSynced records when svclink last changed a managed EndpointSlice
+func MarkLastSynced(obj metav1.Object, now time.Time) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[LastSyncedAnnotation] = now.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// IsSyncedService reports whether the Service was created by svclink from a remote service
+func IsSyncedService(obj metav1.Object) bool {
+	return obj.GetAnnotations()[SyncAnnotation] == "true"
+}
+
+// IsOptedOut reports whether a remote Service opted out of sync with the sync annotation set to "false"
+func IsOptedOut(obj metav1.Object) bool {
+	return obj.GetAnnotations()[SyncAnnotation] == "false"
+}
+
+// IsTargetedAt reports whether the svclink deployment named clusterName may import a remote Service.
+// A Service without the target clusters annotation targets every deployment, one with the annotation
+// only targets the deployments it lists, so an unnamed deployment never imports it.
+func IsTargetedAt(obj metav1.Object, clusterName string) bool {
+	targets, ok := obj.GetAnnotations()[TargetClustersAnnotation]
+	if !ok {
+		return true
+	}
+	if clusterName == "" {
+		return false
+	}
+	for _, target := range strings.Split(targets, ",") {
+		if strings.TrimSpace(target) == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// EndpointQuota returns the endpoint quota of a local Namespace set with the endpoint quota annotation
+func EndpointQuota(obj metav1.Object) (int, bool, error) {
+	value, ok := obj.GetAnnotations()[EndpointQuotaAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	quota, err := strconv.Atoi(value)
+	if err != nil || quota < 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q on namespace %s", EndpointQuotaAnnotation, value, obj.GetName())
+	}
+	return quota, true, nil
+}
+
+// Headless returns whether a remote service asks for a headless local Service with the headless annotation, and
+// whether the annotation is set
+func Headless(obj metav1.Object) (bool, bool, error) {
+	value, ok := obj.GetAnnotations()[HeadlessAnnotation]
+	if !ok {
+		return false, false, nil
+	}
+	headless, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid %s annotation %q on service %s/%s",
+			HeadlessAnnotation, value, obj.GetNamespace(), obj.GetName())
+	}
+	return headless, true, nil
+}
+
+// PinnedClusters returns the clusters a local Service is pinned to, if it has a pin that has not expired at now
+func PinnedClusters(obj metav1.Object, now time.Time) ([]string, bool, error) {
+	annotations := obj.GetAnnotations()
+	clusters, ok := annotations[PinnedClustersAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+	until, err := time.Parse(time.RFC3339, annotations[PinnedUntilAnnotation])
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s annotation %q on service %s/%s",
+			PinnedUntilAnnotation, annotations[PinnedUntilAnnotation], obj.GetNamespace(), obj.GetName())
+	}
+	if !now.Before(until) {
+		return nil, false, nil
+	}
+
+	var pinned []string
+	for _, cluster := range strings.Split(clusters, ",") {
+		if cluster = strings.TrimSpace(cluster); cluster != "" {
+			pinned = append(pinned, cluster)
+		}
+	}
+	return pinned, true, nil
+}
+
+// Pin pins a local Service to clusters until the given time
+func Pin(obj metav1.Object, clusters []string, until time.Time) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 2)
+	}
+	annotations[PinnedClustersAnnotation] = strings.Join(clusters, ",")
+	annotations[PinnedUntilAnnotation] = until.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// Unpin removes the pin of a local Service
+func Unpin(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	delete(annotations, PinnedClustersAnnotation)
+	delete(annotations, PinnedUntilAnnotation)
+	obj.SetAnnotations(annotations)
+}
+
+// SimulatedDisconnect returns the time until which a ClusterLink is treated as unreachable, and whether
+// the simulated disconnect is in effect at now
+func SimulatedDisconnect(obj metav1.Object, now time.Time) (time.Time, bool, error) {
+	value, ok := obj.GetAnnotations()[SimulatedDisconnectAnnotation]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s annotation %q on ClusterLink %s/%s",
+			SimulatedDisconnectAnnotation, value, obj.GetNamespace(), obj.GetName())
+	}
+	return until, now.Before(until), nil
+}
+
+// SimulateDisconnect makes the controller treat a ClusterLink as unreachable until the given time
+func SimulateDisconnect(obj metav1.Object, until time.Time) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SimulatedDisconnectAnnotation] = until.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// EndSimulatedDisconnect removes the simulated disconnect of a ClusterLink
+func EndSimulatedDisconnect(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	delete(annotations, SimulatedDisconnectAnnotation)
+	obj.SetAnnotations(annotations)
+}
+
+// MarkStale marks an imported EndpointSlice as holding the last known endpoints of a cluster unreachable since
+// the given time. It reports whether the annotation changed.
+func MarkStale(obj metav1.Object, since time.Time) bool {
+	value := since.UTC().Format(time.RFC3339)
+	annotations := obj.GetAnnotations()
+	if annotations[StaleSinceAnnotation] == value {
+		return false
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[StaleSinceAnnotation] = value
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// ClearStale removes the stale mark of an imported EndpointSlice
+func ClearStale(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[StaleSinceAnnotation]; ok {
+		delete(annotations, StaleSinceAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// RequestSync requests an immediate sync of a ClusterLink or local Service by setting the sync now annotation
+// to the given time
+func RequestSync(obj metav1.Object, now time.Time) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SyncNowAnnotation] = now.UTC().Format(time.RFC3339Nano)
+	obj.SetAnnotations(annotations)
+}
+
+// SyncRequested reports whether an update of an object sets a new sync now annotation
+func SyncRequested(old, updated metav1.Object) bool {
+	value := updated.GetAnnotations()[SyncNowAnnotation]
+	return value != "" && value != old.GetAnnotations()[SyncNowAnnotation]
+}
+
+// MarkSynced marks a Service as created by svclink from a remote service
+func MarkSynced(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SyncAnnotation] = "true"
+	obj.SetAnnotations(annotations)
+}
+
+// SyncedLabels returns the label keys svclink copied onto a Service from its remote service
+func SyncedLabels(obj metav1.Object) []string {
+	value := obj.GetAnnotations()[SyncedLabelsAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// MarkSyncedLabels records the label keys svclink copied onto a Service from its remote service
+func MarkSyncedLabels(obj metav1.Object, keys []string) {
+	annotations := obj.GetAnnotations()
+	if len(keys) == 0 {
+		if _, ok := annotations[SyncedLabelsAnnotation]; ok {
+			delete(annotations, SyncedLabelsAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[SyncedLabelsAnnotation] = strings.Join(keys, ",")
+	obj.SetAnnotations(annotations)
+}
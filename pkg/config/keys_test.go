@@ -0,0 +1,293 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestMarkManaged(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		expectedErr bool
+	}{
+		{
+			name: "new slice",
+		},
+		{
+			name:   "keeps third-party labels",
+			labels: map[string]string{"team": "payments", ManagedByLabel: ManagedByValue},
+		},
+		{
+			name:        "refuses slices managed by another controller",
+			labels:      map[string]string{ManagedByLabel: "endpointslice-controller.k8s.io"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Name: "web-svclink-c1", Namespace: "default", Labels: tt.labels}}
+			err := MarkManaged(slice, "web", "c1")
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				if slice.Labels[ManagedByLabel] != "endpointslice-controller.k8s.io" {
+					t.Error("labels of a slice managed by another controller were modified")
+				}
+				return
+			}
+
+			if !IsManagedByUs(slice) {
+				t.Error("expected slice to be managed by svclink")
+			}
+			if cluster, ok := SourceCluster(slice); !ok || cluster != "c1" {
+				t.Errorf("expected source cluster c1, got %q", cluster)
+			}
+			if slice.Labels[ServiceNameLabel] != "web" {
+				t.Errorf("expected service name label web, got %q", slice.Labels[ServiceNameLabel])
+			}
+			for key, value := range tt.labels {
+				if key != ManagedByLabel && slice.Labels[key] != value {
+					t.Errorf("third-party label %s was clobbered", key)
+				}
+			}
+		})
+	}
+}
+
+func TestIsManagedByUs(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "managed-by label", labels: map[string]string{ManagedByLabel: ManagedByValue}, expected: true},
+		{name: "legacy cluster label", labels: map[string]string{ClusterLabel: "c1"}, expected: true},
+		{name: "native slice", labels: map[string]string{ManagedByLabel: "endpointslice-controller.k8s.io"}, expected: false},
+		{name: "no labels", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}}
+			if result := IsManagedByUs(slice); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsOptedOut(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{name: "opt-out annotation", annotations: map[string]string{SyncAnnotation: "false"}, expected: true},
+		{name: "synced service", annotations: map[string]string{SyncAnnotation: "true"}, expected: false},
+		{name: "no annotations", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &metav1.ObjectMeta{Annotations: tt.annotations}
+			if result := IsOptedOut(svc); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsTargetedAt(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		clusterName string
+		expected    bool
+	}{
+		{name: "no annotation", clusterName: "prod-eu", expected: true},
+		{name: "no annotation and unnamed deployment", expected: true},
+		{name: "listed", annotations: map[string]string{TargetClustersAnnotation: "prod-eu, prod-us"}, clusterName: "prod-us", expected: true},
+		{name: "not listed", annotations: map[string]string{TargetClustersAnnotation: "prod-eu,prod-us"}, clusterName: "staging", expected: false},
+		{name: "unnamed deployment", annotations: map[string]string{TargetClustersAnnotation: "prod-eu"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &metav1.ObjectMeta{Annotations: tt.annotations}
+			if result := IsTargetedAt(svc, tt.clusterName); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHeadless(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		headless    bool
+		ok          bool
+		wantErr     bool
+	}{
+		{name: "no annotation"},
+		{name: "headless", annotations: map[string]string{HeadlessAnnotation: "true"}, headless: true, ok: true},
+		{name: "cluster IP", annotations: map[string]string{HeadlessAnnotation: "false"}, ok: true},
+		{name: "invalid", annotations: map[string]string{HeadlessAnnotation: "None"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headless, ok, err := Headless(&metav1.ObjectMeta{Annotations: tt.annotations})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if headless != tt.headless || ok != tt.ok {
+				t.Errorf("expected %v %v, got %v %v", tt.headless, tt.ok, headless, ok)
+			}
+		})
+	}
+}
+
+func TestPinnedClusters(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	pinned := &metav1.ObjectMeta{}
+	Pin(pinned, []string{"prod-us", "prod-eu"}, now.Add(2*time.Hour))
+
+	tests := []struct {
+		name     string
+		obj      *metav1.ObjectMeta
+		now      time.Time
+		expected []string
+		ok       bool
+		wantErr  bool
+	}{
+		{name: "not pinned", obj: &metav1.ObjectMeta{}, now: now},
+		{name: "pinned", obj: pinned, now: now, expected: []string{"prod-us", "prod-eu"}, ok: true},
+		{name: "expired", obj: pinned, now: now.Add(2 * time.Hour)},
+		{
+			name:    "invalid expiry",
+			obj:     &metav1.ObjectMeta{Annotations: map[string]string{PinnedClustersAnnotation: "prod-us", PinnedUntilAnnotation: "2h"}},
+			now:     now,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, ok, err := PinnedClusters(tt.obj, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.ok || !reflect.DeepEqual(clusters, tt.expected) {
+				t.Errorf("expected %v %v, got %v %v", tt.expected, tt.ok, clusters, ok)
+			}
+		})
+	}
+}
+
+func TestSimulatedDisconnect(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	disconnected := &metav1.ObjectMeta{}
+	SimulateDisconnect(disconnected, now.Add(5*time.Minute))
+
+	if _, ok, err := SimulatedDisconnect(disconnected, now); err != nil || !ok {
+		t.Errorf("expected simulated disconnect to be in effect, got %v %v", ok, err)
+	}
+	if _, ok, _ := SimulatedDisconnect(disconnected, now.Add(5*time.Minute)); ok {
+		t.Error("expected simulated disconnect to expire")
+	}
+	EndSimulatedDisconnect(disconnected)
+	if _, ok, _ := SimulatedDisconnect(disconnected, now); ok {
+		t.Error("expected simulated disconnect to end")
+	}
+	invalid := &metav1.ObjectMeta{Annotations: map[string]string{SimulatedDisconnectAnnotation: "5m"}}
+	if _, _, err := SimulatedDisconnect(invalid, now); err == nil {
+		t.Error("expected invalid expiry to be rejected")
+	}
+}
+
+func TestSyncRequested(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := &metav1.ObjectMeta{}
+	requested := old.DeepCopy()
+	RequestSync(requested, now)
+
+	if !SyncRequested(old, requested) {
+		t.Error("expected a new sync now annotation to request a sync")
+	}
+	if SyncRequested(requested, requested.DeepCopy()) {
+		t.Error("expected an unchanged sync now annotation not to request a sync")
+	}
+	again := requested.DeepCopy()
+	RequestSync(again, now.Add(time.Second))
+	if !SyncRequested(requested, again) {
+		t.Error("expected a bumped sync now annotation to request a sync")
+	}
+	if SyncRequested(requested, old) {
+		t.Error("expected removing the sync now annotation not to request a sync")
+	}
+}
+
+func TestMarkStale(t *testing.T) {
+	since := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	slice := &metav1.ObjectMeta{}
+
+	if !MarkStale(slice, since) || slice.Annotations[StaleSinceAnnotation] != "2026-01-01T12:00:00Z" {
+		t.Errorf("expected the slice to be marked stale, got %v", slice.Annotations)
+	}
+	if MarkStale(slice, since) {
+		t.Error("expected marking a stale slice again not to change it")
+	}
+	ClearStale(slice)
+	if _, ok := slice.Annotations[StaleSinceAnnotation]; ok {
+		t.Error("expected the stale mark to be removed")
+	}
+}
+
+func TestSourceCluster(t *testing.T) {
+	long := "arn.aws.eks.us-east-1.123456789012.cluster." + strings.Repeat("production", 10)
+	for _, cluster := range []string{"east", long} {
+		slice := &metav1.ObjectMeta{}
+		if err := MarkManaged(slice, "web", cluster); err != nil {
+			t.Fatal(err)
+		}
+		if errs := validation.IsValidLabelValue(slice.Labels[ClusterLabel]); len(errs) > 0 {
+			t.Errorf("expected a valid cluster label value for %s, got %s: %v", cluster, slice.Labels[ClusterLabel], errs)
+		}
+		if source, ok := SourceCluster(slice); !ok || source != cluster {
+			t.Errorf("expected source cluster %s, got %s", cluster, source)
+		}
+	}
+	if value := ClusterLabelValue("east"); value != "east" {
+		t.Errorf("expected a short cluster name to be its own label value, got %s", value)
+	}
+
+	legacy := &metav1.ObjectMeta{Labels: map[string]string{ClusterLabel: "west"}}
+	if source, ok := SourceCluster(legacy); !ok || source != "west" {
+		t.Errorf("expected the source cluster of a legacy slice to be read from its label, got %s", source)
+	}
+}
+
+func TestSyncedLabels(t *testing.T) {
+	svc := &metav1.ObjectMeta{}
+	if keys := SyncedLabels(svc); keys != nil {
+		t.Errorf("expected no synced labels, got %v", keys)
+	}
+	MarkSyncedLabels(svc, []string{"app", "tier"})
+	if keys := SyncedLabels(svc); !reflect.DeepEqual(keys, []string{"app", "tier"}) {
+		t.Errorf("expected the synced labels, got %v", keys)
+	}
+	MarkSyncedLabels(svc, nil)
+	if _, ok := svc.Annotations[SyncedLabelsAnnotation]; ok {
+		t.Error("expected the annotation to be removed without synced labels")
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ServiceNameData is the data the name template of services synced to the local cluster is rendered with
+type ServiceNameData struct {
+	// Name is the name of the remote service
+	Name string
+	// Namespace is the local namespace the service is imported into
+	Namespace string
+	// Cluster is the name of the remote cluster the service is imported from
+	Cluster string
+}
+
+// ParseServiceNameTemplate parses the name template of services synced to the local cluster,
+// e.g. "{{.Name}}-remote" or "{{.Name}}-{{.Cluster}}"
+func ParseServiceNameTemplate(text string) (*template.Template, error) {
+	return template.New("service-name").Option("missingkey=error").Parse(text)
+}
+
+// RenderServiceName renders the local name of a service, which must be a valid Service name
+func RenderServiceName(tmpl *template.Template, data ServiceNameData) (string, error) {
+	var name strings.Builder
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", err
+	}
+	if errs := validation.IsDNS1035Label(name.String()); len(errs) > 0 {
+		return "", fmt.Errorf("invalid service name %q: %s", name.String(), strings.Join(errs, ", "))
+	}
+	return name.String(), nil
+}
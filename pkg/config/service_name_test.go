@@ -0,0 +1,45 @@
+package config
+
+import "testing"
+
+func TestRenderServiceName(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     string
+		expectedName string
+		expectedErr  bool
+	}{
+		{
+			name:         "suffix",
+			template:     "{{.Name}}-remote",
+			expectedName: "web-remote",
+		},
+		{
+			name:         "cluster suffix",
+			template:     "{{.Name}}-{{.Cluster}}",
+			expectedName: "web-eu-west",
+		},
+		{
+			name:        "invalid service name",
+			template:    "{{.Namespace}}/{{.Name}}",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseServiceNameTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("failed to parse template: %v", err)
+			}
+
+			name, err := RenderServiceName(tmpl, ServiceNameData{Name: "web", Namespace: "prod", Cluster: "eu-west"})
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if name != tt.expectedName {
+				t.Errorf("expected name %q, got %q", tt.expectedName, name)
+			}
+		})
+	}
+}
@@ -1,31 +1,331 @@
 // Package config provides configuration types and constants for the svclink controller.
 // It defines the controller's configuration structure, cluster configuration,
-// and annotation/label constants used for service synchronization.
+// and annotation/label constants used for service synchronization, with helpers to read and set
+// the labels svclink owns without touching labels of users or other controllers.
 package config
 
 import "time"
 
 // Config holds the controller runtime configuration
 type Config struct {
+	// ClusterName identifies this svclink deployment for the target clusters annotation of remote services
+	ClusterName string
 	// SyncInterval is the interval for periodic sync operations
 	SyncInterval time.Duration
+	// SyncJitter is the maximum fraction of SyncInterval added to each interval, so that the sync cycles of
+	// instances started together drift apart
+	SyncJitter float64
+	// SyncSplay is the window over which the remote clusters of a sync cycle are discovered, each at an offset
+	// that depends on the instance and the cluster; 0 discovers all clusters right away
+	SyncSplay time.Duration
+	// SyncWorkers is the number of services synced concurrently in each cycle
+	SyncWorkers int
+	// DiscoveryWorkers is the number of remote clusters whose services are discovered concurrently
+	DiscoveryWorkers int
+	// ServiceFailureBudget is the number of consecutive failed syncs after which a service is no longer
+	// retried every cycle; 0 disables the failure budget
+	ServiceFailureBudget int
+	// ServiceFailureRetryInterval is how often services that exhausted their failure budget are retried
+	ServiceFailureRetryInterval time.Duration
+	// ServiceRetryBaseDelay is the backoff of the first retry of a service that failed to sync, doubled on every
+	// further failure up to ServiceRetryMaxDelay
+	ServiceRetryBaseDelay time.Duration
+	// ServiceRetryMaxDelay is the maximum backoff between retries of a service that keeps failing
+	ServiceRetryMaxDelay time.Duration
+	// NamespaceEndpointQuota is the maximum number of endpoints published into a local namespace, overridden by
+	// the EndpointQuotaAnnotation of the namespace; 0 disables the quota
+	NamespaceEndpointQuota int
+	// MaxEndpointsPerSlice is the maximum number of endpoints published in one EndpointSlice, the endpoints of a
+	// cluster being split across several EndpointSlices above it
+	MaxEndpointsPerSlice int
+	// SliceWriteQPS is the number of EndpointSlice writes per second svclink sends to the local API server;
+	// 0 leaves writes unlimited
+	SliceWriteQPS float64
+	// SliceWriteBurst is the number of EndpointSlice writes sent in a burst above SliceWriteQPS
+	SliceWriteBurst int
+	// MirrorEndpoints mirrors the imported endpoints of selectorless services into core/v1 Endpoints objects
+	MirrorEndpoints bool
+	// MaxClustersPerService is the maximum number of clusters contributing endpoints to a service, preferring
+	// clusters with a lower cost weight and latency; 0 disables the limit
+	MaxClustersPerService int
+	// LocalClusterPriority is the failover priority of the endpoints of the local cluster, compared with the
+	// priority of ClusterLinks
+	LocalClusterPriority int32
+	// OnboardingBatchSize is the number of namespaces of a newly connected cluster imported per sync cycle;
+	// 0 imports every namespace in the first cycle
+	OnboardingBatchSize int
+	// VerificationInterval is how often managed EndpointSlices are verified against the remote clusters; 0 disables verification
+	VerificationInterval time.Duration
 	// IncludedNamespaces If specified, only services in these namespaces will be synced.
 	IncludedNamespaces []string
+	// SyncServiceTypes If specified, only services of these types are synced from every cluster
+	SyncServiceTypes []string
 	// SyncServicesToLocalCluster indicates whether to sync services from remote clusters to the local cluster
 	SyncServicesToLocalCluster bool
+	// StripSelectors indicates whether services synced to the local cluster are created without a selector, so that
+	// local pods are never selected and svclink is the only source of their endpoints
+	StripSelectors bool
+	// ServiceNameTemplate is the name template of services synced to the local cluster, rendered with
+	// ServiceNameData. If empty, services keep their remote name.
+	ServiceNameTemplate string
+	// CapabilityCacheTTL is how long remote cluster version and API discovery results are cached
+	CapabilityCacheTTL time.Duration
+	// ExecPluginDir is searched for exec credential plugin binaries referenced by remote kubeconfigs
+	ExecPluginDir string
+	// ExecPlugins is the allowlist of exec credential plugin command names remote kubeconfigs may use
+	ExecPlugins []string
+	// AllowCrossNamespaceSecrets allows ClusterLinks to reference credentials in other namespaces than their own
+	AllowCrossNamespaceSecrets bool
+	// PrometheusMetadata enables propagating Prometheus scrape annotations onto Services synced to the local cluster
+	PrometheusMetadata bool
+	// PrometheusAnnotations is the allowlist of annotation keys propagated when PrometheusMetadata is enabled
+	PrometheusAnnotations []string
+	// PrometheusPorts is the allowlist of port names or numbers Prometheus may scrape; empty allows all ports
+	PrometheusPorts []string
+	// CredentialsExpiryWindow is how long before client certificate expiry the CredentialsExpiring condition is raised
+	CredentialsExpiryWindow time.Duration
+	// StatusHistoryRetention is how long resolved disconnect and sync error episodes are kept in the status of
+	// ClusterLinks; 0 disables the history
+	StatusHistoryRetention time.Duration
+	// StatusHistoryLimit is the maximum number of episodes kept in the status of a ClusterLink
+	StatusHistoryLimit int
+	// StatusErrorLimit is the maximum number of distinct errors kept in the status of a ClusterLink; 0 disables
+	// the error list
+	StatusErrorLimit int
+	// StatusHeartbeatInterval is how often LastConnected and LastSyncTime are refreshed in the status of
+	// ClusterLinks when nothing else in the status changed
+	StatusHeartbeatInterval time.Duration
+	// CircuitBreakerThreshold is the number of consecutive failures after which a cluster is degraded and only
+	// probed every CircuitBreakerProbeInterval; 0 disables the circuit breaker
+	CircuitBreakerThreshold int
+	// CircuitBreakerProbeInterval is how often a degraded cluster is probed
+	CircuitBreakerProbeInterval time.Duration
+	// ReachabilitySampleSize is the number of endpoints dialed per cluster with spec.reachabilityProbe each cycle
+	ReachabilitySampleSize int
+	// ReachabilityTimeout is how long the reachability probe waits for an endpoint to accept a connection
+	ReachabilityTimeout time.Duration
+	// WatchRemoteEndpoints watches the native EndpointSlices of connected clusters with informers, so that remote
+	// endpoint changes are synced as they happen and endpoints are read from the informer caches
+	WatchRemoteEndpoints bool
+	// NamespaceSummary reports the services and endpoints synced per remote namespace in the status of ClusterLinks
+	NamespaceSummary bool
+	// OrphanExpiry is how long an imported local service may stay orphaned, exposed by no remote cluster,
+	// before its managed Service and EndpointSlices are pruned; 0 only reports orphans
+	OrphanExpiry time.Duration
+	// SyncedServiceRetention is how long a Service created by svclink may stay orphaned before it is pruned along with
+	// its managed EndpointSlices, when shorter than OrphanExpiry; 0 leaves them to OrphanExpiry
+	SyncedServiceRetention time.Duration
+	// SliceGCInterval is how often the managed EndpointSlices of all namespaces are checked against the discovered
+	// services, deleting those whose service is no longer imported from their source cluster; 0 disables it
+	SliceGCInterval time.Duration
+	// NormalizeKubeconfigs rewrites ClusterLink kubeconfigs to a minimal single-context form
+	NormalizeKubeconfigs bool
+	// CAPIDiscovery creates ClusterLinks for the Cluster API workload clusters of the local cluster
+	CAPIDiscovery bool
+	// ClusterDiscoveryProviders are the "<provider>:<scope>" cloud provider scopes clusters are discovered from
+	ClusterDiscoveryProviders []string
+	// ClusterDiscoveryNamespace is the namespace discovered ClusterLinks are created in
+	ClusterDiscoveryNamespace string
+	// ClusterDiscoveryInterval is the interval between cloud provider cluster listings
+	ClusterDiscoveryInterval time.Duration
+	// MetricsBindAddress is the address the metrics and admin endpoints are served on
+	MetricsBindAddress string
+	// MemoryLimit is the soft memory limit of the process in bytes. When set, services are processed
+	// one namespace at a time and remote lists are paginated, instead of materializing every service at once.
+	MemoryLimit int64
+	// ListPageSize is the number of objects requested per page of remote lists when MemoryLimit is set
+	ListPageSize int64
+	// ShutdownGracePeriod is how long in-flight syncs are given to finish on shutdown before they are aborted
+	ShutdownGracePeriod time.Duration
+	// LeaderElection enables leader election so that only one replica syncs at a time
+	LeaderElection bool
+	// LeaderElectionNamespace is the namespace of the leader election Lease, the namespace of the controller
+	// Pod when empty
+	LeaderElectionNamespace string
+	// LeaderElectionID is the name of the leader election Lease
+	LeaderElectionID string
+	// ShardCount is the number of shards ClusterLinks are spread across, each replica syncing the ClusterLinks of
+	// one shard; 1 disables sharding
+	ShardCount int
+	// ShardIndex is the shard of the ClusterLinks this replica syncs, from 0 to ShardCount-1
+	ShardIndex int
+	// HotStandby keeps the remote clients and capabilities of non-leader replicas warm
+	HotStandby bool
+	// DebugConfigMap is the namespace/name of a ConfigMap holding runtime verbosity and debug targets
+	DebugConfigMap string
+	// StaleEndpointTTL is how long the EndpointSlices imported from an unreachable cluster are kept, marked stale,
+	// before they are deleted; 0 drops its endpoints as soon as the cluster is unreachable
+	StaleEndpointTTL time.Duration
+	// DisabledClusterSlices is what happens to the EndpointSlices imported from disabled ClusterLinks,
+	// one of the DisabledClusterSlices* values
+	DisabledClusterSlices string
+	// Preflight is what happens when the startup preflight finds hazardous ClusterLinks, one of the Preflight* values
+	Preflight string
+	// PublishNotReadyAddresses is whether not-ready remote endpoints are imported, one of the
+	// PublishNotReadyAddresses* values
+	PublishNotReadyAddresses string
+	// PortMergePolicy is how the ports of a service exported by several clusters with different ports are merged,
+	// one of the PortMergePolicy* values
+	PortMergePolicy string
+	// FixtureDir is a directory of fixture files the remote clusters are loaded from instead of the ClusterLinks,
+	// for testing against the local cluster only
+	FixtureDir string
 }
 
 const (
-	// SyncAnnotation is the annotation key to mark services for sync
+	// DisabledClusterSlicesRetain leaves the EndpointSlices imported from disabled ClusterLinks as they are
+	DisabledClusterSlicesRetain = "retain"
+	// DisabledClusterSlicesDelete deletes the EndpointSlices imported from disabled ClusterLinks
+	DisabledClusterSlicesDelete = "delete"
+)
+
+const (
+	// PreflightRefuse refuses to start when the preflight finds hazardous ClusterLinks
+	PreflightRefuse = "refuse"
+	// PreflightDegrade starts without syncing the hazardous ClusterLinks, which report the hazard in their status
+	PreflightDegrade = "degrade"
+	// PreflightOff skips the preflight
+	PreflightOff = "off"
+)
+
+const (
+	// PublishNotReadyAddressesService imports the not-ready endpoints of the remote services that set
+	// spec.publishNotReadyAddresses
+	PublishNotReadyAddressesService = "service"
+	// PublishNotReadyAddressesAlways imports the not-ready endpoints of every remote service
+	PublishNotReadyAddressesAlways = "always"
+	// PublishNotReadyAddressesNever imports ready endpoints only, whatever the remote services set
+	PublishNotReadyAddressesNever = "never"
+)
+
+const (
+	// PortMergePolicyUnion imports every port exported by any cluster
+	PortMergePolicyUnion = "union"
+	// PortMergePolicyIntersection imports the ports exported by every cluster only
+	PortMergePolicyIntersection = "intersection"
+	// PortMergePolicyFirstCluster imports the ports of the first cluster in name order exporting the service
+	PortMergePolicyFirstCluster = "first-cluster-wins"
+)
+
+// DefaultPrometheusAnnotations are the conventional Prometheus scrape annotations
+var DefaultPrometheusAnnotations = []string{
+	"prometheus.io/scrape",
+	"prometheus.io/port",
+	"prometheus.io/path",
+	"prometheus.io/scheme",
+}
+
+const (
+	// SyncAnnotation is the annotation key to mark services for sync. Remote services set it to "false" to opt out.
 	SyncAnnotation = "cloudpilot.ai/svclink"
+	// TargetClustersAnnotation is the annotation key listing the comma-separated --cluster-name of the
+	// svclink deployments allowed to import a remote service. Every deployment imports it when absent.
+	TargetClustersAnnotation = "cloudpilot.ai/svclink-target-clusters"
+	// SourceNamespaceAnnotation is the annotation key recording the remote namespace of the endpoints of an
+	// EndpointSlice, when a namespace mapping imports them into a differently named local namespace
+	SourceNamespaceAnnotation = "cloudpilot.ai/svclink-source-namespace"
+	// SourceClusterAnnotation is the annotation key recording the full name of the remote cluster whose endpoints
+	// an EndpointSlice holds, as the cluster label only holds a hash of names too long for a label value
+	SourceClusterAnnotation = "cloudpilot.ai/svclink-source-cluster"
+	// EndpointQuotaAnnotation is the annotation key of a local Namespace overriding the maximum number of endpoints
+	// svclink publishes into it; "0" disables the quota for the namespace
+	EndpointQuotaAnnotation = "cloudpilot.ai/svclink-endpoint-quota"
+	// PinnedClustersAnnotation is the annotation key of a local Service listing the comma-separated clusters its
+	// endpoints are exclusively imported from until PinnedUntilAnnotation, set by "svclink pin"
+	PinnedClustersAnnotation = "cloudpilot.ai/svclink-pinned-clusters"
+	// SyncedLabelsAnnotation is the annotation key of a Service created by svclink listing the comma-separated label
+	// keys it copied from the remote service, so that only those are removed once the remote service drops them
+	SyncedLabelsAnnotation = "cloudpilot.ai/svclink-synced-labels"
+	// HeadlessAnnotation is the annotation key of a remote service overriding whether the Service svclink creates
+	// for it in the local cluster is headless; without it, the local Service is headless if the remote one is
+	HeadlessAnnotation = "cloudpilot.ai/svclink-headless"
+	// PinnedUntilAnnotation is the annotation key of a local Service holding the RFC 3339 expiry of its pin
+	PinnedUntilAnnotation = "cloudpilot.ai/svclink-pinned-until"
+	// SyncNowAnnotation is the annotation key of a ClusterLink or local Service whose changes request an immediate
+	// sync of all services or of the Service, set by "svclink sync"
+	SyncNowAnnotation = "cloudpilot.ai/svclink-sync-now"
+	// StaleSinceAnnotation is the annotation key of an imported EndpointSlice holding the RFC 3339 time since which
+	// its cluster is unreachable, while its last known endpoints are kept for --stale-endpoint-ttl
+	StaleSinceAnnotation = "cloudpilot.ai/svclink-stale-since"
+	// SourceSlicesAnnotation is the annotation key of an imported EndpointSlice listing the remote EndpointSlices
+	// its endpoints were read from, as comma-separated name=resourceVersion/generation
+	SourceSlicesAnnotation = "cloudpilot.ai/svclink-source-slices"
+	// LastSyncedAnnotation is the annotation key of an imported EndpointSlice holding the RFC 3339 time svclink last
+	// changed it
+	LastSyncedAnnotation = "cloudpilot.ai/svclink-last-synced"
+	// ShardLabel is the label key of a ClusterLink pinning it to a shard, from 0 to --shard-count minus 1, instead of
+	// the shard its name hashes to
+	ShardLabel = "cloudpilot.ai/svclink-shard"
+	// SimulatedDisconnectAnnotation is the annotation key of a ClusterLink holding the RFC 3339 time until which
+	// the controller treats the cluster as unreachable, set by "svclink chaos disconnect"
+	SimulatedDisconnectAnnotation = "cloudpilot.ai/svclink-simulated-disconnect-until"
 	// ClusterLabel is the label key to identify which cluster an EndpointSlice belongs to
 	ClusterLabel = "cloudpilot.ai/svclink-cluster"
 	// ServiceNameLabel is the standard Kubernetes label for service name
 	ServiceNameLabel = "kubernetes.io/service-name"
+	// DiscoverySourceLabel is the label key identifying the cloud provider scope a ClusterLink was discovered from
+	DiscoverySourceLabel = "svclink.cloudpilot.ai/discovery-source"
 	// ManagedByLabel is the standard Kubernetes label for identifying the controller managing the resource
 	ManagedByLabel = "endpointslice.kubernetes.io/managed-by"
 	// ManagedByValue is the value used in the managed-by label for svclink-created EndpointSlices
 	ManagedByValue = "svclink.cloudpilot.ai"
 	// DefaultSyncInterval is the default interval for periodic sync operations
 	DefaultSyncInterval = 30 * time.Second
+	// DefaultSyncJitter is the default maximum fraction of the sync interval added to each interval
+	DefaultSyncJitter = 0.1
+	// DefaultServiceFailureBudget is the default number of consecutive failed syncs before a service is backed off
+	DefaultServiceFailureBudget = 5
+	// DefaultServiceFailureRetryInterval is the default retry interval of services that exhausted their failure budget
+	DefaultServiceFailureRetryInterval = 10 * time.Minute
+	// DefaultServiceRetryBaseDelay is the default backoff of the first retry of a failed service
+	DefaultServiceRetryBaseDelay = time.Second
+	// DefaultServiceRetryMaxDelay is the default maximum backoff between retries of a failed service
+	DefaultServiceRetryMaxDelay = 5 * time.Minute
+	// DefaultVerificationInterval is the default interval between EndpointSlice verifications
+	DefaultVerificationInterval = 30 * time.Minute
+	// DefaultMaxEndpointsPerSlice is the default maximum number of endpoints per EndpointSlice, the default of
+	// kube-controller-manager
+	DefaultMaxEndpointsPerSlice = 100
+	// MaxEndpointsPerSliceLimit is the maximum number of endpoints the API server accepts in an EndpointSlice
+	MaxEndpointsPerSliceLimit = 1000
+	// DefaultSyncedServiceRetention is the default retention of orphaned Services created by svclink
+	DefaultSyncedServiceRetention = time.Hour
+	// DefaultSliceWriteBurst is the default number of EndpointSlice writes sent in a burst above SliceWriteQPS
+	DefaultSliceWriteBurst = 50
+	// DefaultListPageSize is the default number of objects per page of remote lists when MemoryLimit is set
+	DefaultListPageSize = 500
+	// DefaultShutdownGracePeriod is the default time in-flight syncs are given to finish on shutdown, below the
+	// default terminationGracePeriodSeconds of Pods
+	DefaultShutdownGracePeriod = 20 * time.Second
+	// DefaultLeaderElectionID is the default name of the Lease used for leader election
+	DefaultLeaderElectionID = "svclink-leader"
+	// DefaultMetricsPort is the default port of the metrics and admin endpoints
+	DefaultMetricsPort = 8080
+	// DefaultSyncWorkers is the default number of services synced concurrently
+	DefaultSyncWorkers = 4
+	// DefaultDiscoveryWorkers is the default number of clusters discovered concurrently
+	DefaultDiscoveryWorkers = 8
+	// DefaultCapabilityCacheTTL is the default lifetime of cached remote cluster capabilities
+	DefaultCapabilityCacheTTL = 10 * time.Minute
+	// DefaultClusterDiscoveryInterval is the default interval between cloud provider cluster listings
+	DefaultClusterDiscoveryInterval = 5 * time.Minute
+	// DefaultCredentialsExpiryWindow is the default window before client certificate expiry in which
+	// the CredentialsExpiring condition is raised
+	DefaultCredentialsExpiryWindow = 7 * 24 * time.Hour
+	// DefaultStatusHistoryRetention is the default retention of resolved episodes in the status of ClusterLinks
+	DefaultStatusHistoryRetention = 24 * time.Hour
+	// DefaultStatusHistoryLimit is the default maximum number of episodes in the status of a ClusterLink
+	DefaultStatusHistoryLimit = 20
+	// DefaultStatusErrorLimit is the default maximum number of errors in the status of a ClusterLink
+	DefaultStatusErrorLimit = 10
+	// DefaultCircuitBreakerThreshold is the default number of consecutive failures after which a cluster is degraded
+	DefaultCircuitBreakerThreshold = 5
+	// DefaultCircuitBreakerProbeInterval is the default interval at which degraded clusters are probed
+	DefaultCircuitBreakerProbeInterval = 5 * time.Minute
+	// DefaultReachabilitySampleSize is the default number of endpoints dialed per cluster by the reachability probe
+	DefaultReachabilitySampleSize = 5
+	// DefaultReachabilityTimeout is the default dial timeout of the reachability probe
+	DefaultReachabilityTimeout = 2 * time.Second
+	// DefaultStatusHeartbeatInterval is the default interval at which LastConnected and LastSyncTime are refreshed
+	DefaultStatusHeartbeatInterval = 5 * time.Minute
 )
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/clusterdiscovery"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// clusterConnectionReconciler turns ClusterLinks into connected remote clusters
+type clusterConnectionReconciler struct {
+	ctrlClient     client.Client
+	links          *clusterlink.Links
+	cfg            *config.Config
+	capiDiscoverer *clusterdiscovery.CAPIDiscoverer
+	recorder       record.EventRecorder
+	bus            *eventBus
+}
+
+func newClusterConnectionReconciler(ctrlClient client.Client, links *clusterlink.Links, cfg *config.Config, capiDiscoverer *clusterdiscovery.CAPIDiscoverer,
+	recorder record.EventRecorder, bus *eventBus) *clusterConnectionReconciler {
+	return &clusterConnectionReconciler{
+		ctrlClient:     ctrlClient,
+		links:          links,
+		cfg:            cfg,
+		capiDiscoverer: capiDiscoverer,
+		recorder:       recorder,
+		bus:            bus,
+	}
+}
+
+// reconcile connects to every linked cluster and publishes the result
+func (r *clusterConnectionReconciler) reconcile(ctx context.Context) error {
+	if r.cfg.FixtureDir != "" {
+		return r.reconcileFixtures(ctx)
+	}
+
+	// Cluster API clusters are read from the cache, so new workload clusters are linked within the same cycle
+	if r.capiDiscoverer != nil {
+		if err := r.capiDiscoverer.Reconcile(ctx); err != nil {
+			klog.Errorf("Failed to discover Cluster API clusters: %v", err)
+		}
+	}
+
+	if r.cfg.NormalizeKubeconfigs {
+		if err := clusterlink.NormalizeKubeconfigs(ctx, r.ctrlClient); err != nil {
+			klog.Errorf("Failed to normalize ClusterLink kubeconfigs: %v", err)
+		}
+	}
+
+	clusterInfos, inactive, err := r.links.ListClusterInfo(ctx, r.ctrlClient)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster info: %w", err)
+	}
+	for _, skew := range inactive.VersionSkews {
+		r.recorder.Eventf(skew.ClusterLink, corev1.EventTypeWarning, "VersionSkew", "%s", skew.Message)
+	}
+
+	// The EndpointSlices of paused clusters and of the clusters of other shards are left as they are
+	event := clustersConnected{clusterInfos: clusterInfos, retained: inactive.Paused.Union(inactive.Unowned), removed: sets.New[string]()}
+	if r.cfg.DisabledClusterSlices == config.DisabledClusterSlicesDelete {
+		event.removed = inactive.Disabled
+	} else {
+		event.retained = event.retained.Union(inactive.Disabled)
+	}
+	event.stale, event.removed = staleClusters(inactive.Unreachable, r.cfg.StaleEndpointTTL, time.Now(), event.removed)
+	event.retained = event.retained.Union(sets.KeySet(event.stale))
+	return r.bus.clustersConnected.publish(ctx, event)
+}
+
+// staleClusters returns the unreachable clusters whose imported EndpointSlices are kept, those unreachable for
+// less than ttl, and adds the clusters unreachable for longer to removed. Without ttl, the endpoints of
+// unreachable clusters are dropped by the sync of their services.
+func staleClusters(unreachable map[string]time.Time, ttl time.Duration, now time.Time, removed sets.Set[string]) (map[string]time.Time, sets.Set[string]) {
+	stale := make(map[string]time.Time)
+	if ttl <= 0 {
+		return stale, removed
+	}
+	for cluster, since := range unreachable {
+		if now.Sub(since) < ttl {
+			stale[cluster] = since
+			continue
+		}
+		klog.V(2).Infof("Cluster %s is unreachable since %s, deleting the EndpointSlices imported from it", cluster, since.Format(time.RFC3339))
+		removed = removed.Union(sets.New(cluster))
+	}
+	return stale, removed
+}
+
+// reconcileFixtures publishes the remote clusters loaded from the fixture directory in place of the ClusterLinks.
+// Fixtures are read again every cycle, so that edits are picked up without a restart.
+func (r *clusterConnectionReconciler) reconcileFixtures(ctx context.Context) error {
+	clusterInfos, err := clusterlink.LoadFixtureClusters(r.cfg.FixtureDir)
+	if err != nil {
+		return fmt.Errorf("failed to load fixture clusters: %w", err)
+	}
+
+	return r.bus.clustersConnected.publish(ctx, clustersConnected{
+		clusterInfos: clusterInfos,
+		retained:     sets.New[string](),
+		removed:      sets.New[string](),
+		stale:        make(map[string]time.Time),
+	})
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestStaleClusters(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	unreachable := map[string]time.Time{
+		"recent":  now.Add(-time.Minute),
+		"expired": now.Add(-10 * time.Minute),
+	}
+
+	stale, removed := staleClusters(unreachable, 5*time.Minute, now, sets.New("disabled"))
+	if len(stale) != 1 || !stale["recent"].Equal(unreachable["recent"]) {
+		t.Errorf("expected only the recently unreachable cluster to be stale, got %v", stale)
+	}
+	if !removed.Equal(sets.New("disabled", "expired")) {
+		t.Errorf("expected the expired cluster to be removed, got %v", sets.List(removed))
+	}
+
+	stale, removed = staleClusters(unreachable, 0, now, sets.New[string]())
+	if len(stale) != 0 || removed.Len() != 0 {
+		t.Errorf("expected no stale or removed clusters without a TTL, got %v %v", stale, sets.List(removed))
+	}
+}
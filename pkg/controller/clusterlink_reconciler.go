@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// clusterLinkReconciler reacts to ClusterLink changes between sync cycles: a created or updated ClusterLink is
+// connected to and validated right away, so its status reflects the new spec within seconds, and a deleted
+// ClusterLink is torn down by dropping its remote client and per-cluster state and deleting the EndpointSlices
+// imported from its cluster. The services of the cluster are still synced by the sync cycles, and a new sync now
+// annotation on a ClusterLink starts a sync cycle right away.
+type clusterLinkReconciler struct {
+	ctrlClient   client.Client
+	links        *clusterlink.Links
+	sliceUpdater *updater.SliceUpdater
+	recorder     record.EventRecorder
+	// cycle is held by sync cycles and the reconciles of ClusterLinks
+	cycle *sync.Mutex
+	// requestSync requests an immediate sync cycle
+	requestSync func()
+}
+
+func newClusterLinkReconciler(ctrlClient client.Client, links *clusterlink.Links, sliceUpdater *updater.SliceUpdater, recorder record.EventRecorder,
+	cycle *sync.Mutex, requestSync func()) *clusterLinkReconciler {
+	return &clusterLinkReconciler{
+		ctrlClient:   ctrlClient,
+		links:        links,
+		sliceUpdater: sliceUpdater,
+		recorder:     recorder,
+		cycle:        cycle,
+		requestSync:  requestSync,
+	}
+}
+
+// setupWithManager watches ClusterLinks. Status updates do not change the generation and are left out. Sync
+// requests do not change the generation either and are handled without a reconcile.
+func (r *clusterLinkReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusterlink").
+		For(&svclinkv1alpha1.ClusterLink{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&svclinkv1alpha1.ClusterLink{}, handler.Funcs{
+			UpdateFunc: func(_ context.Context, e event.UpdateEvent, _ workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+				if config.SyncRequested(e.ObjectOld, e.ObjectNew) {
+					klog.Infof("Sync requested on ClusterLink %s", e.ObjectNew.GetName())
+					r.requestSync()
+				}
+			},
+		}).
+		Complete(r)
+}
+
+// Reconcile connects to the cluster of a ClusterLink, or tears it down once the ClusterLink is deleted
+func (r *clusterLinkReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	r.cycle.Lock()
+	defer r.cycle.Unlock()
+
+	inactive, err := r.links.ConnectClusterLink(ctx, r.ctrlClient, req.Name)
+	if apierrors.IsNotFound(err) {
+		return reconcile.Result{}, r.teardown(ctx, req.Name)
+	}
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to connect to cluster %s: %w", req.Name, err)
+	}
+	if inactive.Unowned.Has(req.Name) {
+		// The ClusterLink may have been moved to another shard, which takes over its EndpointSlices
+		r.links.ForgetClusterLink(req.Name)
+		return reconcile.Result{}, nil
+	}
+	for _, skew := range inactive.VersionSkews {
+		r.recorder.Eventf(skew.ClusterLink, corev1.EventTypeWarning, "VersionSkew", "%s", skew.Message)
+	}
+	return reconcile.Result{}, nil
+}
+
+// teardown forgets a deleted ClusterLink and deletes the EndpointSlices imported from its cluster
+func (r *clusterLinkReconciler) teardown(ctx context.Context, name string) error {
+	klog.Infof("ClusterLink %s was deleted, removing the EndpointSlices imported from its cluster", name)
+	r.links.ForgetClusterLink(name)
+	if err := r.sliceUpdater.DeleteClusterSlices(ctx, name); err != nil {
+		return fmt.Errorf("failed to delete EndpointSlices of cluster %s: %w", name, err)
+	}
+	return nil
+}
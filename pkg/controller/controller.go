@@ -6,37 +6,56 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	utilserrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
-	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
 	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterdiscovery"
 	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
 	"github.com/cloudpilot-ai/svclink/pkg/config"
 	"github.com/cloudpilot-ai/svclink/pkg/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
 	"github.com/cloudpilot-ai/svclink/pkg/updater"
 )
 
-// Controller is the main svclink controller
+// Controller is the main svclink controller. A sync cycle is split across reconcilers that
+// communicate through an event bus: cluster connection, service discovery, service mirroring
+// and endpoint publication.
 type Controller struct {
 	ctrlClient client.Client
+	apiReader  client.Reader
+	// links connects to the linked clusters and keeps their state across sync cycles
+	links *clusterlink.Links
 
 	cfg               *config.Config
 	manager           ctrl.Manager
-	serviceDiscoverer *discoverer.ServiceDiscoverer
-	aggregator        *aggregator.EndpointAggregator
-	sliceUpdater      *updater.SliceUpdater
-	serviceUpdater    *updater.ServiceUpdater
+	clusterDiscoverer *clusterdiscovery.Discoverer
+	bus               *eventBus
+	// cycle serializes sync cycles and the targeted syncs of local Services
+	cycle *sync.Mutex
+	// syncNow carries the requests for an immediate sync cycle
+	syncNow chan struct{}
+
+	clusterConnection   *clusterConnectionReconciler
+	serviceDiscovery    *serviceDiscoveryReconciler
+	serviceMirroring    *serviceMirroringReconciler
+	endpointPublication *endpointPublicationReconciler
+	syncSetDiff         *syncSetDiffReporter
+	orphans             *orphanTracker
+	sliceGC             *sliceGarbageCollector
 }
 
 // newScheme creates and registers all required schemes
@@ -67,36 +86,108 @@ func NewController(cfg *config.Config, restConfig *rest.Config) (*Controller, er
 	// Create controller-runtime manager
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: runtimeScheme,
+		Metrics: metricsserver.Options{
+			BindAddress: cfg.MetricsBindAddress,
+		},
+		LeaderElection:                cfg.LeaderElection,
+		LeaderElectionNamespace:       cfg.LeaderElectionNamespace,
+		LeaderElectionID:              cfg.LeaderElectionID,
+		LeaderElectionReleaseOnCancel: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create manager: %w", err)
 	}
 
-	serviceDiscoverer := discoverer.NewServiceDiscoverer(mgr.GetClient())
-	aggregator := aggregator.NewEndpointAggregator(mgr.GetClient())
-	sliceUpdater := updater.NewSliceUpdater(mgr.GetClient())
-	serviceUpdater := updater.NewServiceUpdater(mgr.GetClient())
+	links := clusterlink.NewLinks(mgr.GetAPIReader(), cfg)
+	serviceDiscoverer, err := discoverer.NewServiceDiscoverer(mgr.GetClient(), links, cfg)
+	if err != nil {
+		return nil, err
+	}
+	serviceDiscoverer.SetClusterOffset(newSplay(cfg.SyncSplay).offset)
+	if err := mgr.AddMetricsServerExtraHandler(discoverer.FilteredPath, serviceDiscoverer.FilteredHandler()); err != nil {
+		return nil, fmt.Errorf("failed to register filtered services endpoint: %w", err)
+	}
+	aggregator := aggregator.NewEndpointAggregator(mgr.GetClient(), links, cfg)
+	sliceUpdater := updater.NewSliceUpdater(mgr.GetClient(), mgr.GetAPIReader(), cfg, mgr.GetEventRecorderFor("svclink"))
+	serviceUpdater := updater.NewServiceUpdater(mgr.GetClient(), cfg)
+
+	var clusterDiscoverer *clusterdiscovery.Discoverer
+	if len(cfg.ClusterDiscoveryProviders) > 0 {
+		providers := make([]clusterdiscovery.Provider, 0, len(cfg.ClusterDiscoveryProviders))
+		for _, spec := range cfg.ClusterDiscoveryProviders {
+			provider, err := clusterdiscovery.NewProvider(spec)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, provider)
+		}
+		clusterDiscoverer = clusterdiscovery.NewDiscoverer(mgr.GetClient(), cfg.ClusterDiscoveryNamespace, providers)
+	}
+
+	var capiDiscoverer *clusterdiscovery.CAPIDiscoverer
+	if cfg.CAPIDiscovery {
+		capiDiscoverer = clusterdiscovery.NewCAPIDiscoverer(mgr.GetClient(), mgr.GetAPIReader())
+	}
+
+	bus := &eventBus{}
+	orphans := newOrphanTracker(mgr.GetClient(), cfg, sliceUpdater, serviceUpdater, bus)
+	if err := mgr.AddMetricsServerExtraHandler(OrphansPath, orphans); err != nil {
+		return nil, fmt.Errorf("failed to register orphaned services endpoint: %w", err)
+	}
+	cycle := &sync.Mutex{}
+	syncNow := make(chan struct{}, 1)
+	localServices := newLocalServiceReconciler(cycle, sliceUpdater, bus)
+	if err := localServices.setupWithManager(mgr); err != nil {
+		return nil, fmt.Errorf("failed to watch local services: %w", err)
+	}
+	links.OnEndpointSliceChange(localServices.remoteChanged)
+	if cfg.FixtureDir == "" {
+		clusterLinks := newClusterLinkReconciler(mgr.GetClient(), links, sliceUpdater, mgr.GetEventRecorderFor("svclink"), cycle,
+			func() { requestSync(syncNow) })
+		if err := clusterLinks.setupWithManager(mgr); err != nil {
+			return nil, fmt.Errorf("failed to watch ClusterLinks: %w", err)
+		}
+	}
 
 	return &Controller{
 		ctrlClient: mgr.GetClient(),
+		apiReader:  mgr.GetAPIReader(),
+		links:      links,
 
 		cfg:               cfg,
 		manager:           mgr,
-		serviceDiscoverer: serviceDiscoverer,
-		aggregator:        aggregator,
-		sliceUpdater:      sliceUpdater,
-		serviceUpdater:    serviceUpdater,
+		clusterDiscoverer: clusterDiscoverer,
+		bus:               bus,
+		cycle:             cycle,
+		syncNow:           syncNow,
+
+		clusterConnection: newClusterConnectionReconciler(mgr.GetClient(), links, cfg, capiDiscoverer,
+			mgr.GetEventRecorderFor("svclink"), bus),
+		serviceDiscovery: newServiceDiscoveryReconciler(mgr.GetClient(), links, cfg, serviceDiscoverer,
+			mgr.GetEventRecorderFor("svclink"), bus),
+		serviceMirroring: newServiceMirroringReconciler(mgr.GetClient(), cfg, serviceUpdater, bus),
+		endpointPublication: newEndpointPublicationReconciler(mgr.GetClient(), links, cfg, aggregator, sliceUpdater,
+			mgr.GetEventRecorderFor("svclink"), bus),
+		syncSetDiff: newSyncSetDiffReporter(mgr.GetEventRecorderFor("svclink"), bus),
+		orphans:     orphans,
+		sliceGC:     newSliceGarbageCollector(cfg, sliceUpdater, bus),
 	}, nil
 }
 
-// Run starts the controller
+// Run starts the controller. Once ctx is cancelled no new sync starts, and the in-flight syncs are drained for
+// up to the shutdown grace period before they are aborted and the manager, along with the leader election Lease,
+// is stopped.
 func (c *Controller) Run(ctx context.Context) error {
 	klog.Info("Starting svclink controller")
 
+	// The manager and the syncs outlive ctx until the in-flight syncs are drained
+	runCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	defer stop()
+
 	// Start the controller-runtime manager (handles ClusterLink events)
 	go func() {
 		klog.Info("Starting controller-runtime manager")
-		if err := c.manager.Start(ctx); err != nil {
+		if err := c.manager.Start(runCtx); err != nil {
 			klog.Fatalf("Failed to start manager: %v", err)
 		}
 	}()
@@ -107,129 +198,169 @@ func (c *Controller) Run(ctx context.Context) error {
 	}
 	klog.Info("Manager cache synced")
 
-	// Start sync loop for service synchronization
-	go c.syncLoop(ctx)
+	if err := c.preflight(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		if c.cfg.LeaderElection && c.cfg.HotStandby {
+			c.standbyLoop(ctx)
+		}
+
+		// Only the leader writes, Elected is closed right away when leader election is disabled
+		select {
+		case <-c.manager.Elected():
+		case <-ctx.Done():
+			return
+		}
+		if c.cfg.LeaderElection {
+			klog.Info("Elected as leader")
+		}
+
+		// Start materializing ClusterLinks for clusters discovered from cloud providers
+		if c.clusterDiscoverer != nil {
+			go c.clusterDiscoverer.Run(ctx, c.cfg.ClusterDiscoveryInterval)
+		}
+
+		// Start sync loop for service synchronization
+		c.syncLoop(ctx, runCtx)
+	}()
 
 	<-ctx.Done()
 	klog.Info("Shutting down svclink controller")
+	c.drain(stop)
 	return nil
 }
 
-// syncLoop runs the sync process periodically
-func (c *Controller) syncLoop(ctx context.Context) {
-	// Run sync immediately and then periodically
-	wait.UntilWithContext(ctx, c.sync, c.cfg.SyncInterval)
+// drain waits for the in-flight sync cycle, targeted syncs and service retries to finish. Syncs still running
+// after the shutdown grace period are aborted through stop; the services they interrupted are logged and synced
+// again by the next leader.
+func (c *Controller) drain(stop context.CancelFunc) {
+	drained := make(chan struct{})
+	go func() {
+		// The cycle lock is kept, so that no targeted sync starts once the in-flight syncs are drained
+		c.cycle.Lock()
+		c.endpointPublication.services.drain()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		klog.Info("In-flight syncs drained")
+	case <-time.After(c.cfg.ShutdownGracePeriod):
+		klog.Warningf("In-flight syncs did not finish within the shutdown grace period of %s, aborting them", c.cfg.ShutdownGracePeriod)
+		stop()
+		<-drained
+	}
+	stop()
+	c.cycle.Unlock()
 }
 
-// sync performs one sync cycle
-func (c *Controller) sync(ctx context.Context) {
-	klog.Info("Starting sync cycle")
+// preflight checks the ClusterLinks for configurations that would create sync loops or collisions at runtime.
+// Depending on the preflight mode, hazards refuse the start or exclude the hazardous ClusterLinks from sync.
+func (c *Controller) preflight(ctx context.Context) error {
+	if c.cfg.Preflight == config.PreflightOff || c.cfg.FixtureDir != "" {
+		return nil
+	}
 
-	clusterInfos, err := clusterlink.ListClusterInfo(ctx, c.ctrlClient)
+	hazards, err := c.links.Preflight(ctx, c.ctrlClient)
 	if err != nil {
-		klog.Errorf("Failed to list cluster info: %v", err)
-		return
+		return fmt.Errorf("preflight failed: %w", err)
+	}
+	if len(hazards) == 0 {
+		klog.Info("Preflight found no hazardous ClusterLinks")
+		return nil
 	}
 
-	// Discover which remote clusters have these services
-	klog.Info("Discovering services across clusters")
-	services, err := c.serviceDiscoverer.DiscoverServices(ctx, clusterInfos, c.cfg.IncludedNamespaces)
-	if err != nil {
-		klog.Errorf("Failed to discover services: %v", err)
-		return
+	messages := make([]string, 0, len(hazards))
+	for _, hazard := range hazards {
+		messages = append(messages, hazard.String())
+	}
+	if c.cfg.Preflight == config.PreflightRefuse {
+		return fmt.Errorf("refusing to start, preflight found hazardous ClusterLinks (use --preflight=degrade to skip them): %s",
+			strings.Join(messages, "; "))
 	}
 
-	if c.cfg.SyncServicesToLocalCluster {
-		klog.Info("Syncing services to local cluster")
-		if err := c.serviceUpdater.SyncServicesToLocalCluster(ctx, services); err != nil {
-			klog.Errorf("Failed to update services in local cluster: %v", err)
-			return
+	klog.Warningf("Preflight found hazardous ClusterLinks, they are not synced: %s", strings.Join(messages, "; "))
+	c.links.SetHazards(hazards)
+	return nil
+}
+
+// syncLoop runs the sync process periodically until ctx is cancelled, or right away when a sync is requested.
+// Sync cycles run with runCtx, so that a cycle in flight when ctx is cancelled is drained rather than
+// interrupted. Intervals are jittered, so that instances started together drift apart instead of listing shared
+// remote clusters in lockstep.
+func (c *Controller) syncLoop(ctx, runCtx context.Context) {
+	for {
+		// Run sync immediately and then periodically
+		c.sync(runCtx)
+
+		interval := c.cfg.SyncInterval
+		if c.cfg.SyncJitter > 0 {
+			interval = wait.Jitter(interval, c.cfg.SyncJitter)
 		}
-	} else {
-		filteredServices, err := c.filterServicesExistingInLocalCluster(ctx, c.cfg.IncludedNamespaces, services)
-		if err != nil {
-			klog.Errorf("Failed to filter services: %v", err)
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
 			return
+		case <-c.syncNow:
+			timer.Stop()
+			klog.Info("Sync requested, starting a sync cycle ahead of the interval")
+		case <-timer.C:
 		}
-		services = filteredServices
 	}
+}
 
-	// For each service, aggregate endpoints and update EndpointSlices
-	klog.Info("Aggregating endpoints and updating EndpointSlices")
-	errs := make([]error, 0)
-	for key, svcInfo := range services {
-		if err := c.syncService(ctx, svcInfo, clusterInfos); err != nil {
-			errs = append(errs, fmt.Errorf("failed to sync service %s: %v", key, err))
-		}
+// requestSync requests an immediate sync cycle. Requests made while one is pending are merged into it.
+func requestSync(syncNow chan<- struct{}) {
+	select {
+	case syncNow <- struct{}{}:
+	default:
 	}
+}
 
-	if len(errs) > 0 {
-		klog.Errorf("Sync cycle completed with errors: %v", utilserrors.NewAggregate(errs))
-		return
-	}
+// sync performs one sync cycle. The cluster connection reconciler starts the cycle, the other
+// reconcilers run as the events they subscribe to are published.
+func (c *Controller) sync(ctx context.Context) {
+	c.cycle.Lock()
+	defer c.cycle.Unlock()
 
-	klog.Infof("Sync cycle completed, processed %d services", len(services))
-}
+	klog.Info("Starting sync cycle")
 
-// syncService syncs a single service
-func (c *Controller) syncService(ctx context.Context, svcInfo *apisdiscoverer.ServiceInfo, clusterInfos map[string]*clusterlink.ClusterInfo) error {
-	klog.V(4).Infof("Syncing service %s/%s from clusters: %v",
-		svcInfo.Namespace, svcInfo.Name, svcInfo.Clusters)
-
-	// Aggregate endpoints from all clusters
-	clusterEndpoints, err := c.aggregator.AggregateEndpoints(
-		ctx,
-		svcInfo.Namespace,
-		svcInfo.Name,
-		svcInfo.Clusters,
-		clusterInfos,
-	)
-	if err != nil {
-		return err
-	}
+	c.refreshDebugSettings(ctx)
 
-	// Update EndpointSlices
-	if err := c.sliceUpdater.UpdateEndpointSlices(
-		ctx,
-		svcInfo.Namespace,
-		svcInfo.Name,
-		clusterEndpoints,
-	); err != nil {
-		return err
+	if err := c.clusterConnection.reconcile(ctx); err != nil {
+		klog.Errorf("Sync cycle completed with errors: %v", err)
+		return
 	}
 
-	return nil
+	klog.Info("Sync cycle completed")
 }
 
-// filterServicesExistingInLocalCluster filters the services map to only include services
-// that exist in the local cluster. This ensures EndpointSlices are only created for
-// services that have a corresponding Service object in the local cluster.
-func (c *Controller) filterServicesExistingInLocalCluster(ctx context.Context, includedNamespaces []string, services map[string]*apisdiscoverer.ServiceInfo) (map[string]*apisdiscoverer.ServiceInfo, error) {
-	var svcList corev1.ServiceList
-	if err := c.ctrlClient.List(ctx, &svcList); err != nil {
-		return nil, err
+// refreshDebugSettings applies the verbosity and debug targets of the debug ConfigMap, if configured.
+// The ConfigMap is read directly from the API server to avoid caching ConfigMaps cluster-wide.
+func (c *Controller) refreshDebugSettings(ctx context.Context) {
+	if c.cfg.DebugConfigMap == "" {
+		return
 	}
 
-	// Build a set of local services for efficient lookup
-	localServices := make(map[string]struct{})
-	includedNSSet := sets.New(includedNamespaces...)
-
-	for _, svc := range svcList.Items {
-		// Check if the service is in an included namespace
-		if includedNSSet.Len() > 0 && !includedNSSet.Has(svc.Namespace) {
-			continue
-		}
-		key := svc.Namespace + "/" + svc.Name
-		localServices[key] = struct{}{}
+	namespace, name, err := cache.SplitMetaNamespaceKey(c.cfg.DebugConfigMap)
+	if err != nil {
+		klog.Errorf("Invalid debug ConfigMap %q: %v", c.cfg.DebugConfigMap, err)
+		return
 	}
 
-	// Filter services to only include those that exist locally
-	filtered := make(map[string]*apisdiscoverer.ServiceInfo)
-	for key, svcInfo := range services {
-		if _, existsLocally := localServices[key]; existsLocally {
-			filtered[key] = svcInfo
+	cm := &corev1.ConfigMap{}
+	if err := c.apiReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Failed to get debug ConfigMap %s: %v", c.cfg.DebugConfigMap, err)
+			return
 		}
+		cm = nil
 	}
 
-	return filtered, nil
+	if err := logging.ApplyConfigMap(cm); err != nil {
+		klog.Errorf("Failed to apply debug ConfigMap %s: %v", c.cfg.DebugConfigMap, err)
+	}
 }
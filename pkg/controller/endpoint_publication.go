@@ -0,0 +1,425 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// endpointPublicationReconciler aggregates the endpoints of mirrored services and publishes them as EndpointSlices
+type endpointPublicationReconciler struct {
+	ctrlClient    client.Client
+	links         *clusterlink.Links
+	cfg           *config.Config
+	aggregator    *aggregator.EndpointAggregator
+	sliceUpdater  *updater.SliceUpdater
+	failureBudget *failureBudget
+	endpointQuota *endpointQuota
+	portConflicts *portConflicts
+	syncCounts    *syncCounts
+	reachability  *reachabilityProber
+	recorder      record.EventRecorder
+	// services syncs and retries services one by one
+	services *serviceQueue
+
+	// lastVerification is when managed EndpointSlices were last verified against the remote clusters
+	lastVerification time.Time
+	// verifying tells whether managed EndpointSlices are verified in the current sync cycle
+	verifying bool
+}
+
+func newEndpointPublicationReconciler(
+	ctrlClient client.Client,
+	links *clusterlink.Links,
+	cfg *config.Config,
+	aggregator *aggregator.EndpointAggregator,
+	sliceUpdater *updater.SliceUpdater,
+	recorder record.EventRecorder,
+	bus *eventBus,
+) *endpointPublicationReconciler {
+	r := &endpointPublicationReconciler{
+		ctrlClient:    ctrlClient,
+		links:         links,
+		cfg:           cfg,
+		aggregator:    aggregator,
+		sliceUpdater:  sliceUpdater,
+		failureBudget: newFailureBudget(cfg.ServiceFailureBudget, cfg.ServiceFailureRetryInterval),
+		endpointQuota: newEndpointQuota(),
+		portConflicts: newPortConflicts(),
+		syncCounts:    newSyncCounts(),
+		reachability:  newReachabilityProber(cfg.ReachabilitySampleSize, cfg.ReachabilityTimeout),
+		recorder:      recorder,
+		verifying:     cfg.VerificationInterval > 0,
+	}
+	r.services = newServiceQueue(cfg.SyncWorkers, cfg.ServiceRetryBaseDelay, cfg.ServiceRetryMaxDelay, r.processService,
+		func(key string) bool { return r.failureBudget.shouldSync(key, time.Now()) })
+	bus.clustersConnected.subscribe(r.begin)
+	bus.clustersConnected.subscribe(r.removeClusters)
+	bus.clustersConnected.subscribe(r.markStaleClusters)
+	bus.servicesMirrored.subscribe(r.reconcile)
+	bus.discoveryCompleted.subscribe(r.complete)
+	return r
+}
+
+// begin starts the counts of a sync cycle, so that the services synced by targeted syncs since the last cycle
+// are not counted twice
+func (r *endpointPublicationReconciler) begin(_ context.Context, _ clustersConnected) error {
+	r.syncCounts.end()
+	return nil
+}
+
+// removeClusters deletes the EndpointSlices imported from clusters that are no longer synced
+func (r *endpointPublicationReconciler) removeClusters(ctx context.Context, event clustersConnected) error {
+	var errs []error
+	for cluster := range event.removed {
+		if err := r.sliceUpdater.DeleteClusterSlices(ctx, cluster); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete EndpointSlices of cluster %s: %w", cluster, err))
+		}
+	}
+	return utilserrors.NewAggregate(errs)
+}
+
+// markStaleClusters marks the EndpointSlices imported from unreachable clusters whose last known endpoints are kept
+func (r *endpointPublicationReconciler) markStaleClusters(ctx context.Context, event clustersConnected) error {
+	var errs []error
+	for cluster, since := range event.stale {
+		if err := r.sliceUpdater.MarkClusterSlicesStale(ctx, cluster, since); err != nil {
+			errs = append(errs, fmt.Errorf("failed to mark EndpointSlices of cluster %s stale: %w", cluster, err))
+		}
+	}
+	return utilserrors.NewAggregate(errs)
+}
+
+func (r *endpointPublicationReconciler) reconcile(ctx context.Context, event servicesMirrored) error {
+	// For each service, aggregate endpoints and update EndpointSlices
+	if r.verifying {
+		klog.Info("Aggregating endpoints and updating EndpointSlices, verifying managed EndpointSlices against remote clusters")
+	} else {
+		klog.Info("Aggregating endpoints and updating EndpointSlices")
+	}
+
+	if err := r.syncServices(ctx, event.services, event.clusterInfos, event.retained, r.verifying); err != nil {
+		return err
+	}
+
+	klog.Infof("Processed %d services", len(event.services))
+	return nil
+}
+
+// complete forgets the failures, endpoint quota and retries of services that are gone, publishes the services
+// that keep failing and decides whether the next sync cycle verifies the managed EndpointSlices
+func (r *endpointPublicationReconciler) complete(ctx context.Context, event discoveryCompleted) error {
+	active := func(key string) bool {
+		_, ok := event.services[key]
+		return ok
+	}
+	r.failureBudget.prune(active)
+	r.endpointQuota.prune(active)
+	r.portConflicts.prune(active)
+	r.services.prune(active)
+	r.publishFailingServices(ctx, event.services, event.clusterInfos)
+	r.publishSyncResults(ctx, event)
+	r.publishReachability(ctx, event.clusterInfos)
+
+	metrics.PublishedEndpoints.Reset()
+	for namespace, count := range r.endpointQuota.published() {
+		metrics.PublishedEndpoints.WithLabelValues(namespace).Set(float64(count))
+	}
+
+	if r.verifying {
+		r.lastVerification = time.Now()
+	}
+	r.verifying = r.cfg.VerificationInterval > 0 && time.Since(r.lastVerification) >= r.cfg.VerificationInterval
+	return nil
+}
+
+// syncServices syncs services through the service queue and waits until each of them was synced once.
+// Services are queued in fair order so that every remote cluster gets its turn, regardless of how many
+// services it exports.
+func (r *endpointPublicationReconciler) syncServices(
+	ctx context.Context,
+	services map[string]*apisdiscoverer.ServiceInfo,
+	clusterInfos map[string]*clusterlink.ClusterInfo,
+	retained sets.Set[string],
+	verify bool,
+) error {
+	now := time.Now()
+	items := make([]*queuedService, 0, len(services))
+	for _, svcInfo := range fairOrder(services) {
+		key := svcInfo.Namespace + "/" + svcInfo.Name
+		if !r.failureBudget.shouldSync(key, now) {
+			logging.V(4, svcInfo.Namespace, key).Infof("Skipping service %s, failure budget exhausted", key)
+			continue
+		}
+		items = append(items, &queuedService{svcInfo: svcInfo, clusterInfos: clusterInfos, retained: retained, verify: verify})
+	}
+	return r.services.run(ctx, items)
+}
+
+// processService syncs a service taken from the service queue. The attempt a sync waits for counts against
+// the failure budget and in the sync results of the cycle, retries only count once they succeed.
+func (r *endpointPublicationReconciler) processService(ctx context.Context, item *queuedService, batched bool) error {
+	svcInfo := item.svcInfo
+	err := r.syncService(ctx, svcInfo, item.clusterInfos, item.retained, item.verify && batched)
+	if err != nil && ctx.Err() != nil {
+		// Aborted at the end of the shutdown grace period, the EndpointSlices may be partially updated
+		klog.Warningf("Sync of service %s/%s aborted by shutdown, its EndpointSlices are repaired by the next sync: %v",
+			svcInfo.Namespace, svcInfo.Name, err)
+		return fmt.Errorf("sync of service %s/%s aborted: %w", svcInfo.Namespace, svcInfo.Name, err)
+	}
+	if !batched {
+		if err != nil {
+			logging.V(4, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Retry of service %s/%s failed: %v",
+				svcInfo.Namespace, svcInfo.Name, err)
+			return err
+		}
+		klog.V(2).Infof("Service %s/%s synced on retry", svcInfo.Namespace, svcInfo.Name)
+		r.recordSyncResult(ctx, svcInfo, nil)
+		return nil
+	}
+
+	r.recordSyncResult(ctx, svcInfo, err)
+	if err != nil {
+		r.syncCounts.recordFailure(svcInfo.Clusters, serviceSyncError(svcInfo, err))
+		return fmt.Errorf("failed to sync service %s/%s: %w", svcInfo.Namespace, svcInfo.Name, err)
+	}
+	return nil
+}
+
+// recordSyncResult updates the failure budget of a service with the result of its sync
+func (r *endpointPublicationReconciler) recordSyncResult(ctx context.Context, svcInfo *apisdiscoverer.ServiceInfo, syncErr error) {
+	key := svcInfo.Namespace + "/" + svcInfo.Name
+
+	if syncErr == nil {
+		if r.failureBudget.recordSuccess(key) {
+			klog.Infof("Service %s recovered after exhausting its failure budget", key)
+		}
+		return
+	}
+
+	if !r.failureBudget.recordFailure(key, syncErr, time.Now()) {
+		return
+	}
+	klog.Warningf("Service %s failed to sync %d times in a row, retrying every %s: %v",
+		key, r.cfg.ServiceFailureBudget, r.cfg.ServiceFailureRetryInterval, syncErr)
+
+	local := &corev1.Service{}
+	if err := r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: svcInfo.Namespace, Name: svcInfo.Name}, local); err != nil {
+		return
+	}
+	r.recorder.Eventf(local, corev1.EventTypeWarning, "SyncFailureBudgetExhausted",
+		"Failed to sync %d times in a row, retrying every %s: %v", r.cfg.ServiceFailureBudget, r.cfg.ServiceFailureRetryInterval, syncErr)
+}
+
+// publishFailingServices exposes the services that exhausted their failure budget through
+// metrics and the status of the ClusterLinks of the clusters they are imported from
+func (r *endpointPublicationReconciler) publishFailingServices(ctx context.Context, services map[string]*apisdiscoverer.ServiceInfo, clusterInfos map[string]*clusterlink.ClusterInfo) {
+	exhausted := r.failureBudget.exhaustedServices()
+
+	metrics.ServiceFailureBudgetExhausted.Reset()
+	failingByCluster := make(map[string][]svclinkv1alpha1.FailingService)
+	for key, failure := range exhausted {
+		svcInfo := services[key]
+		metrics.ServiceFailureBudgetExhausted.WithLabelValues(svcInfo.Namespace, svcInfo.Name).Set(float64(failure.count))
+
+		for _, clusterName := range svcInfo.Clusters {
+			failingByCluster[clusterName] = append(failingByCluster[clusterName], svclinkv1alpha1.FailingService{
+				Service:   key,
+				Failures:  int32(failure.count),
+				LastError: failure.lastError,
+				Since:     metav1.NewTime(failure.since),
+			})
+		}
+	}
+
+	for clusterName, clusterInfo := range clusterInfos {
+		failing := failingByCluster[clusterName]
+		sort.Slice(failing, func(i, j int) bool { return failing[i].Service < failing[j].Service })
+		clusterlink.UpdateFailingServices(ctx, r.ctrlClient, clusterInfo, failing)
+	}
+}
+
+// publishSyncResults records the services synced, endpoints published and services skipped in this cycle in
+// the status of the ClusterLink of every cluster, with the completion time and duration of successful syncs.
+// A sync is successful when the services of the cluster were discovered and none of them failed to sync.
+func (r *endpointPublicationReconciler) publishSyncResults(ctx context.Context, event discoveryCompleted) {
+	counts, failed := r.syncCounts.end()
+	now := time.Now()
+	for clusterName, clusterInfo := range event.clusterInfos {
+		result := clusterlink.SyncResult{
+			Skipped: event.skipped[clusterName],
+		}
+		if clusterCounts, ok := counts[clusterName]; ok {
+			result.Services = clusterCounts.services
+			result.Endpoints = clusterCounts.endpoints
+			if r.cfg.NamespaceSummary {
+				result.NamespaceSummary = clusterCounts.namespaceSummary()
+			}
+		}
+		errs, failed := failed[clusterName]
+		result.Errors = errs
+		if clusterInfo.ClusterLink.Status.Error == "" && !failed {
+			result.Completed = now
+			result.Duration = now.Sub(event.started)
+		}
+		r.links.UpdateSyncResult(ctx, r.ctrlClient, clusterInfo, result)
+	}
+}
+
+// publishReachability probes the endpoints sampled in this cycle and reports the outcome in the NetworkReachable
+// condition of the probed clusters. The condition is removed from the other clusters.
+func (r *endpointPublicationReconciler) publishReachability(ctx context.Context, clusterInfos map[string]*clusterlink.ClusterInfo) {
+	results := r.reachability.probe(ctx, clusterInfos)
+	for name, clusterInfo := range clusterInfos {
+		clusterlink.UpdateNetworkReachability(ctx, r.ctrlClient, clusterInfo, results[name])
+	}
+}
+
+// syncService syncs a single service. When verify is set, the managed EndpointSlices are first
+// compared with the freshly aggregated endpoints and discrepancies are reported before being repaired.
+// The EndpointSlices imported from retained clusters are left as they are.
+func (r *endpointPublicationReconciler) syncService(
+	ctx context.Context,
+	svcInfo *apisdiscoverer.ServiceInfo,
+	clusterInfos map[string]*clusterlink.ClusterInfo,
+	retained sets.Set[string],
+	verify bool,
+) error {
+	logging.V(4, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Syncing service %s/%s from clusters: %v",
+		svcInfo.Namespace, svcInfo.Name, svcInfo.Clusters)
+
+	svcInfo = r.applyPin(ctx, svcInfo)
+	r.reportPortConflicts(ctx, svcInfo)
+
+	// Aggregate endpoints from all clusters
+	clusterEndpoints, err := r.aggregator.AggregateEndpoints(ctx, svcInfo, clusterInfos)
+	if err != nil {
+		return &phaseError{phase: svclinkv1alpha1.SyncPhaseAggregate, err: err}
+	}
+
+	// Services over the endpoint quota of their namespace are withdrawn
+	if !r.admitEndpoints(ctx, svcInfo, clusterEndpoints) {
+		clusterEndpoints = nil
+	}
+
+	if verify {
+		if _, err := r.sliceUpdater.VerifyEndpointSlices(ctx, svcInfo.Namespace, svcInfo.Name, clusterEndpoints, retained); err != nil {
+			klog.Errorf("Failed to verify EndpointSlices of service %s/%s: %v", svcInfo.Namespace, svcInfo.Name, err)
+		}
+	}
+
+	// Update EndpointSlices
+	if err := r.sliceUpdater.UpdateEndpointSlices(
+		ctx,
+		svcInfo.Namespace,
+		svcInfo.Name,
+		clusterEndpoints,
+		retained,
+	); err != nil {
+		return &phaseError{phase: svclinkv1alpha1.SyncPhaseUpdate, err: err}
+	}
+	if err := r.sliceUpdater.UpdateEndpoints(ctx, svcInfo.Namespace, svcInfo.Name, clusterEndpoints, retained); err != nil {
+		return &phaseError{phase: svclinkv1alpha1.SyncPhaseUpdate, err: err}
+	}
+
+	r.syncCounts.record(svcInfo, clusterEndpoints)
+	r.reachability.record(clusterInfos, clusterEndpoints)
+	return nil
+}
+
+// admitEndpoints reports whether the aggregated endpoints of a service fit in the endpoint quota of its namespace
+func (r *endpointPublicationReconciler) admitEndpoints(ctx context.Context, svcInfo *apisdiscoverer.ServiceInfo, clusterEndpoints []aggregator.ClusterEndpoints) bool {
+	endpoints := 0
+	for _, ce := range clusterEndpoints {
+		endpoints += len(ce.Endpoints)
+	}
+
+	limit := r.cfg.NamespaceEndpointQuota
+	namespace := &corev1.Namespace{}
+	if err := r.ctrlClient.Get(ctx, client.ObjectKey{Name: svcInfo.Namespace}, namespace); err == nil {
+		if quota, ok, err := config.EndpointQuota(namespace); err != nil {
+			klog.Errorf("Ignoring endpoint quota: %v", err)
+		} else if ok {
+			limit = quota
+		}
+	}
+
+	admitted, justRejected := r.endpointQuota.admit(svcInfo.Namespace, svcInfo.Name, endpoints, limit)
+	if !justRejected {
+		return admitted
+	}
+	klog.Warningf("Service %s/%s withdrawn: its %d endpoints exceed the endpoint quota of %d in namespace %s",
+		svcInfo.Namespace, svcInfo.Name, endpoints, limit, svcInfo.Namespace)
+
+	local := &corev1.Service{}
+	if err := r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: svcInfo.Namespace, Name: svcInfo.Name}, local); err == nil {
+		r.recorder.Eventf(local, corev1.EventTypeWarning, "EndpointQuotaExceeded",
+			"%d remote endpoints exceed the endpoint quota of %d in namespace %s", endpoints, limit, svcInfo.Namespace)
+	}
+	return admitted
+}
+
+// reportPortConflicts reports the clusters of a service disagreeing on its ports with a warning event on the local
+// Service, when the disagreement changes
+func (r *endpointPublicationReconciler) reportPortConflicts(ctx context.Context, svcInfo *apisdiscoverer.ServiceInfo) {
+	_, conflicts, _ := aggregator.MergeServicePorts(svcInfo, r.cfg.PortMergePolicy)
+	if !r.portConflicts.changed(svcInfo.Namespace+"/"+svcInfo.Name, conflicts) {
+		return
+	}
+	message := fmt.Sprintf("The clusters exporting the service disagree on its ports, merged with the %s policy: %s",
+		cmp.Or(r.cfg.PortMergePolicy, config.PortMergePolicyUnion), strings.Join(conflicts, "; "))
+	klog.Warningf("Service %s/%s: %s", svcInfo.Namespace, svcInfo.Name, message)
+
+	local := &corev1.Service{}
+	if err := r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: svcInfo.Namespace, Name: svcInfo.Name}, local); err == nil {
+		r.recorder.Eventf(local, corev1.EventTypeWarning, "PortConflict", "%s", message)
+	}
+}
+
+// phaseError is an error of a service sync with the phase it occurred in
+type phaseError struct {
+	phase svclinkv1alpha1.SyncPhase
+	err   error
+}
+
+func (e *phaseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *phaseError) Unwrap() error {
+	return e.err
+}
+
+// serviceSyncError returns the error of a service that failed to sync, to be recorded in the status of its clusters
+func serviceSyncError(svcInfo *apisdiscoverer.ServiceInfo, err error) clusterlink.SyncError {
+	phase := svclinkv1alpha1.SyncPhaseUpdate
+	var pe *phaseError
+	if errors.As(err, &pe) {
+		phase = pe.phase
+	}
+	return clusterlink.SyncError{
+		Phase:   phase,
+		Message: fmt.Sprintf("Failed to sync service %s/%s: %v", svcInfo.Namespace, svcInfo.Name, err),
+	}
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// failingSlicesClient serves local Services without EndpointSlices and rejects the first creations of EndpointSlices
+type failingSlicesClient struct {
+	client.Client
+	mu       sync.Mutex
+	failures int
+	attempts int
+	created  []string
+}
+
+func (c *failingSlicesClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	if svc, ok := obj.(*corev1.Service); ok {
+		svc.ObjectMeta = metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, UID: "uid"}
+		return nil
+	}
+	return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *failingSlicesClient) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return nil
+}
+
+func (c *failingSlicesClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	if c.attempts <= c.failures {
+		return apierrors.NewServiceUnavailable("etcd leader changed")
+	}
+	c.created = append(c.created, obj.GetName())
+	return nil
+}
+
+func (c *failingSlicesClient) createdSlices() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.created...)
+}
+
+// newTestPublication creates an endpointPublicationReconciler writing EndpointSlices through ctrlClient, with
+// the endpoints of payments/api exported by the east cluster
+func newTestPublication(t *testing.T, ctrlClient client.Client, cfg *config.Config, recorder record.EventRecorder) (
+	*endpointPublicationReconciler, map[string]*apisdiscoverer.ServiceInfo, map[string]*clusterlink.ClusterInfo) {
+	t.Helper()
+	remote := fake.NewClientset(&discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "payments",
+			Name:      "api-abc12",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "api"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{"10.0.0.1"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+		}},
+		Ports: []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To(int32(80))}},
+	})
+	clusterInfos := map[string]*clusterlink.ClusterInfo{"east": {
+		Name:        "east",
+		Enabled:     true,
+		Client:      remote,
+		ClusterLink: svclinkv1alpha1.ClusterLink{ObjectMeta: metav1.ObjectMeta{Name: "east"}},
+	}}
+	services := map[string]*apisdiscoverer.ServiceInfo{
+		"payments/api": {Namespace: "payments", Name: "api", Clusters: []string{"east"}},
+	}
+
+	r := newEndpointPublicationReconciler(ctrlClient, nil, cfg, aggregator.NewEndpointAggregator(ctrlClient, nil, cfg),
+		updater.NewSliceUpdater(ctrlClient, ctrlClient, cfg, recorder), recorder, &eventBus{})
+	return r, services, clusterInfos
+}
+
+func TestSyncServicesRequeuesFailedSliceWrites(t *testing.T) {
+	ctrlClient := &failingSlicesClient{failures: 2}
+	cfg := &config.Config{
+		SyncWorkers:           1,
+		ServiceRetryBaseDelay: time.Millisecond,
+		ServiceRetryMaxDelay:  10 * time.Millisecond,
+		MaxEndpointsPerSlice:  100,
+	}
+	r, services, clusterInfos := newTestPublication(t, ctrlClient, cfg, record.NewFakeRecorder(10))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := r.syncServices(ctx, services, clusterInfos, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "failed to update EndpointSlice") {
+		t.Fatalf("expected the failed EndpointSlice write to fail the sync, got %v", err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for len(ctrlClient.createdSlices()) == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected payments/api to be requeued until its EndpointSlice is written")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	ctrlClient.mu.Lock()
+	defer ctrlClient.mu.Unlock()
+	if ctrlClient.attempts != 3 {
+		t.Errorf("expected 3 attempts to write the EndpointSlice, got %d", ctrlClient.attempts)
+	}
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"sync"
+)
+
+// endpointQuota limits the number of endpoints published into each local namespace, so that a single
+// tenant importing tens of thousands of remote endpoints cannot bloat kube-proxy on every node.
+// Services keep the share they were admitted with across sync cycles, so a published service is
+// never displaced by a service discovered later.
+type endpointQuota struct {
+	mu sync.Mutex
+	// usage holds the endpoints published per namespace and service name
+	usage map[string]map[string]int
+	// rejected holds the namespace/name keys of services over quota
+	rejected map[string]struct{}
+}
+
+func newEndpointQuota() *endpointQuota {
+	return &endpointQuota{
+		usage:    make(map[string]map[string]int),
+		rejected: make(map[string]struct{}),
+	}
+}
+
+// admit reports whether a service may publish endpoints into namespace without exceeding limit,
+// and whether it was just rejected. A limit of 0 disables the quota.
+func (eq *endpointQuota) admit(namespace, name string, endpoints, limit int) (admitted, justRejected bool) {
+	eq.mu.Lock()
+	defer eq.mu.Unlock()
+
+	key := namespace + "/" + name
+	services := eq.usage[namespace]
+
+	others := 0
+	for service, count := range services {
+		if service != name {
+			others += count
+		}
+	}
+
+	if limit > 0 && others+endpoints > limit {
+		delete(services, name)
+		_, wasRejected := eq.rejected[key]
+		eq.rejected[key] = struct{}{}
+		return false, !wasRejected
+	}
+
+	if services == nil {
+		services = make(map[string]int)
+		eq.usage[namespace] = services
+	}
+	services[name] = endpoints
+	delete(eq.rejected, key)
+	return true, false
+}
+
+// published returns the number of endpoints published per namespace
+func (eq *endpointQuota) published() map[string]int {
+	eq.mu.Lock()
+	defer eq.mu.Unlock()
+
+	published := make(map[string]int, len(eq.usage))
+	for namespace, services := range eq.usage {
+		for _, count := range services {
+			published[namespace] += count
+		}
+	}
+	return published
+}
+
+// prune forgets services that are no longer discovered
+func (eq *endpointQuota) prune(active func(key string) bool) {
+	eq.mu.Lock()
+	defer eq.mu.Unlock()
+
+	for namespace, services := range eq.usage {
+		for name := range services {
+			if !active(namespace + "/" + name) {
+				delete(services, name)
+			}
+		}
+		if len(services) == 0 {
+			delete(eq.usage, namespace)
+		}
+	}
+	for key := range eq.rejected {
+		if !active(key) {
+			delete(eq.rejected, key)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package controller
+
+import "testing"
+
+func TestEndpointQuota(t *testing.T) {
+	eq := newEndpointQuota()
+
+	if admitted, _ := eq.admit("prod", "web", 60, 100); !admitted {
+		t.Fatal("expected service within quota to be admitted")
+	}
+	admitted, justRejected := eq.admit("prod", "api", 50, 100)
+	if admitted || !justRejected {
+		t.Fatalf("expected service over quota to be rejected, got admitted=%v justRejected=%v", admitted, justRejected)
+	}
+	if _, justRejected := eq.admit("prod", "api", 50, 100); justRejected {
+		t.Error("expected rejection to be reported once")
+	}
+
+	// Admitted services keep their share and may grow within the quota
+	if admitted, _ := eq.admit("prod", "web", 90, 100); !admitted {
+		t.Error("expected admitted service to keep its share")
+	}
+	if admitted, _ := eq.admit("staging", "api", 500, 100); admitted {
+		t.Error("expected quota to apply to every namespace")
+	}
+	if admitted, _ := eq.admit("staging", "api", 500, 0); !admitted {
+		t.Error("expected a limit of 0 to disable the quota")
+	}
+
+	eq.prune(func(key string) bool { return key != "prod/web" })
+	if admitted, _ := eq.admit("prod", "api", 50, 100); !admitted {
+		t.Error("expected quota of pruned services to be released")
+	}
+	if published := eq.published(); published["prod"] != 50 || published["staging"] != 500 {
+		t.Errorf("unexpected published endpoints: %v", published)
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+// clustersConnected is published once the ClusterLinks have been read and their remote clients are ready
+type clustersConnected struct {
+	clusterInfos map[string]*clusterlink.ClusterInfo
+	// retained holds the clusters that are not synced but whose imported EndpointSlices are left as they are
+	retained sets.Set[string]
+	// removed holds the clusters that are not synced and whose imported EndpointSlices are deleted
+	removed sets.Set[string]
+	// stale holds the retained clusters that are unreachable, with the time since which they are unreachable
+	stale map[string]time.Time
+}
+
+// servicesDiscovered is published once the services exported by the remote clusters are known.
+// In chunked mode, it is published once per namespace with the services of that namespace.
+type servicesDiscovered struct {
+	clusterInfos map[string]*clusterlink.ClusterInfo
+	retained     sets.Set[string]
+	services     map[string]*apisdiscoverer.ServiceInfo
+}
+
+// servicesMirrored is published once the discovered services have a local Service to attach EndpointSlices to
+type servicesMirrored struct {
+	clusterInfos map[string]*clusterlink.ClusterInfo
+	retained     sets.Set[string]
+	services     map[string]*apisdiscoverer.ServiceInfo
+}
+
+// discoveryCompleted is published once every discovered service went through the other reconcilers.
+// In chunked mode, the services carry no Service object.
+type discoveryCompleted struct {
+	clusterInfos map[string]*clusterlink.ClusterInfo
+	retained     sets.Set[string]
+	services     map[string]*apisdiscoverer.ServiceInfo
+	// skipped counts, per cluster, the services not synced because of filters or spec.maxServices
+	skipped map[string]int
+	// started is when the discovery of the services started
+	started time.Time
+}
+
+// topic delivers events of a single type to its subscribers, in subscription order
+type topic[T any] struct {
+	mu       sync.RWMutex
+	handlers []func(context.Context, T) error
+}
+
+func (t *topic[T]) subscribe(handler func(context.Context, T) error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers = append(t.handlers, handler)
+}
+
+// publish hands the event to every subscriber and returns their aggregated errors.
+// Delivery is synchronous, so a publisher observes the outcome of everything downstream of it.
+func (t *topic[T]) publish(ctx context.Context, event T) error {
+	t.mu.RLock()
+	handlers := t.handlers
+	t.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilserrors.NewAggregate(errs)
+}
+
+// eventBus connects the reconcilers of the controller. Each reconciler subscribes to the events
+// it consumes and publishes the events it produces, without referencing the other reconcilers.
+type eventBus struct {
+	clustersConnected  topic[clustersConnected]
+	servicesDiscovered topic[servicesDiscovered]
+	servicesMirrored   topic[servicesMirrored]
+	discoveryCompleted topic[discoveryCompleted]
+}
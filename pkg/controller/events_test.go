@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTopicPublish(t *testing.T) {
+	var bus eventBus
+	var delivered []string
+
+	bus.servicesDiscovered.subscribe(func(_ context.Context, _ servicesDiscovered) error {
+		delivered = append(delivered, "first")
+		return errors.New("first failed")
+	})
+	bus.servicesDiscovered.subscribe(func(_ context.Context, _ servicesDiscovered) error {
+		delivered = append(delivered, "second")
+		return nil
+	})
+
+	err := bus.servicesDiscovered.publish(context.Background(), servicesDiscovered{})
+	if err == nil || err.Error() != "first failed" {
+		t.Errorf("expected the error of the first subscriber, got %v", err)
+	}
+	if len(delivered) != 2 || delivered[0] != "first" || delivered[1] != "second" {
+		t.Errorf("expected delivery to every subscriber in order, got %v", delivered)
+	}
+
+	if err := bus.servicesMirrored.publish(context.Background(), servicesMirrored{}); err != nil {
+		t.Errorf("expected publishing without subscribers to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// serviceFailure tracks the consecutive sync failures of a service
+type serviceFailure struct {
+	count     int
+	lastError string
+	since     time.Time
+	// nextRetry is when a service whose budget is exhausted is retried next
+	nextRetry time.Time
+}
+
+// failureBudget stops hot-retrying services that keep failing. After budget consecutive failures a
+// service is only retried every retryInterval instead of every sync cycle, until it succeeds again.
+type failureBudget struct {
+	mu            sync.Mutex
+	budget        int
+	retryInterval time.Duration
+	failures      map[string]*serviceFailure
+}
+
+func newFailureBudget(budget int, retryInterval time.Duration) *failureBudget {
+	return &failureBudget{
+		budget:        budget,
+		retryInterval: retryInterval,
+		failures:      make(map[string]*serviceFailure),
+	}
+}
+
+// exhausted reports whether the failure is over budget. A budget of 0 disables the failure budget.
+func (fb *failureBudget) exhausted(failure *serviceFailure) bool {
+	return fb.budget > 0 && failure.count >= fb.budget
+}
+
+// shouldSync reports whether the service should be synced in this cycle
+func (fb *failureBudget) shouldSync(key string, now time.Time) bool {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	failure, ok := fb.failures[key]
+	if !ok || !fb.exhausted(failure) {
+		return true
+	}
+	return !now.Before(failure.nextRetry)
+}
+
+// recordFailure records a failed sync and reports whether the service just exhausted its budget
+func (fb *failureBudget) recordFailure(key string, err error, now time.Time) bool {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	failure, ok := fb.failures[key]
+	if !ok {
+		failure = &serviceFailure{since: now}
+		fb.failures[key] = failure
+	}
+	failure.count++
+	failure.lastError = err.Error()
+
+	if !fb.exhausted(failure) {
+		return false
+	}
+	failure.nextRetry = now.Add(fb.retryInterval)
+	return failure.count == fb.budget
+}
+
+// recordSuccess resets the failures of a service and reports whether its budget was exhausted
+func (fb *failureBudget) recordSuccess(key string) bool {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	failure, ok := fb.failures[key]
+	if !ok {
+		return false
+	}
+	delete(fb.failures, key)
+	return fb.exhausted(failure)
+}
+
+// exhaustedServices returns a copy of the failures of services whose budget is exhausted
+func (fb *failureBudget) exhaustedServices() map[string]serviceFailure {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	exhausted := make(map[string]serviceFailure)
+	for key, failure := range fb.failures {
+		if fb.exhausted(failure) {
+			exhausted[key] = *failure
+		}
+	}
+	return exhausted
+}
+
+// prune forgets services that are no longer discovered
+func (fb *failureBudget) prune(active func(key string) bool) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	for key := range fb.failures {
+		if !active(key) {
+			delete(fb.failures, key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestFailureBudget(t *testing.T) {
+	now := time.Now()
+	fb := newFailureBudget(3, 10*time.Minute)
+	syncErr := errors.New("boom")
+
+	for i := 1; i <= 2; i++ {
+		if justExhausted := fb.recordFailure("default/web", syncErr, now); justExhausted {
+			t.Fatalf("budget exhausted after %d failures", i)
+		}
+		if !fb.shouldSync("default/web", now) {
+			t.Fatalf("service within budget should be hot-retried")
+		}
+	}
+
+	if justExhausted := fb.recordFailure("default/web", syncErr, now); !justExhausted {
+		t.Fatal("expected budget to be exhausted after 3 failures")
+	}
+	if fb.shouldSync("default/web", now.Add(time.Minute)) {
+		t.Error("service over budget should not be hot-retried")
+	}
+	if !fb.shouldSync("default/web", now.Add(10*time.Minute)) {
+		t.Error("service over budget should be retried after the retry interval")
+	}
+	if exhausted := fb.exhaustedServices(); exhausted["default/web"].count != 3 || exhausted["default/web"].lastError != "boom" {
+		t.Errorf("unexpected exhausted services: %+v", exhausted)
+	}
+
+	// A failure after the budget is exhausted is not reported again
+	if justExhausted := fb.recordFailure("default/web", syncErr, now); justExhausted {
+		t.Error("expected exhaustion to be reported once")
+	}
+
+	if recovered := fb.recordSuccess("default/web"); !recovered {
+		t.Error("expected success to report recovery of an exhausted service")
+	}
+	if len(fb.exhaustedServices()) != 0 {
+		t.Error("expected no exhausted services after success")
+	}
+}
+
+func TestFailureBudget_Disabled(t *testing.T) {
+	now := time.Now()
+	fb := newFailureBudget(0, 10*time.Minute)
+
+	for i := 0; i < 10; i++ {
+		fb.recordFailure("default/web", errors.New("boom"), now)
+	}
+	if !fb.shouldSync("default/web", now) {
+		t.Error("services should always be retried when the failure budget is disabled")
+	}
+}
+
+func TestFailureBudgetExhaustedByFailedSliceWrites(t *testing.T) {
+	ctrlClient := &failingSlicesClient{failures: 1000}
+	cfg := &config.Config{
+		SyncWorkers:                 1,
+		ServiceRetryBaseDelay:       time.Millisecond,
+		ServiceRetryMaxDelay:        10 * time.Millisecond,
+		MaxEndpointsPerSlice:        100,
+		ServiceFailureBudget:        2,
+		ServiceFailureRetryInterval: time.Hour,
+	}
+	recorder := record.NewFakeRecorder(10)
+	r, services, clusterInfos := newTestPublication(t, ctrlClient, cfg, recorder)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for cycle := 1; cycle <= 2; cycle++ {
+		if err := r.syncServices(ctx, services, clusterInfos, nil, false); err == nil {
+			t.Fatalf("expected the EndpointSlice write to fail in cycle %d", cycle)
+		}
+	}
+
+	failure, ok := r.failureBudget.exhaustedServices()["payments/api"]
+	if !ok || failure.count != 2 || !strings.Contains(failure.lastError, "failed to create EndpointSlice") {
+		t.Fatalf("expected payments/api to exhaust its failure budget with the failed write, got %+v", r.failureBudget.exhaustedServices())
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "SyncFailureBudgetExhausted") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("expected a SyncFailureBudgetExhausted event")
+	}
+
+	// Until the retry interval elapses, the service is left out of the sync cycles
+	if err := r.syncServices(ctx, services, clusterInfos, nil, false); err != nil {
+		t.Errorf("expected the exhausted service to be skipped, got %v", err)
+	}
+}
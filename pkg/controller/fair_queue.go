@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"sort"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+// fairOrder orders services so that sync workers take turns between remote clusters.
+// Services are queued per cluster and clusters are visited round-robin, so a remote cluster
+// exporting thousands of services cannot starve the services of smaller clusters within a cycle.
+// A service present in several clusters is scheduled once, on the first turn that reaches it.
+func fairOrder(services map[string]*apisdiscoverer.ServiceInfo) []*apisdiscoverer.ServiceInfo {
+	keys := make([]string, 0, len(services))
+	for key := range services {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	queues := make(map[string][]string)
+	for _, key := range keys {
+		for _, cluster := range services[key].Clusters {
+			queues[cluster] = append(queues[cluster], key)
+		}
+	}
+
+	clusters := make([]string, 0, len(queues))
+	for cluster := range queues {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	ordered := make([]*apisdiscoverer.ServiceInfo, 0, len(services))
+	scheduled := make(map[string]struct{}, len(services))
+	schedule := func(key string) {
+		if _, ok := scheduled[key]; ok {
+			return
+		}
+		scheduled[key] = struct{}{}
+		ordered = append(ordered, services[key])
+	}
+
+	for len(clusters) > 0 {
+		remaining := clusters[:0]
+		for _, cluster := range clusters {
+			queue := queues[cluster]
+			// Skip services already scheduled through another cluster so every turn makes progress
+			for len(queue) > 0 {
+				key := queue[0]
+				queue = queue[1:]
+				if _, ok := scheduled[key]; !ok {
+					schedule(key)
+					break
+				}
+			}
+			queues[cluster] = queue
+			if len(queue) > 0 {
+				remaining = append(remaining, cluster)
+			}
+		}
+		clusters = remaining
+	}
+
+	// Services not present in any remote cluster still need their slices reconciled
+	for _, key := range keys {
+		schedule(key)
+	}
+
+	return ordered
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"testing"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+func TestFairOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		services map[string]*apisdiscoverer.ServiceInfo
+		expected []string
+	}{
+		{
+			name: "large cluster does not starve small clusters",
+			services: map[string]*apisdiscoverer.ServiceInfo{
+				"big/a":   {Namespace: "big", Name: "a", Clusters: []string{"big"}},
+				"big/b":   {Namespace: "big", Name: "b", Clusters: []string{"big"}},
+				"big/c":   {Namespace: "big", Name: "c", Clusters: []string{"big"}},
+				"small/a": {Namespace: "small", Name: "a", Clusters: []string{"small"}},
+				"tiny/a":  {Namespace: "tiny", Name: "a", Clusters: []string{"tiny"}},
+			},
+			expected: []string{"big/a", "small/a", "tiny/a", "big/b", "big/c"},
+		},
+		{
+			name: "service in several clusters is scheduled once",
+			services: map[string]*apisdiscoverer.ServiceInfo{
+				"default/shared": {Namespace: "default", Name: "shared", Clusters: []string{"c1", "c2"}},
+				"default/only2":  {Namespace: "default", Name: "only2", Clusters: []string{"c2"}},
+			},
+			expected: []string{"default/shared", "default/only2"},
+		},
+		{
+			name: "service without clusters is still scheduled",
+			services: map[string]*apisdiscoverer.ServiceInfo{
+				"default/gone": {Namespace: "default", Name: "gone"},
+				"default/web":  {Namespace: "default", Name: "web", Clusters: []string{"c1"}},
+			},
+			expected: []string{"default/web", "default/gone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered := fairOrder(tt.services)
+			if len(ordered) != len(tt.expected) {
+				t.Fatalf("expected %d services, got %d", len(tt.expected), len(ordered))
+			}
+			for i, svcInfo := range ordered {
+				if key := svcInfo.Namespace + "/" + svcInfo.Name; key != tt.expected[i] {
+					t.Errorf("position %d: expected %s, got %s", i, tt.expected[i], key)
+				}
+			}
+		})
+	}
+}
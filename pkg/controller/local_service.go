@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// remoteChangeBuffer is how many remote endpoint changes wait to be reconciled before further changes are left
+// to the next sync cycle
+const remoteChangeBuffer = 1024
+
+// localServiceReconciler syncs a local Service as soon as it is created, as soon as the EndpointSlices of the
+// remote services it imports change, as soon as one of its managed EndpointSlices is modified or deleted by
+// someone else than svclink, or as soon as a sync is requested with its sync now annotation, rather than
+// in the next sync cycle. Targeted syncs reuse the clusters and services discovered in the last sync cycle and never run concurrently
+// with a sync cycle; Services no remote cluster exported in the last cycle wait for the next one.
+type localServiceReconciler struct {
+	bus          *eventBus
+	sliceUpdater *updater.SliceUpdater
+	// cycle is held by sync cycles and targeted syncs
+	cycle *sync.Mutex
+
+	// remoteChanges carries the local Services whose remote EndpointSlices changed
+	remoteChanges chan event.GenericEvent
+
+	mu   sync.Mutex
+	last *discoveryCompleted
+	// imports maps the remote services of the last sync cycle, keyed by cluster/namespace/name, to local keys
+	imports map[string][]string
+}
+
+func newLocalServiceReconciler(cycle *sync.Mutex, sliceUpdater *updater.SliceUpdater, bus *eventBus) *localServiceReconciler {
+	r := &localServiceReconciler{
+		bus:           bus,
+		sliceUpdater:  sliceUpdater,
+		cycle:         cycle,
+		remoteChanges: make(chan event.GenericEvent, remoteChangeBuffer),
+	}
+	bus.discoveryCompleted.subscribe(r.complete)
+	return r
+}
+
+// setupWithManager watches the creation of local Services, their sync requests, the external modifications of
+// their managed EndpointSlices and the remote endpoint changes reported by the EndpointSlice informers. Services
+// created by svclink are synced by the cycle that creates them and only their sync requests are watched.
+func (r *localServiceReconciler) setupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("local-service").
+		For(&corev1.Service{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return !config.IsSyncedService(e.Object) },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return config.SyncRequested(e.ObjectOld, e.ObjectNew) },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		})).
+		Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(managedSliceService),
+			builder.WithPredicates(r.externalSliceChanges())).
+		WatchesRawSource(source.Channel(r.remoteChanges, &handler.EnqueueRequestForObject{})).
+		Complete(r)
+}
+
+// externalSliceChanges passes the updates and deletions of managed EndpointSlices that svclink did not make, so
+// that they are reverted right away. The writes of svclink itself match the endpoints it last published.
+func (r *localServiceReconciler) externalSliceChanges() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			slice, ok := e.ObjectNew.(*discoveryv1.EndpointSlice)
+			if !ok || !config.IsManagedByUs(e.ObjectOld) || !r.sliceUpdater.ModifiedExternally(slice) {
+				return false
+			}
+			klog.V(2).Infof("EndpointSlice %s/%s was modified outside of svclink, reverting it", slice.Namespace, slice.Name)
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if !config.IsManagedByUs(e.Object) || !r.sliceUpdater.DeletedExternally(e.Object.GetNamespace(), e.Object.GetName()) {
+				return false
+			}
+			klog.V(2).Infof("EndpointSlice %s/%s was deleted outside of svclink, recreating it", e.Object.GetNamespace(), e.Object.GetName())
+			return true
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// managedSliceService returns the local Service of a managed EndpointSlice
+func managedSliceService(_ context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[config.ServiceNameLabel]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+// complete remembers the clusters and services of the last sync cycle
+func (r *localServiceReconciler) complete(_ context.Context, event discoveryCompleted) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last = &event
+	r.imports = make(map[string][]string)
+	for key, svcInfo := range event.services {
+		for _, cluster := range svcInfo.Clusters {
+			remoteKey := remoteServiceKey(cluster, svcInfo.SourceNamespace(cluster), svcInfo.SourceServiceName())
+			r.imports[remoteKey] = append(r.imports[remoteKey], key)
+		}
+	}
+	return nil
+}
+
+// remoteChanged queues the local Services importing a remote service whose EndpointSlices changed. Changes
+// that do not fit in the queue are picked up by the next sync cycle.
+func (r *localServiceReconciler) remoteChanged(cluster, namespace, serviceName string) {
+	r.mu.Lock()
+	keys := r.imports[remoteServiceKey(cluster, namespace, serviceName)]
+	r.mu.Unlock()
+
+	for _, key := range keys {
+		localNamespace, localName, _ := strings.Cut(key, "/")
+		select {
+		case r.remoteChanges <- event.GenericEvent{Object: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: localNamespace, Name: localName},
+		}}:
+		default:
+			klog.V(4).Infof("Leaving the endpoint change of service %s from cluster %s to the next sync cycle", key, cluster)
+		}
+	}
+}
+
+func remoteServiceKey(cluster, namespace, name string) string {
+	return cluster + "/" + namespace + "/" + name
+}
+
+// Reconcile mirrors and publishes the endpoints of a local Service imported from the remote clusters of the
+// last sync cycle. Failed syncs are retried with backoff.
+func (r *localServiceReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	r.cycle.Lock()
+	defer r.cycle.Unlock()
+
+	svcInfo, last := r.lookup(req.String())
+	if svcInfo == nil {
+		return reconcile.Result{}, nil
+	}
+
+	klog.V(2).Infof("Syncing service %s outside of the sync cycle", req)
+	return reconcile.Result{}, r.bus.servicesDiscovered.publish(ctx, servicesDiscovered{
+		clusterInfos: last.clusterInfos,
+		retained:     last.retained,
+		services:     map[string]*apisdiscoverer.ServiceInfo{req.String(): svcInfo},
+	})
+}
+
+// lookup returns a service discovered in the last sync cycle along with the cycle, or nil if it was not discovered
+func (r *localServiceReconciler) lookup(key string) (*apisdiscoverer.ServiceInfo, *discoveryCompleted) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		return nil, nil
+	}
+	svcInfo, ok := r.last.services[key]
+	if !ok {
+		return nil, nil
+	}
+	return svcInfo, r.last
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestLocalServiceReconciler(t *testing.T) {
+	bus := &eventBus{}
+	r := newLocalServiceReconciler(&sync.Mutex{}, nil, bus)
+
+	var synced []string
+	bus.servicesDiscovered.subscribe(func(_ context.Context, event servicesDiscovered) error {
+		synced = append(synced, sets.List(sets.KeySet(event.services))...)
+		return nil
+	})
+
+	ctx := context.Background()
+	api := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "payments", Name: "api"}}
+	if _, err := r.Reconcile(ctx, api); err != nil || len(synced) != 0 {
+		t.Fatalf("expected no sync before the first sync cycle, got %v (err %v)", synced, err)
+	}
+
+	if err := bus.discoveryCompleted.publish(ctx, discoveryCompleted{
+		services: map[string]*apisdiscoverer.ServiceInfo{
+			"payments/api": {Name: "api", Namespace: "payments", Clusters: []string{"east"}},
+			"payments/web": {Name: "web", Namespace: "payments", Clusters: []string{"east"}},
+		},
+		retained: sets.New[string](),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "payments", Name: "ledger"}}
+	for _, req := range []reconcile.Request{api, ledger} {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile(%s) error = %v", req, err)
+		}
+	}
+	if len(synced) != 1 || synced[0] != "payments/api" {
+		t.Errorf("expected only payments/api to be synced, got %v", synced)
+	}
+}
+
+func TestLocalServiceReconcilerRemoteChanged(t *testing.T) {
+	bus := &eventBus{}
+	r := newLocalServiceReconciler(&sync.Mutex{}, nil, bus)
+
+	if err := bus.discoveryCompleted.publish(context.Background(), discoveryCompleted{
+		services: map[string]*apisdiscoverer.ServiceInfo{
+			"payments/api": {
+				Name: "api", Namespace: "payments", Clusters: []string{"east", "west"},
+				SourceNamespaces: map[string]string{"west": "payments-prod"},
+			},
+			"payments/web": {Name: "web", Namespace: "payments", Clusters: []string{"east"}, SourceName: "frontend"},
+		},
+		retained: sets.New[string](),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r.remoteChanged("west", "payments-prod", "api")
+	r.remoteChanged("east", "payments", "frontend")
+	r.remoteChanged("east", "payments", "web")
+	r.remoteChanged("west", "payments", "api")
+
+	var queued []string
+	for len(r.remoteChanges) > 0 {
+		change := <-r.remoteChanges
+		queued = append(queued, client.ObjectKeyFromObject(change.Object).String())
+	}
+	if len(queued) != 2 || queued[0] != "payments/api" || queued[1] != "payments/web" {
+		t.Errorf("expected payments/api and payments/web to be queued, got %v", queued)
+	}
+}
+
+func TestManagedSliceService(t *testing.T) {
+	slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api-svclink-abc"}}
+	if requests := managedSliceService(context.Background(), slice); len(requests) != 0 {
+		t.Errorf("expected no request for an EndpointSlice without service, got %v", requests)
+	}
+
+	if err := config.MarkManaged(slice, "api", "east"); err != nil {
+		t.Fatal(err)
+	}
+	requests := managedSliceService(context.Background(), slice)
+	if len(requests) != 1 || requests[0].String() != "payments/api" {
+		t.Errorf("expected payments/api to be queued, got %v", requests)
+	}
+}
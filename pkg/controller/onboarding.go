@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+// onboarding imports the services of clusters connected for the first time in batches of local namespaces,
+// in lexical order, so that onboarding a cluster with thousands of services doesn't stall the sync of the
+// other clusters. Progress is recorded in the status of the ClusterLink and resumes from it after a restart.
+type onboarding struct {
+	mu sync.Mutex
+	// batchSize is the number of namespaces onboarded per sync cycle; 0 onboards every namespace at once
+	batchSize int
+	// progress holds the onboarding progress of the clusters being onboarded in the current cycle
+	progress map[string]*clusterOnboarding
+}
+
+type clusterOnboarding struct {
+	lastNamespace string
+	// seen and admitted hold the namespaces of the cluster seen and newly admitted in the current cycle
+	seen     sets.Set[string]
+	admitted sets.Set[string]
+	// rejected holds the namespace/name keys of the services of the cluster left to the next cycles
+	rejected sets.Set[string]
+}
+
+func newOnboarding(batchSize int) *onboarding {
+	return &onboarding{batchSize: batchSize}
+}
+
+// begin starts a sync cycle with the clusters being onboarded
+func (o *onboarding) begin(clusterInfos map[string]*clusterlink.ClusterInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.progress = make(map[string]*clusterOnboarding)
+	for name, clusterInfo := range clusterInfos {
+		if status := clusterInfo.ClusterLink.Status.Onboarding; status != nil {
+			o.progress[name] = &clusterOnboarding{
+				lastNamespace: status.LastNamespace,
+				seen:          sets.New[string](),
+				admitted:      sets.New[string](),
+				rejected:      sets.New[string](),
+			}
+		}
+	}
+}
+
+// admit removes the clusters being onboarded from the services of the namespaces left to the next cycles,
+// and the services left without clusters. Services must be admitted in the lexical order of their namespace.
+func (o *onboarding) admit(services map[string]*apisdiscoverer.ServiceInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.progress) == 0 || o.batchSize == 0 {
+		return
+	}
+
+	// Keys are ordered by namespace first, which sorting namespace/name keys doesn't ensure
+	keys := sets.List(sets.KeySet(services))
+	sort.SliceStable(keys, func(i, j int) bool {
+		return services[keys[i]].Namespace < services[keys[j]].Namespace
+	})
+	for _, key := range keys {
+		svcInfo := services[key]
+		clusters := svcInfo.Clusters[:0:0]
+		for _, cluster := range svcInfo.Clusters {
+			progress, ok := o.progress[cluster]
+			if !ok || progress.admit(key, svcInfo.Namespace, o.batchSize) {
+				clusters = append(clusters, cluster)
+			}
+		}
+		if len(clusters) == 0 {
+			delete(services, key)
+			continue
+		}
+		svcInfo.Clusters = clusters
+	}
+}
+
+func (co *clusterOnboarding) admit(key, namespace string, batchSize int) bool {
+	co.seen.Insert(namespace)
+	if namespace <= co.lastNamespace || co.admitted.Has(namespace) {
+		return true
+	}
+	if co.admitted.Len() < batchSize {
+		co.admitted.Insert(namespace)
+		return true
+	}
+	co.rejected.Insert(key)
+	return false
+}
+
+// restrict applies the decisions of the current cycle to services, e.g. to the whole set of services
+// discovered in chunks
+func (o *onboarding) restrict(services map[string]*apisdiscoverer.ServiceInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	rejected := make(map[string]sets.Set[string], len(o.progress))
+	for cluster, progress := range o.progress {
+		rejected[cluster] = progress.rejected
+	}
+	removeRejectedClusters(services, rejected)
+}
+
+// end completes the sync cycle and returns the onboarding progress of each cluster being onboarded,
+// nil for the clusters whose onboarding completed
+func (o *onboarding) end() map[string]*svclinkv1alpha1.OnboardingStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	statuses := make(map[string]*svclinkv1alpha1.OnboardingStatus, len(o.progress))
+	for cluster, progress := range o.progress {
+		if progress.rejected.Len() == 0 {
+			statuses[cluster] = nil
+			continue
+		}
+
+		status := &svclinkv1alpha1.OnboardingStatus{
+			LastNamespace:   progress.lastNamespace,
+			TotalNamespaces: int32(progress.seen.Len()),
+		}
+		for namespace := range progress.admitted {
+			status.LastNamespace = max(status.LastNamespace, namespace)
+		}
+		for namespace := range progress.seen {
+			if namespace <= status.LastNamespace {
+				status.ImportedNamespaces++
+			}
+		}
+		statuses[cluster] = status
+	}
+	o.progress = nil
+	return statuses
+}
+
+// removeRejectedClusters removes the clusters from the services they rejected, given by namespace/name key
+// per cluster, and the services left without clusters
+func removeRejectedClusters(services map[string]*apisdiscoverer.ServiceInfo, rejected map[string]sets.Set[string]) {
+	if len(rejected) == 0 {
+		return
+	}
+	for key, svcInfo := range services {
+		clusters := svcInfo.Clusters[:0:0]
+		for _, cluster := range svcInfo.Clusters {
+			if !rejected[cluster].Has(key) {
+				clusters = append(clusters, cluster)
+			}
+		}
+		if len(clusters) == 0 {
+			delete(services, key)
+			continue
+		}
+		svcInfo.Clusters = clusters
+	}
+}
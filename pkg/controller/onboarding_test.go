@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+func TestOnboarding(t *testing.T) {
+	discovered := func() map[string]*apisdiscoverer.ServiceInfo {
+		services := make(map[string]*apisdiscoverer.ServiceInfo)
+		for _, key := range [][2]string{{"a", "web"}, {"a-b", "web"}, {"b", "api"}, {"b", "web"}, {"c", "web"}} {
+			services[key[0]+"/"+key[1]] = &apisdiscoverer.ServiceInfo{
+				Namespace: key[0],
+				Name:      key[1],
+				Clusters:  []string{"new", "old"},
+			}
+		}
+		return services
+	}
+
+	tests := []struct {
+		name         string
+		batchSize    int
+		status       *svclinkv1alpha1.OnboardingStatus
+		wantImported int
+		wantProgress *svclinkv1alpha1.OnboardingStatus
+	}{
+		{
+			name:         "first batch",
+			batchSize:    2,
+			status:       &svclinkv1alpha1.OnboardingStatus{},
+			wantImported: 2,
+			wantProgress: &svclinkv1alpha1.OnboardingStatus{LastNamespace: "a-b", ImportedNamespaces: 2, TotalNamespaces: 4},
+		},
+		{
+			name:         "last batch completes the onboarding",
+			batchSize:    2,
+			status:       &svclinkv1alpha1.OnboardingStatus{LastNamespace: "a-b", ImportedNamespaces: 2, TotalNamespaces: 4},
+			wantImported: 5,
+		},
+		{
+			name:         "batches disabled",
+			status:       &svclinkv1alpha1.OnboardingStatus{},
+			wantImported: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterInfos := map[string]*clusterlink.ClusterInfo{"old": {}, "new": {}}
+			clusterInfos["new"].ClusterLink.Status.Onboarding = tt.status
+			services := discovered()
+
+			o := newOnboarding(tt.batchSize)
+			o.begin(clusterInfos)
+			o.admit(services)
+			progress := o.end()
+
+			imported := 0
+			for _, svcInfo := range services {
+				if len(svcInfo.Clusters) == 2 {
+					imported++
+				} else if !reflect.DeepEqual(svcInfo.Clusters, []string{"old"}) {
+					t.Errorf("expected clusters not being onboarded to be kept, got %v", svcInfo.Clusters)
+				}
+			}
+			if imported != tt.wantImported {
+				t.Errorf("expected %d services imported from the onboarded cluster, got %d", tt.wantImported, imported)
+			}
+			if got, ok := progress["new"]; !ok || !reflect.DeepEqual(got, tt.wantProgress) {
+				t.Errorf("expected progress %+v, got %+v", tt.wantProgress, got)
+			}
+			if _, ok := progress["old"]; ok {
+				t.Error("expected no progress for a cluster not being onboarded")
+			}
+		})
+	}
+}
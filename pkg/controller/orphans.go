@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// OrphansPath is the metrics server path serving the orphaned services report
+const OrphansPath = "/orphans"
+
+// orphanTracker finds the local services imported by svclink, either created by it or holding EndpointSlices it
+// manages, that no connected remote cluster exposes anymore. Services with EndpointSlices of retained clusters
+// are not orphans. Orphans are reported through metrics and the orphans endpoint, and once a service stayed
+// orphaned for the orphan expiry, its managed Service and EndpointSlices are pruned. The Services svclink created
+// are pruned after the synced service retention instead, when it is shorter.
+type orphanTracker struct {
+	ctrlClient     client.Client
+	expiry         time.Duration
+	retention      time.Duration
+	sliceUpdater   *updater.SliceUpdater
+	serviceUpdater *updater.ServiceUpdater
+	// since holds when each orphan, as namespace/name, was first found. It is only accessed from the sync loop.
+	since map[string]time.Time
+
+	mu     sync.RWMutex
+	report apisdiscoverer.OrphanReport
+}
+
+func newOrphanTracker(ctrlClient client.Client, cfg *config.Config, sliceUpdater *updater.SliceUpdater,
+	serviceUpdater *updater.ServiceUpdater, bus *eventBus) *orphanTracker {
+	t := &orphanTracker{
+		ctrlClient:     ctrlClient,
+		expiry:         cfg.OrphanExpiry,
+		retention:      cfg.SyncedServiceRetention,
+		sliceUpdater:   sliceUpdater,
+		serviceUpdater: serviceUpdater,
+		since:          make(map[string]time.Time),
+		report:         apisdiscoverer.OrphanReport{Services: []apisdiscoverer.OrphanedService{}},
+	}
+	bus.discoveryCompleted.subscribe(t.complete)
+	return t
+}
+
+func (t *orphanTracker) complete(ctx context.Context, event discoveryCompleted) error {
+	imported, err := t.importedServices(ctx, event.retained)
+	if err != nil {
+		return err
+	}
+
+	orphans := findOrphans(imported, event.services, t.since, time.Now())
+	// The services of a cluster that failed its discovery are missing from the discovered services
+	failed := discoveryFailed(event.clusterInfos)
+	var kept []apisdiscoverer.OrphanedService
+	for _, orphan := range orphans {
+		expiry := t.expiryFor(orphan)
+		if expiry <= 0 {
+			kept = append(kept, orphan)
+			continue
+		}
+		pruneAt := orphan.Since.Add(expiry)
+		if time.Now().Before(pruneAt) || failed {
+			orphan.PruneAt = &pruneAt
+			kept = append(kept, orphan)
+			continue
+		}
+		if err := t.prune(ctx, orphan); err != nil {
+			klog.Errorf("Failed to prune orphaned service %s/%s: %v", orphan.Namespace, orphan.Name, err)
+			orphan.PruneAt = &pruneAt
+			kept = append(kept, orphan)
+			continue
+		}
+		delete(t.since, orphan.Namespace+"/"+orphan.Name)
+	}
+
+	t.publish(kept)
+	return nil
+}
+
+// expiryFor returns how long an orphan is kept before it is pruned, 0 keeping it: the synced service retention for
+// the Services svclink created, or the orphan expiry if it is shorter
+func (t *orphanTracker) expiryFor(orphan apisdiscoverer.OrphanedService) time.Duration {
+	if !orphan.Created || t.retention <= 0 {
+		return t.expiry
+	}
+	if t.expiry > 0 {
+		return min(t.expiry, t.retention)
+	}
+	return t.retention
+}
+
+// discoveryFailed reports whether the discovery of a connected cluster failed in the sync cycle
+func discoveryFailed(clusterInfos map[string]*clusterlink.ClusterInfo) bool {
+	for _, clusterInfo := range clusterInfos {
+		if clusterInfo.ClusterLink.Status.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// importedServices returns the local services imported by svclink, keyed by namespace/name. The services
+// holding EndpointSlices of retained clusters are left out, as their clusters are expected to come back.
+func (t *orphanTracker) importedServices(ctx context.Context, retained sets.Set[string]) (map[string]*apisdiscoverer.OrphanedService, error) {
+	imported := make(map[string]*apisdiscoverer.OrphanedService)
+	service := func(namespace, name string) *apisdiscoverer.OrphanedService {
+		key := namespace + "/" + name
+		if imported[key] == nil {
+			imported[key] = &apisdiscoverer.OrphanedService{Namespace: namespace, Name: name}
+		}
+		return imported[key]
+	}
+
+	var svcList corev1.ServiceList
+	if err := t.ctrlClient.List(ctx, &svcList); err != nil {
+		return nil, err
+	}
+	for _, svc := range svcList.Items {
+		if config.IsSyncedService(&svc) {
+			service(svc.Namespace, svc.Name).Created = true
+		}
+	}
+
+	clusterReq, err := labels.NewRequirement(config.ClusterLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	var sliceList discoveryv1.EndpointSliceList
+	if err := t.ctrlClient.List(ctx, &sliceList, client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*clusterReq)}); err != nil {
+		return nil, err
+	}
+	retainedServices := sets.New[string]()
+	for _, slice := range sliceList.Items {
+		name := slice.Labels[config.ServiceNameLabel]
+		if !config.IsManagedByUs(&slice) || name == "" {
+			continue
+		}
+		service(slice.Namespace, name).EndpointSlices++
+		if cluster, _ := config.SourceCluster(&slice); retained.Has(cluster) {
+			retainedServices.Insert(slice.Namespace + "/" + name)
+		}
+	}
+
+	for key := range retainedServices {
+		delete(imported, key)
+	}
+	return imported, nil
+}
+
+// findOrphans returns the imported services missing from the discovered services, sorted by namespace/name.
+// since is updated to hold when each orphan was first found, orphans found for the first time are since now.
+func findOrphans(imported map[string]*apisdiscoverer.OrphanedService, discovered map[string]*apisdiscoverer.ServiceInfo,
+	since map[string]time.Time, now time.Time) []apisdiscoverer.OrphanedService {
+	var orphans []apisdiscoverer.OrphanedService
+	for _, key := range sets.List(sets.KeySet(imported)) {
+		if _, ok := discovered[key]; ok {
+			continue
+		}
+		if _, ok := since[key]; !ok {
+			since[key] = now
+		}
+		orphan := *imported[key]
+		orphan.Since = since[key]
+		orphans = append(orphans, orphan)
+	}
+
+	// Services exposed again, or whose managed resources are gone, start over when they are orphaned again
+	for key := range since {
+		if _, ok := imported[key]; !ok {
+			delete(since, key)
+		} else if _, ok := discovered[key]; ok {
+			delete(since, key)
+		}
+	}
+	return orphans
+}
+
+// prune deletes the managed EndpointSlices and Endpoints of an orphaned service and the Service itself if svclink
+// created it
+func (t *orphanTracker) prune(ctx context.Context, orphan apisdiscoverer.OrphanedService) error {
+	if err := t.sliceUpdater.DeleteServiceSlices(ctx, orphan.Namespace, orphan.Name); err != nil {
+		return err
+	}
+	if err := t.sliceUpdater.DeleteServiceEndpoints(ctx, orphan.Namespace, orphan.Name); err != nil {
+		return err
+	}
+	if orphan.Created {
+		if err := t.serviceUpdater.DeleteSyncedService(ctx, orphan.Namespace, orphan.Name); err != nil {
+			return err
+		}
+	}
+	metrics.PrunedOrphans.WithLabelValues(orphan.Namespace).Inc()
+	klog.Infof("Pruned orphaned service %s/%s, orphaned since %s", orphan.Namespace, orphan.Name,
+		orphan.Since.Format(time.RFC3339))
+	return nil
+}
+
+// publish replaces the report with the orphans of the finished cycle and updates the metrics
+func (t *orphanTracker) publish(orphans []apisdiscoverer.OrphanedService) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if orphans == nil {
+		orphans = []apisdiscoverer.OrphanedService{}
+	}
+	t.report = apisdiscoverer.OrphanReport{Services: orphans}
+
+	metrics.OrphanedServices.Reset()
+	for _, orphan := range orphans {
+		metrics.OrphanedServices.WithLabelValues(orphan.Namespace).Inc()
+	}
+}
+
+// ServeHTTP serves the orphaned services report of the last sync cycle as JSON
+func (t *orphanTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// servicesClient serves a fixed list of Services without EndpointSlices and records deletes
+type servicesClient struct {
+	client.Client
+	services []corev1.Service
+	deleted  []string
+}
+
+func (c *servicesClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch list := list.(type) {
+	case *corev1.ServiceList:
+		list.Items = c.services
+	case *discoveryv1.EndpointSliceList:
+		list.Items = nil
+	}
+	return nil
+}
+
+func (c *servicesClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	for _, svc := range c.services {
+		if svc.Namespace == key.Namespace && svc.Name == key.Name {
+			svc.DeepCopyInto(obj.(*corev1.Service))
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *servicesClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	c.deleted = append(c.deleted, obj.GetNamespace()+"/"+obj.GetName())
+	return nil
+}
+
+func TestFindOrphans(t *testing.T) {
+	first := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	now := first.Add(time.Hour)
+
+	imported := map[string]*apisdiscoverer.OrphanedService{
+		"payments/api":    {Namespace: "payments", Name: "api", Created: true, EndpointSlices: 2},
+		"payments/ledger": {Namespace: "payments", Name: "ledger", EndpointSlices: 1},
+		"orders/api":      {Namespace: "orders", Name: "api", EndpointSlices: 1},
+	}
+	discovered := map[string]*apisdiscoverer.ServiceInfo{
+		"payments/api": {Name: "api", Namespace: "payments"},
+	}
+	since := map[string]time.Time{
+		"payments/ledger": first,
+		// Exposed again and gone locally, both start over
+		"payments/api":  first,
+		"legacy/worker": first,
+	}
+
+	orphans := findOrphans(imported, discovered, since, now)
+
+	want := []apisdiscoverer.OrphanedService{
+		{Namespace: "orders", Name: "api", EndpointSlices: 1, Since: now},
+		{Namespace: "payments", Name: "ledger", EndpointSlices: 1, Since: first},
+	}
+	if len(orphans) != len(want) {
+		t.Fatalf("findOrphans() = %+v, want %+v", orphans, want)
+	}
+	for i := range want {
+		if orphans[i].Namespace != want[i].Namespace || orphans[i].Name != want[i].Name ||
+			orphans[i].EndpointSlices != want[i].EndpointSlices || !orphans[i].Since.Equal(want[i].Since) {
+			t.Errorf("orphan %d = %+v, want %+v", i, orphans[i], want[i])
+		}
+	}
+
+	if len(since) != 2 || !since["orders/api"].Equal(now) || !since["payments/ledger"].Equal(first) {
+		t.Errorf("since = %v, want orders/api at %v and payments/ledger at %v", since, now, first)
+	}
+}
+
+func TestOrphanTrackerExpiryFor(t *testing.T) {
+	created := apisdiscoverer.OrphanedService{Created: true}
+	imported := apisdiscoverer.OrphanedService{EndpointSlices: 1}
+
+	tests := []struct {
+		name      string
+		expiry    time.Duration
+		retention time.Duration
+		orphan    apisdiscoverer.OrphanedService
+		want      time.Duration
+	}{
+		{name: "created service after the retention", retention: time.Hour, orphan: created, want: time.Hour},
+		{name: "created service after a shorter expiry", expiry: 10 * time.Minute, retention: time.Hour, orphan: created, want: 10 * time.Minute},
+		{name: "created service after a shorter retention", expiry: 24 * time.Hour, retention: time.Hour, orphan: created, want: time.Hour},
+		{name: "created service without retention", expiry: 24 * time.Hour, orphan: created, want: 24 * time.Hour},
+		{name: "created service kept", orphan: created, want: 0},
+		{name: "user service left to the expiry", expiry: 24 * time.Hour, retention: time.Hour, orphan: imported, want: 24 * time.Hour},
+		{name: "user service kept", retention: time.Hour, orphan: imported, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &orphanTracker{expiry: tt.expiry, retention: tt.retention}
+			if got := tracker.expiryFor(tt.orphan); got != tt.want {
+				t.Errorf("expiryFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrphanTrackerPrunesCreatedServices(t *testing.T) {
+	synced := func(name string) corev1.Service {
+		svc := corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: name}}
+		config.MarkSynced(&svc)
+		return svc
+	}
+	cfg := &config.Config{SyncedServiceRetention: time.Hour}
+	healthy := map[string]*clusterlink.ClusterInfo{"east": {}}
+	broken := map[string]*clusterlink.ClusterInfo{"east": {ClusterLink: svclinkv1alpha1.ClusterLink{
+		Status: svclinkv1alpha1.ClusterLinkStatus{Error: "Service sync error: timeout"},
+	}}}
+
+	tests := []struct {
+		name         string
+		clusterInfos map[string]*clusterlink.ClusterInfo
+		wantDeleted  []string
+		wantKept     []string
+	}{
+		{name: "retention elapsed", clusterInfos: healthy, wantDeleted: []string{"payments/api"}, wantKept: []string{"web"}},
+		{name: "discovery failed", clusterInfos: broken, wantKept: []string{"api", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &servicesClient{services: []corev1.Service{
+				synced("api"),
+				synced("web"),
+				{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "local"}},
+			}}
+			tracker := newOrphanTracker(fake, cfg, updater.NewSliceUpdater(fake, fake, cfg, nil),
+				updater.NewServiceUpdater(fake, cfg), &eventBus{})
+			tracker.since["payments/api"] = time.Now().Add(-2 * time.Hour)
+			tracker.since["payments/web"] = time.Now().Add(-10 * time.Minute)
+
+			if err := tracker.complete(context.Background(), discoveryCompleted{clusterInfos: tt.clusterInfos}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(fake.deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", fake.deleted, tt.wantDeleted)
+			}
+			var kept []string
+			for _, orphan := range tracker.report.Services {
+				if orphan.PruneAt == nil {
+					t.Errorf("orphan %s has no prune time", orphan.Name)
+				}
+				kept = append(kept, orphan.Name)
+			}
+			if !slices.Equal(kept, tt.wantKept) {
+				t.Errorf("kept orphans = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+)
+
+// applyPin restricts the clusters of a service to the clusters its local Service is pinned to with
+// "svclink pin", while the pin lasts. The EndpointSlices of the other clusters are removed by the sync.
+func (r *endpointPublicationReconciler) applyPin(ctx context.Context, svcInfo *apisdiscoverer.ServiceInfo) *apisdiscoverer.ServiceInfo {
+	local := &corev1.Service{}
+	if err := r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: svcInfo.Namespace, Name: svcInfo.Name}, local); err != nil {
+		return svcInfo
+	}
+	clusters, ok, err := config.PinnedClusters(local, time.Now())
+	if err != nil {
+		klog.Errorf("Ignoring pin: %v", err)
+		return svcInfo
+	}
+	if !ok {
+		return svcInfo
+	}
+
+	pinned := *svcInfo
+	pinnedSet := sets.New(clusters...)
+	pinned.Clusters = nil
+	for _, cluster := range svcInfo.Clusters {
+		if pinnedSet.Has(cluster) {
+			pinned.Clusters = append(pinned.Clusters, cluster)
+		}
+	}
+	if len(pinned.Clusters) == 0 {
+		klog.Warningf("Service %s/%s is pinned to clusters %v that don't export it, no endpoints are published",
+			svcInfo.Namespace, svcInfo.Name, clusters)
+	}
+	logging.V(4, svcInfo.Namespace, svcInfo.Namespace+"/"+svcInfo.Name).Infof("Service %s/%s is pinned to clusters %v until %s",
+		svcInfo.Namespace, svcInfo.Name, clusters, local.Annotations[config.PinnedUntilAnnotation])
+	return &pinned
+}
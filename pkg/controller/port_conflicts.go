@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"slices"
+	"sync"
+)
+
+// portConflicts remembers the port conflicts last reported for each service, so that the clusters of a service
+// disagreeing on its ports are reported when the disagreement changes rather than every sync cycle
+type portConflicts struct {
+	mu sync.Mutex
+	// reported holds the conflicts last reported per namespace/name key
+	reported map[string][]string
+}
+
+func newPortConflicts() *portConflicts {
+	return &portConflicts{reported: make(map[string][]string)}
+}
+
+// changed records the port conflicts of a service and reports whether they differ from those last recorded
+func (pc *portConflicts) changed(key string, conflicts []string) bool {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if slices.Equal(pc.reported[key], conflicts) {
+		return false
+	}
+	if len(conflicts) == 0 {
+		delete(pc.reported, key)
+	} else {
+		pc.reported[key] = conflicts
+	}
+	return len(conflicts) > 0
+}
+
+// prune forgets services that are no longer discovered
+func (pc *portConflicts) prune(active func(key string) bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	for key := range pc.reported {
+		if !active(key) {
+			delete(pc.reported, key)
+		}
+	}
+}
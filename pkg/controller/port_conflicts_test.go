@@ -0,0 +1,26 @@
+package controller
+
+import "testing"
+
+func TestPortConflicts(t *testing.T) {
+	pc := newPortConflicts()
+
+	if pc.changed("prod/web", nil) {
+		t.Error("expected a service without conflicts not to be reported")
+	}
+	conflicts := []string{`port "metrics" is not exported by clusters [west]`}
+	if !pc.changed("prod/web", conflicts) {
+		t.Error("expected new conflicts to be reported")
+	}
+	if pc.changed("prod/web", conflicts) {
+		t.Error("expected conflicts to be reported once")
+	}
+	if pc.changed("prod/web", nil) || !pc.changed("prod/web", conflicts) {
+		t.Error("expected conflicts coming back to be reported again")
+	}
+
+	pc.prune(func(key string) bool { return key != "prod/web" })
+	if !pc.changed("prod/web", conflicts) {
+		t.Error("expected the conflicts of a pruned service to be reported again")
+	}
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
+)
+
+// reachabilityProber dials a sample of the endpoints imported from the clusters with spec.reachabilityProbe, to
+// tell whether their pod network is routable from the local cluster. The sample of every cluster is drawn
+// uniformly from the ready TCP endpoints published in the sync cycle. Services are synced concurrently, so the
+// samples are guarded by a mutex.
+type reachabilityProber struct {
+	sampleSize int
+	timeout    time.Duration
+
+	mu sync.Mutex
+	// seen counts the candidate endpoints per cluster, samples holds the sampled host:port addresses
+	seen    map[string]int
+	samples map[string][]string
+}
+
+func newReachabilityProber(sampleSize int, timeout time.Duration) *reachabilityProber {
+	return &reachabilityProber{
+		sampleSize: sampleSize,
+		timeout:    timeout,
+		seen:       make(map[string]int),
+		samples:    make(map[string][]string),
+	}
+}
+
+// record offers the endpoints published for a service to the samples of the probed clusters
+func (p *reachabilityProber) record(clusterInfos map[string]*clusterlink.ClusterInfo, clusterEndpoints []aggregator.ClusterEndpoints) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, ce := range clusterEndpoints {
+		clusterInfo, ok := clusterInfos[ce.ClusterName]
+		if !ok || !clusterInfo.ClusterLink.Spec.ReachabilityProbe || ce.AddressType == discoveryv1.AddressTypeFQDN {
+			continue
+		}
+		port, ok := tcpPort(ce.Ports)
+		if !ok {
+			continue
+		}
+		for _, endpoint := range ce.Endpoints {
+			if (endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready) || len(endpoint.Addresses) == 0 {
+				continue
+			}
+			p.offer(ce.ClusterName, net.JoinHostPort(endpoint.Addresses[0], strconv.Itoa(int(port))))
+		}
+	}
+}
+
+// offer adds an address to the sample of a cluster by reservoir sampling
+func (p *reachabilityProber) offer(cluster, address string) {
+	p.seen[cluster]++
+	if len(p.samples[cluster]) < p.sampleSize {
+		p.samples[cluster] = append(p.samples[cluster], address)
+		return
+	}
+	if i := rand.IntN(p.seen[cluster]); i < p.sampleSize {
+		p.samples[cluster][i] = address
+	}
+}
+
+// probe dials the sampled endpoints of the probed clusters, starts a new sample and returns the reachability of
+// every probed cluster, which is empty for clusters without endpoints
+func (p *reachabilityProber) probe(ctx context.Context, clusterInfos map[string]*clusterlink.ClusterInfo) map[string]*clusterlink.Reachability {
+	p.mu.Lock()
+	samples := p.samples
+	p.seen = make(map[string]int)
+	p.samples = make(map[string][]string)
+	p.mu.Unlock()
+
+	results := make(map[string]*clusterlink.Reachability)
+	for name, clusterInfo := range clusterInfos {
+		if clusterInfo.ClusterLink.Spec.ReachabilityProbe {
+			results[name] = &clusterlink.Reachability{Sampled: len(samples[name])}
+		}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	dialer := &net.Dialer{Timeout: p.timeout}
+	for cluster, addresses := range samples {
+		result, ok := results[cluster]
+		if !ok {
+			continue
+		}
+		for _, address := range addresses {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				conn, err := dialer.DialContext(ctx, "tcp", address)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					result.Failure = dialFailure(err)
+					return
+				}
+				_ = conn.Close()
+				result.Reached++
+			}()
+		}
+	}
+	wg.Wait()
+
+	metrics.NetworkReachableRatio.Reset()
+	for cluster, result := range results {
+		if result.Sampled > 0 {
+			metrics.NetworkReachableRatio.WithLabelValues(cluster).Set(float64(result.Reached) / float64(result.Sampled))
+		}
+	}
+	return results
+}
+
+// tcpPort returns the first TCP port of an EndpointSlice
+func tcpPort(ports []discoveryv1.EndpointPort) (int32, bool) {
+	for _, port := range ports {
+		if port.Port != nil && (port.Protocol == nil || *port.Protocol == corev1.ProtocolTCP) {
+			return *port.Port, true
+		}
+	}
+	return 0, false
+}
+
+// dialFailure returns the cause of a dial error without the dialed address, so that the condition reporting
+// it does not change with every sample
+func dialFailure(err error) string {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Err != nil {
+		return opErr.Err.Error()
+	}
+	return err.Error()
+}
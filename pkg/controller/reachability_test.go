@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+func TestReachabilityProber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	open := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	// A port that was just released refuses connections
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := int32(closedListener.Addr().(*net.TCPAddr).Port)
+	closedListener.Close()
+
+	clusterInfos := map[string]*clusterlink.ClusterInfo{
+		"east":  {ClusterLink: svclinkv1alpha1.ClusterLink{Spec: svclinkv1alpha1.ClusterLinkSpec{ReachabilityProbe: true}}},
+		"west":  {ClusterLink: svclinkv1alpha1.ClusterLink{Spec: svclinkv1alpha1.ClusterLinkSpec{ReachabilityProbe: true}}},
+		"north": {ClusterLink: svclinkv1alpha1.ClusterLink{Spec: svclinkv1alpha1.ClusterLinkSpec{ReachabilityProbe: true}}},
+		"south": {},
+	}
+	endpoints := func(ready ...bool) []discoveryv1.Endpoint {
+		var result []discoveryv1.Endpoint
+		for _, r := range ready {
+			result = append(result, discoveryv1.Endpoint{Addresses: []string{"127.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(r)}})
+		}
+		return result
+	}
+
+	p := newReachabilityProber(2, time.Second)
+	p.record(clusterInfos, []aggregator.ClusterEndpoints{
+		{ClusterName: "east", AddressType: discoveryv1.AddressTypeIPv4, Ports: []discoveryv1.EndpointPort{{Port: ptr.To(open)}}, Endpoints: endpoints(true, true, true)},
+		{ClusterName: "west", AddressType: discoveryv1.AddressTypeIPv4, Ports: []discoveryv1.EndpointPort{{Port: ptr.To(closed)}}, Endpoints: endpoints(true, false)},
+		{ClusterName: "south", AddressType: discoveryv1.AddressTypeIPv4, Ports: []discoveryv1.EndpointPort{{Port: ptr.To(open)}}, Endpoints: endpoints(true)},
+	})
+
+	results := p.probe(context.Background(), clusterInfos)
+	if len(results) != 3 {
+		t.Fatalf("expected results for the 3 probed clusters, got %v", results)
+	}
+	if east := results["east"]; east.Sampled != 2 || east.Reached != 2 {
+		t.Errorf("expected east to reach its 2 sampled endpoints, got %+v", east)
+	}
+	if west := results["west"]; west.Sampled != 1 || west.Reached != 0 || west.Failure == "" {
+		t.Errorf("expected west to fail its only ready endpoint, got %+v", west)
+	}
+	if north := results["north"]; north.Sampled != 0 {
+		t.Errorf("expected north to have no endpoints to probe, got %+v", north)
+	}
+	if strings.Contains(results["west"].Failure, "127.0.0.1") {
+		t.Errorf("expected the failure not to contain the address, got %q", results["west"].Failure)
+	}
+
+	if results := p.probe(context.Background(), clusterInfos); results["east"].Sampled != 0 {
+		t.Errorf("expected the sample to restart after a probe, got %+v", results["east"])
+	}
+}
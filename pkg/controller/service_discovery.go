@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/discoverer"
+)
+
+// serviceDiscoveryReconciler finds the services exported by the connected clusters
+type serviceDiscoveryReconciler struct {
+	ctrlClient        client.Client
+	links             *clusterlink.Links
+	cfg               *config.Config
+	serviceDiscoverer *discoverer.ServiceDiscoverer
+	recorder          record.EventRecorder
+	bus               *eventBus
+	quota             *serviceQuota
+	onboarding        *onboarding
+}
+
+func newServiceDiscoveryReconciler(ctrlClient client.Client, links *clusterlink.Links, cfg *config.Config, serviceDiscoverer *discoverer.ServiceDiscoverer,
+	recorder record.EventRecorder, bus *eventBus) *serviceDiscoveryReconciler {
+	r := &serviceDiscoveryReconciler{
+		ctrlClient:        ctrlClient,
+		links:             links,
+		cfg:               cfg,
+		serviceDiscoverer: serviceDiscoverer,
+		recorder:          recorder,
+		bus:               bus,
+		quota:             newServiceQuota(),
+		onboarding:        newOnboarding(cfg.OnboardingBatchSize),
+	}
+	bus.clustersConnected.subscribe(r.reconcile)
+	return r
+}
+
+func (r *serviceDiscoveryReconciler) reconcile(ctx context.Context, event clustersConnected) error {
+	// Discover which remote clusters have these services
+	klog.Info("Discovering services across clusters")
+	started := time.Now()
+	if r.cfg.MemoryLimit > 0 {
+		return r.reconcileInChunks(ctx, event, started)
+	}
+
+	services, err := r.serviceDiscoverer.DiscoverServices(ctx, event.clusterInfos, r.cfg.IncludedNamespaces)
+	if err != nil {
+		return fmt.Errorf("failed to discover services: %w", err)
+	}
+
+	limits := serviceLimits(event.clusterInfos)
+	r.onboarding.begin(event.clusterInfos)
+	r.quota.begin()
+	r.onboarding.admit(services)
+	r.quota.admit(services, limits)
+	r.reportOnboarding(ctx, event.clusterInfos)
+	rejected := r.reportQuota(ctx, event.clusterInfos, limits)
+
+	err = r.bus.servicesDiscovered.publish(ctx, servicesDiscovered{
+		clusterInfos: event.clusterInfos,
+		retained:     event.retained,
+		services:     services,
+	})
+	return utilserrors.NewAggregate([]error{err, r.bus.discoveryCompleted.publish(ctx, discoveryCompleted{
+		clusterInfos: event.clusterInfos,
+		retained:     event.retained,
+		services:     services,
+		skipped:      r.skippedServices(rejected),
+		started:      started,
+	})})
+}
+
+// reconcileInChunks publishes the discovered services one namespace at a time, which bounds
+// memory use by the largest namespace rather than by the number of services across all clusters
+func (r *serviceDiscoveryReconciler) reconcileInChunks(ctx context.Context, event clustersConnected, started time.Time) error {
+	limits := serviceLimits(event.clusterInfos)
+	r.onboarding.begin(event.clusterInfos)
+	r.quota.begin()
+	services, err := r.serviceDiscoverer.DiscoverServicesInChunks(ctx, event.clusterInfos, r.cfg.IncludedNamespaces, r.cfg.ListPageSize,
+		func(ctx context.Context, chunk map[string]*apisdiscoverer.ServiceInfo) error {
+			r.onboarding.admit(chunk)
+			r.quota.admit(chunk, limits)
+			if len(chunk) == 0 {
+				return nil
+			}
+			return r.bus.servicesDiscovered.publish(ctx, servicesDiscovered{
+				clusterInfos: event.clusterInfos,
+				retained:     event.retained,
+				services:     chunk,
+			})
+		})
+	r.onboarding.restrict(services)
+	r.quota.restrict(services)
+	r.reportOnboarding(ctx, event.clusterInfos)
+	rejected := r.reportQuota(ctx, event.clusterInfos, limits)
+
+	return utilserrors.NewAggregate([]error{err, r.bus.discoveryCompleted.publish(ctx, discoveryCompleted{
+		clusterInfos: event.clusterInfos,
+		retained:     event.retained,
+		services:     services,
+		skipped:      r.skippedServices(rejected),
+		started:      started,
+	})})
+}
+
+// serviceLimits returns the spec.maxServices of the clusters that set it
+func serviceLimits(clusterInfos map[string]*clusterlink.ClusterInfo) map[string]int {
+	limits := make(map[string]int)
+	for name, clusterInfo := range clusterInfos {
+		if maxServices := clusterInfo.ClusterLink.Spec.MaxServices; maxServices != nil {
+			limits[name] = int(*maxServices)
+		}
+	}
+	return limits
+}
+
+// skippedServices adds the services rejected by spec.maxServices to the services suppressed by filters, per cluster
+func (r *serviceDiscoveryReconciler) skippedServices(rejected map[string]int) map[string]int {
+	skipped := r.serviceDiscoverer.FilteredServices()
+	for cluster, count := range rejected {
+		skipped[cluster] += count
+	}
+	return skipped
+}
+
+// reportQuota completes the quota cycle, sets the QuotaExceeded condition of the clusters with rejected services
+// and returns the number of services rejected per cluster
+func (r *serviceDiscoveryReconciler) reportQuota(ctx context.Context, clusterInfos map[string]*clusterlink.ClusterInfo, limits map[string]int) map[string]int {
+	rejected := r.quota.end()
+	for name, clusterInfo := range clusterInfos {
+		var message string
+		if rejected[name] > 0 {
+			message = fmt.Sprintf("%d services are not imported, the cluster exports more than maxServices %d",
+				rejected[name], limits[name])
+		}
+		if !r.links.SetQuotaExceeded(ctx, r.ctrlClient, clusterInfo, message) || message == "" {
+			continue
+		}
+		klog.Warningf("ClusterLink %s: %s", name, message)
+		r.recorder.Eventf(&clusterInfo.ClusterLink, corev1.EventTypeWarning, "ServiceQuotaExceeded", "%s", message)
+	}
+	return rejected
+}
+
+// reportOnboarding completes the onboarding cycle and records the progress of the clusters being onboarded.
+// Clusters that failed to sync keep their progress, as the namespaces they export are not known.
+func (r *serviceDiscoveryReconciler) reportOnboarding(ctx context.Context, clusterInfos map[string]*clusterlink.ClusterInfo) {
+	for name, progress := range r.onboarding.end() {
+		clusterInfo, ok := clusterInfos[name]
+		if !ok || clusterInfo.ClusterLink.Status.Error != "" {
+			continue
+		}
+		if progress != nil {
+			klog.Infof("Onboarding ClusterLink %s: %d of %d namespaces imported", name, progress.ImportedNamespaces, progress.TotalNamespaces)
+		}
+		clusterlink.UpdateOnboarding(ctx, r.ctrlClient, clusterInfo, progress)
+	}
+}
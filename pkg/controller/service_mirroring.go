@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// serviceMirroringReconciler makes sure every discovered service has a local Service. Services are
+// created locally when SyncServicesToLocalCluster is set, otherwise services without one are dropped.
+type serviceMirroringReconciler struct {
+	ctrlClient     client.Client
+	cfg            *config.Config
+	serviceUpdater *updater.ServiceUpdater
+	bus            *eventBus
+}
+
+func newServiceMirroringReconciler(ctrlClient client.Client, cfg *config.Config, serviceUpdater *updater.ServiceUpdater, bus *eventBus) *serviceMirroringReconciler {
+	r := &serviceMirroringReconciler{
+		ctrlClient:     ctrlClient,
+		cfg:            cfg,
+		serviceUpdater: serviceUpdater,
+		bus:            bus,
+	}
+	bus.servicesDiscovered.subscribe(r.reconcile)
+	return r
+}
+
+func (r *serviceMirroringReconciler) reconcile(ctx context.Context, event servicesDiscovered) error {
+	services := event.services
+
+	if r.cfg.SyncServicesToLocalCluster {
+		klog.Info("Syncing services to local cluster")
+		if err := r.serviceUpdater.SyncServicesToLocalCluster(ctx, services); err != nil {
+			return fmt.Errorf("failed to update services in local cluster: %w", err)
+		}
+	} else {
+		filteredServices, err := r.filterServicesExistingInLocalCluster(ctx, r.cfg.IncludedNamespaces, services)
+		if err != nil {
+			return fmt.Errorf("failed to filter services: %w", err)
+		}
+		services = filteredServices
+	}
+
+	return r.bus.servicesMirrored.publish(ctx, servicesMirrored{
+		clusterInfos: event.clusterInfos,
+		retained:     event.retained,
+		services:     services,
+	})
+}
+
+// filterServicesExistingInLocalCluster filters the services map to only include services
+// that exist in the local cluster. This ensures EndpointSlices are only created for
+// services that have a corresponding Service object in the local cluster.
+// Local services are listed per namespace of the discovered services.
+func (r *serviceMirroringReconciler) filterServicesExistingInLocalCluster(ctx context.Context, includedNamespaces []string, services map[string]*apisdiscoverer.ServiceInfo) (map[string]*apisdiscoverer.ServiceInfo, error) {
+	includedNSSet := sets.New(includedNamespaces...)
+	namespaces := sets.New[string]()
+	for _, svcInfo := range services {
+		// Check if the service is in an included namespace
+		if includedNSSet.Len() > 0 && !includedNSSet.Has(svcInfo.Namespace) {
+			continue
+		}
+		namespaces.Insert(svcInfo.Namespace)
+	}
+
+	// Build a set of local services for efficient lookup
+	localServices := make(map[string]struct{})
+	for namespace := range namespaces {
+		var svcList corev1.ServiceList
+		if err := r.ctrlClient.List(ctx, &svcList, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, svc := range svcList.Items {
+			key := svc.Namespace + "/" + svc.Name
+			localServices[key] = struct{}{}
+		}
+	}
+
+	// Filter services to only include those that exist locally
+	filtered := make(map[string]*apisdiscoverer.ServiceInfo)
+	for key, svcInfo := range services {
+		if _, existsLocally := localServices[key]; existsLocally {
+			filtered[key] = svcInfo
+		}
+	}
+
+	return filtered, nil
+}
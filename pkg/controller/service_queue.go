@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+const (
+	// serviceRetryQPS and serviceRetryBurst bound the overall rate of service retries, so that many services
+	// failing at once are retried in a steady stream rather than all together
+	serviceRetryQPS   = 10
+	serviceRetryBurst = 100
+)
+
+// queuedService is a service waiting in the service queue, with the clusters of the sync it was queued by
+type queuedService struct {
+	svcInfo      *apisdiscoverer.ServiceInfo
+	clusterInfos map[string]*clusterlink.ClusterInfo
+	retained     sets.Set[string]
+	verify       bool
+}
+
+// serviceBatch is the services queued by one sync, which waits until each of them was synced once
+type serviceBatch struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func (b *serviceBatch) done(err error) {
+	if err != nil {
+		b.mu.Lock()
+		b.errs = append(b.errs, err)
+		b.mu.Unlock()
+	}
+	b.wg.Done()
+}
+
+// serviceQueue syncs services from a rate-limited workqueue keyed by namespace/name. A sync waits for the first
+// attempt of each of its services only; services that fail are retried on their own with exponential backoff,
+// so that a flapping service neither delays the other services nor waits for the next sync cycle.
+type serviceQueue struct {
+	queue   workqueue.TypedRateLimitingInterface[string]
+	workers int
+	// process syncs a queued service; batched tells whether the attempt is the one a sync waits for
+	process func(ctx context.Context, item *queuedService, batched bool) error
+	// retryable reports whether a service that failed is retried before the next sync cycle
+	retryable func(key string) bool
+	start     sync.Once
+
+	mu      sync.Mutex
+	items   map[string]*queuedService
+	batches map[string]*serviceBatch
+}
+
+func newServiceQueue(
+	workers int,
+	baseDelay, maxDelay time.Duration,
+	process func(ctx context.Context, item *queuedService, batched bool) error,
+	retryable func(key string) bool,
+) *serviceQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	rateLimiter := workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[string](baseDelay, maxDelay),
+		&workqueue.TypedBucketRateLimiter[string]{Limiter: rate.NewLimiter(rate.Limit(serviceRetryQPS), serviceRetryBurst)},
+	)
+	return &serviceQueue{
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
+			Name: "service-sync",
+		}),
+		workers:   workers,
+		process:   process,
+		retryable: retryable,
+		items:     make(map[string]*queuedService),
+		batches:   make(map[string]*serviceBatch),
+	}
+}
+
+// run queues services in the given order and waits until each of them was synced once. It returns the errors
+// of the first attempts.
+func (q *serviceQueue) run(ctx context.Context, items []*queuedService) error {
+	q.start.Do(func() {
+		for i := 0; i < q.workers; i++ {
+			go wait.UntilWithContext(ctx, q.worker, time.Second)
+		}
+		go func() {
+			<-ctx.Done()
+			q.queue.ShutDown()
+		}()
+	})
+
+	batch := &serviceBatch{}
+	keys := make([]string, 0, len(items))
+	q.mu.Lock()
+	for _, item := range items {
+		key := item.svcInfo.Namespace + "/" + item.svcInfo.Name
+		if previous, ok := q.batches[key]; ok {
+			previous.done(nil)
+		}
+		q.items[key] = item
+		q.batches[key] = batch
+		batch.wg.Add(1)
+		keys = append(keys, key)
+	}
+	q.mu.Unlock()
+	for _, key := range keys {
+		q.queue.Add(key)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		batch.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return utilserrors.NewAggregate(batch.errs)
+}
+
+// drain stops queuing services and waits until the services being synced are done. Pending retries are dropped.
+func (q *serviceQueue) drain() {
+	q.queue.ShutDownWithDrain()
+}
+
+func (q *serviceQueue) worker(ctx context.Context) {
+	for q.processNext(ctx) {
+	}
+}
+
+// processNext syncs the next queued service. Failed services are queued again with backoff, unless they are
+// no longer retryable; they are then synced by the next sync cycle.
+func (q *serviceQueue) processNext(ctx context.Context) bool {
+	key, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(key)
+
+	q.mu.Lock()
+	item, ok := q.items[key]
+	batch := q.batches[key]
+	delete(q.batches, key)
+	q.mu.Unlock()
+	if !ok {
+		// The service is gone since it was queued
+		q.queue.Forget(key)
+		return true
+	}
+
+	err := q.process(ctx, item, batch != nil)
+	if batch != nil {
+		batch.done(err)
+	}
+	if err != nil && q.retryable(key) {
+		klog.V(4).Infof("Retrying service %s after %d failed retries", key, q.queue.NumRequeues(key))
+		q.queue.AddRateLimited(key)
+		return true
+	}
+
+	q.queue.Forget(key)
+	q.mu.Lock()
+	// Keep the service when a newer sync queued it again in the meantime
+	if q.items[key] == item {
+		delete(q.items, key)
+	}
+	q.mu.Unlock()
+	return true
+}
+
+// prune stops retrying services that are no longer discovered
+func (q *serviceQueue) prune(active func(key string) bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for key := range q.items {
+		if _, queued := q.batches[key]; !queued && !active(key) {
+			delete(q.items, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+func TestServiceQueue(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = make(map[string]int)
+		retried  = make(chan string, 10)
+	)
+	process := func(_ context.Context, item *queuedService, batched bool) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := item.svcInfo.Namespace + "/" + item.svcInfo.Name
+		attempts[key]++
+		if !batched {
+			retried <- key
+		}
+		if key == "payments/flaky" && attempts[key] < 3 {
+			return errors.New("conflict")
+		}
+		return nil
+	}
+	q := newServiceQueue(2, time.Millisecond, 10*time.Millisecond, process, func(string) bool { return true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := q.run(ctx, []*queuedService{
+		{svcInfo: &apisdiscoverer.ServiceInfo{Namespace: "payments", Name: "api"}},
+		{svcInfo: &apisdiscoverer.ServiceInfo{Namespace: "payments", Name: "flaky"}},
+	})
+	if err == nil {
+		t.Fatal("expected the first attempt of payments/flaky to fail")
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case key := <-retried:
+			if key != "payments/flaky" {
+				t.Errorf("expected only payments/flaky to be retried, got %s", key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected payments/flaky to be retried until it succeeds")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts["payments/api"] != 1 || attempts["payments/flaky"] != 3 {
+		t.Errorf("unexpected attempts %v", attempts)
+	}
+}
+
+func TestServiceQueueDrain(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished bool
+	process := func(context.Context, *queuedService, bool) error {
+		close(started)
+		<-release
+		finished = true
+		return nil
+	}
+	q := newServiceQueue(1, time.Millisecond, 10*time.Millisecond, process, func(string) bool { return true })
+
+	go func() {
+		_ = q.run(context.Background(), []*queuedService{
+			{svcInfo: &apisdiscoverer.ServiceInfo{Namespace: "payments", Name: "api"}},
+		})
+	}()
+	<-started
+
+	drained := make(chan struct{})
+	go func() {
+		q.drain()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		t.Fatal("expected drain to wait for the service being synced")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-drained:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected drain to return once the service was synced")
+	}
+	if !finished {
+		t.Error("expected the service being synced to finish")
+	}
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+// serviceQuota limits the number of services imported from each cluster to its spec.maxServices.
+// Services imported in the previous cycle keep their place, so a service is never displaced by a
+// service exported later, and new services are admitted by name while the quota allows.
+// When the quota is lowered below the number of imported services, services are admitted anew.
+type serviceQuota struct {
+	mu sync.Mutex
+	// imported holds, per cluster, the namespace/name keys of the services imported in the previous cycle
+	imported map[string]sets.Set[string]
+	// admitted and rejected hold, per cluster, the keys of the services admitted and rejected in the current cycle
+	admitted map[string]sets.Set[string]
+	rejected map[string]sets.Set[string]
+}
+
+func newServiceQuota() *serviceQuota {
+	return &serviceQuota{
+		imported: make(map[string]sets.Set[string]),
+		admitted: make(map[string]sets.Set[string]),
+		rejected: make(map[string]sets.Set[string]),
+	}
+}
+
+// begin starts a sync cycle
+func (sq *serviceQuota) begin() {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	sq.admitted = make(map[string]sets.Set[string])
+	sq.rejected = make(map[string]sets.Set[string])
+}
+
+// admit removes the clusters over their limit from the services, and the services left without clusters.
+// Clusters without a limit are not restricted.
+func (sq *serviceQuota) admit(services map[string]*apisdiscoverer.ServiceInfo, limits map[string]int) {
+	if len(limits) == 0 {
+		return
+	}
+
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	for _, key := range sets.List(sets.KeySet(services)) {
+		svcInfo := services[key]
+		clusters := svcInfo.Clusters[:0:0]
+		for _, cluster := range svcInfo.Clusters {
+			limit, ok := limits[cluster]
+			if !ok || sq.admitOne(cluster, key, limit) {
+				clusters = append(clusters, cluster)
+			}
+		}
+		if len(clusters) == 0 {
+			delete(services, key)
+			continue
+		}
+		svcInfo.Clusters = clusters
+	}
+}
+
+func (sq *serviceQuota) admitOne(cluster, key string, limit int) bool {
+	imported := sq.imported[cluster]
+	if imported.Len() > limit {
+		imported = nil
+	}
+	admitted := sq.admitted[cluster]
+	if admitted == nil {
+		admitted = sets.New[string]()
+		sq.admitted[cluster] = admitted
+	}
+
+	if !imported.Has(key) && imported.Union(admitted).Len() >= limit {
+		if sq.rejected[cluster] == nil {
+			sq.rejected[cluster] = sets.New[string]()
+		}
+		sq.rejected[cluster].Insert(key)
+		return false
+	}
+	admitted.Insert(key)
+	return true
+}
+
+// restrict applies the decisions of the current cycle to services, e.g. to the whole set of services
+// discovered in chunks
+func (sq *serviceQuota) restrict(services map[string]*apisdiscoverer.ServiceInfo) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	removeRejectedClusters(services, sq.rejected)
+}
+
+// end completes the sync cycle and returns the number of services rejected per cluster
+func (sq *serviceQuota) end() map[string]int {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	sq.imported = sq.admitted
+	rejected := make(map[string]int, len(sq.rejected))
+	for cluster, keys := range sq.rejected {
+		rejected[cluster] = keys.Len()
+	}
+	return rejected
+}
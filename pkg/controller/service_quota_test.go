@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+)
+
+func TestServiceQuota(t *testing.T) {
+	discovered := func(keys ...string) map[string]*apisdiscoverer.ServiceInfo {
+		services := make(map[string]*apisdiscoverer.ServiceInfo, len(keys))
+		for _, key := range keys {
+			services[key] = &apisdiscoverer.ServiceInfo{Clusters: []string{"east", "west"}}
+		}
+		return services
+	}
+	cycle := func(sq *serviceQuota, services map[string]*apisdiscoverer.ServiceInfo, limits map[string]int) map[string]int {
+		sq.begin()
+		sq.admit(services, limits)
+		return sq.end()
+	}
+
+	sq := newServiceQuota()
+	services := discovered("prod/b", "prod/c", "prod/d")
+	if rejected := cycle(sq, services, map[string]int{"east": 2}); rejected["east"] != 1 || rejected["west"] != 0 {
+		t.Fatalf("unexpected rejected services: %v", rejected)
+	}
+	if clusters := services["prod/d"].Clusters; !reflect.DeepEqual(clusters, []string{"west"}) {
+		t.Errorf("expected over quota cluster to be removed, got %v", clusters)
+	}
+
+	// Imported services keep their place when a service sorting before them is exported
+	services = discovered("prod/a", "prod/b", "prod/c")
+	services["prod/a"].Clusters = []string{"east"}
+	cycle(sq, services, map[string]int{"east": 2, "west": 2})
+	if _, ok := services["prod/a"]; ok {
+		t.Error("expected new service without clusters within quota to be removed")
+	}
+	if clusters := services["prod/c"].Clusters; !reflect.DeepEqual(clusters, []string{"east", "west"}) {
+		t.Errorf("expected imported service to be kept, got %v", clusters)
+	}
+
+	// Lowering the quota below the imported services admits services anew
+	services = discovered("prod/a", "prod/b", "prod/c")
+	cycle(sq, services, map[string]int{"east": 1})
+	if _, ok := services["prod/a"]; !ok || !reflect.DeepEqual(services["prod/b"].Clusters, []string{"west"}) {
+		t.Errorf("expected quota to be applied anew, got a=%v b=%v", services["prod/a"], services["prod/b"].Clusters)
+	}
+
+	// Decisions made on chunks restrict the whole set of discovered services
+	sq.begin()
+	chunk := discovered("prod/c")
+	sq.admit(chunk, map[string]int{"east": 1})
+	services = discovered("prod/a", "prod/c")
+	sq.restrict(services)
+	if !reflect.DeepEqual(services["prod/c"].Clusters, []string{"west"}) || len(services["prod/a"].Clusters) != 2 {
+		t.Errorf("unexpected restricted services: a=%v c=%v", services["prod/a"].Clusters, services["prod/c"].Clusters)
+	}
+}
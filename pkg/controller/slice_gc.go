@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/updater"
+)
+
+// sliceGarbageCollector periodically deletes the managed EndpointSlices, across all namespaces, whose service is
+// no longer imported from their source cluster. The sync of a service only cleans up the EndpointSlices of the
+// services discovered in the cycle, this pass catches the services deleted remotely and the namespaces excluded
+// since they were imported.
+type sliceGarbageCollector struct {
+	sliceUpdater *updater.SliceUpdater
+	interval     time.Duration
+	// orphanExpiry leaves the EndpointSlices of orphaned services to the orphan tracker when set
+	orphanExpiry time.Duration
+	// last is when garbage was last collected. It is only accessed from the sync loop.
+	last time.Time
+}
+
+func newSliceGarbageCollector(cfg *config.Config, sliceUpdater *updater.SliceUpdater, bus *eventBus) *sliceGarbageCollector {
+	gc := &sliceGarbageCollector{
+		sliceUpdater: sliceUpdater,
+		interval:     cfg.SliceGCInterval,
+		orphanExpiry: cfg.OrphanExpiry,
+	}
+	if gc.interval > 0 {
+		bus.discoveryCompleted.subscribe(gc.complete)
+	}
+	return gc
+}
+
+func (gc *sliceGarbageCollector) complete(ctx context.Context, event discoveryCompleted) error {
+	now := time.Now()
+	if now.Sub(gc.last) < gc.interval {
+		return nil
+	}
+	gc.last = now
+
+	deleted, err := gc.sliceUpdater.CollectGarbage(ctx, func(slice *discoveryv1.EndpointSlice) bool {
+		return gc.qualifies(slice, event)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to garbage collect EndpointSlices: %w", err)
+	}
+	if deleted > 0 {
+		klog.Infof("Garbage collected %d EndpointSlices", deleted)
+	}
+	return nil
+}
+
+// qualifies reports whether a managed EndpointSlice is still backed by its service and source cluster. The
+// EndpointSlices of retained clusters, of clusters whose services could not be discovered in the cycle and
+// those created since the discovery started are kept, as the cycle tells nothing about them.
+func (gc *sliceGarbageCollector) qualifies(slice *discoveryv1.EndpointSlice, event discoveryCompleted) bool {
+	if !slice.CreationTimestamp.Time.Before(event.started) {
+		return true
+	}
+
+	cluster, _ := config.SourceCluster(slice)
+	if event.retained.Has(cluster) {
+		return true
+	}
+	clusterInfo, ok := event.clusterInfos[cluster]
+	if !ok {
+		return false
+	}
+	if clusterInfo.ClusterLink.Status.Error != "" {
+		return true
+	}
+
+	svcInfo, ok := event.services[slice.Namespace+"/"+slice.Labels[config.ServiceNameLabel]]
+	if !ok {
+		// Orphaned services are pruned by the orphan tracker once the orphan expiry elapsed
+		return gc.orphanExpiry > 0
+	}
+	return slices.Contains(svcInfo.Clusters, cluster)
+}
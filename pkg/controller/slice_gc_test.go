@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestSliceGarbageCollectorQualifies(t *testing.T) {
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := discoveryCompleted{
+		clusterInfos: map[string]*clusterlink.ClusterInfo{
+			"east": {},
+			"west": {},
+			"broken": {ClusterLink: svclinkv1alpha1.ClusterLink{
+				Status: svclinkv1alpha1.ClusterLinkStatus{Error: "Service sync error: timeout"},
+			}},
+		},
+		retained: sets.New("paused"),
+		services: map[string]*apisdiscoverer.ServiceInfo{
+			"payments/api": {Name: "api", Namespace: "payments", Clusters: []string{"east"}},
+		},
+		started: started,
+	}
+	slice := func(service, cluster string, created time.Time) *discoveryv1.EndpointSlice {
+		s := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "payments",
+			Name:              service + "-svclink-abc",
+			CreationTimestamp: metav1.NewTime(created),
+		}}
+		if err := config.MarkManaged(s, service, cluster); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	before := started.Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		slice        *discoveryv1.EndpointSlice
+		orphanExpiry time.Duration
+		want         bool
+	}{
+		{name: "imported from its cluster", slice: slice("api", "east", before), want: true},
+		{name: "no longer exported by its cluster", slice: slice("api", "west", before), want: false},
+		{name: "cluster gone", slice: slice("api", "deleted", before), want: false},
+		{name: "retained cluster", slice: slice("ledger", "paused", before), want: true},
+		{name: "cluster failing discovery", slice: slice("ledger", "broken", before), want: true},
+		{name: "created during the cycle", slice: slice("ledger", "east", started.Add(time.Second)), want: true},
+		{name: "orphaned service", slice: slice("ledger", "east", before), want: false},
+		{name: "orphaned service left to the orphan expiry", slice: slice("ledger", "east", before), orphanExpiry: time.Hour, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gc := &sliceGarbageCollector{orphanExpiry: tt.orphanExpiry}
+			if got := gc.qualifies(tt.slice, event); got != tt.want {
+				t.Errorf("qualifies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"hash/fnv"
+	"os"
+	"time"
+)
+
+// splay spreads the clusters discovered by a sync cycle over a window, so that svclink instances sharing remote
+// clusters do not list them all at the same time. The offset of a cluster depends on the instance and the
+// cluster, so the instances listing a cluster are spread over the window, and is stable across cycles, so that
+// each cluster is still listed every sync interval.
+type splay struct {
+	instance string
+	window   time.Duration
+}
+
+// newSplay returns the splay of this instance, identified by its hostname; a zero window disables the splay
+func newSplay(window time.Duration) *splay {
+	instance, _ := os.Hostname()
+	return &splay{instance: instance, window: window}
+}
+
+// offset returns how long after the start of a sync cycle the given cluster is discovered
+func (s *splay) offset(cluster string) time.Duration {
+	if s.window <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(s.instance))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(cluster))
+	return time.Duration(hash.Sum64() % uint64(s.window))
+}
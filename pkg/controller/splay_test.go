@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSplayOffset(t *testing.T) {
+	disabled := &splay{instance: "svclink-0"}
+	if offset := disabled.offset("production-east"); offset != 0 {
+		t.Errorf("expected no offset without a window, got %s", offset)
+	}
+
+	window := 10 * time.Second
+	first := &splay{instance: "svclink-0", window: window}
+	second := &splay{instance: "svclink-1", window: window}
+	differ := 0
+	for i := 0; i < 20; i++ {
+		cluster := fmt.Sprintf("cluster-%d", i)
+		offset := first.offset(cluster)
+		if offset < 0 || offset >= window {
+			t.Fatalf("expected the offset of %s within the window, got %s", cluster, offset)
+		}
+		if offset != first.offset(cluster) {
+			t.Fatalf("expected the offset of %s to be stable", cluster)
+		}
+		if offset != second.offset(cluster) {
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Error("expected instances to list clusters at different offsets")
+	}
+}
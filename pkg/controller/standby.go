@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// standbyLoop keeps the remote clients of a replica that is not the leader warm until it is elected,
+// so that the first sync cycle after a failover does not pay for connecting to every remote cluster.
+// The standby only reads from the local and remote clusters.
+func (c *Controller) standbyLoop(ctx context.Context) {
+	standbyCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		select {
+		case <-c.manager.Elected():
+		case <-ctx.Done():
+		}
+	}()
+
+	klog.Info("Waiting for leader election, keeping remote clients warm")
+	wait.UntilWithContext(standbyCtx, c.warmRemoteClients, c.cfg.SyncInterval)
+}
+
+// warmRemoteClients builds the clients and capabilities of every linked cluster and sends each a
+// cheap request, which keeps connections open and credentials issued by exec plugins fresh
+func (c *Controller) warmRemoteClients(ctx context.Context) {
+	clusterInfos, err := c.links.WarmClusterClients(ctx, c.ctrlClient)
+	if err != nil {
+		klog.Errorf("Failed to warm remote clients: %v", err)
+		return
+	}
+
+	for name, clusterInfo := range clusterInfos {
+		if _, err := clusterInfo.Client.Discovery().ServerVersion(); err != nil {
+			klog.V(4).Infof("Failed to reach cluster %s while on standby: %v", name, err)
+		}
+	}
+	klog.V(4).Infof("Warmed remote clients of %d clusters", len(clusterInfos))
+}
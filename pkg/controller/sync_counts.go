@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+// clusterSyncCounts is what was synced from a cluster in a sync cycle
+type clusterSyncCounts struct {
+	services  int
+	endpoints int
+	// namespaces holds the services and endpoints per remote namespace
+	namespaces map[string]*svclinkv1alpha1.NamespaceSyncSummary
+}
+
+// namespaceSummary returns the counts per remote namespace sorted by namespace
+func (c *clusterSyncCounts) namespaceSummary() []svclinkv1alpha1.NamespaceSyncSummary {
+	if c == nil || len(c.namespaces) == 0 {
+		return nil
+	}
+	summary := make([]svclinkv1alpha1.NamespaceSyncSummary, 0, len(c.namespaces))
+	for _, namespace := range sets.List(sets.KeySet(c.namespaces)) {
+		summary = append(summary, *c.namespaces[namespace])
+	}
+	return summary
+}
+
+// syncCounts counts, per cluster, the services synced and the endpoints published in the current sync cycle,
+// and records the errors of the services that failed to sync. Services are synced concurrently, so the counts
+// are guarded by a mutex.
+type syncCounts struct {
+	mu       sync.Mutex
+	clusters map[string]*clusterSyncCounts
+	failed   map[string][]clusterlink.SyncError
+}
+
+func newSyncCounts() *syncCounts {
+	return &syncCounts{
+		clusters: make(map[string]*clusterSyncCounts),
+		failed:   make(map[string][]clusterlink.SyncError),
+	}
+}
+
+// record counts a synced service with the endpoints published for it from each cluster
+func (sc *syncCounts) record(svcInfo *apisdiscoverer.ServiceInfo, clusterEndpoints []aggregator.ClusterEndpoints) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for _, cluster := range svcInfo.Clusters {
+		counts := sc.cluster(cluster)
+		counts.services++
+		counts.namespace(svcInfo.SourceNamespace(cluster)).Services++
+	}
+	for _, ce := range clusterEndpoints {
+		counts := sc.cluster(ce.ClusterName)
+		counts.endpoints += len(ce.Endpoints)
+		counts.namespace(svcInfo.SourceNamespace(ce.ClusterName)).Endpoints += int32(len(ce.Endpoints))
+	}
+}
+
+func (sc *syncCounts) cluster(name string) *clusterSyncCounts {
+	if sc.clusters[name] == nil {
+		sc.clusters[name] = &clusterSyncCounts{namespaces: make(map[string]*svclinkv1alpha1.NamespaceSyncSummary)}
+	}
+	return sc.clusters[name]
+}
+
+func (c *clusterSyncCounts) namespace(name string) *svclinkv1alpha1.NamespaceSyncSummary {
+	if c.namespaces[name] == nil {
+		c.namespaces[name] = &svclinkv1alpha1.NamespaceSyncSummary{Namespace: name}
+	}
+	return c.namespaces[name]
+}
+
+// recordFailure records the error of a service that failed to sync for each of its clusters
+func (sc *syncCounts) recordFailure(clusters []string, syncError clusterlink.SyncError) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for _, cluster := range clusters {
+		sc.failed[cluster] = append(sc.failed[cluster], syncError)
+	}
+}
+
+// end completes the sync cycle and returns the counts per cluster, and the errors of the clusters with services
+// that failed to sync. Clusters without synced services have no counts.
+func (sc *syncCounts) end() (clusters map[string]*clusterSyncCounts, failed map[string][]clusterlink.SyncError) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	clusters, failed = sc.clusters, sc.failed
+	sc.clusters = make(map[string]*clusterSyncCounts)
+	sc.failed = make(map[string][]clusterlink.SyncError)
+	return clusters, failed
+}
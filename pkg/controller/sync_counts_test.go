@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	apisdiscoverer "github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+)
+
+func TestSyncCounts(t *testing.T) {
+	sc := newSyncCounts()
+	sc.record(&apisdiscoverer.ServiceInfo{
+		Name:             "api",
+		Namespace:        "payments",
+		Clusters:         []string{"east", "west"},
+		SourceNamespaces: map[string]string{"west": "payments-west"},
+	}, []aggregator.ClusterEndpoints{
+		{ClusterName: "east", Endpoints: make([]discoveryv1.Endpoint, 3)},
+		{ClusterName: "west", Endpoints: make([]discoveryv1.Endpoint, 1)},
+	})
+	sc.record(&apisdiscoverer.ServiceInfo{
+		Name:      "web",
+		Namespace: "frontend",
+		Clusters:  []string{"east"},
+	}, []aggregator.ClusterEndpoints{
+		{ClusterName: "east", Endpoints: make([]discoveryv1.Endpoint, 2)},
+	})
+
+	syncError := clusterlink.SyncError{Phase: svclinkv1alpha1.SyncPhaseUpdate, Message: "conflict"}
+	sc.recordFailure([]string{"west"}, syncError)
+
+	clusters, failed := sc.end()
+	if _, ok := failed["east"]; ok || !reflect.DeepEqual(failed["west"], []clusterlink.SyncError{syncError}) {
+		t.Errorf("expected only west to have failed, got %v", failed)
+	}
+	if clusters["east"].services != 2 || clusters["west"].services != 1 {
+		t.Errorf("unexpected service counts east=%d west=%d", clusters["east"].services, clusters["west"].services)
+	}
+	if clusters["east"].endpoints != 5 || clusters["west"].endpoints != 1 {
+		t.Errorf("unexpected endpoint counts east=%d west=%d", clusters["east"].endpoints, clusters["west"].endpoints)
+	}
+
+	wantEast := []svclinkv1alpha1.NamespaceSyncSummary{
+		{Namespace: "frontend", Services: 1, Endpoints: 2},
+		{Namespace: "payments", Services: 1, Endpoints: 3},
+	}
+	if got := clusters["east"].namespaceSummary(); !reflect.DeepEqual(got, wantEast) {
+		t.Errorf("unexpected namespace summary of east %+v, want %+v", got, wantEast)
+	}
+	wantWest := []svclinkv1alpha1.NamespaceSyncSummary{{Namespace: "payments-west", Services: 1, Endpoints: 1}}
+	if got := clusters["west"].namespaceSummary(); !reflect.DeepEqual(got, wantWest) {
+		t.Errorf("unexpected namespace summary of west %+v, want %+v", got, wantWest)
+	}
+
+	clusters, failed = sc.end()
+	if len(clusters) != 0 || len(failed) != 0 {
+		t.Errorf("expected the counts to be reset, got %v and %v", clusters, failed)
+	}
+}
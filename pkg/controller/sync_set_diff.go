@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// syncSet is the set of services synced from a cluster, as remote namespace/name, under a ClusterLink generation
+type syncSet struct {
+	generation int64
+	services   sets.Set[string]
+}
+
+// syncSetDiffReporter summarizes in an event on the ClusterLink how a spec change altered the services synced
+// from its cluster. The sync set before the change is compared with the first sync set after it, so remote
+// changes in between are included. Clusters that are not connected keep their last sync set, so a spec change
+// made while a cluster is unreachable is reported once it reconnects.
+type syncSetDiffReporter struct {
+	recorder record.EventRecorder
+	// syncSets are only accessed from the sync loop
+	syncSets map[string]syncSet
+}
+
+func newSyncSetDiffReporter(recorder record.EventRecorder, bus *eventBus) *syncSetDiffReporter {
+	r := &syncSetDiffReporter{
+		recorder: recorder,
+		syncSets: make(map[string]syncSet),
+	}
+	bus.discoveryCompleted.subscribe(r.complete)
+	return r
+}
+
+func (r *syncSetDiffReporter) complete(_ context.Context, event discoveryCompleted) error {
+	current := make(map[string]sets.Set[string], len(event.clusterInfos))
+	for cluster := range event.clusterInfos {
+		current[cluster] = sets.New[string]()
+	}
+	for _, svcInfo := range event.services {
+		for _, cluster := range svcInfo.Clusters {
+			if services, ok := current[cluster]; ok {
+				services.Insert(svcInfo.SourceNamespace(cluster) + "/" + svcInfo.SourceServiceName())
+			}
+		}
+	}
+
+	for cluster, clusterInfo := range event.clusterInfos {
+		generation := clusterInfo.ClusterLink.Generation
+		previous, ok := r.syncSets[cluster]
+		r.syncSets[cluster] = syncSet{generation: generation, services: current[cluster]}
+		if !ok || previous.generation == generation {
+			continue
+		}
+
+		summary := summarizeSyncSetDiff(previous.services, current[cluster])
+		if summary == "" {
+			continue
+		}
+		klog.Infof("ClusterLink %s changed the synced services: %s", cluster, summary)
+		r.recorder.Eventf(&clusterInfo.ClusterLink, corev1.EventTypeNormal, "SyncSetChanged",
+			"Generation %d changed the synced services: %s", generation, summary)
+	}
+	return nil
+}
+
+// summarizeSyncSetDiff describes the services added and removed between two sync sets per remote namespace,
+// e.g. "+12 services in namespace payments, -3 services in namespace legacy"
+func summarizeSyncSetDiff(previous, current sets.Set[string]) string {
+	added := countByNamespace(current.Difference(previous))
+	removed := countByNamespace(previous.Difference(current))
+
+	var parts []string
+	for _, namespace := range sets.List(sets.KeySet(added)) {
+		parts = append(parts, fmt.Sprintf("+%d %s in namespace %s", added[namespace], services(added[namespace]), namespace))
+	}
+	for _, namespace := range sets.List(sets.KeySet(removed)) {
+		parts = append(parts, fmt.Sprintf("-%d %s in namespace %s", removed[namespace], services(removed[namespace]), namespace))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func countByNamespace(keys sets.Set[string]) map[string]int {
+	counts := make(map[string]int)
+	for key := range keys {
+		namespace, _, _ := strings.Cut(key, "/")
+		counts[namespace]++
+	}
+	return counts
+}
+
+func services(count int) string {
+	if count == 1 {
+		return "service"
+	}
+	return "services"
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestSummarizeSyncSetDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous sets.Set[string]
+		current  sets.Set[string]
+		want     string
+	}{
+		{
+			name:     "unchanged",
+			previous: sets.New("payments/api"),
+			current:  sets.New("payments/api"),
+			want:     "",
+		},
+		{
+			name:     "added and removed",
+			previous: sets.New("payments/api", "legacy/a", "legacy/b"),
+			current:  sets.New("payments/api", "payments/ledger", "payments/billing", "orders/api"),
+			want:     "+1 service in namespace orders, +2 services in namespace payments, -2 services in namespace legacy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeSyncSetDiff(tt.previous, tt.current); got != tt.want {
+				t.Errorf("summarizeSyncSetDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
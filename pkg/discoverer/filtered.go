@@ -0,0 +1,93 @@
+package discoverer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
+)
+
+// maxFilteredSample bounds the number of filtered entries kept per cluster
+const maxFilteredSample = 50
+
+// FilteredPath is the metrics server path serving the filtered services report
+const FilteredPath = "/filtered"
+
+// filteredTracker records the services suppressed by filters during a sync cycle
+// and keeps the report of the last completed cycle
+type filteredTracker struct {
+	mu     sync.RWMutex
+	report discoverer.FilteredReport
+}
+
+func newFilteredTracker() *filteredTracker {
+	return &filteredTracker{
+		report: discoverer.FilteredReport{Clusters: map[string]*discoverer.FilteredCluster{}},
+	}
+}
+
+// newFilteredCluster returns an empty per-cycle record for a cluster
+func newFilteredCluster() *discoverer.FilteredCluster {
+	return &discoverer.FilteredCluster{
+		Namespaces: map[discoverer.FilterReason]int{},
+		Services:   map[discoverer.FilterReason]int{},
+	}
+}
+
+// recordFiltered adds a filtered namespace (empty name) or service to the cluster record
+func recordFiltered(record *discoverer.FilteredCluster, namespace, name string, reason discoverer.FilterReason) {
+	if name == "" {
+		record.Namespaces[reason]++
+	} else {
+		record.Services[reason]++
+	}
+	if len(record.Sample) < maxFilteredSample {
+		record.Sample = append(record.Sample, discoverer.FilteredService{Namespace: namespace, Name: name, Reason: reason})
+	}
+}
+
+// publish replaces the report with the records of the finished cycle and updates the metrics
+func (ft *filteredTracker) publish(clusters map[string]*discoverer.FilteredCluster) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	ft.report = discoverer.FilteredReport{Clusters: clusters}
+
+	metrics.FilteredNamespaces.Reset()
+	metrics.FilteredServices.Reset()
+	for cluster, record := range clusters {
+		for reason, count := range record.Namespaces {
+			metrics.FilteredNamespaces.WithLabelValues(cluster, string(reason)).Set(float64(count))
+		}
+		for reason, count := range record.Services {
+			metrics.FilteredServices.WithLabelValues(cluster, string(reason)).Set(float64(count))
+		}
+	}
+}
+
+// filteredServices returns the number of services filtered per cluster in the last sync cycle
+func (ft *filteredTracker) filteredServices() map[string]int {
+	ft.mu.RLock()
+	defer ft.mu.RUnlock()
+
+	counts := make(map[string]int, len(ft.report.Clusters))
+	for cluster, record := range ft.report.Clusters {
+		for _, count := range record.Services {
+			counts[cluster] += count
+		}
+	}
+	return counts
+}
+
+// ServeHTTP serves the filtered services report of the last sync cycle as JSON
+func (ft *filteredTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ft.mu.RLock()
+	defer ft.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ft.report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
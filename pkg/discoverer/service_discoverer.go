@@ -3,52 +3,298 @@
 // Services can be controlled using ClusterLink spec:
 // - spec.excludedNamespaces: list of namespaces to exclude
 // - spec.includedNamespaces: if specified, only sync these namespaces
+// - spec.namespaceSelector: if specified, only sync namespaces whose labels match
 // - spec.excludedServices: list of services (namespace/name) to exclude
+// - spec.includedServices: if specified, only sync these services (namespace/name)
+// - spec.serviceSelector: if specified, only sync services whose labels match
+// - spec.syncServiceTypes: if specified, only sync services of these types
+// - spec.namespaceMappings: import the services of a remote namespace into a differently named local namespace
 package discoverer
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"text/template"
+	"time"
 
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
 	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
 )
 
 // ServiceDiscoverer discovers services across all clusters (excluding kube-system)
 type ServiceDiscoverer struct {
 	kubeClient client.Client
+	// links records the sync errors of the linked clusters in their status
+	links    *clusterlink.Links
+	cfg      *config.Config
+	filtered *filteredTracker
+	// serviceTypes are the service types synced from every cluster, all types when empty
+	serviceTypes sets.Set[corev1.ServiceType]
+	// serviceName renders the local name of services synced to the local cluster, nil keeps remote names
+	serviceName *template.Template
+	// clusterOffset returns how long after the start of the discovery a cluster is listed, nil lists all at once
+	clusterOffset func(clusterName string) time.Duration
 }
 
 // NewServiceDiscoverer creates a new ServiceDiscoverer
-func NewServiceDiscoverer(kubeClient client.Client) *ServiceDiscoverer {
+func NewServiceDiscoverer(kubeClient client.Client, links *clusterlink.Links, cfg *config.Config) (*ServiceDiscoverer, error) {
+	serviceTypes := sets.New[corev1.ServiceType]()
+	for _, serviceType := range cfg.SyncServiceTypes {
+		serviceTypes.Insert(corev1.ServiceType(serviceType))
+	}
+
+	// Renaming only applies to the services svclink creates in the local cluster
+	var serviceName *template.Template
+	if cfg.SyncServicesToLocalCluster && cfg.ServiceNameTemplate != "" {
+		var err error
+		if serviceName, err = config.ParseServiceNameTemplate(cfg.ServiceNameTemplate); err != nil {
+			return nil, fmt.Errorf("invalid service name template: %w", err)
+		}
+	}
+
 	return &ServiceDiscoverer{
-		kubeClient: kubeClient,
+		kubeClient:   kubeClient,
+		links:        links,
+		cfg:          cfg,
+		filtered:     newFilteredTracker(),
+		serviceTypes: serviceTypes,
+		serviceName:  serviceName,
+	}, nil
+}
+
+// SetClusterOffset delays the discovery of each cluster by the offset returned for it, which spreads the lists
+// of remote clusters over the sync interval
+func (sd *ServiceDiscoverer) SetClusterOffset(offset func(clusterName string) time.Duration) {
+	sd.clusterOffset = offset
+}
+
+// byOffset returns the cluster names in the order their discovery starts
+func (sd *ServiceDiscoverer) byOffset(clusterInfos map[string]*clusterlink.ClusterInfo) []string {
+	names := sets.List(sets.KeySet(clusterInfos))
+	if sd.clusterOffset != nil {
+		sort.SliceStable(names, func(i, j int) bool {
+			return sd.clusterOffset(names[i]) < sd.clusterOffset(names[j])
+		})
 	}
+	return names
 }
 
-// DiscoverServices discovers all services across all clusters and returns them
+// awaitOffset waits until the offset of a cluster has passed since started. It returns false when ctx is done
+// first.
+func (sd *ServiceDiscoverer) awaitOffset(ctx context.Context, started time.Time, clusterName string) bool {
+	if sd.clusterOffset == nil {
+		return true
+	}
+	delay := time.Until(started.Add(sd.clusterOffset(clusterName)))
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// FilteredHandler serves the services suppressed by filters in the last sync cycle
+func (sd *ServiceDiscoverer) FilteredHandler() http.Handler {
+	return sd.filtered
+}
+
+// FilteredServices returns the number of services suppressed by filters per cluster in the last sync cycle
+func (sd *ServiceDiscoverer) FilteredServices() map[string]int {
+	return sd.filtered.filteredServices()
+}
+
+// DiscoverServices discovers all services across all clusters and returns them. Clusters are discovered
+// concurrently by up to DiscoveryWorkers workers, and their services are merged in cluster name order.
 func (sd *ServiceDiscoverer) DiscoverServices(ctx context.Context, clusterInfos map[string]*clusterlink.ClusterInfo, includedNamespaces []string) (map[string]*discoverer.ServiceInfo, error) {
-	services := make(map[string]*discoverer.ServiceInfo)
 	includedNS := sets.New(includedNamespaces...)
+	filtered := make(map[string]*discoverer.FilteredCluster, len(clusterInfos))
+	discovered := make(map[string]map[string]*discoverer.ServiceInfo, len(clusterInfos))
+	for clusterName := range clusterInfos {
+		filtered[clusterName] = newFilteredCluster()
+		discovered[clusterName] = make(map[string]*discoverer.ServiceInfo)
+	}
 
-	for clusterName, clusterInfo := range clusterInfos {
-		err := sd.discoverInCluster(ctx, clusterName, clusterInfo, services, includedNS)
+	var g errgroup.Group
+	g.SetLimit(max(sd.cfg.DiscoveryWorkers, 1))
+	started := time.Now()
+	for _, clusterName := range sd.byOffset(clusterInfos) {
+		if !sd.awaitOffset(ctx, started, clusterName) {
+			break
+		}
+		clusterInfo := clusterInfos[clusterName]
+		g.Go(func() error {
+			err := sd.discoverInCluster(ctx, clusterName, clusterInfo, discovered[clusterName], includedNS, filtered[clusterName])
+
+			// Always update cluster status: either with error or clear error (nil means success)
+			sd.links.UpdateClusterSyncError(ctx, sd.kubeClient, clusterInfo, clusterName, err)
+
+			if err != nil {
+				klog.Errorf("Failed to discover services in cluster %s: %v", clusterName, err)
+			}
+			// A cluster failing to discover does not stop the discovery of the others
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]*discoverer.ServiceInfo)
+	for _, clusterName := range sets.List(sets.KeySet(discovered)) {
+		mergeServices(services, discovered[clusterName])
+	}
 
-		// Always update cluster status: either with error or clear error (nil means success)
-		clusterlink.UpdateClusterSyncError(ctx, sd.kubeClient, clusterInfo, clusterName, err)
+	sd.filtered.publish(filtered)
 
+	klog.Infof("Discovered %d services across %d remote clusters", len(services), len(clusterInfos))
+	return services, nil
+}
+
+// mergeServices adds the services discovered in one cluster to the services discovered in other clusters
+func mergeServices(services, cluster map[string]*discoverer.ServiceInfo) {
+	for key, svcInfo := range cluster {
+		merged, ok := services[key]
+		if !ok {
+			services[key] = svcInfo
+			continue
+		}
+		merged.Clusters = append(merged.Clusters, svcInfo.Clusters...)
+		merged.Service = svcInfo.Service
+		for clusterName, namespace := range svcInfo.SourceNamespaces {
+			if merged.SourceNamespaces == nil {
+				merged.SourceNamespaces = make(map[string]string)
+			}
+			merged.SourceNamespaces[clusterName] = namespace
+		}
+		for clusterName := range svcInfo.PublishNotReadyAddresses {
+			if merged.PublishNotReadyAddresses == nil {
+				merged.PublishNotReadyAddresses = make(map[string]bool)
+			}
+			merged.PublishNotReadyAddresses[clusterName] = true
+		}
+		for clusterName, ports := range svcInfo.ClusterPorts {
+			if merged.ClusterPorts == nil {
+				merged.ClusterPorts = make(map[string][]corev1.ServicePort)
+			}
+			merged.ClusterPorts[clusterName] = ports
+		}
+	}
+}
+
+// DiscoverServicesInChunks discovers the same services as DiscoverServices without materializing them
+// all at once: services are handed to handle one namespace at a time and remote lists are paginated
+// with pageSize. It returns every discovered service without its Service object, along with the
+// aggregated errors returned by handle.
+func (sd *ServiceDiscoverer) DiscoverServicesInChunks(ctx context.Context,
+	clusterInfos map[string]*clusterlink.ClusterInfo,
+	includedNamespaces []string,
+	pageSize int64,
+	handle func(context.Context, map[string]*discoverer.ServiceInfo) error,
+) (map[string]*discoverer.ServiceInfo, error) {
+	includedNS := sets.New(includedNamespaces...)
+	filtered := make(map[string]*discoverer.FilteredCluster, len(clusterInfos))
+	filters := make(map[string]*clusterFilter, len(clusterInfos))
+	clusterErrs := make(map[string]error)
+
+	// Only namespace names are kept for the whole cycle. Namespaces are grouped by their local name,
+	// so that the services mapped into the same local namespace are handled in the same chunk.
+	clustersByNamespace := make(map[string][]clusterNamespace)
+	started := time.Now()
+	for _, clusterName := range sd.byOffset(clusterInfos) {
+		filtered[clusterName] = newFilteredCluster()
+		if !sd.awaitOffset(ctx, started, clusterName) {
+			clusterErrs[clusterName] = ctx.Err()
+			continue
+		}
+		clusterInfo := clusterInfos[clusterName]
+		cf, err := newClusterFilter(clusterInfo.ClusterLink.Spec)
 		if err != nil {
+			clusterErrs[clusterName] = err
 			klog.Errorf("Failed to discover services in cluster %s: %v", clusterName, err)
 			continue
 		}
+		filters[clusterName] = cf
+
+		namespaces, err := sd.eligibleNamespaces(ctx, clusterName, clusterInfo, cf, includedNS, filtered[clusterName], pageSize)
+		if err != nil {
+			clusterErrs[clusterName] = err
+			klog.Errorf("Failed to discover services in cluster %s: %v", clusterName, err)
+			continue
+		}
+		for _, namespace := range namespaces {
+			target := cf.spec.MapNamespace(namespace, cf.namespaceMap)
+			clustersByNamespace[target] = append(clustersByNamespace[target], clusterNamespace{
+				cluster:   clusterName,
+				namespace: namespace,
+			})
+		}
+	}
+
+	discovered := make(map[string]*discoverer.ServiceInfo)
+	var handleErrs []error
+	for _, namespace := range sets.List(sets.KeySet(clustersByNamespace)) {
+		chunk := make(map[string]*discoverer.ServiceInfo)
+		for _, source := range clustersByNamespace[namespace] {
+			clusterName := source.cluster
+			if clusterErrs[clusterName] != nil {
+				continue
+			}
+			if err := sd.discoverInNamespace(ctx, clusterName, clusterInfos[clusterName], filters[clusterName],
+				source.namespace, chunk, filtered[clusterName], pageSize); err != nil {
+				clusterErrs[clusterName] = err
+				klog.Errorf("Failed to discover services in cluster %s: %v", clusterName, err)
+			}
+		}
+		if len(chunk) == 0 {
+			continue
+		}
+
+		for key, svcInfo := range chunk {
+			discovered[key] = &discoverer.ServiceInfo{
+				Name:             svcInfo.Name,
+				Namespace:        svcInfo.Namespace,
+				Clusters:         svcInfo.Clusters,
+				SourceNamespaces: svcInfo.SourceNamespaces,
+				SourceName:       svcInfo.SourceName,
+
+				PublishNotReadyAddresses: svcInfo.PublishNotReadyAddresses,
+				ClusterPorts:             svcInfo.ClusterPorts,
+			}
+		}
+		if err := handle(ctx, chunk); err != nil {
+			handleErrs = append(handleErrs, fmt.Errorf("namespace %s: %w", namespace, err))
+		}
 	}
 
-	klog.Infof("Discovered %d services across %d remote clusters", len(services), len(clusterInfos))
-	return services, nil
+	for clusterName, clusterInfo := range clusterInfos {
+		sd.links.UpdateClusterSyncError(ctx, sd.kubeClient, clusterInfo, clusterName, clusterErrs[clusterName])
+	}
+	sd.filtered.publish(filtered)
+
+	klog.Infof("Discovered %d services across %d remote clusters in %d namespaces",
+		len(discovered), len(clusterInfos), len(clustersByNamespace))
+	return discovered, utilserrors.NewAggregate(handleErrs)
 }
 
 // discoverInCluster discovers services in a single cluster
@@ -56,37 +302,141 @@ func (sd *ServiceDiscoverer) discoverInCluster(ctx context.Context, clusterName
 	clusterInfo *clusterlink.ClusterInfo,
 	services map[string]*discoverer.ServiceInfo,
 	cfgIncludedNamespaces sets.Set[string],
+	filtered *discoverer.FilteredCluster,
 ) error {
-	spec := clusterInfo.ClusterLink.Spec
-
-	excludedNS := spec.ToExcludedNamespaceSet()
-	includedNS := spec.ToIncludedNamespaceSet()
-	excludedSvc := spec.ToExcludedServiceSet()
-	excludedSvcName := spec.ToExcludedServiceNameSet()
+	cf, err := newClusterFilter(clusterInfo.ClusterLink.Spec)
+	if err != nil {
+		return err
+	}
 
-	nsList, err := clusterInfo.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	namespaces, err := sd.eligibleNamespaces(ctx, clusterName, clusterInfo, cf, cfgIncludedNamespaces, filtered, 0)
 	if err != nil {
-		klog.Errorf("Failed to list namespaces in cluster %s: %v", clusterName, err)
 		return err
 	}
 
-	for ni := range nsList.Items {
-		namespace := nsList.Items[ni].Name
+	for _, namespace := range namespaces {
+		if err := sd.discoverInNamespace(ctx, clusterName, clusterInfo, cf, namespace, services, filtered, 0); err != nil {
+			return err
+		}
+	}
 
-		if cfgIncludedNamespaces.Len() > 0 && !cfgIncludedNamespaces.Has(namespace) {
-			// If includedNamespaces is specified, skip services not in that set
-			klog.V(4).Infof("Namespace %s skipped as not in included namespaces", namespace)
-			continue
+	return nil
+}
+
+// clusterNamespace is a namespace of a remote cluster
+type clusterNamespace struct {
+	cluster   string
+	namespace string
+}
+
+// clusterFilter holds the pre-computed namespace and service filters of a ClusterLink
+type clusterFilter struct {
+	spec              svclinkv1alpha1.ClusterLinkSpec
+	excludedNS        svclinkv1alpha1.NameMatcher
+	includedNS        svclinkv1alpha1.NameMatcher
+	excludedSvc       svclinkv1alpha1.NameMatcher
+	includedSvc       svclinkv1alpha1.NameMatcher
+	excludedSvcName   svclinkv1alpha1.NameMatcher
+	serviceTypes      sets.Set[corev1.ServiceType]
+	namespaceMap      map[string]string
+	namespaceSelector labels.Selector
+	serviceSelector   labels.Selector
+}
+
+func newClusterFilter(spec svclinkv1alpha1.ClusterLinkSpec) (*clusterFilter, error) {
+	if err := spec.ValidateFilterPatterns(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	namespaceSelector, err := spec.ToNamespaceLabelSelector()
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespaceSelector: %w", err)
+	}
+	serviceSelector, err := spec.ToServiceLabelSelector()
+	if err != nil {
+		return nil, fmt.Errorf("invalid serviceSelector: %w", err)
+	}
+
+	return &clusterFilter{
+		spec:              spec,
+		excludedNS:        spec.ToExcludedNamespaceSet(),
+		includedNS:        spec.ToIncludedNamespaceSet(),
+		excludedSvc:       spec.ToExcludedServiceSet(),
+		includedSvc:       spec.ToIncludedServiceSet(),
+		excludedSvcName:   spec.ToExcludedServiceNameSet(),
+		serviceTypes:      spec.ToServiceTypeSet(),
+		namespaceMap:      spec.ToNamespaceMap(),
+		namespaceSelector: namespaceSelector,
+		serviceSelector:   serviceSelector,
+	}, nil
+}
+
+// eligibleNamespaces returns the namespaces of a cluster that pass the namespace filters.
+// Namespaces are listed in pages of pageSize, 0 lists them at once.
+func (sd *ServiceDiscoverer) eligibleNamespaces(ctx context.Context, clusterName string,
+	clusterInfo *clusterlink.ClusterInfo,
+	cf *clusterFilter,
+	cfgIncludedNamespaces sets.Set[string],
+	filtered *discoverer.FilteredCluster,
+	pageSize int64,
+) ([]string, error) {
+	var namespaces []string
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		nsList, err := clusterInfo.Client.CoreV1().Namespaces().List(ctx, opts)
+		if err != nil {
+			klog.Errorf("Failed to list namespaces in cluster %s: %v", clusterName, err)
+			return nil, err
 		}
 
-		// Check if namespace should be excluded based on all exclusion/inclusion rules
-		if spec.ShouldExcludeNamespace(namespace, &excludedNS, &includedNS) {
-			klog.V(4).Infof("Namespace %s excluded from sync in cluster %s",
-				namespace, clusterName)
-			continue
+		for ni := range nsList.Items {
+			namespace := nsList.Items[ni].Name
+
+			// includedNamespaces refers to the local namespace the services are imported into
+			if cfgIncludedNamespaces.Len() > 0 && !cfgIncludedNamespaces.Has(cf.spec.MapNamespace(namespace, cf.namespaceMap)) {
+				// If includedNamespaces is specified, skip services not in that set
+				klog.V(4).Infof("Namespace %s skipped as not in included namespaces", namespace)
+				recordFiltered(filtered, namespace, "", discoverer.FilterReasonIncludedNamespaces)
+				continue
+			}
+
+			// Check if namespace should be excluded based on all exclusion/inclusion rules
+			if cf.spec.ShouldExcludeNamespace(namespace, &cf.excludedNS, &cf.includedNS) {
+				logging.V(4, clusterName, namespace).Infof("Namespace %s excluded from sync in cluster %s",
+					namespace, clusterName)
+				recordFiltered(filtered, namespace, "", discoverer.FilterReasonNamespace)
+				continue
+			}
+
+			if !cf.namespaceSelector.Matches(labels.Set(nsList.Items[ni].Labels)) {
+				logging.V(4, clusterName, namespace).Infof("Namespace %s does not match the namespace selector of cluster %s",
+					namespace, clusterName)
+				recordFiltered(filtered, namespace, "", discoverer.FilterReasonNamespaceSelector)
+				continue
+			}
+
+			namespaces = append(namespaces, namespace)
+		}
+
+		if nsList.Continue == "" {
+			return namespaces, nil
 		}
+		opts.Continue = nsList.Continue
+	}
+}
 
-		svcList, err := clusterInfo.Client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+// discoverInNamespace adds the services of a cluster namespace that pass the service filters to services.
+// Services are listed in pages of pageSize, 0 lists them at once.
+func (sd *ServiceDiscoverer) discoverInNamespace(ctx context.Context, clusterName string,
+	clusterInfo *clusterlink.ClusterInfo,
+	cf *clusterFilter,
+	namespace string,
+	services map[string]*discoverer.ServiceInfo,
+	filtered *discoverer.FilteredCluster,
+	pageSize int64,
+) error {
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		svcList, err := clusterInfo.Client.CoreV1().Services(namespace).List(ctx, opts)
 		if err != nil {
 			klog.Errorf("Failed to list services in namespace %s of cluster %s: %v",
 				namespace, clusterName, err)
@@ -96,30 +446,101 @@ func (sd *ServiceDiscoverer) discoverInCluster(ctx context.Context, clusterName
 		for _, svc := range svcList.Items {
 			serviceName := svc.Name
 
+			// The opt-out annotation of the remote service wins over the ClusterLink filters
+			if config.IsOptedOut(&svc) {
+				logging.V(4, clusterName, namespace, namespace+"/"+serviceName).Infof("Service %s/%s opted out of sync in cluster %s",
+					namespace, serviceName, clusterName)
+				recordFiltered(filtered, namespace, serviceName, discoverer.FilterReasonOptOut)
+				continue
+			}
+
+			if !config.IsTargetedAt(&svc, sd.cfg.ClusterName) {
+				logging.V(4, clusterName, namespace, namespace+"/"+serviceName).Infof("Service %s/%s in cluster %s does not target this deployment",
+					namespace, serviceName, clusterName)
+				recordFiltered(filtered, namespace, serviceName, discoverer.FilterReasonTargetClusters)
+				continue
+			}
+
 			// Check if service should be excluded based on all exclusion/inclusion rules
-			if spec.ShouldExcludeService(namespace, serviceName, &excludedSvc, &excludedSvcName) {
-				klog.V(4).Infof("Service %s/%s excluded from sync in cluster %s",
+			if cf.spec.ShouldExcludeService(namespace, serviceName, &cf.excludedSvc, &cf.excludedSvcName, &cf.includedSvc) {
+				logging.V(4, clusterName, namespace, namespace+"/"+serviceName).Infof("Service %s/%s excluded from sync in cluster %s",
+					namespace, serviceName, clusterName)
+				recordFiltered(filtered, namespace, serviceName, discoverer.FilterReasonService)
+				continue
+			}
+
+			if (sd.serviceTypes.Len() > 0 && !sd.serviceTypes.Has(svc.Spec.Type)) ||
+				cf.spec.ShouldExcludeServiceType(svc.Spec.Type, &cf.serviceTypes) {
+				logging.V(4, clusterName, namespace, namespace+"/"+serviceName).Infof("Service %s/%s of type %s excluded from sync in cluster %s",
+					namespace, serviceName, svc.Spec.Type, clusterName)
+				recordFiltered(filtered, namespace, serviceName, discoverer.FilterReasonServiceType)
+				continue
+			}
+
+			if !cf.serviceSelector.Matches(labels.Set(svc.Labels)) {
+				logging.V(4, clusterName, namespace, namespace+"/"+serviceName).Infof("Service %s/%s does not match the service selector of cluster %s",
 					namespace, serviceName, clusterName)
+				recordFiltered(filtered, namespace, serviceName, discoverer.FilterReasonServiceSelector)
 				continue
 			}
 
-			// Add or update service info
-			key := namespace + "/" + serviceName
+			// Add or update service info, keyed by the local namespace and name the service is imported as
+			target := cf.spec.MapNamespace(namespace, cf.namespaceMap)
+			localName, err := sd.localServiceName(serviceName, target, clusterName)
+			if err != nil {
+				klog.Errorf("Skipping service %s/%s of cluster %s: %v", namespace, serviceName, clusterName, err)
+				continue
+			}
+			key := target + "/" + localName
 			svcInfo, exists := services[key]
 			if !exists || svcInfo == nil {
 				svcInfo = &discoverer.ServiceInfo{
-					Name:      serviceName,
-					Namespace: namespace,
+					Name:      localName,
+					Namespace: target,
 					Clusters:  []string{},
 				}
+				if localName != serviceName {
+					svcInfo.SourceName = serviceName
+				}
 				services[key] = svcInfo
 			}
 			svcInfo.Clusters = append(svcInfo.Clusters, clusterName)
 			svcInfo.Service = &svc
+			if svcInfo.ClusterPorts == nil {
+				svcInfo.ClusterPorts = make(map[string][]corev1.ServicePort)
+			}
+			svcInfo.ClusterPorts[clusterName] = svc.Spec.Ports
+			if svc.Spec.PublishNotReadyAddresses {
+				if svcInfo.PublishNotReadyAddresses == nil {
+					svcInfo.PublishNotReadyAddresses = make(map[string]bool)
+				}
+				svcInfo.PublishNotReadyAddresses[clusterName] = true
+			}
+			if target != namespace {
+				if svcInfo.SourceNamespaces == nil {
+					svcInfo.SourceNamespaces = make(map[string]string)
+				}
+				svcInfo.SourceNamespaces[clusterName] = namespace
+			}
 
-			klog.V(4).Infof("Found service %s in cluster %s", key, clusterName)
+			logging.V(4, clusterName, namespace, key).Infof("Found service %s in cluster %s", key, clusterName)
 		}
+
+		if svcList.Continue == "" {
+			return nil
+		}
+		opts.Continue = svcList.Continue
 	}
+}
 
-	return nil
+// localServiceName returns the name a remote service is imported as in the local cluster
+func (sd *ServiceDiscoverer) localServiceName(serviceName, namespace, clusterName string) (string, error) {
+	if sd.serviceName == nil {
+		return serviceName, nil
+	}
+	return config.RenderServiceName(sd.serviceName, config.ServiceNameData{
+		Name:      serviceName,
+		Namespace: namespace,
+		Cluster:   clusterName,
+	})
 }
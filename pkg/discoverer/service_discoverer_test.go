@@ -0,0 +1,190 @@
+package discoverer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	svclinkv1alpha1 "github.com/cloudpilot-ai/svclink/pkg/apis/svclink/v1alpha1"
+	"github.com/cloudpilot-ai/svclink/pkg/clusterlink"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// statusClient accepts the ClusterLink status patches written after the discovery of each cluster
+type statusClient struct {
+	client.Client
+}
+
+func (statusClient) Status() client.SubResourceWriter {
+	return statusWriter{}
+}
+
+type statusWriter struct {
+	client.SubResourceWriter
+}
+
+func (statusWriter) Patch(context.Context, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+	return nil
+}
+
+// remoteCluster returns a cluster serving the given namespaces and services
+func remoteCluster(name string, spec svclinkv1alpha1.ClusterLinkSpec, objects ...runtime.Object) *clusterlink.ClusterInfo {
+	return &clusterlink.ClusterInfo{
+		Name:        name,
+		Enabled:     true,
+		Client:      fake.NewSimpleClientset(objects...),
+		ClusterLink: svclinkv1alpha1.ClusterLink{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec},
+	}
+}
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func service(namespace, name string) *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func TestDiscoverServicesNamespaceSelector(t *testing.T) {
+	spec := svclinkv1alpha1.ClusterLinkSpec{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"svclink.io/export": "true"}},
+	}
+	clusterInfos := map[string]*clusterlink.ClusterInfo{
+		"east": remoteCluster("east", spec,
+			namespace("payments", map[string]string{"svclink.io/export": "true"}),
+			namespace("sandbox", nil),
+			service("payments", "api"),
+			service("sandbox", "api")),
+	}
+
+	sd, err := NewServiceDiscoverer(statusClient{}, clusterlink.NewLinks(nil, &config.Config{}), &config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	services, err := sd.DiscoverServices(context.Background(), clusterInfos, nil)
+	if err != nil {
+		t.Fatalf("DiscoverServices() error = %v", err)
+	}
+
+	if got := sets.KeySet(services); !got.Equal(sets.New("payments/api")) {
+		t.Errorf("discovered %v, want payments/api only", sets.List(got))
+	}
+	record := sd.filtered.report.Clusters["east"]
+	if record.Namespaces[discoverer.FilterReasonNamespaceSelector] != 1 {
+		t.Errorf("expected sandbox to be filtered by the namespace selector, got %+v", record)
+	}
+
+	clusterInfos["east"].ClusterLink.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "svclink.io/export", Operator: "Bogus"}},
+	}
+	if services, _ := sd.DiscoverServices(context.Background(), clusterInfos, nil); len(services) != 0 {
+		t.Errorf("expected an invalid selector to discover nothing, got %v", sets.List(sets.KeySet(services)))
+	}
+}
+
+func TestDiscoverServicesInChunks(t *testing.T) {
+	clusterInfos := map[string]*clusterlink.ClusterInfo{
+		"east": remoteCluster("east", svclinkv1alpha1.ClusterLinkSpec{},
+			namespace("orders", nil), namespace("payments", nil),
+			service("orders", "api"), service("payments", "api"), service("payments", "ledger")),
+		"west": remoteCluster("west", svclinkv1alpha1.ClusterLinkSpec{},
+			namespace("payments", nil),
+			service("payments", "api")),
+	}
+
+	sd, err := NewServiceDiscoverer(statusClient{}, clusterlink.NewLinks(nil, &config.Config{}), &config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks [][]string
+	services, err := sd.DiscoverServicesInChunks(context.Background(), clusterInfos, nil, 100,
+		func(_ context.Context, chunk map[string]*discoverer.ServiceInfo) error {
+			chunks = append(chunks, sets.List(sets.KeySet(chunk)))
+			for key, svcInfo := range chunk {
+				if svcInfo.Service == nil {
+					t.Errorf("expected the chunk to hold the Service of %s", key)
+				}
+			}
+			if _, ok := chunk["orders/api"]; ok {
+				return errors.New("conflict")
+			}
+			return nil
+		})
+
+	// Every namespace is handled in a chunk of its own, with the services of all clusters
+	want := [][]string{{"orders/api"}, {"payments/api", "payments/ledger"}}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "namespace orders: conflict") {
+		t.Errorf("expected the handler error of orders, got %v", err)
+	}
+
+	if got := sets.KeySet(services); !got.Equal(sets.New("orders/api", "payments/api", "payments/ledger")) {
+		t.Errorf("discovered %v, want every service", sets.List(got))
+	}
+	if api := services["payments/api"]; !reflect.DeepEqual(api.Clusters, []string{"east", "west"}) || api.Service != nil {
+		t.Errorf("expected payments/api of both clusters without its Service, got %+v", api)
+	}
+}
+
+func TestDiscoverServicesWorkers(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	// Every namespace list holds a worker for a moment, so that workers overlap
+	throttled := func(cluster *clusterlink.ClusterInfo, err error) *clusterlink.ClusterInfo {
+		cluster.Client.(*fake.Clientset).PrependReactor("list", "namespaces",
+			func(k8stesting.Action) (bool, runtime.Object, error) {
+				mu.Lock()
+				inFlight++
+				maxInFlight = max(maxInFlight, inFlight)
+				mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return err != nil, nil, err
+			})
+		return cluster
+	}
+
+	clusterInfos := make(map[string]*clusterlink.ClusterInfo)
+	for _, name := range []string{"c1", "c2", "c3", "c4", "c5"} {
+		clusterInfos[name] = throttled(remoteCluster(name, svclinkv1alpha1.ClusterLinkSpec{},
+			namespace("payments", nil), service("payments", "api")), nil)
+	}
+	clusterInfos["broken"] = throttled(remoteCluster("broken", svclinkv1alpha1.ClusterLinkSpec{}), errors.New("timeout"))
+
+	sd, err := NewServiceDiscoverer(statusClient{}, clusterlink.NewLinks(nil, &config.Config{}), &config.Config{DiscoveryWorkers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	services, err := sd.DiscoverServices(context.Background(), clusterInfos, nil)
+	if err != nil {
+		t.Fatalf("DiscoverServices() error = %v", err)
+	}
+
+	if maxInFlight != 2 {
+		t.Errorf("expected 2 clusters to be discovered at a time, got %d", maxInFlight)
+	}
+	// A failing cluster does not stop the others, and clusters are merged in name order
+	if api := services["payments/api"]; api == nil || !reflect.DeepEqual(api.Clusters, []string{"c1", "c2", "c3", "c4", "c5"}) {
+		t.Errorf("expected payments/api of every healthy cluster in name order, got %+v", api)
+	}
+	if clusterInfos["broken"].ClusterLink.Status.Error == "" {
+		t.Error("expected the discovery error to be recorded in the status of broken")
+	}
+}
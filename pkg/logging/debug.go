@@ -0,0 +1,137 @@
+// Package logging provides runtime adjustable log verbosity and targeted debug logging.
+// Debug settings are read from a ConfigMap so that verbosity can be raised globally, or only
+// for specific clusters and services, without restarting the controller.
+package logging
+
+import (
+	"flag"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// VerbosityKey is the ConfigMap key overriding the global klog verbosity
+	VerbosityKey = "verbosity"
+	// DebugTargetsKey is the ConfigMap key listing clusters, namespaces or namespace/name services
+	// whose log messages are emitted regardless of the global verbosity
+	DebugTargetsKey = "debugTargets"
+)
+
+var (
+	mu sync.RWMutex
+	// debugTargets are the clusters, namespaces and namespace/name services logged at full verbosity
+	debugTargets = sets.New[string]()
+	// startupVerbosity is the verbosity set by flags, restored when the override is removed
+	startupVerbosity string
+	// appliedVerbosity is the verbosity currently applied from the ConfigMap
+	appliedVerbosity string
+)
+
+// V returns a klog.Verbose that is enabled when the global verbosity is at least level,
+// or when any of the given keys (cluster name, namespace or namespace/name) is a debug target.
+func V(level klog.Level, keys ...string) klog.Verbose {
+	if IsDebugTarget(keys...) {
+		return klog.V(0)
+	}
+	return klog.V(level)
+}
+
+// IsDebugTarget reports whether any of the given keys is a debug target
+func IsDebugTarget(keys ...string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if debugTargets.Len() == 0 {
+		return false
+	}
+	for _, key := range keys {
+		if debugTargets.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDebugTargets replaces the set of debug targets
+func SetDebugTargets(targets []string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	debugTargets = sets.New(targets...)
+}
+
+// ApplyConfigMap applies the debug settings of the ConfigMap. A nil ConfigMap resets
+// the verbosity to its startup value and clears all debug targets.
+func ApplyConfigMap(cm *corev1.ConfigMap) error {
+	var data map[string]string
+	if cm != nil {
+		data = cm.Data
+	}
+
+	targets, err := parseTargets(data[DebugTargetsKey])
+	if err != nil {
+		return err
+	}
+	SetDebugTargets(targets)
+
+	return setVerbosity(data[VerbosityKey])
+}
+
+// setVerbosity sets the global klog verbosity, restoring the startup verbosity when value is empty
+func setVerbosity(value string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if appliedVerbosity == "" {
+		if value == "" {
+			return nil
+		}
+		startupVerbosity = "0"
+		if f := flag.CommandLine.Lookup("v"); f != nil {
+			startupVerbosity = f.Value.String()
+		}
+	}
+
+	target := value
+	if target == "" {
+		target = startupVerbosity
+	}
+	var level klog.Level
+	if err := level.Set(target); err != nil {
+		return err
+	}
+	if value != appliedVerbosity {
+		klog.Infof("Log verbosity set to %s", target)
+	}
+	appliedVerbosity = value
+	return nil
+}
+
+// parseTargets parses debug targets given either as a YAML list or as comma/newline separated values
+func parseTargets(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "-") {
+		var targets []string
+		if err := yaml.Unmarshal([]byte(value), &targets); err != nil {
+			return nil, err
+		}
+		return targets, nil
+	}
+
+	var targets []string
+	for _, target := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{
+			name:     "empty value",
+			value:    "",
+			expected: nil,
+		},
+		{
+			name:     "yaml flow list",
+			value:    `["prod-eu", "payments/api"]`,
+			expected: []string{"prod-eu", "payments/api"},
+		},
+		{
+			name:     "yaml block list",
+			value:    "- prod-eu\n- payments/api\n",
+			expected: []string{"prod-eu", "payments/api"},
+		},
+		{
+			name:     "comma separated",
+			value:    "prod-eu, payments/api",
+			expected: []string{"prod-eu", "payments/api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseTargets(tt.value)
+			if err != nil {
+				t.Fatalf("parseTargets failed: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsDebugTarget(t *testing.T) {
+	SetDebugTargets([]string{"prod-eu", "payments/api"})
+	defer SetDebugTargets(nil)
+
+	if !IsDebugTarget("other-cluster", "payments/api") {
+		t.Error("expected payments/api to be a debug target")
+	}
+	if IsDebugTarget("prod-us", "payments/web") {
+		t.Error("expected prod-us and payments/web not to be debug targets")
+	}
+}
@@ -0,0 +1,97 @@
+// Package metrics defines the Prometheus metrics exported by svclink.
+// Metrics are registered with the controller-runtime registry and served by the manager's metrics server.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "svclink"
+
+var (
+	// FilteredServices is the number of remote services suppressed by filters in the last sync cycle
+	FilteredServices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "filtered_services",
+		Help:      "Number of services seen on a remote cluster but not synced because of a filter, by reason.",
+	}, []string{"cluster", "reason"})
+
+	// FilteredNamespaces is the number of remote namespaces suppressed by filters in the last sync cycle
+	FilteredNamespaces = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "filtered_namespaces",
+		Help:      "Number of namespaces of a remote cluster whose services are not synced because of a filter, by reason.",
+	}, []string{"cluster", "reason"})
+
+	// ServiceFailureBudgetExhausted is set for services that exhausted their failure budget
+	ServiceFailureBudgetExhausted = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "service_failure_budget_exhausted",
+		Help:      "Consecutive sync failures of services that exhausted their failure budget and are no longer hot-retried.",
+	}, []string{"namespace", "service"})
+
+	// PublishedEndpoints is the number of endpoints published into each local namespace
+	PublishedEndpoints = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "published_endpoints",
+		Help:      "Number of remote endpoints published into a local namespace, counted against the namespace endpoint quota.",
+	}, []string{"namespace"})
+
+	// VerificationMismatches counts discrepancies found between managed EndpointSlices and remote endpoints
+	VerificationMismatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verification_mismatch_total",
+		Help:      "Number of managed EndpointSlices found not to match the endpoints of their remote cluster during verification, by reason.",
+	}, []string{"cluster", "reason"})
+
+	// NetworkReachableRatio is the share of the sampled endpoints of a cluster reached by the reachability probe
+	NetworkReachableRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "network_reachable_ratio",
+		Help:      "Share of the sampled endpoints imported from a cluster that accepted a TCP connection from the controller in the last sync cycle.",
+	}, []string{"cluster"})
+
+	// OrphanedServices is the number of imported local services no remote cluster exposes anymore
+	OrphanedServices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "orphaned_services",
+		Help:      "Number of local services imported by svclink that no connected remote cluster exposes anymore.",
+	}, []string{"namespace"})
+
+	// PrunedOrphans counts the orphaned services whose managed resources were pruned after the orphan expiry
+	PrunedOrphans = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pruned_orphans_total",
+		Help:      "Number of orphaned services whose managed Service and EndpointSlices were pruned after the orphan expiry.",
+	}, []string{"namespace"})
+
+	// DuplicateAddresses counts the endpoint addresses dropped because another endpoint of the service published them
+	DuplicateAddresses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "duplicate_addresses_total",
+		Help:      "Number of remote endpoint addresses not published because an overlapping EndpointSlice or a preferred cluster already published them for the service.",
+	}, []string{"cluster"})
+
+	// GarbageCollectedSlices counts the managed EndpointSlices deleted by the garbage collection pass
+	GarbageCollectedSlices = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "garbage_collected_endpointslices_total",
+		Help:      "Number of managed EndpointSlices deleted by garbage collection because their service is no longer imported from their source cluster.",
+	}, []string{"namespace"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		FilteredServices,
+		FilteredNamespaces,
+		ServiceFailureBudgetExhausted,
+		PublishedEndpoints,
+		VerificationMismatches,
+		NetworkReachableRatio,
+		OrphanedServices,
+		PrunedOrphans,
+		DuplicateAddresses,
+		GarbageCollectedSlices,
+	)
+}
@@ -0,0 +1,127 @@
+// Package sdk lets applications query which remote clusters back a service imported by svclink.
+// It watches the EndpointSlices svclink manages through an informer, so smart clients can implement
+// their own cluster affinity without parsing the slices and svclink labels themselves.
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// ClusterBackend describes the endpoints a remote cluster contributes to a service
+type ClusterBackend struct {
+	// Cluster is the name of the ClusterLink of the remote cluster
+	Cluster string
+	// SourceNamespace is the namespace of the service in the remote cluster
+	SourceNamespace string
+	// Endpoints is the number of endpoints imported from the cluster
+	Endpoints int
+	// ReadyEndpoints is the number of imported endpoints that are ready
+	ReadyEndpoints int
+}
+
+// Client answers queries about imported services from an informer cache of svclink EndpointSlices
+type Client struct {
+	factory  informers.SharedInformerFactory
+	informer cache.SharedIndexInformer
+	lister   discoverylisters.EndpointSliceLister
+}
+
+// NewClient creates a Client watching the svclink EndpointSlices in namespace, or in all namespaces
+// if namespace is empty. Start must be called before querying it.
+func NewClient(kubeClient kubernetes.Interface, namespace string) *Client {
+	factory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			// Slices created before the managed-by label was introduced only carry the cluster label
+			options.LabelSelector = config.ClusterLabel
+		}))
+	sliceInformer := factory.Discovery().V1().EndpointSlices()
+
+	return &Client{
+		factory:  factory,
+		informer: sliceInformer.Informer(),
+		lister:   sliceInformer.Lister(),
+	}
+}
+
+// Start starts the informer and waits for its cache to sync. The informer stops when ctx is done.
+func (c *Client) Start(ctx context.Context) error {
+	c.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
+		return fmt.Errorf("failed to sync svclink EndpointSlices: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Clusters returns the remote clusters currently backing the service, sorted by cluster name
+func (c *Client) Clusters(namespace, service string) ([]ClusterBackend, error) {
+	slices, err := c.lister.EndpointSlices(namespace).List(labels.SelectorFromSet(labels.Set{
+		config.ServiceNameLabel: service,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	byCluster := make(map[string]*ClusterBackend)
+	for _, slice := range slices {
+		cluster, ok := config.SourceCluster(slice)
+		if !ok {
+			continue
+		}
+		backend, ok := byCluster[cluster]
+		if !ok {
+			sourceNamespace := slice.Namespace
+			if ns, ok := slice.Annotations[config.SourceNamespaceAnnotation]; ok {
+				sourceNamespace = ns
+			}
+			backend = &ClusterBackend{Cluster: cluster, SourceNamespace: sourceNamespace}
+			byCluster[cluster] = backend
+		}
+		for _, ep := range slice.Endpoints {
+			backend.Endpoints++
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				backend.ReadyEndpoints++
+			}
+		}
+	}
+
+	backends := make([]ClusterBackend, 0, len(byCluster))
+	for _, backend := range byCluster {
+		backends = append(backends, *backend)
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].Cluster < backends[j].Cluster
+	})
+	return backends, nil
+}
+
+// OnChange registers a handler called with the namespace and name of a service whenever the
+// endpoints imported for it change. It must be called before Start.
+func (c *Client) OnChange(handler func(namespace, service string)) error {
+	notify := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		if slice, ok := obj.(*discoveryv1.EndpointSlice); ok {
+			handler(slice.Namespace, slice.Labels[config.ServiceNameLabel])
+		}
+	}
+	_, err := c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_, obj interface{}) { notify(obj) },
+		DeleteFunc: notify,
+	})
+	return err
+}
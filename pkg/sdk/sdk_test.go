@@ -0,0 +1,65 @@
+package sdk
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestClient_Clusters(t *testing.T) {
+	slice := func(name, cluster string, annotations map[string]string, ready ...bool) *discoveryv1.EndpointSlice {
+		s := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{config.ServiceNameLabel: "web"},
+				Annotations: annotations,
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+		}
+		if cluster != "" {
+			s.Labels[config.ClusterLabel] = cluster
+			s.Labels[config.ManagedByLabel] = config.ManagedByValue
+		}
+		for _, r := range ready {
+			s.Endpoints = append(s.Endpoints, discoveryv1.Endpoint{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(r)},
+			})
+		}
+		return s
+	}
+
+	kubeClient := fake.NewClientset(
+		slice("web-local", "", nil, true),
+		slice("web-east", "east", nil, true, false),
+		slice("web-west-1", "west", map[string]string{config.SourceNamespaceAnnotation: "prod"}, true),
+		slice("web-west-2", "west", map[string]string{config.SourceNamespaceAnnotation: "prod"}, true),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := NewClient(kubeClient, "")
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	got, err := client.Clusters("default", "web")
+	if err != nil {
+		t.Fatalf("Clusters() error = %v", err)
+	}
+	want := []ClusterBackend{
+		{Cluster: "east", SourceNamespace: "default", Endpoints: 2, ReadyEndpoints: 1},
+		{Cluster: "west", SourceNamespace: "prod", Endpoints: 2, ReadyEndpoints: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Clusters() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package updater
+
+import (
+	"sort"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+)
+
+// sliceChunk is the part of the endpoints of a cluster and address type published in one EndpointSlice
+type sliceChunk struct {
+	name string
+	// legacyName is the name earlier releases gave the EndpointSlice of the chunk
+	legacyName string
+	endpoints  aggregator.ClusterEndpoints
+}
+
+// chunks splits the endpoints of a cluster and address type into EndpointSlices of at most maxEndpoints
+// endpoints, ordered by address so that an unchanged set of endpoints is always split the same way. Each chunk
+// is named after its index, so that services below the limit keep their single EndpointSlice.
+func (su *SliceUpdater) chunks(serviceName string, ce aggregator.ClusterEndpoints) []sliceChunk {
+	if su.maxEndpoints <= 0 || len(ce.Endpoints) <= su.maxEndpoints {
+		return []sliceChunk{{
+			name:       endpointSliceName(serviceName, ce, 0),
+			legacyName: legacyEndpointSliceName(serviceName, ce, 0),
+			endpoints:  ce,
+		}}
+	}
+
+	endpoints := append([]discoveryv1.Endpoint(nil), ce.Endpoints...)
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return strings.Join(endpoints[i].Addresses, ",") < strings.Join(endpoints[j].Addresses, ",")
+	})
+
+	chunks := make([]sliceChunk, 0, (len(endpoints)+su.maxEndpoints-1)/su.maxEndpoints)
+	for start := 0; start < len(endpoints); start += su.maxEndpoints {
+		chunk := ce
+		chunk.Endpoints = endpoints[start:min(start+su.maxEndpoints, len(endpoints))]
+		chunks = append(chunks, sliceChunk{
+			name:       endpointSliceName(serviceName, ce, len(chunks)),
+			legacyName: legacyEndpointSliceName(serviceName, ce, len(chunks)),
+			endpoints:  chunk,
+		})
+	}
+	return chunks
+}
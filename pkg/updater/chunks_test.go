@@ -0,0 +1,59 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+)
+
+func TestChunks(t *testing.T) {
+	su := &SliceUpdater{maxEndpoints: 100}
+	clusterEndpoints := func(count int) aggregator.ClusterEndpoints {
+		ce := aggregator.ClusterEndpoints{ClusterName: "east", AddressType: discoveryv1.AddressTypeIPv4}
+		// Endpoints are listed in reverse order, chunks are ordered by address
+		for i := count - 1; i >= 0; i-- {
+			ce.Endpoints = append(ce.Endpoints, discoveryv1.Endpoint{Addresses: []string{fmt.Sprintf("10.0.%d.%d", i/256, i%256)}})
+		}
+		return ce
+	}
+
+	chunks := su.chunks("web", clusterEndpoints(80))
+	if len(chunks) != 1 || chunks[0].name != endpointSliceName("web", chunks[0].endpoints, 0) || len(chunks[0].endpoints.Endpoints) != 80 {
+		t.Fatalf("expected endpoints below the limit to stay in a single slice, got %d chunks", len(chunks))
+	}
+
+	chunks = su.chunks("web", clusterEndpoints(250))
+	wantLegacyNames := []string{"web-svclink-east", "web-svclink-east-1", "web-svclink-east-2"}
+	wantSizes := []int{100, 100, 50}
+	if len(chunks) != len(wantSizes) {
+		t.Fatalf("expected %d chunks, got %d", len(wantSizes), len(chunks))
+	}
+	seen := make(map[string]bool)
+	names := make(map[string]bool)
+	for i, chunk := range chunks {
+		if chunk.name != endpointSliceName("web", chunk.endpoints, i) || chunk.legacyName != wantLegacyNames[i] ||
+			len(chunk.endpoints.Endpoints) != wantSizes[i] {
+			t.Errorf("expected chunk %d to be named after its index with %d endpoints, got %s (%s) with %d", i, wantSizes[i],
+				chunk.name, chunk.legacyName, len(chunk.endpoints.Endpoints))
+		}
+		if names[chunk.name] {
+			t.Errorf("expected chunk %d to have a distinct name, got %s", i, chunk.name)
+		}
+		names[chunk.name] = true
+		if chunk.endpoints.ClusterName != "east" || chunk.endpoints.AddressType != discoveryv1.AddressTypeIPv4 {
+			t.Errorf("expected chunk %d to keep the cluster and address type, got %s %s", i, chunk.endpoints.ClusterName, chunk.endpoints.AddressType)
+		}
+		for _, ep := range chunk.endpoints.Endpoints {
+			if seen[ep.Addresses[0]] {
+				t.Errorf("expected endpoint %s to be published in a single chunk", ep.Addresses[0])
+			}
+			seen[ep.Addresses[0]] = true
+		}
+	}
+	if first := chunks[0].endpoints.Endpoints[0].Addresses[0]; first != "10.0.0.0" {
+		t.Errorf("expected chunks to be ordered by address, got %s first", first)
+	}
+}
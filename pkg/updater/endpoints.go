@@ -0,0 +1,269 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// maxEndpointsAddresses is the number of addresses an Endpoints object holds before it is truncated, like the
+// Endpoints of kube-controller-manager
+const maxEndpointsAddresses = 1000
+
+// UpdateEndpoints mirrors the endpoints imported for a service into a core/v1 Endpoints object named after the
+// service, for consumers that do not read EndpointSlices. The endpoints of retained clusters are read from their
+// EndpointSlices. Services with a selector are skipped, as their Endpoints belong to kube-controller-manager.
+func (su *SliceUpdater) UpdateEndpoints(
+	ctx context.Context,
+	namespace, serviceName string,
+	clusterEndpoints []aggregator.ClusterEndpoints,
+	retainedClusters sets.Set[string],
+) error {
+	if !su.mirrorEndpoints {
+		return nil
+	}
+
+	service := &corev1.Service{}
+	if err := su.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: serviceName}, service); err != nil {
+		return fmt.Errorf("failed to get service %s/%s: %w", namespace, serviceName, err)
+	}
+	if len(service.Spec.Selector) > 0 {
+		klog.V(4).Infof("Not mirroring the endpoints of service %s/%s into Endpoints, it has a selector", namespace, serviceName)
+		return nil
+	}
+
+	retained, err := su.retainedEndpoints(ctx, namespace, serviceName, retainedClusters)
+	if err != nil {
+		return err
+	}
+	subsets, truncated := endpointsSubsets(slices.Concat(clusterEndpoints, retained))
+
+	endpoints := &corev1.Endpoints{}
+	key := client.ObjectKey{Namespace: namespace, Name: serviceName}
+	if err := su.kubeClient.Get(ctx, key, endpoints); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get Endpoints: %w", err)
+		}
+		endpoints = &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Name:       service.Name,
+				UID:        service.UID,
+			}},
+		}}
+		markMirroredEndpoints(endpoints, subsets, truncated)
+		if err := su.kubeClient.Create(ctx, endpoints); err != nil {
+			return fmt.Errorf("failed to create Endpoints: %w", err)
+		}
+		klog.Infof("Created Endpoints %s/%s mirroring %d subsets", namespace, serviceName, len(subsets))
+		return nil
+	}
+
+	// The Endpoints kube-controller-manager left behind when svclink stripped the selector of a Service it
+	// created are taken over
+	managedBy := endpoints.Labels[config.ManagedByLabel]
+	if managedBy != config.ManagedByValue && (managedBy != "" || !config.IsSyncedService(service)) {
+		return fmt.Errorf("refusing to update Endpoints %s/%s managed by %q", namespace, serviceName, managedBy)
+	}
+	original := endpoints.DeepCopy()
+	markMirroredEndpoints(endpoints, subsets, truncated)
+	if equality.Semantic.DeepEqual(original, endpoints) {
+		return nil
+	}
+	if err := su.kubeClient.Update(ctx, endpoints); err != nil {
+		return fmt.Errorf("failed to update Endpoints: %w", err)
+	}
+	klog.V(4).Infof("Updated Endpoints %s/%s mirroring %d subsets", namespace, serviceName, len(subsets))
+	return nil
+}
+
+// DeleteServiceEndpoints deletes the Endpoints mirrored for a service, if svclink manages them
+func (su *SliceUpdater) DeleteServiceEndpoints(ctx context.Context, namespace, serviceName string) error {
+	if !su.mirrorEndpoints {
+		return nil
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := su.kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: serviceName}, endpoints); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if endpoints.Labels[config.ManagedByLabel] != config.ManagedByValue {
+		return nil
+	}
+	if err := su.kubeClient.Delete(ctx, endpoints); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Endpoints %s/%s: %w", namespace, serviceName, err)
+	}
+	klog.Infof("Deleted Endpoints %s/%s of orphaned service", namespace, serviceName)
+	return nil
+}
+
+// retainedEndpoints returns the endpoints of the managed EndpointSlices of a service imported from retained clusters
+func (su *SliceUpdater) retainedEndpoints(ctx context.Context, namespace, serviceName string,
+	retainedClusters sets.Set[string]) ([]aggregator.ClusterEndpoints, error) {
+	if retainedClusters.Len() == 0 {
+		return nil, nil
+	}
+
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := su.kubeClient.List(ctx, sliceList, client.InNamespace(namespace),
+		client.MatchingLabels{config.ServiceNameLabel: serviceName}); err != nil {
+		return nil, fmt.Errorf("failed to list EndpointSlices: %w", err)
+	}
+
+	var retained []aggregator.ClusterEndpoints
+	for _, slice := range sliceList.Items {
+		cluster, _ := config.SourceCluster(&slice)
+		if !config.IsManagedByUs(&slice) || !retainedClusters.Has(cluster) {
+			continue
+		}
+		retained = append(retained, aggregator.ClusterEndpoints{
+			ClusterName: cluster,
+			AddressType: slice.AddressType,
+			Endpoints:   slice.Endpoints,
+			Ports:       slice.Ports,
+		})
+	}
+	return retained, nil
+}
+
+// markMirroredEndpoints sets the subsets of mirrored Endpoints, with the labels that keep the EndpointSlice
+// mirroring controller from mirroring them back and the over-capacity annotation when they were truncated
+func markMirroredEndpoints(endpoints *corev1.Endpoints, subsets []corev1.EndpointSubset, truncated bool) {
+	if endpoints.Labels == nil {
+		endpoints.Labels = make(map[string]string, 2)
+	}
+	endpoints.Labels[config.ManagedByLabel] = config.ManagedByValue
+	endpoints.Labels[discoveryv1.LabelSkipMirror] = "true"
+
+	if truncated {
+		if endpoints.Annotations == nil {
+			endpoints.Annotations = make(map[string]string, 1)
+		}
+		endpoints.Annotations[corev1.EndpointsOverCapacity] = "truncated"
+	} else {
+		delete(endpoints.Annotations, corev1.EndpointsOverCapacity)
+	}
+	endpoints.Subsets = subsets
+}
+
+// endpointsSubsets groups the IP endpoints of the clusters into subsets sharing the same ports. Addresses are
+// sorted and ready addresses are kept first when there are more than maxEndpointsAddresses, in which case the
+// subsets are truncated. FQDN endpoints have no place in Endpoints and are left out.
+func endpointsSubsets(clusterEndpoints []aggregator.ClusterEndpoints) ([]corev1.EndpointSubset, bool) {
+	type group struct {
+		ports    []corev1.EndpointPort
+		ready    []corev1.EndpointAddress
+		notReady []corev1.EndpointAddress
+	}
+	groups := make(map[string]*group)
+	total := 0
+	for _, ce := range clusterEndpoints {
+		if ce.AddressType == discoveryv1.AddressTypeFQDN {
+			continue
+		}
+		ports := endpointsPorts(ce.Ports)
+		key := portsKey(ports)
+		if groups[key] == nil {
+			groups[key] = &group{ports: ports}
+		}
+		g := groups[key]
+		for _, ep := range ce.Endpoints {
+			for _, address := range ep.Addresses {
+				epAddress := corev1.EndpointAddress{IP: address}
+				if ep.Hostname != nil {
+					epAddress.Hostname = *ep.Hostname
+				}
+				if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+					g.ready = append(g.ready, epAddress)
+				} else {
+					g.notReady = append(g.notReady, epAddress)
+				}
+				total++
+			}
+		}
+	}
+
+	keys := sets.List(sets.KeySet(groups))
+	for _, key := range keys {
+		sortAddresses(groups[key].ready)
+		sortAddresses(groups[key].notReady)
+	}
+
+	// Ready addresses are kept before not ready ones, subset by subset
+	remaining := maxEndpointsAddresses
+	keep := func(addresses []corev1.EndpointAddress) []corev1.EndpointAddress {
+		kept := addresses[:min(len(addresses), remaining)]
+		remaining -= len(kept)
+		return kept
+	}
+	for _, key := range keys {
+		groups[key].ready = keep(groups[key].ready)
+	}
+	for _, key := range keys {
+		groups[key].notReady = keep(groups[key].notReady)
+	}
+
+	subsets := []corev1.EndpointSubset{}
+	for _, key := range keys {
+		g := groups[key]
+		if len(g.ready) == 0 && len(g.notReady) == 0 {
+			continue
+		}
+		subsets = append(subsets, corev1.EndpointSubset{
+			Addresses:         g.ready,
+			NotReadyAddresses: g.notReady,
+			Ports:             g.ports,
+		})
+	}
+	return subsets, total > maxEndpointsAddresses
+}
+
+// endpointsPorts converts EndpointSlice ports to Endpoints ports, sorted by name
+func endpointsPorts(ports []discoveryv1.EndpointPort) []corev1.EndpointPort {
+	converted := make([]corev1.EndpointPort, 0, len(ports))
+	for _, port := range ports {
+		epPort := corev1.EndpointPort{AppProtocol: port.AppProtocol}
+		if port.Name != nil {
+			epPort.Name = *port.Name
+		}
+		if port.Port != nil {
+			epPort.Port = *port.Port
+		}
+		if port.Protocol != nil {
+			epPort.Protocol = *port.Protocol
+		}
+		converted = append(converted, epPort)
+	}
+	sort.Slice(converted, func(i, j int) bool { return converted[i].Name < converted[j].Name })
+	return converted
+}
+
+// portsKey identifies a set of sorted Endpoints ports
+func portsKey(ports []corev1.EndpointPort) string {
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		parts = append(parts, fmt.Sprintf("%s/%d/%s", port.Name, port.Port, port.Protocol))
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortAddresses(addresses []corev1.EndpointAddress) {
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].IP < addresses[j].IP })
+}
@@ -0,0 +1,83 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+)
+
+func TestEndpointsSubsets(t *testing.T) {
+	http := []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To(int32(80)), Protocol: ptr.To(corev1.ProtocolTCP)}}
+	grpc := []discoveryv1.EndpointPort{{Name: ptr.To("grpc"), Port: ptr.To(int32(9090)), Protocol: ptr.To(corev1.ProtocolTCP)}}
+
+	subsets, truncated := endpointsSubsets([]aggregator.ClusterEndpoints{
+		{
+			ClusterName: "east",
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       http,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+				{Addresses: []string{"10.0.0.1"}, Hostname: ptr.To("api-0")},
+			},
+		},
+		{
+			ClusterName: "west",
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       http,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.1.0.1"}}},
+		},
+		{
+			ClusterName: "west",
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       grpc,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.1.0.2"}}},
+		},
+		{
+			ClusterName: "dns",
+			AddressType: discoveryv1.AddressTypeFQDN,
+			Ports:       http,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"api.example.com"}}},
+		},
+	})
+	if truncated {
+		t.Error("expected the subsets not to be truncated")
+	}
+	if len(subsets) != 2 {
+		t.Fatalf("expected one subset per set of ports, got %+v", subsets)
+	}
+	if subsets[0].Ports[0].Name != "grpc" || len(subsets[0].Addresses) != 1 || subsets[0].Addresses[0].IP != "10.1.0.2" {
+		t.Errorf("unexpected grpc subset %+v", subsets[0])
+	}
+	http0 := subsets[1]
+	if len(http0.Addresses) != 2 || http0.Addresses[0].IP != "10.0.0.1" || http0.Addresses[0].Hostname != "api-0" ||
+		http0.Addresses[1].IP != "10.1.0.1" {
+		t.Errorf("expected the ready http addresses of both clusters sorted, got %+v", http0.Addresses)
+	}
+	if len(http0.NotReadyAddresses) != 1 || http0.NotReadyAddresses[0].IP != "10.0.0.2" {
+		t.Errorf("expected the not ready http address, got %+v", http0.NotReadyAddresses)
+	}
+
+	// Above the capacity, ready addresses are kept first
+	var endpoints []discoveryv1.Endpoint
+	for i := range maxEndpointsAddresses + 10 {
+		endpoints = append(endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{fmt.Sprintf("10.%d.%d.%d", i/65536, i/256%256, i%256)},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(i >= 20)},
+		})
+	}
+	subsets, truncated = endpointsSubsets([]aggregator.ClusterEndpoints{
+		{ClusterName: "east", AddressType: discoveryv1.AddressTypeIPv4, Ports: http, Endpoints: endpoints},
+	})
+	if !truncated {
+		t.Error("expected the subsets to be truncated")
+	}
+	if len(subsets) != 1 || len(subsets[0].Addresses) != maxEndpointsAddresses-10 || len(subsets[0].NotReadyAddresses) != 10 {
+		t.Errorf("expected %d ready and 10 not ready addresses, got %d and %d", maxEndpointsAddresses-10,
+			len(subsets[0].Addresses), len(subsets[0].NotReadyAddresses))
+	}
+}
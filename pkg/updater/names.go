@@ -0,0 +1,51 @@
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+)
+
+const (
+	// sliceNameInfix separates the service name from the hash in the names of managed EndpointSlices
+	sliceNameInfix = "-svclink-"
+	// sliceNameHashLength is the number of hex characters of the hash in the names of managed EndpointSlices
+	sliceNameHashLength = 10
+)
+
+// endpointSliceName returns the name of the EndpointSlice holding a chunk of the endpoints of a cluster and
+// address type, `{service}-svclink-{hash}`. The service name is truncated so that the name fits in a DNS label,
+// and the hash covers the full service name, cluster name, address type and chunk, so that names stay valid
+// and distinct whatever the length and characters of the service and cluster names. The cluster and service
+// are recorded in the labels and annotations of the EndpointSlice.
+func endpointSliceName(serviceName string, ce aggregator.ClusterEndpoints, chunk int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%d", serviceName, ce.ClusterName, ce.AddressType, chunk)))
+	hash := hex.EncodeToString(sum[:])[:sliceNameHashLength]
+
+	prefix := serviceName
+	if maxPrefix := validation.DNS1123LabelMaxLength - len(sliceNameInfix) - sliceNameHashLength; len(prefix) > maxPrefix {
+		prefix = strings.TrimRight(prefix[:maxPrefix], "-.")
+	}
+	return prefix + sliceNameInfix + hash
+}
+
+// legacyEndpointSliceName returns the name earlier releases gave the EndpointSlice of a chunk, `{service}-svclink-{cluster}`
+// suffixed with the lowercased address type for other address types than IPv4 and with the index of the chunk
+// after the first. EndpointSlices of legacy names are replaced by their hashed names by the next sync of their
+// service.
+func legacyEndpointSliceName(serviceName string, ce aggregator.ClusterEndpoints, chunk int) string {
+	name := fmt.Sprintf("%s-svclink-%s", serviceName, ce.ClusterName)
+	if ce.AddressType != discoveryv1.AddressTypeIPv4 {
+		name += "-" + strings.ToLower(string(ce.AddressType))
+	}
+	if chunk > 0 {
+		name = fmt.Sprintf("%s-%d", name, chunk)
+	}
+	return name
+}
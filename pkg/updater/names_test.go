@@ -0,0 +1,48 @@
+package updater
+
+import (
+	"strings"
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+)
+
+func TestEndpointSliceName(t *testing.T) {
+	ipv4 := aggregator.ClusterEndpoints{ClusterName: "east", AddressType: discoveryv1.AddressTypeIPv4}
+	ipv6 := aggregator.ClusterEndpoints{ClusterName: "east", AddressType: discoveryv1.AddressTypeIPv6}
+	// The legacy names of these slices collide: web-svclink-east-ipv6
+	collidingCluster := aggregator.ClusterEndpoints{ClusterName: "east-ipv6", AddressType: discoveryv1.AddressTypeIPv4}
+	longCluster := aggregator.ClusterEndpoints{
+		ClusterName: "arn.aws.eks.us-east-1.123456789012.cluster." + strings.Repeat("production", 20),
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	longService := strings.Repeat("a", 62) + "-"
+
+	names := map[string]string{
+		"ipv4":              endpointSliceName("web", ipv4, 0),
+		"ipv4 second chunk": endpointSliceName("web", ipv4, 1),
+		"ipv6":              endpointSliceName("web", ipv6, 0),
+		"colliding cluster": endpointSliceName("web", collidingCluster, 0),
+		"long cluster":      endpointSliceName("web", longCluster, 0),
+		"long service":      endpointSliceName(longService, ipv4, 0),
+	}
+	seen := make(map[string]string)
+	for slice, name := range names {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			t.Errorf("expected the name of the %s slice to be a valid DNS label, got %s: %v", slice, name, errs)
+		}
+		if other, ok := seen[name]; ok {
+			t.Errorf("expected the %s and %s slices to have distinct names, both got %s", slice, other, name)
+		}
+		seen[name] = slice
+	}
+	if !strings.HasPrefix(names["ipv4"], "web-svclink-") {
+		t.Errorf("expected the name to start with the service name, got %s", names["ipv4"])
+	}
+	if endpointSliceName("web", ipv4, 0) != names["ipv4"] {
+		t.Error("expected names to be deterministic")
+	}
+}
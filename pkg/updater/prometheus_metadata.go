@@ -0,0 +1,95 @@
+package updater
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// prometheusPortAnnotation is the conventional annotation naming the port Prometheus should scrape
+const prometheusPortAnnotation = "prometheus.io/port"
+
+// applyPrometheusMetadata sets the allowlisted Prometheus scrape annotations of the remote service on a local
+// service with PrometheusMetadata, so that Prometheus service discovery in the local cluster scrapes remote
+// workloads through the synced EndpointSlices. Scrape annotations of disallowed ports are removed, including
+// those copied with the other annotations when the Service is created.
+func (su *ServiceUpdater) applyPrometheusMetadata(local, remote *corev1.Service) {
+	if !su.cfg.PrometheusMetadata {
+		return
+	}
+	annotationKeys := sets.New(su.cfg.PrometheusAnnotations...)
+	desired := prometheusAnnotations(remote, annotationKeys, sets.New(su.cfg.PrometheusPorts...))
+	applyManagedAnnotations(local, annotationKeys, desired)
+}
+
+// prometheusAnnotations returns the allowlisted annotations of the remote service.
+// When a port allowlist is configured and the scrape port is not allowed, no annotations are
+// returned so Prometheus does not scrape the service at all.
+func prometheusAnnotations(remote *corev1.Service, annotationKeys, allowedPorts sets.Set[string]) map[string]string {
+	desired := make(map[string]string)
+	for key, value := range remote.Annotations {
+		if annotationKeys.Has(key) {
+			desired[key] = value
+		}
+	}
+
+	if allowedPorts.Len() == 0 {
+		return desired
+	}
+
+	// Without an explicit port Prometheus scrapes every service port
+	port := desired[prometheusPortAnnotation]
+	if !isPrometheusPortAllowed(remote, port, allowedPorts) {
+		klog.V(4).Infof("Prometheus scrape port %q of service %s/%s is not allowed, skipping annotations",
+			port, remote.Namespace, remote.Name)
+		return map[string]string{}
+	}
+	return desired
+}
+
+// isPrometheusPortAllowed reports whether the scrape port matches an allowlisted port name or number.
+// An empty port is only allowed when every port of the service is allowed.
+func isPrometheusPortAllowed(svc *corev1.Service, port string, allowedPorts sets.Set[string]) bool {
+	if port == "" {
+		for _, svcPort := range svc.Spec.Ports {
+			if !allowedPorts.Has(svcPort.Name) && !allowedPorts.Has(strconv.Itoa(int(svcPort.Port))) {
+				return false
+			}
+		}
+		return len(svc.Spec.Ports) > 0
+	}
+
+	if allowedPorts.Has(port) {
+		return true
+	}
+	for _, svcPort := range svc.Spec.Ports {
+		if strconv.Itoa(int(svcPort.Port)) == port || svcPort.TargetPort.String() == port {
+			return allowedPorts.Has(svcPort.Name)
+		}
+	}
+	return false
+}
+
+// applyManagedAnnotations sets the managed annotation keys of the service to the desired values,
+// removing managed keys that are no longer desired. It returns whether anything changed.
+func applyManagedAnnotations(svc *corev1.Service, managedKeys sets.Set[string], desired map[string]string) bool {
+	changed := false
+	for key := range managedKeys {
+		value, want := desired[key]
+		current, has := svc.Annotations[key]
+		switch {
+		case want && (!has || current != value):
+			if svc.Annotations == nil {
+				svc.Annotations = make(map[string]string)
+			}
+			svc.Annotations[key] = value
+			changed = true
+		case !want && has:
+			delete(svc.Annotations, key)
+			changed = true
+		}
+	}
+	return changed
+}
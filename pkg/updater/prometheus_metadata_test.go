@@ -0,0 +1,93 @@
+package updater
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+func TestPrometheusAnnotations(t *testing.T) {
+	remote := func(annotations map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api", Annotations: annotations},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)},
+				{Name: "metrics", Port: 9100, TargetPort: intstr.FromInt32(9100)},
+			}},
+		}
+	}
+	scrape := map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "9100", "team": "payments"}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		allowedPorts []string
+		expected     int
+	}{
+		{name: "no port allowlist", annotations: scrape, expected: 2},
+		{name: "allowed port name", annotations: scrape, allowedPorts: []string{"metrics"}, expected: 2},
+		{name: "allowed port number", annotations: scrape, allowedPorts: []string{"9100"}, expected: 2},
+		{name: "disallowed port", annotations: scrape, allowedPorts: []string{"http"}},
+		{name: "every port scraped", annotations: map[string]string{"prometheus.io/scrape": "true"}, allowedPorts: []string{"metrics"}},
+		{name: "every port allowed", annotations: map[string]string{"prometheus.io/scrape": "true"}, allowedPorts: []string{"http", "metrics"}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			su := NewServiceUpdater(nil, &config.Config{
+				PrometheusMetadata:    true,
+				PrometheusAnnotations: config.DefaultPrometheusAnnotations,
+				PrometheusPorts:       tt.allowedPorts,
+			})
+			local := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"prometheus.io/path": "/stale"}}}
+			su.applyPrometheusMetadata(local, remote(tt.annotations))
+			if len(local.Annotations) != tt.expected {
+				t.Errorf("expected %d scrape annotations, got %v", tt.expected, local.Annotations)
+			}
+		})
+	}
+}
+
+func TestCreateMissingServicePrometheusMetadata(t *testing.T) {
+	serviceInfo := &discoverer.ServiceInfo{Namespace: "payments", Name: "api", Service: &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "payments",
+			Name:        "api",
+			Annotations: map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "9100", "team": "payments"},
+		},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "metrics", Port: 9100}}},
+	}}
+
+	fake := &singleStackClient{}
+	su := NewServiceUpdater(fake, &config.Config{
+		PrometheusMetadata:    true,
+		PrometheusAnnotations: config.DefaultPrometheusAnnotations,
+		PrometheusPorts:       []string{"http"},
+	})
+	if err := su.createMissingService(context.Background(), "payments", "api", serviceInfo); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.created) != 1 {
+		t.Fatalf("expected the service to be created, got %d creates", len(fake.created))
+	}
+	annotations := fake.created[0].Annotations
+	if _, ok := annotations["prometheus.io/scrape"]; ok || annotations["team"] != "payments" {
+		t.Errorf("expected the scrape annotations of a disallowed port to be left out from the start, got %v", annotations)
+	}
+	if _, ok := serviceInfo.Service.Annotations[config.SyncAnnotation]; ok {
+		t.Error("expected the annotations of the remote service to be left as they are")
+	}
+
+	// The created service is up to date
+	updating := &updatingClient{}
+	su.ctrlClient = updating
+	if err := su.updateSyncedService(context.Background(), fake.created[0], serviceInfo); err != nil || len(updating.updated) != 0 {
+		t.Errorf("expected the created service not to be written again, got %d updates (err %v)", len(updating.updated), err)
+	}
+}
@@ -0,0 +1,52 @@
+package updater
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rateLimitedClient spreads the writes of a client over time, so that a large remote cluster connecting does not
+// burst hundreds of writes at the local API server. Reads are served as they come.
+type rateLimitedClient struct {
+	client.Client
+	limiter *rate.Limiter
+}
+
+// newRateLimitedClient returns a client writing at most qps objects per second with bursts of burst writes.
+// A qps of 0 leaves writes unlimited.
+func newRateLimitedClient(c client.Client, qps float64, burst int) client.Client {
+	if qps <= 0 {
+		return c
+	}
+	return &rateLimitedClient{Client: c, limiter: rate.NewLimiter(rate.Limit(qps), max(burst, 1))}
+}
+
+func (c *rateLimitedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *rateLimitedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *rateLimitedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *rateLimitedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
@@ -0,0 +1,51 @@
+package updater
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// countingClient counts the objects written through it
+type countingClient struct {
+	client.Client
+	writes int
+}
+
+func (c *countingClient) Create(context.Context, client.Object, ...client.CreateOption) error {
+	c.writes++
+	return nil
+}
+
+func (c *countingClient) Delete(context.Context, client.Object, ...client.DeleteOption) error {
+	c.writes++
+	return nil
+}
+
+func TestRateLimitedClient(t *testing.T) {
+	counting := &countingClient{}
+	if newRateLimitedClient(counting, 0, 10) != client.Client(counting) {
+		t.Error("expected writes to be left unlimited without a rate")
+	}
+
+	limited := newRateLimitedClient(counting, 0.1, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	slice := &discoveryv1.EndpointSlice{}
+	if err := limited.Create(ctx, slice); err != nil {
+		t.Fatalf("first write of the burst failed: %v", err)
+	}
+	if err := limited.Delete(ctx, slice); err != nil {
+		t.Fatalf("second write of the burst failed: %v", err)
+	}
+	if err := limited.Create(ctx, slice); err == nil {
+		t.Error("expected the write past the burst to wait longer than the context allows")
+	}
+	if counting.writes != 2 {
+		t.Errorf("expected 2 writes to reach the API server, got %d", counting.writes)
+	}
+}
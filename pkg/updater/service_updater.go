@@ -2,13 +2,18 @@ package updater
 
 import (
 	"context"
+	"maps"
+	"slices"
 
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
 	"github.com/cloudpilot-ai/svclink/pkg/config"
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apiserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
@@ -16,11 +21,13 @@ import (
 
 type ServiceUpdater struct {
 	ctrlClient client.Client
+	cfg        *config.Config
 }
 
-func NewServiceUpdater(ctrlClient client.Client) *ServiceUpdater {
+func NewServiceUpdater(ctrlClient client.Client, cfg *config.Config) *ServiceUpdater {
 	return &ServiceUpdater{
 		ctrlClient: ctrlClient,
+		cfg:        cfg,
 	}
 }
 
@@ -46,18 +53,22 @@ func (su *ServiceUpdater) SyncServicesToLocalCluster(ctx context.Context, servic
 			klog.Infof("Created namespace %s as it does not exist in local cluster", ns)
 		}
 
-		existingServices, err := su.getExistingServiceNames(ctx, ns)
+		existingServices, err := su.getExistingServices(ctx, ns)
 		if err != nil {
 			return err
 		}
 
 		for _, name := range serviceNames {
-			if _, exists := existingServices[name]; exists {
+			serviceInfo := services[ns+"/"+name]
+			if serviceInfo == nil {
 				continue
 			}
 
-			serviceInfo := services[ns+"/"+name]
-			if serviceInfo == nil {
+			if existing, exists := existingServices[name]; exists {
+				// A failed update leaves the Service as it is, its EndpointSlices are still synced
+				if err := su.updateSyncedService(ctx, existing, serviceInfo); err != nil {
+					klog.Errorf("Failed to update service %s/%s: %v", ns, name, err)
+				}
 				continue
 			}
 
@@ -86,15 +97,15 @@ func (su *ServiceUpdater) groupServicesByNamespace(services map[string]*discover
 	return namespaceServiceMap
 }
 
-// getExistingServiceNames retrieves the names of existing services in the specified namespace.
-func (su *ServiceUpdater) getExistingServiceNames(ctx context.Context, namespace string) (map[string]struct{}, error) {
+// getExistingServices retrieves the existing services in the specified namespace, keyed by name.
+func (su *ServiceUpdater) getExistingServices(ctx context.Context, namespace string) (map[string]*corev1.Service, error) {
 	svcList := &corev1.ServiceList{}
 	if err := su.ctrlClient.List(ctx, svcList, client.InNamespace(namespace)); err != nil {
 		return nil, err
 	}
 
-	return lo.SliceToMap(svcList.Items, func(svc corev1.Service) (string, struct{}) {
-		return svc.Name, struct{}{}
+	return lo.SliceToMap(svcList.Items, func(svc corev1.Service) (string, *corev1.Service) {
+		return svc.Name, &svc
 	}), nil
 }
 
@@ -104,28 +115,143 @@ func (su *ServiceUpdater) createMissingService(ctx context.Context, namespace, n
 		return nil
 	}
 
-	annotations := serviceInfo.Service.Annotations
-	if annotations == nil {
-		annotations = make(map[string]string)
-	}
-	annotations[config.SyncAnnotation] = "true"
-
 	newSvc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
 			Namespace:   namespace,
-			Labels:      serviceInfo.Service.Labels,
-			Annotations: annotations,
-		},
-		Spec: corev1.ServiceSpec{
-			Ports:    serviceInfo.Service.Spec.Ports,
-			Selector: serviceInfo.Service.Spec.Selector,
+			Annotations: maps.Clone(serviceInfo.Service.Annotations),
 		},
 	}
+	su.applyRemoteSpec(newSvc, serviceInfo)
+	// The cluster IP of a Service cannot change once it is created
+	if importedHeadless(serviceInfo.Service) {
+		newSvc.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	applyIPFamilies(newSvc, serviceInfo.Service)
+
+	config.MarkSynced(newSvc)
 
-	if err := su.ctrlClient.Create(ctx, newSvc); err != nil {
+	err := su.ctrlClient.Create(ctx, newSvc)
+	if apiserrors.IsInvalid(err) && len(newSvc.Spec.IPFamilies) > 0 {
+		// The IP families of the remote service may not be configured in the local cluster
+		klog.Warningf("Creating service %s/%s with the IP families of the cluster as its IP families %v are invalid: %v",
+			namespace, name, newSvc.Spec.IPFamilies, err)
+		newSvc.Spec.IPFamilies = nil
+		newSvc.Spec.IPFamilyPolicy = nil
+		err = su.ctrlClient.Create(ctx, newSvc)
+	}
+	if err != nil {
 		return err
 	}
+	if len(serviceInfo.SourceNamespaces) > 0 {
+		klog.Infof("Created service %s/%s as it exists in remote clusters, mapped from namespaces %v",
+			namespace, name, serviceInfo.SourceNamespaces)
+		return nil
+	}
 	klog.Infof("Created service %s/%s as it exists in remote clusters", namespace, name)
 	return nil
 }
+
+// updateSyncedService propagates the changes of the remote service to the local service svclink created from it.
+// Services created by users are never modified.
+func (su *ServiceUpdater) updateSyncedService(ctx context.Context, local *corev1.Service, serviceInfo *discoverer.ServiceInfo) error {
+	if serviceInfo.Service == nil || !config.IsSyncedService(local) {
+		return nil
+	}
+
+	if headless := importedHeadless(serviceInfo.Service); headless != (local.Spec.ClusterIP == corev1.ClusterIPNone) {
+		klog.V(2).Infof("Service %s/%s should be headless: %v, delete it to recreate it as it cannot change once created",
+			local.Namespace, local.Name, headless)
+	}
+
+	updated := local.DeepCopy()
+	su.applyRemoteSpec(updated, serviceInfo)
+	if equality.Semantic.DeepEqual(local, updated) {
+		return nil
+	}
+
+	if err := su.ctrlClient.Update(ctx, updated); err != nil {
+		return err
+	}
+	klog.Infof("Updated service %s/%s as it changed in remote clusters", local.Namespace, local.Name)
+	return nil
+}
+
+// applyRemoteSpec sets the labels and spec fields svclink keeps in sync with the remote service on a local service.
+// Annotations are copied once when the Service is created, as svclink and users annotate local Services.
+// The ports of the clusters exporting the service are merged under the port merge policy.
+// With StripSelectors, the selector is left out so that svclink is the only source of endpoints of the Service.
+func (su *ServiceUpdater) applyRemoteSpec(local *corev1.Service, serviceInfo *discoverer.ServiceInfo) {
+	remote := serviceInfo.Service
+	applySyncedLabels(local, remote)
+	su.applyPrometheusMetadata(local, remote)
+	local.Spec.Ports = slices.Clone(remote.Spec.Ports)
+	// A Service needs ports, clusters without a port in common keep those of the remote service
+	if ports, _, ok := aggregator.MergeServicePorts(serviceInfo, su.cfg.PortMergePolicy); ok && len(ports) > 0 {
+		local.Spec.Ports = ports
+	}
+	local.Spec.Selector = maps.Clone(remote.Spec.Selector)
+	if su.cfg.StripSelectors {
+		local.Spec.Selector = nil
+	}
+	// Routing fields that can change once the Service is created; appProtocol is copied with the ports
+	local.Spec.SessionAffinity = remote.Spec.SessionAffinity
+	local.Spec.SessionAffinityConfig = remote.Spec.SessionAffinityConfig.DeepCopy()
+	local.Spec.TrafficDistribution = remote.Spec.TrafficDistribution
+}
+
+// applySyncedLabels copies the labels of the remote service onto a local service. Labels set by users or other
+// controllers are left alone: only the keys svclink copied before and the remote service dropped are removed.
+func applySyncedLabels(local, remote *corev1.Service) {
+	for _, key := range config.SyncedLabels(local) {
+		if _, ok := remote.Labels[key]; !ok {
+			delete(local.Labels, key)
+		}
+	}
+	if len(remote.Labels) > 0 && local.Labels == nil {
+		local.Labels = make(map[string]string, len(remote.Labels))
+	}
+	maps.Copy(local.Labels, remote.Labels)
+	config.MarkSyncedLabels(local, slices.Sorted(maps.Keys(remote.Labels)))
+}
+
+// applyIPFamilies sets the IP families of the remote service on a local service being created. They are not kept in
+// sync as the primary IP family of a Service cannot change. RequireDualStack is relaxed to PreferDualStack so that
+// the Service can be created in a single-stack local cluster.
+func applyIPFamilies(local, remote *corev1.Service) {
+	local.Spec.IPFamilies = slices.Clone(remote.Spec.IPFamilies)
+	local.Spec.IPFamilyPolicy = remote.Spec.IPFamilyPolicy
+	if ptr.Deref(local.Spec.IPFamilyPolicy, "") == corev1.IPFamilyPolicyRequireDualStack {
+		local.Spec.IPFamilyPolicy = ptr.To(corev1.IPFamilyPolicyPreferDualStack)
+	}
+}
+
+// importedHeadless returns whether the local Service created for a remote service is headless: as set by the
+// headless annotation of the remote service, else as the remote service is.
+func importedHeadless(remote *corev1.Service) bool {
+	headless, ok, err := config.Headless(remote)
+	if err != nil {
+		klog.Warning(err)
+	} else if ok {
+		return headless
+	}
+	return remote.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
+// DeleteSyncedService deletes a local service that svclink created from a remote service.
+// Services svclink did not create are left alone.
+func (su *ServiceUpdater) DeleteSyncedService(ctx context.Context, namespace, name string) error {
+	svc := &corev1.Service{}
+	if err := su.ctrlClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, svc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !config.IsSyncedService(svc) {
+		return nil
+	}
+
+	if err := su.ctrlClient.Delete(ctx, svc); err != nil && !apiserrors.IsNotFound(err) {
+		return err
+	}
+	klog.Infof("Deleted service %s/%s as no remote cluster exposes it anymore", namespace, name)
+	return nil
+}
@@ -0,0 +1,206 @@
+package updater
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/apis/discoverer"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// updatingClient records the Services updated through it
+type updatingClient struct {
+	client.Client
+	updated []*corev1.Service
+}
+
+func (c *updatingClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	c.updated = append(c.updated, obj.(*corev1.Service))
+	return nil
+}
+
+// singleStackClient creates Services in a single-stack IPv4 cluster
+type singleStackClient struct {
+	client.Client
+	created []*corev1.Service
+}
+
+func (c *singleStackClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	svc := obj.(*corev1.Service)
+	if len(svc.Spec.IPFamilies) > 1 || slices.Contains(svc.Spec.IPFamilies, corev1.IPv6Protocol) {
+		return apiserrors.NewInvalid(schema.GroupKind{Kind: "Service"}, svc.Name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "ipFamilies"), svc.Spec.IPFamilies, "not configured on this cluster"),
+		})
+	}
+	c.created = append(c.created, svc.DeepCopy())
+	return nil
+}
+
+func TestCreateMissingServiceIPFamilies(t *testing.T) {
+	remote := func(policy corev1.IPFamilyPolicy, families ...corev1.IPFamily) *discoverer.ServiceInfo {
+		return &discoverer.ServiceInfo{Namespace: "payments", Name: "api", Service: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api"},
+			Spec: corev1.ServiceSpec{
+				Ports:          []corev1.ServicePort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP}},
+				IPFamilies:     families,
+				IPFamilyPolicy: ptr.To(policy),
+			},
+		}}
+	}
+
+	fake := &singleStackClient{}
+	su := NewServiceUpdater(fake, &config.Config{})
+	if err := su.createMissingService(context.Background(), "payments", "api",
+		remote(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol)); err != nil {
+		t.Fatal(err)
+	}
+	if err := su.createMissingService(context.Background(), "payments", "api",
+		remote(corev1.IPFamilyPolicyRequireDualStack, corev1.IPv6Protocol, corev1.IPv4Protocol)); err != nil {
+		t.Fatalf("expected a dual-stack service to be created in a single-stack cluster: %v", err)
+	}
+	if len(fake.created) != 2 {
+		t.Fatalf("expected 2 services to be created, got %d", len(fake.created))
+	}
+	if families := fake.created[0].Spec.IPFamilies; len(families) != 1 || families[0] != corev1.IPv4Protocol {
+		t.Errorf("expected the IP families of the remote service, got %v", families)
+	}
+	if dualStack := fake.created[1].Spec; dualStack.IPFamilies != nil || dualStack.IPFamilyPolicy != nil {
+		t.Errorf("expected the IP families to be left to the cluster, got %v %v", dualStack.IPFamilies, dualStack.IPFamilyPolicy)
+	}
+}
+
+func TestApplyIPFamilies(t *testing.T) {
+	local := &corev1.Service{}
+	applyIPFamilies(local, &corev1.Service{Spec: corev1.ServiceSpec{
+		IPFamilies:     []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+		IPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyRequireDualStack),
+	}})
+	if ptr.Deref(local.Spec.IPFamilyPolicy, "") != corev1.IPFamilyPolicyPreferDualStack || len(local.Spec.IPFamilies) != 2 {
+		t.Errorf("expected RequireDualStack to be relaxed, got %v %v", local.Spec.IPFamilyPolicy, local.Spec.IPFamilies)
+	}
+}
+
+func TestUpdateSyncedService(t *testing.T) {
+	remote := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api", Labels: map[string]string{"app": "api", "tier": "backend"}},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "api"},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8081), Protocol: corev1.ProtocolTCP},
+				{Name: "grpc", Port: 9090, TargetPort: intstr.FromInt32(9090), Protocol: corev1.ProtocolTCP},
+			},
+			SessionAffinity:     corev1.ServiceAffinityClientIP,
+			TrafficDistribution: ptr.To(corev1.ServiceTrafficDistributionPreferClose),
+		},
+	}
+	local := func(synced bool) *corev1.Service {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "payments",
+				Name:        "api",
+				Labels:      map[string]string{"app": "api", "owner": "sre"},
+				Annotations: map[string]string{config.PinnedClustersAnnotation: "east"},
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "api"},
+				Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080), Protocol: corev1.ProtocolTCP}},
+			},
+		}
+		if synced {
+			config.MarkSynced(svc)
+		}
+		return svc
+	}
+	serviceInfo := &discoverer.ServiceInfo{Namespace: "payments", Name: "api", Service: remote}
+
+	fake := &updatingClient{}
+	su := NewServiceUpdater(fake, &config.Config{})
+	if err := su.updateSyncedService(context.Background(), local(false), serviceInfo); err != nil || len(fake.updated) != 0 {
+		t.Fatalf("expected user-created services to be left alone, got %d updates (err %v)", len(fake.updated), err)
+	}
+
+	if err := su.updateSyncedService(context.Background(), local(true), serviceInfo); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.updated) != 1 {
+		t.Fatalf("expected the synced service to be updated once, got %d updates", len(fake.updated))
+	}
+	updated := fake.updated[0]
+	if len(updated.Spec.Ports) != 2 || updated.Spec.Ports[0].TargetPort.IntVal != 8081 || updated.Labels["tier"] != "backend" {
+		t.Errorf("expected the remote ports and labels, got %+v %v", updated.Spec.Ports, updated.Labels)
+	}
+	if updated.Spec.SessionAffinity != corev1.ServiceAffinityClientIP || ptr.Deref(updated.Spec.TrafficDistribution, "") != corev1.ServiceTrafficDistributionPreferClose {
+		t.Errorf("expected the remote session affinity and traffic distribution, got %+v", updated.Spec)
+	}
+	if !config.IsSyncedService(updated) || updated.Annotations[config.PinnedClustersAnnotation] != "east" {
+		t.Errorf("expected the local annotations to be kept, got %v", updated.Annotations)
+	}
+
+	if updated.Labels["owner"] != "sre" {
+		t.Errorf("expected the labels set in the local cluster to be kept, got %v", updated.Labels)
+	}
+
+	if err := su.updateSyncedService(context.Background(), updated, serviceInfo); err != nil || len(fake.updated) != 1 {
+		t.Errorf("expected an up to date service not to be written again, got %d updates (err %v)", len(fake.updated), err)
+	}
+
+	// Only the labels copied from the remote service are removed once it drops them
+	dropped := remote.DeepCopy()
+	delete(dropped.Labels, "tier")
+	if err := su.updateSyncedService(context.Background(), updated,
+		&discoverer.ServiceInfo{Namespace: "payments", Name: "api", Service: dropped}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.updated) != 2 {
+		t.Fatalf("expected the dropped label to be removed, got %d updates", len(fake.updated))
+	}
+	if labels := fake.updated[1].Labels; labels["tier"] != "" || labels["owner"] != "sre" || labels["app"] != "api" {
+		t.Errorf("expected the tier label to be removed and the others kept, got %v", labels)
+	}
+	updated = fake.updated[1]
+
+	stripping := NewServiceUpdater(fake, &config.Config{StripSelectors: true})
+	if err := stripping.updateSyncedService(context.Background(), updated, serviceInfo); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.updated) != 3 || fake.updated[2].Spec.Selector != nil {
+		t.Errorf("expected the selector to be stripped from the synced service, got %d updates", len(fake.updated))
+	}
+}
+
+func TestImportedHeadless(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterIP   string
+		annotations map[string]string
+		expected    bool
+	}{
+		{name: "cluster IP", clusterIP: "10.96.0.10"},
+		{name: "headless", clusterIP: corev1.ClusterIPNone, expected: true},
+		{name: "forced headless", clusterIP: "10.96.0.10", annotations: map[string]string{config.HeadlessAnnotation: "true"}, expected: true},
+		{name: "forced cluster IP", clusterIP: corev1.ClusterIPNone, annotations: map[string]string{config.HeadlessAnnotation: "false"}},
+		{name: "invalid annotation", clusterIP: corev1.ClusterIPNone, annotations: map[string]string{config.HeadlessAnnotation: "yes"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api", Annotations: tt.annotations},
+				Spec:       corev1.ServiceSpec{ClusterIP: tt.clusterIP},
+			}
+			if got := importedHeadless(remote); got != tt.expected {
+				t.Errorf("expected headless %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -5,65 +5,89 @@ package updater
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
 	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/logging"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
 )
 
 // SliceUpdater updates EndpointSlices in the local cluster
 type SliceUpdater struct {
 	kubeClient client.Client
+	// apiReader reads EndpointSlices past the cache after an update conflict
+	apiReader client.Reader
+	recorder  record.EventRecorder
+	published publishedChecksums
+	// maxEndpoints is the maximum number of endpoints published in one EndpointSlice
+	maxEndpoints int
+	// mirrorEndpoints mirrors the imported endpoints of selectorless services into core/v1 Endpoints
+	mirrorEndpoints bool
 }
 
 // NewSliceUpdater creates a new SliceUpdater
-func NewSliceUpdater(ctrlClient client.Client) *SliceUpdater {
+func NewSliceUpdater(ctrlClient client.Client, apiReader client.Reader, cfg *config.Config,
+	recorder record.EventRecorder) *SliceUpdater {
 	return &SliceUpdater{
-		kubeClient: ctrlClient,
+		kubeClient:      newRateLimitedClient(ctrlClient, cfg.SliceWriteQPS, cfg.SliceWriteBurst),
+		apiReader:       apiReader,
+		recorder:        recorder,
+		maxEndpoints:    cfg.MaxEndpointsPerSlice,
+		mirrorEndpoints: cfg.MirrorEndpoints,
 	}
 }
 
-// UpdateEndpointSlices creates or updates EndpointSlices for each remote cluster
+// UpdateEndpointSlices creates or updates EndpointSlices for each remote cluster.
+// EndpointSlices of retained clusters are never deleted. A failed write doesn't stop the other
+// clusters from being updated, the errors of all failed writes are returned together.
 func (su *SliceUpdater) UpdateEndpointSlices(
 	ctx context.Context,
 	namespace, serviceName string,
 	clusterEndpoints []aggregator.ClusterEndpoints,
+	retainedClusters sets.Set[string],
 ) error {
+	var errs []error
 	for _, ce := range clusterEndpoints {
-		if err := su.updateSliceForCluster(ctx, namespace, serviceName, ce); err != nil {
-			klog.Errorf("Failed to update EndpointSlice for cluster %s, service %s/%s: %v",
-				ce.ClusterName, namespace, serviceName, err)
-			// Continue with other clusters even if one fails
+		for _, chunk := range su.chunks(serviceName, ce) {
+			if err := su.updateSliceForCluster(ctx, namespace, serviceName, chunk.name, chunk.endpoints); err != nil {
+				// Continue with other clusters even if one fails
+				errs = append(errs, fmt.Errorf("failed to update EndpointSlice %s for cluster %s: %w", chunk.name, ce.ClusterName, err))
+			}
 		}
 	}
 
 	// Clean up EndpointSlices for clusters that no longer have endpoints
-	if err := su.cleanupOrphanedSlices(ctx, namespace, serviceName, clusterEndpoints); err != nil {
-		klog.Errorf("Failed to cleanup orphaned slices for service %s/%s: %v", namespace, serviceName, err)
+	if err := su.cleanupOrphanedSlices(ctx, namespace, serviceName, clusterEndpoints, retainedClusters); err != nil {
+		errs = append(errs, fmt.Errorf("failed to cleanup orphaned EndpointSlices: %w", err))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// updateSliceForCluster creates or updates an EndpointSlice for a specific cluster
+// updateSliceForCluster creates or updates an EndpointSlice holding endpoints of a specific cluster
 func (su *SliceUpdater) updateSliceForCluster(
 	ctx context.Context,
-	namespace, serviceName string,
+	namespace, serviceName, sliceName string,
 	ce aggregator.ClusterEndpoints,
 ) error {
-	sliceName := fmt.Sprintf("%s-svclink-%s", serviceName, ce.ClusterName)
-
 	// Get the service to set as owner reference
 	service := &corev1.Service{}
 	serviceKey := client.ObjectKey{Namespace: namespace, Name: serviceName}
@@ -81,65 +105,114 @@ func (su *SliceUpdater) updateSliceForCluster(
 
 	slice := &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      sliceName,
-			Namespace: namespace,
-			Labels: map[string]string{
-				config.ServiceNameLabel: serviceName,
-				config.ClusterLabel:     ce.ClusterName,
-				config.ManagedByLabel:   config.ManagedByValue,
-			},
+			Name:            sliceName,
+			Namespace:       namespace,
 			OwnerReferences: []metav1.OwnerReference{ownerRef},
 		},
-		AddressType: discoveryv1.AddressTypeIPv4,
+		AddressType: ce.AddressType,
 		Endpoints:   ce.Endpoints,
 		Ports:       ce.Ports,
 	}
+	if err := config.MarkManaged(slice, serviceName, ce.ClusterName); err != nil {
+		return err
+	}
+	config.MarkSourceNamespace(slice, ce.SourceNamespace)
+	config.MarkSourceSlices(slice, sourceSlices(ce.SourceSlices))
+	config.MarkLastSynced(slice, time.Now())
 
-	// Try to get existing slice
-	existing := &discoveryv1.EndpointSlice{}
-	sliceKey := client.ObjectKey{Namespace: namespace, Name: sliceName}
-	if err := su.kubeClient.Get(ctx, sliceKey, existing); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to get EndpointSlice: %w", err)
-		}
-		// Create new slice
-		if err = su.kubeClient.Create(ctx, slice); err != nil {
+	create := func() error {
+		if err := su.kubeClient.Create(ctx, slice); err != nil {
 			return fmt.Errorf("failed to create EndpointSlice: %w", err)
 		}
-		klog.Infof("Created EndpointSlice %s/%s for cluster %s with %d endpoints",
-			namespace, sliceName, ce.ClusterName, len(ce.Endpoints))
+		su.published.set(namespace+"/"+sliceName, endpointsChecksum(ce.AddressType, ce.Endpoints, ce.Ports))
+		klog.Infof("Created EndpointSlice %s/%s for cluster %s with %d %s endpoints",
+			namespace, sliceName, ce.ClusterName, len(ce.Endpoints), ce.AddressType)
 		return nil
 	}
 
-	// Update existing slice
-	existing.Endpoints = ce.Endpoints
-	existing.Ports = ce.Ports
-	if existing.Labels == nil {
-		existing.Labels = make(map[string]string)
-	}
-	existing.Labels[config.ServiceNameLabel] = serviceName
-	existing.Labels[config.ClusterLabel] = ce.ClusterName
-	existing.Labels[config.ManagedByLabel] = config.ManagedByValue
+	// Update the existing slice, reading it again past the cache when a concurrent
+	// writer changed it between the read and the update
+	sliceKey := client.ObjectKey{Namespace: namespace, Name: sliceName}
+	reader := client.Reader(su.kubeClient)
+	created := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing := &discoveryv1.EndpointSlice{}
+		if err := reader.Get(ctx, sliceKey, existing); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get EndpointSlice: %w", err)
+			}
+			created = true
+			return create()
+		}
 
-	if err := su.kubeClient.Update(ctx, existing); err != nil {
-		return fmt.Errorf("failed to update EndpointSlice: %w", err)
+		original := existing.DeepCopy()
+
+		// Refuse to take over slices of other controllers with the same name
+		if err := config.MarkManaged(existing, serviceName, ce.ClusterName); err != nil {
+			return fmt.Errorf("refusing to update EndpointSlice: %w", err)
+		}
+		if existing.AddressType != ce.AddressType {
+			// The address type of an EndpointSlice is immutable, replace the slice instead
+			if err := su.kubeClient.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to replace %s EndpointSlice: %w", existing.AddressType, err)
+			}
+			created = true
+			return create()
+		}
+		config.MarkSourceNamespace(existing, ce.SourceNamespace)
+		config.ClearStale(existing)
+		config.MarkSourceSlices(existing, sourceSlices(ce.SourceSlices))
+		existing.Endpoints = ce.Endpoints
+		existing.Ports = ce.Ports
+
+		// The last synced time only moves when the slice changes, so that unchanged slices are not written
+		if equality.Semantic.DeepEqual(original, existing) {
+			return nil
+		}
+		config.MarkLastSynced(existing, time.Now())
+		if err := su.kubeClient.Update(ctx, existing); err != nil {
+			if su.apiReader != nil {
+				reader = su.apiReader
+			}
+			return fmt.Errorf("failed to update EndpointSlice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			if su.recorder != nil {
+				su.recorder.Eventf(service, corev1.EventTypeWarning, "EndpointSliceConflict",
+					"EndpointSlice %s for cluster %s kept conflicting with concurrent updates: %v",
+					sliceName, ce.ClusterName, err)
+			}
+		}
+		return err
 	}
+	if created {
+		return nil
+	}
+	su.published.set(namespace+"/"+sliceName, endpointsChecksum(ce.AddressType, ce.Endpoints, ce.Ports))
 
-	klog.V(4).Infof("Updated EndpointSlice %s/%s for cluster %s with %d endpoints",
+	logging.V(4, ce.ClusterName, namespace, namespace+"/"+serviceName).Infof("Updated EndpointSlice %s/%s for cluster %s with %d endpoints",
 		namespace, sliceName, ce.ClusterName, len(ce.Endpoints))
 	return nil
 }
 
-// cleanupOrphanedSlices removes EndpointSlices for clusters that are no longer active
+// cleanupOrphanedSlices removes EndpointSlices for clusters and address types that are no longer active, the
+// extra chunks of clusters whose endpoints shrank, and the slices of legacy names replaced by hashed names
 func (su *SliceUpdater) cleanupOrphanedSlices(
 	ctx context.Context,
 	namespace, serviceName string,
 	activeClusterEndpoints []aggregator.ClusterEndpoints,
+	retainedClusters sets.Set[string],
 ) error {
-	// Build set of active clusters
-	activeClusters := sets.NewString(lo.Map(activeClusterEndpoints, func(ce aggregator.ClusterEndpoints, _ int) string {
-		return ce.ClusterName
-	})...)
+	// Build set of active slices, including every chunk of the endpoints of a cluster
+	activeSlices := sets.New[string]()
+	for _, ce := range activeClusterEndpoints {
+		activeSlices.Insert(lo.Map(su.chunks(serviceName, ce), func(chunk sliceChunk, _ int) string {
+			return chunk.name
+		})...)
+	}
 
 	// List all EndpointSlices for this service with cluster label
 	selector := labels.SelectorFromSet(labels.Set{
@@ -161,14 +234,13 @@ func (su *SliceUpdater) cleanupOrphanedSlices(
 		return err
 	}
 
-	// Delete slices for inactive clusters
+	// Delete slices for inactive clusters and address types
 	for _, slice := range sliceList.Items {
-		if slice.Labels == nil {
+		if !config.IsManagedByUs(&slice) || activeSlices.Has(slice.Name) {
 			continue
 		}
-
-		clusterName := slice.Labels[config.ClusterLabel]
-		if activeClusters.Has(clusterName) {
+		clusterName, _ := config.SourceCluster(&slice)
+		if retainedClusters.Has(clusterName) {
 			continue
 		}
 
@@ -176,8 +248,114 @@ func (su *SliceUpdater) cleanupOrphanedSlices(
 			return fmt.Errorf("failed to delete orphaned EndpointSlice %s/%s: %w",
 				namespace, slice.Name, err)
 		}
+		su.published.delete(namespace + "/" + slice.Name)
 		klog.Infof("Deleted orphaned EndpointSlice %s/%s for cluster %s", namespace, slice.Name, clusterName)
 	}
 
 	return nil
 }
+
+// DeleteClusterSlices deletes the EndpointSlices imported from a cluster in every namespace
+func (su *SliceUpdater) DeleteClusterSlices(ctx context.Context, cluster string) error {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := su.kubeClient.List(ctx, sliceList, client.MatchingLabels{config.ClusterLabel: config.ClusterLabelValue(cluster)}); err != nil {
+		return err
+	}
+
+	for _, slice := range sliceList.Items {
+		// Hashed cluster label values may be shared by clusters with long names, the annotation tells them apart
+		if source, _ := config.SourceCluster(&slice); !config.IsManagedByUs(&slice) || source != cluster {
+			continue
+		}
+		if err := su.kubeClient.Delete(ctx, &slice); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete EndpointSlice %s/%s: %w", slice.Namespace, slice.Name, err)
+		}
+		su.published.delete(slice.Namespace + "/" + slice.Name)
+		klog.Infof("Deleted EndpointSlice %s/%s of removed cluster %s", slice.Namespace, slice.Name, cluster)
+	}
+	return nil
+}
+
+// MarkClusterSlicesStale marks the EndpointSlices imported from a cluster unreachable since the given time with
+// the stale since annotation. Their endpoints are left as they are.
+func (su *SliceUpdater) MarkClusterSlicesStale(ctx context.Context, cluster string, since time.Time) error {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := su.kubeClient.List(ctx, sliceList, client.MatchingLabels{config.ClusterLabel: config.ClusterLabelValue(cluster)}); err != nil {
+		return err
+	}
+
+	for i := range sliceList.Items {
+		slice := &sliceList.Items[i]
+		if source, _ := config.SourceCluster(slice); !config.IsManagedByUs(slice) || source != cluster {
+			continue
+		}
+		original := slice.DeepCopy()
+		if !config.MarkStale(slice, since) {
+			continue
+		}
+		if err := su.kubeClient.Patch(ctx, slice, client.MergeFrom(original)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to mark EndpointSlice %s/%s stale: %w", slice.Namespace, slice.Name, err)
+		}
+		klog.Infof("Keeping the last known endpoints of EndpointSlice %s/%s, cluster %s is unreachable since %s",
+			slice.Namespace, slice.Name, cluster, since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CollectGarbage deletes the EndpointSlices managed by svclink, across all namespaces, that keep does not retain,
+// and returns the number of deleted EndpointSlices
+func (su *SliceUpdater) CollectGarbage(ctx context.Context, keep func(slice *discoveryv1.EndpointSlice) bool) (int, error) {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := su.kubeClient.List(ctx, sliceList, client.MatchingLabels{config.ManagedByLabel: config.ManagedByValue}); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range sliceList.Items {
+		slice := &sliceList.Items[i]
+		if keep(slice) {
+			continue
+		}
+		if err := su.kubeClient.Delete(ctx, slice); err != nil && !apierrors.IsNotFound(err) {
+			return deleted, fmt.Errorf("failed to delete EndpointSlice %s/%s: %w", slice.Namespace, slice.Name, err)
+		}
+		su.published.delete(slice.Namespace + "/" + slice.Name)
+		metrics.GarbageCollectedSlices.WithLabelValues(slice.Namespace).Inc()
+		cluster, _ := config.SourceCluster(slice)
+		klog.Infof("Garbage collected EndpointSlice %s/%s of service %s imported from cluster %s",
+			slice.Namespace, slice.Name, slice.Labels[config.ServiceNameLabel], cluster)
+		deleted++
+	}
+	return deleted, nil
+}
+
+// DeleteServiceSlices deletes the EndpointSlices imported for a local service from every cluster
+func (su *SliceUpdater) DeleteServiceSlices(ctx context.Context, namespace, serviceName string) error {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := su.kubeClient.List(ctx, sliceList, client.InNamespace(namespace),
+		client.MatchingLabels{config.ServiceNameLabel: serviceName}); err != nil {
+		return err
+	}
+
+	for _, slice := range sliceList.Items {
+		if !config.IsManagedByUs(&slice) {
+			continue
+		}
+		if err := su.kubeClient.Delete(ctx, &slice); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete EndpointSlice %s/%s: %w", slice.Namespace, slice.Name, err)
+		}
+		su.published.delete(slice.Namespace + "/" + slice.Name)
+		klog.Infof("Deleted EndpointSlice %s/%s of orphaned service %s", slice.Namespace, slice.Name, serviceName)
+	}
+	return nil
+}
+
+// sourceSlices formats the remote EndpointSlices endpoints were read from as comma-separated
+// name=resourceVersion/generation
+func sourceSlices(sources []aggregator.SourceSlice) string {
+	parts := make([]string, 0, len(sources))
+	for _, source := range sources {
+		parts = append(parts, fmt.Sprintf("%s=%s/%d", source.Name, source.ResourceVersion, source.Generation))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,255 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+)
+
+// conflictingClient serves one managed EndpointSlice and rejects its first updates with conflicts
+type conflictingClient struct {
+	client.Client
+	conflicts int
+	updates   int
+	updated   *discoveryv1.EndpointSlice
+}
+
+func (c *conflictingClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	switch o := obj.(type) {
+	case *corev1.Service:
+		o.ObjectMeta = metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, UID: "uid"}
+	case *discoveryv1.EndpointSlice:
+		o.ObjectMeta = metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}
+		o.AddressType = discoveryv1.AddressTypeIPv4
+		if err := config.MarkManaged(o, "web", "east"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *conflictingClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	c.updates++
+	if c.updates <= c.conflicts {
+		return apierrors.NewConflict(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"},
+			obj.GetName(), nil)
+	}
+	c.updated = obj.(*discoveryv1.EndpointSlice)
+	return nil
+}
+
+func (c *conflictingClient) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return nil
+}
+
+// countingReader counts the reads past the cache
+type countingReader struct {
+	client.Reader
+	gets int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestUpdateSliceForClusterConflicts(t *testing.T) {
+	ce := aggregator.ClusterEndpoints{
+		ClusterName: "east",
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+		Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To(int32(80))}},
+	}
+
+	t.Run("retries with fresh reads", func(t *testing.T) {
+		fake := &conflictingClient{conflicts: 2}
+		reader := &countingReader{Reader: fake}
+		recorder := record.NewFakeRecorder(10)
+		su := NewSliceUpdater(fake, reader, &config.Config{MaxEndpointsPerSlice: 100}, recorder)
+
+		if err := su.updateSliceForCluster(context.Background(), "default", "web", "web-svclink-abc", ce); err != nil {
+			t.Fatalf("update should succeed after retries: %v", err)
+		}
+		if fake.updates != 3 {
+			t.Errorf("expected 3 update attempts, got %d", fake.updates)
+		}
+		if reader.gets != 2 {
+			t.Errorf("expected every retry to read past the cache, got %d reads", reader.gets)
+		}
+		if fake.updated == nil || len(fake.updated.Endpoints) != 1 {
+			t.Errorf("expected the endpoints to be written, got %+v", fake.updated)
+		}
+		if len(recorder.Events) != 0 {
+			t.Errorf("expected no events, got %q", <-recorder.Events)
+		}
+	})
+
+	t.Run("reports persistent conflicts", func(t *testing.T) {
+		fake := &conflictingClient{conflicts: 100}
+		recorder := record.NewFakeRecorder(10)
+		su := NewSliceUpdater(fake, fake, &config.Config{MaxEndpointsPerSlice: 100}, recorder)
+
+		err := su.updateSliceForCluster(context.Background(), "default", "web", "web-svclink-abc", ce)
+		if !apierrors.IsConflict(err) {
+			t.Fatalf("expected a conflict error, got %v", err)
+		}
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, "EndpointSliceConflict") {
+				t.Errorf("unexpected event %q", event)
+			}
+		default:
+			t.Error("expected an EndpointSliceConflict event")
+		}
+	})
+
+	t.Run("returns persistent conflicts to the caller", func(t *testing.T) {
+		fake := &conflictingClient{conflicts: 100}
+		su := NewSliceUpdater(fake, fake, &config.Config{MaxEndpointsPerSlice: 100}, record.NewFakeRecorder(10))
+
+		err := su.UpdateEndpointSlices(context.Background(), "default", "web", []aggregator.ClusterEndpoints{ce}, nil)
+		if !apierrors.IsConflict(err) {
+			t.Fatalf("expected the conflict to be returned once the retries are exhausted, got %v", err)
+		}
+		if fake.updated != nil {
+			t.Errorf("expected no update to succeed, got %+v", fake.updated)
+		}
+	})
+}
+
+func TestSourceSlices(t *testing.T) {
+	got := sourceSlices([]aggregator.SourceSlice{
+		{Name: "web-abc12", ResourceVersion: "1042", Generation: 3},
+		{Name: "web-def34", ResourceVersion: "1057", Generation: 1},
+	})
+	if want := "web-abc12=1042/3,web-def34=1057/1"; got != want {
+		t.Errorf("sourceSlices() = %q, want %q", got, want)
+	}
+	if got := sourceSlices(nil); got != "" {
+		t.Errorf("sourceSlices(nil) = %q, want no sources", got)
+	}
+}
+
+// slicesClient lists fixed EndpointSlices and records deletes
+type slicesClient struct {
+	client.Client
+	slices  []discoveryv1.EndpointSlice
+	deleted []string
+}
+
+func (c *slicesClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	list.(*discoveryv1.EndpointSliceList).Items = c.slices
+	return nil
+}
+
+func (c *slicesClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	c.deleted = append(c.deleted, obj.GetName())
+	return nil
+}
+
+func TestCleanupOrphanedSlicesRetainedClusters(t *testing.T) {
+	managed := func(name, cluster string) discoveryv1.EndpointSlice {
+		slice := discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if err := config.MarkManaged(&slice, "web", cluster); err != nil {
+			t.Fatal(err)
+		}
+		return slice
+	}
+	fake := &slicesClient{slices: []discoveryv1.EndpointSlice{managed("web-paused", "paused"), managed("web-west", "west")}}
+	su := NewSliceUpdater(fake, fake, &config.Config{}, record.NewFakeRecorder(10))
+
+	// Neither cluster has endpoints anymore, the slices of the paused cluster are left as they are
+	if err := su.UpdateEndpointSlices(context.Background(), "default", "web", nil, sets.New("paused")); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != "web-west" {
+		t.Errorf("deleted %v, want web-west only", fake.deleted)
+	}
+}
+
+// rejectingClient serves a Service without EndpointSlices and rejects the creation of the slices of one cluster
+type rejectingClient struct {
+	client.Client
+	rejected string
+	created  []string
+}
+
+func (c *rejectingClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	if o, ok := obj.(*corev1.Service); ok {
+		o.ObjectMeta = metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, UID: "uid"}
+		return nil
+	}
+	return apierrors.NewNotFound(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, key.Name)
+}
+
+func (c *rejectingClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	if cluster, _ := config.SourceCluster(obj.(*discoveryv1.EndpointSlice)); cluster == c.rejected {
+		return apierrors.NewForbidden(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"},
+			obj.GetName(), errors.New("denied by admission webhook"))
+	}
+	c.created = append(c.created, obj.GetName())
+	return nil
+}
+
+func (c *rejectingClient) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return nil
+}
+
+func TestUpdateEndpointSlicesFailedWrites(t *testing.T) {
+	endpoints := func(cluster string) aggregator.ClusterEndpoints {
+		return aggregator.ClusterEndpoints{
+			ClusterName: cluster,
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+		}
+	}
+	fake := &rejectingClient{rejected: "east"}
+	su := NewSliceUpdater(fake, fake, &config.Config{MaxEndpointsPerSlice: 100}, record.NewFakeRecorder(10))
+
+	err := su.UpdateEndpointSlices(context.Background(), "default", "web",
+		[]aggregator.ClusterEndpoints{endpoints("east"), endpoints("west")}, nil)
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "cluster east") {
+		t.Fatalf("expected the failed write of east to be returned, got %v", err)
+	}
+	if len(fake.created) != 1 {
+		t.Errorf("expected the slice of west to be written despite the failure of east, created %v", fake.created)
+	}
+}
+
+func TestDeleteClusterSlices(t *testing.T) {
+	var slices []discoveryv1.EndpointSlice
+	for _, namespace := range []string{"orders", "payments"} {
+		slice := discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "api-west"}}
+		if err := config.MarkManaged(&slice, "api", "west"); err != nil {
+			t.Fatal(err)
+		}
+		slices = append(slices, slice)
+	}
+	fake := &slicesClient{slices: slices}
+	su := NewSliceUpdater(fake, fake, &config.Config{}, record.NewFakeRecorder(10))
+	su.published.set("payments/api-west", "checksum")
+
+	if err := su.DeleteClusterSlices(context.Background(), "west"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.deleted) != 2 {
+		t.Errorf("deleted %v, want the slices of west in every namespace", fake.deleted)
+	}
+	if _, ok := su.published.get("payments/api-west"); ok {
+		t.Error("expected the deleted slice to be forgotten")
+	}
+}
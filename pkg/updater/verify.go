@@ -0,0 +1,169 @@
+package updater
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudpilot-ai/svclink/pkg/aggregator"
+	"github.com/cloudpilot-ai/svclink/pkg/config"
+	"github.com/cloudpilot-ai/svclink/pkg/metrics"
+)
+
+const (
+	// mismatchMissing means a slice that should exist was not found in the local cluster
+	mismatchMissing = "Missing"
+	// mismatchModified means a slice was changed by someone else since svclink last wrote it
+	mismatchModified = "Modified"
+	// mismatchDiverged means a slice does not match the remote cluster and svclink has no record of writing it
+	mismatchDiverged = "Diverged"
+	// mismatchOrphaned means a managed slice exists for a cluster or address type without remote endpoints
+	mismatchOrphaned = "Orphaned"
+)
+
+// publishedChecksums records the checksum of the endpoints svclink last wrote to each slice,
+// keyed by namespace/name, to tell remote changes apart from local modifications
+type publishedChecksums struct {
+	mu        sync.Mutex
+	checksums map[string]string
+}
+
+func (pc *publishedChecksums) get(key string) (string, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	checksum, ok := pc.checksums[key]
+	return checksum, ok
+}
+
+func (pc *publishedChecksums) set(key, checksum string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if pc.checksums == nil {
+		pc.checksums = make(map[string]string)
+	}
+	pc.checksums[key] = checksum
+}
+
+func (pc *publishedChecksums) delete(key string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	delete(pc.checksums, key)
+}
+
+// ModifiedExternally reports whether the endpoints or ports of a managed EndpointSlice differ from those svclink
+// last published to it. EndpointSlices svclink did not publish to since it started are reported as modified.
+func (su *SliceUpdater) ModifiedExternally(slice *discoveryv1.EndpointSlice) bool {
+	published, ok := su.published.get(slice.Namespace + "/" + slice.Name)
+	return !ok || published != endpointsChecksum(slice.AddressType, slice.Endpoints, slice.Ports)
+}
+
+// DeletedExternally reports whether a deleted managed EndpointSlice is one svclink still publishes to
+func (su *SliceUpdater) DeletedExternally(namespace, name string) bool {
+	_, ok := su.published.get(namespace + "/" + name)
+	return ok
+}
+
+// VerifyEndpointSlices compares the managed EndpointSlices of a service in the local cluster with
+// endpoints freshly read from the remote clusters and reports every discrepancy. Discrepancies
+// are repaired by the UpdateEndpointSlices call that follows in the same sync.
+func (su *SliceUpdater) VerifyEndpointSlices(
+	ctx context.Context,
+	namespace, serviceName string,
+	clusterEndpoints []aggregator.ClusterEndpoints,
+	retainedClusters sets.Set[string],
+) (int, error) {
+	sliceList := &discoveryv1.EndpointSliceList{}
+	if err := su.kubeClient.List(ctx, sliceList, client.InNamespace(namespace),
+		client.MatchingLabels(labels.Set{config.ServiceNameLabel: serviceName})); err != nil {
+		return 0, err
+	}
+	local := make(map[string]*discoveryv1.EndpointSlice)
+	for i := range sliceList.Items {
+		slice := &sliceList.Items[i]
+		if config.IsManagedByUs(slice) {
+			local[slice.Name] = slice
+		}
+	}
+
+	mismatches := 0
+	report := func(cluster, sliceName, reason string) {
+		mismatches++
+		metrics.VerificationMismatches.WithLabelValues(cluster, reason).Inc()
+		klog.Warningf("Verification mismatch for EndpointSlice %s/%s of cluster %s: %s", namespace, sliceName, cluster, reason)
+	}
+
+	for _, ce := range clusterEndpoints {
+		for _, chunk := range su.chunks(serviceName, ce) {
+			slice, ok := local[chunk.name]
+			if !ok {
+				// A slice of a legacy name is replaced by the update that follows
+				if _, legacy := local[chunk.legacyName]; legacy {
+					delete(local, chunk.legacyName)
+					continue
+				}
+				report(ce.ClusterName, chunk.name, mismatchMissing)
+				continue
+			}
+			delete(local, chunk.name)
+
+			localChecksum := endpointsChecksum(slice.AddressType, slice.Endpoints, slice.Ports)
+			if published, ok := su.published.get(namespace + "/" + chunk.name); ok {
+				if localChecksum != published {
+					report(ce.ClusterName, chunk.name, mismatchModified)
+				}
+				continue
+			}
+			if localChecksum != endpointsChecksum(chunk.endpoints.AddressType, chunk.endpoints.Endpoints, chunk.endpoints.Ports) {
+				report(ce.ClusterName, chunk.name, mismatchDiverged)
+			}
+		}
+	}
+
+	for sliceName, slice := range local {
+		cluster, _ := config.SourceCluster(slice)
+		if retainedClusters.Has(cluster) {
+			continue
+		}
+		report(cluster, sliceName, mismatchOrphaned)
+	}
+
+	return mismatches, nil
+}
+
+// endpointsChecksum returns a checksum of the endpoints and ports of a slice that does not depend on their order
+func endpointsChecksum(addressType discoveryv1.AddressType, endpoints []discoveryv1.Endpoint, ports []discoveryv1.EndpointPort) string {
+	sortedEndpoints := append([]discoveryv1.Endpoint(nil), endpoints...)
+	sort.Slice(sortedEndpoints, func(i, j int) bool {
+		return strings.Join(sortedEndpoints[i].Addresses, ",") < strings.Join(sortedEndpoints[j].Addresses, ",")
+	})
+	sortedPorts := append([]discoveryv1.EndpointPort(nil), ports...)
+	sort.Slice(sortedPorts, func(i, j int) bool {
+		return portKey(sortedPorts[i]) < portKey(sortedPorts[j])
+	})
+
+	data, _ := json.Marshal(struct {
+		AddressType discoveryv1.AddressType
+		Endpoints   []discoveryv1.Endpoint
+		Ports       []discoveryv1.EndpointPort
+	}{addressType, sortedEndpoints, sortedPorts})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func portKey(port discoveryv1.EndpointPort) string {
+	data, _ := json.Marshal(port)
+	return string(data)
+}
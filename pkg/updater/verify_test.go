@@ -0,0 +1,56 @@
+package updater
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestEndpointsChecksum(t *testing.T) {
+	a := discoveryv1.Endpoint{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}}
+	b := discoveryv1.Endpoint{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}}
+	http := discoveryv1.EndpointPort{Name: ptr.To("http"), Port: ptr.To(int32(80))}
+	https := discoveryv1.EndpointPort{Name: ptr.To("https"), Port: ptr.To(int32(443))}
+
+	base := endpointsChecksum(discoveryv1.AddressTypeIPv4, []discoveryv1.Endpoint{a, b}, []discoveryv1.EndpointPort{http, https})
+
+	if reordered := endpointsChecksum(discoveryv1.AddressTypeIPv4, []discoveryv1.Endpoint{b, a}, []discoveryv1.EndpointPort{https, http}); reordered != base {
+		t.Error("checksum should not depend on endpoint and port order")
+	}
+	if removed := endpointsChecksum(discoveryv1.AddressTypeIPv4, []discoveryv1.Endpoint{a}, []discoveryv1.EndpointPort{http, https}); removed == base {
+		t.Error("checksum should change when an endpoint is removed")
+	}
+	notReady := b
+	notReady.Conditions.Ready = ptr.To(false)
+	if changed := endpointsChecksum(discoveryv1.AddressTypeIPv4, []discoveryv1.Endpoint{a, notReady}, []discoveryv1.EndpointPort{http, https}); changed == base {
+		t.Error("checksum should change when endpoint conditions change")
+	}
+}
+
+func TestModifiedExternally(t *testing.T) {
+	su := &SliceUpdater{}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: "payments", Name: "api-svclink-abc"},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+	}
+
+	if !su.ModifiedExternally(slice) || su.DeletedExternally(slice.Namespace, slice.Name) {
+		t.Error("an EndpointSlice svclink never published to should only be reported as modified")
+	}
+
+	su.published.set("payments/api-svclink-abc", endpointsChecksum(slice.AddressType, slice.Endpoints, slice.Ports))
+	if su.ModifiedExternally(slice) {
+		t.Error("the endpoints svclink published should not be reported as modified")
+	}
+	if !su.DeletedExternally(slice.Namespace, slice.Name) {
+		t.Error("the deletion of an EndpointSlice svclink publishes to should be reported")
+	}
+
+	slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{Addresses: []string{"10.0.0.2"}})
+	if !su.ModifiedExternally(slice) {
+		t.Error("added endpoints should be reported as modified")
+	}
+}